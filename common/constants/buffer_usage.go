@@ -0,0 +1,9 @@
+package constants
+
+type BufferUsage uint8
+
+const (
+	StaticDraw  BufferUsage = 0 // data is set once, and drawn many times (default)
+	DynamicDraw BufferUsage = 1 // data is changed repeatedly, and drawn many times
+	StreamDraw  BufferUsage = 2 // data is changed for (almost) every frame
+)
@@ -0,0 +1,255 @@
+package common
+
+import (
+	"math"
+)
+
+// ----------------------------------------------------------------------------
+// Curve (Bezier, Elliptical Arc, Catmull-Rom and B-Spline)
+// ----------------------------------------------------------------------------
+
+type Curve struct {
+	nspans   int                                  // number of spans (pieces between the control points)
+	closed   bool                                 // closed curve (end of the last span is the start of the first span)
+	evaluate func(span int, t float32) [3]float32 // point on the span, with its parameter t in [0,1]
+}
+
+func NewCurveQuadraticBezier(p0 [3]float32, p1 [3]float32, p2 [3]float32) *Curve {
+	evaluate := func(span int, t float32) [3]float32 {
+		a, b, c := (1-t)*(1-t), 2*(1-t)*t, t*t
+		return [3]float32{a*p0[0] + b*p1[0] + c*p2[0], a*p0[1] + b*p1[1] + c*p2[1], a*p0[2] + b*p1[2] + c*p2[2]}
+	}
+	return &Curve{nspans: 1, closed: false, evaluate: evaluate}
+}
+
+func NewCurveCubicBezier(p0 [3]float32, p1 [3]float32, p2 [3]float32, p3 [3]float32) *Curve {
+	evaluate := func(span int, t float32) [3]float32 {
+		a, b, c, d := (1-t)*(1-t)*(1-t), 3*(1-t)*(1-t)*t, 3*(1-t)*t*t, t*t*t
+		return [3]float32{
+			a*p0[0] + b*p1[0] + c*p2[0] + d*p3[0],
+			a*p0[1] + b*p1[1] + c*p2[1] + d*p3[1],
+			a*p0[2] + b*p1[2] + c*p2[2] + d*p3[2]}
+	}
+	return &Curve{nspans: 1, closed: false, evaluate: evaluate}
+}
+
+func NewCurveEllipticalArc(center [3]float32, radius [2]float32, rotation_in_degree float32,
+	start_angle_in_degree float32, end_angle_in_degree float32) *Curve {
+	// Elliptical arc on the XY plane, from the start angle to the end angle (counter-clockwise, if end > start),
+	//   with the radii along its own axes, rotated by 'rotation_in_degree'.
+	sweep := float64(end_angle_in_degree - start_angle_in_degree)
+	closed := math.Abs(sweep) >= 360
+	if closed {
+		sweep = math.Copysign(360, sweep)
+	}
+	nspans := int(math.Ceil(math.Abs(sweep) / 90)) // (a span doesn't bend more than 90 degree)
+	if nspans < 1 {
+		nspans = 1
+	}
+	rot := float64(rotation_in_degree) * (math.Pi / 180)
+	cos_r, sin_r := math.Cos(rot), math.Sin(rot)
+	evaluate := func(span int, t float32) [3]float32 {
+		angle := float64(start_angle_in_degree) + sweep*(float64(span)+float64(t))/float64(nspans)
+		if closed && span == nspans-1 && t == 1 {
+			angle = float64(start_angle_in_degree) // (exactly the same as the start point)
+		}
+		x := float64(radius[0]) * math.Cos(angle*(math.Pi/180))
+		y := float64(radius[1]) * math.Sin(angle*(math.Pi/180))
+		return [3]float32{center[0] + float32(cos_r*x-sin_r*y), center[1] + float32(sin_r*x+cos_r*y), center[2]}
+	}
+	return &Curve{nspans: nspans, closed: closed, evaluate: evaluate}
+}
+
+func NewCurveCatmullRom(points [][3]float32, closed bool) *Curve {
+	// Smooth curve through the points (by centripetal Catmull-Rom spline), with a span between each pair of points
+	n := len(points)
+	if n < 2 || (closed && n < 3) {
+		return &Curve{nspans: 0, closed: closed, evaluate: nil}
+	}
+	get_point := func(i int) [3]float32 {
+		if closed {
+			return points[(i+n)%n]
+		} else if i < 0 { // mirrored end points
+			return [3]float32{2*points[0][0] - points[1][0], 2*points[0][1] - points[1][1], 2*points[0][2] - points[1][2]}
+		} else if i >= n {
+			return [3]float32{2*points[n-1][0] - points[n-2][0], 2*points[n-1][1] - points[n-2][1], 2*points[n-1][2] - points[n-2][2]}
+		}
+		return points[i]
+	}
+	get_knot := func(t float32, a [3]float32, b [3]float32) float32 {
+		dx, dy, dz := b[0]-a[0], b[1]-a[1], b[2]-a[2]
+		d := float32(math.Sqrt(float64(dx*dx + dy*dy + dz*dz)))
+		if d < 1e-6 {
+			d = 1e-6
+		}
+		return t + float32(math.Sqrt(float64(d)))
+	}
+	lerp := func(a [3]float32, b [3]float32, ta float32, tb float32, t float32) [3]float32 {
+		wa, wb := (tb-t)/(tb-ta), (t-ta)/(tb-ta)
+		return [3]float32{wa*a[0] + wb*b[0], wa*a[1] + wb*b[1], wa*a[2] + wb*b[2]}
+	}
+	nspans := n - 1
+	if closed {
+		nspans = n
+	}
+	evaluate := func(span int, t float32) [3]float32 {
+		if t == 0 {
+			return get_point(span)
+		} else if t == 1 {
+			return get_point(span + 1)
+		}
+		p0, p1, p2, p3 := get_point(span-1), get_point(span), get_point(span+1), get_point(span+2)
+		t0 := float32(0)
+		t1 := get_knot(t0, p0, p1)
+		t2 := get_knot(t1, p1, p2)
+		t3 := get_knot(t2, p2, p3)
+		t = t1 + (t2-t1)*t // Barry & Goldman's pyramidal formulation
+		a1, a2, a3 := lerp(p0, p1, t0, t1, t), lerp(p1, p2, t1, t2, t), lerp(p2, p3, t2, t3, t)
+		b1, b2 := lerp(a1, a2, t0, t2, t), lerp(a2, a3, t1, t3, t)
+		return lerp(b1, b2, t1, t2, t)
+	}
+	return &Curve{nspans: nspans, closed: closed, evaluate: evaluate}
+}
+
+func NewCurveBSpline(points [][3]float32, degree int, closed bool) *Curve {
+	// Smooth curve (by uniform B-spline) approximating the control points,
+	//   which is clamped to the end points if it's open, or periodic if it's closed.
+	n := len(points)
+	if degree > n-1 {
+		degree = n - 1
+	}
+	if n < 2 || degree < 1 || (closed && n < 3) {
+		return &Curve{nspans: 0, closed: closed, evaluate: nil}
+	}
+	ctrls, knots := points, []float32{}
+	if closed { // periodic, with the first 'degree' control points repeated at the end
+		ctrls = append(append([][3]float32{}, points...), points[:degree]...)
+		for i := 0; i < len(ctrls)+degree+1; i++ {
+			knots = append(knots, float32(i))
+		}
+	} else { // clamped, with the knots repeated at both ends
+		for i := 0; i < n+degree+1; i++ {
+			knots = append(knots, float32(get_clamped_int(i-degree, 0, n-degree)))
+		}
+	}
+	nspans := len(ctrls) - degree
+	evaluate := func(span int, t float32) [3]float32 {
+		if !closed && span == 0 && t == 0 {
+			return points[0]
+		} else if !closed && span == nspans-1 && t == 1 {
+			return points[n-1]
+		}
+		k := span + degree // knot interval [k, k+1]
+		u := knots[k] + (knots[k+1]-knots[k])*t
+		d := make([][3]float32, degree+1)
+		copy(d, ctrls[k-degree:k+1])
+		for r := 1; r <= degree; r++ { // de Boor's algorithm
+			for j := degree; j >= r; j-- {
+				i := j + k - degree
+				alpha := (u - knots[i]) / (knots[i+degree-r+1] - knots[i])
+				for c := 0; c < 3; c++ {
+					d[j][c] = (1-alpha)*d[j-1][c] + alpha*d[j][c]
+				}
+			}
+		}
+		return d[degree]
+	}
+	return &Curve{nspans: nspans, closed: closed, evaluate: evaluate}
+}
+
+func (self *Curve) GetSpanCount() int {
+	return self.nspans
+}
+
+func (self *Curve) IsClosed() bool {
+	return self.closed
+}
+
+func (self *Curve) Evaluate(t float32) [3]float32 {
+	// Point on the curve, with its parameter t in [0,1] (where each span has the same range of t)
+	if self.nspans == 0 {
+		return [3]float32{0, 0, 0}
+	}
+	t = float32(math.Max(0, math.Min(float64(t), 1))) * float32(self.nspans)
+	span := get_clamped_int(int(t), 0, self.nspans-1)
+	return self.evaluate(span, t-float32(span))
+}
+
+// ----------------------------------------------------------------------------
+// Tessellation
+// ----------------------------------------------------------------------------
+
+const curve_max_depth = 12 // maximum depth of the adaptive subdivision (for each span)
+
+func (self *Curve) Subdivide(nsegments int) [][3]float32 {
+	// Points on the curve, with 'nsegments' uniform segments for each span
+	//   (without repeating the first point at the end, if the curve is closed).
+	points := [][3]float32{}
+	for s := 0; s < self.nspans; s++ {
+		for k := 0; k < nsegments; k++ {
+			points = append(points, self.evaluate(s, float32(k)/float32(nsegments)))
+		}
+	}
+	if !self.closed && self.nspans > 0 {
+		points = append(points, self.evaluate(self.nspans-1, 1))
+	}
+	return points
+}
+
+func (self *Curve) Tessellate(tolerance float32, project func(xyz [3]float32) [2]float32) [][3]float32 {
+	// Points on the curve (without repeating the first point at the end, if the curve is closed),
+	//   adaptively subdivided until the segments are within the 'tolerance' from the curve.
+	// If 'project' is given (like WORLD => SCREEN projection), the tolerance is measured in the projected space.
+	if tolerance <= 0 {
+		Logger.Warn("Invalid tolerance (%v) for curve tessellation : 1e-3 is used instead\n", tolerance)
+		tolerance = 1e-3
+	}
+	get_deviation := func(a [3]float32, b [3]float32, m [3]float32) float32 {
+		if project != nil {
+			pa, pb, pm := project(a), project(b), project(m)
+			a, b, m = [3]float32{pa[0], pa[1], 0}, [3]float32{pb[0], pb[1], 0}, [3]float32{pm[0], pm[1], 0}
+		}
+		return get_distance_to_segment(m, a, b)
+	}
+	points := [][3]float32{}
+	var subdivide func(span int, ta float32, pa [3]float32, tb float32, pb [3]float32, depth int)
+	subdivide = func(span int, ta float32, pa [3]float32, tb float32, pb [3]float32, depth int) {
+		tm := (ta + tb) / 2
+		pm := self.evaluate(span, tm)
+		// (always split the first two levels, to catch the S-shaped or the looping pieces)
+		if depth < 2 || (depth < curve_max_depth && get_deviation(pa, pb, pm) > tolerance) {
+			subdivide(span, ta, pa, tm, pm, depth+1)
+			subdivide(span, tm, pm, tb, pb, depth+1)
+		} else {
+			points = append(points, pa)
+		}
+	}
+	for s := 0; s < self.nspans; s++ {
+		subdivide(s, 0, self.evaluate(s, 0), 1, self.evaluate(s, 1), 0)
+	}
+	if !self.closed && self.nspans > 0 {
+		points = append(points, self.evaluate(self.nspans-1, 1))
+	}
+	return points
+}
+
+func get_distance_to_segment(p [3]float32, a [3]float32, b [3]float32) float32 {
+	ab, ap := [3]float32{b[0] - a[0], b[1] - a[1], b[2] - a[2]}, [3]float32{p[0] - a[0], p[1] - a[1], p[2] - a[2]}
+	t, len2 := ab[0]*ap[0]+ab[1]*ap[1]+ab[2]*ap[2], ab[0]*ab[0]+ab[1]*ab[1]+ab[2]*ab[2]
+	if len2 > 0 {
+		t = float32(math.Max(0, math.Min(float64(t/len2), 1)))
+	} else {
+		t = 0
+	}
+	dx, dy, dz := ap[0]-t*ab[0], ap[1]-t*ab[1], ap[2]-t*ab[2]
+	return float32(math.Sqrt(float64(dx*dx + dy*dy + dz*dz)))
+}
+
+func get_clamped_int(v int, vmin int, vmax int) int {
+	if v < vmin {
+		return vmin
+	} else if v > vmax {
+		return vmax
+	}
+	return v
+}
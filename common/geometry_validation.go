@@ -0,0 +1,173 @@
+package common
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ----------------------------------------------------------------------------
+// Geometry Issues (found by Validate() of 2D/3D Geometry)
+// ----------------------------------------------------------------------------
+
+// Kind of the issue is one of INDEX_OUT_OF_RANGE, DEGENERATE_FACE, DUPLICATE_FACE, NON_MANIFOLD_EDGE,
+// INCONSISTENT_WINDING, INVERTED_ORIENTATION, TEXTURE_UV_COUNT, TEXTURE_UV_SIZE, NORMAL_COUNT and INVALID_NORMAL.
+type GeometryIssue struct {
+	Kind    string // kind of the issue (like "INDEX_OUT_OF_RANGE")
+	Element string // VERTEX, EDGE, FACE, TEXTURE or NORMAL
+	Index   int    // index of the element (-1, if it's about all of them)
+	Message string //
+}
+
+func NewGeometryIssue(kind string, element string, index int, message string) GeometryIssue {
+	return GeometryIssue{Kind: kind, Element: element, Index: index, Message: message}
+}
+
+func (self GeometryIssue) String() string {
+	if self.Index < 0 {
+		return fmt.Sprintf("%s : %s", self.Kind, self.Message)
+	}
+	return fmt.Sprintf("%s : %s %d %s", self.Kind, self.Element, self.Index, self.Message)
+}
+
+type RepairOptions struct {
+	FixIndices            bool    // remove the edges & faces with out-of-range vertex indices
+	FixAttributes         bool    // remove (or rebuild) texture UVs & normals that match neither vertices nor faces
+	WeldVertices          bool    // weld the vertices within 'WeldTolerance' (only if their PER_VERT attributes are the same)
+	WeldTolerance         float32 // maximum distance of the vertices to be welded (0 for the same position only)
+	RemoveDegenerateFaces bool    // remove the faces with less than 3 distinct vertices or zero area
+	RemoveDuplicateFaces  bool    // remove the faces with the same vertices as a previous face
+	FixWinding            bool    // flip the faces to make the winding consistent (and facing outward)
+}
+
+func NewRepairOptions() *RepairOptions {
+	// Repair everything, while welding the vertices at the same position only
+	return &RepairOptions{FixIndices: true, FixAttributes: true, WeldVertices: true, WeldTolerance: 0,
+		RemoveDegenerateFaces: true, RemoveDuplicateFaces: true, FixWinding: true}
+}
+
+// ----------------------------------------------------------------------------
+// Face Topology (shared by 2D/3D Geometry)
+// ----------------------------------------------------------------------------
+
+type face_edge struct {
+	key  [2]uint32 // vertex indices of the edge (in increasing order)
+	fidx int       // face
+	fwd  bool      // true, if the face goes from key[0] to key[1]
+}
+
+func get_face_edges(faces [][]uint32) []face_edge {
+	// Edges of all the faces, sorted by their vertices (so that the faces sharing an edge are next to each other)
+	edges := []face_edge{}
+	for fidx, face := range faces {
+		for i := 0; i < len(face); i++ {
+			a, b := face[i], face[(i+1)%len(face)]
+			if a < b {
+				edges = append(edges, face_edge{[2]uint32{a, b}, fidx, true})
+			} else if a > b {
+				edges = append(edges, face_edge{[2]uint32{b, a}, fidx, false})
+			}
+		}
+	}
+	sort.SliceStable(edges, func(i, j int) bool {
+		return edges[i].key[0] < edges[j].key[0] || (edges[i].key[0] == edges[j].key[0] && edges[i].key[1] < edges[j].key[1])
+	})
+	return edges
+}
+
+func for_each_shared_edge(edges []face_edge, callback func(shared []face_edge)) {
+	for i := 0; i < len(edges); {
+		j := i + 1
+		for j < len(edges) && edges[j].key == edges[i].key {
+			j++
+		}
+		callback(edges[i:j])
+		i = j
+	}
+}
+
+func GetDuplicateFaces(faces [][]uint32) []int {
+	// Indices of the faces with the same set of vertices as a previous face (regardless of winding)
+	duplicates := []int{}
+	found := map[string]bool{}
+	for fidx, face := range faces {
+		sorted := append([]uint32{}, face...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		key := fmt.Sprint(sorted)
+		if found[key] {
+			duplicates = append(duplicates, fidx)
+		}
+		found[key] = true
+	}
+	return duplicates
+}
+
+func ValidateFaceTopology(faces [][]uint32) []GeometryIssue {
+	// Find non-manifold edges (shared by more than two faces) and inconsistent winding
+	//   (two faces going through their shared edge in the same direction)
+	//   Note that vertex indices of the faces have to be valid.
+	issues := []GeometryIssue{}
+	for_each_shared_edge(get_face_edges(faces), func(shared []face_edge) {
+		if len(shared) > 2 {
+			issues = append(issues, NewGeometryIssue("NON_MANIFOLD_EDGE", "FACE", shared[0].fidx,
+				fmt.Sprintf("shares the edge (%d,%d) with %d other faces", shared[0].key[0], shared[0].key[1], len(shared)-1)))
+		} else if len(shared) == 2 && shared[0].fwd == shared[1].fwd && shared[0].fidx != shared[1].fidx {
+			issues = append(issues, NewGeometryIssue("INCONSISTENT_WINDING", "FACE", shared[1].fidx,
+				fmt.Sprintf("has the winding inconsistent with face %d", shared[0].fidx)))
+		}
+	})
+	return issues
+}
+
+func GetConsistentWinding(faces [][]uint32) (flip []bool, components [][]int) {
+	// Find the faces to be flipped, to make the winding consistent with their neighbors
+	//   (across the manifold edges), and return them with the connected components of the faces.
+	//   Note that vertex indices of the faces have to be valid.
+	type neighbor struct {
+		fidx int
+		same bool // true, if they go through the shared edge in the same direction
+	}
+	neighbors := make([][]neighbor, len(faces))
+	for_each_shared_edge(get_face_edges(faces), func(shared []face_edge) {
+		if len(shared) == 2 && shared[0].fidx != shared[1].fidx {
+			same := shared[0].fwd == shared[1].fwd
+			neighbors[shared[0].fidx] = append(neighbors[shared[0].fidx], neighbor{shared[1].fidx, same})
+			neighbors[shared[1].fidx] = append(neighbors[shared[1].fidx], neighbor{shared[0].fidx, same})
+		}
+	})
+	flip = make([]bool, len(faces))
+	visited := make([]bool, len(faces))
+	for seed := range faces {
+		if visited[seed] {
+			continue
+		}
+		component := []int{seed}
+		visited[seed] = true
+		for k := 0; k < len(component); k++ { // breadth-first search from the seed face
+			fidx := component[k]
+			for _, n := range neighbors[fidx] {
+				if !visited[n.fidx] {
+					visited[n.fidx] = true
+					flip[n.fidx] = flip[fidx] != n.same
+					component = append(component, n.fidx)
+				}
+			}
+		}
+		components = append(components, component)
+	}
+	return flip, components
+}
+
+func IsClosedSurface(faces [][]uint32, component []int) bool {
+	// Check if every edge of the faces in the component is shared by exactly two of them
+	cfaces := make([][]uint32, len(component))
+	for i, fidx := range component {
+		cfaces[i] = faces[fidx]
+	}
+	closed := len(cfaces) > 0
+	for_each_shared_edge(get_face_edges(cfaces), func(shared []face_edge) {
+		if len(shared) != 2 {
+			closed = false
+		}
+	})
+	return closed
+}
@@ -0,0 +1,525 @@
+package common
+
+import (
+	"math"
+	"sort"
+)
+
+// ----------------------------------------------------------------------------
+// Polygon Triangulation (with holes)
+// ----------------------------------------------------------------------------
+
+// TriangulatePolygon triangulates a polygon given as an outer ring followed by its holes,
+// by bridging the holes into the outer ring and then clipping the ears
+// (based on the 'earcut' algorithm of Mapbox, https://github.com/mapbox/earcut, ISC License).
+// Collinear and duplicate points are skipped, and self-touching rings are split into simple ones.
+// It returns the triangles (in counter-clockwise order) with the indices of the points,
+// as if all the rings were concatenated in the order of 'rings'.
+
+func TriangulatePolygon(rings [][][2]float32) [][3]int {
+	triangles := [][3]int{}
+	if len(rings) == 0 || len(rings[0]) < 3 {
+		return triangles
+	}
+	tr := triangulator{}
+	start := 0
+	var outer *tri_node = nil
+	holes := []*tri_node{}
+	for i, ring := range rings {
+		list := tr.linked_list(ring, start, i == 0)
+		start += len(ring)
+		if i == 0 {
+			outer = list
+		} else if list != nil {
+			if list == list.next {
+				list.steiner = true
+			}
+			holes = append(holes, get_leftmost(list))
+		}
+	}
+	if outer == nil || outer.next == outer.prev {
+		return triangles
+	}
+	if len(holes) > 0 {
+		sort.SliceStable(holes, func(a, b int) bool {
+			return holes[a].x < holes[b].x || (holes[a].x == holes[b].x && holes[a].y < holes[b].y)
+		})
+		for _, hole := range holes {
+			outer = eliminate_hole(hole, outer)
+		}
+	}
+	// z-order curve hashing of the points, for large polygons
+	if len(rings[0]) > 80 {
+		minx, miny, maxx, maxy := outer.x, outer.y, outer.x, outer.y
+		for p := outer.next; p != outer; p = p.next {
+			minx, miny = math.Min(minx, p.x), math.Min(miny, p.y)
+			maxx, maxy = math.Max(maxx, p.x), math.Max(maxy, p.y)
+		}
+		tr.minx, tr.miny = minx, miny
+		if size := math.Max(maxx-minx, maxy-miny); size != 0 {
+			tr.inv_size = 32767 / size
+		}
+	}
+	tr.earcut_linked(outer, 0)
+	for _, t := range tr.triangles {
+		if area := (t[1].x-t[0].x)*(t[2].y-t[0].y) - (t[2].x-t[0].x)*(t[1].y-t[0].y); area < 0 {
+			triangles = append(triangles, [3]int{t[0].i, t[2].i, t[1].i})
+		} else {
+			triangles = append(triangles, [3]int{t[0].i, t[1].i, t[2].i})
+		}
+	}
+	return triangles
+}
+
+type tri_node struct {
+	i            int       // index of the point
+	x, y         float64   // coordinates of the point
+	prev, next   *tri_node // previous & next nodes in the polygon ring
+	z            int32     // z-order curve value
+	prevz, nextz *tri_node // previous & next nodes in z-order
+	steiner      bool      // indicates whether this is a steiner point (a hole of a single point)
+}
+
+type triangulator struct {
+	triangles  [][3]*tri_node //
+	minx, miny float64        // for z-order curve hashing
+	inv_size   float64        // for z-order curve hashing (0, if not used)
+}
+
+func (self *triangulator) linked_list(ring [][2]float32, start int, clockwise bool) *tri_node {
+	// Create a circular doubly linked list from the ring, in the specified winding order
+	sum := 0.0
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		sum += float64(ring[j][0]-ring[i][0]) * float64(ring[i][1]+ring[j][1])
+	}
+	var last *tri_node = nil
+	if clockwise == (sum > 0) {
+		for i := 0; i < len(ring); i++ {
+			last = insert_node(start+i, ring[i], last)
+		}
+	} else {
+		for i := len(ring) - 1; i >= 0; i-- {
+			last = insert_node(start+i, ring[i], last)
+		}
+	}
+	if last != nil && equals(last, last.next) {
+		remove_node(last)
+		last = last.next
+	}
+	return last
+}
+
+func (self *triangulator) earcut_linked(ear *tri_node, pass int) {
+	// Clip the ears of the polygon, or try harder in the next pass, if no more ear is found
+	if ear == nil {
+		return
+	}
+	if pass == 0 && self.inv_size != 0 {
+		self.index_curve(ear)
+	}
+	stop := ear
+	for ear.prev != ear.next {
+		prev, next := ear.prev, ear.next
+		if (self.inv_size != 0 && self.is_ear_hashed(ear)) || (self.inv_size == 0 && is_ear(ear)) {
+			self.triangles = append(self.triangles, [3]*tri_node{prev, ear, next})
+			remove_node(ear)
+			ear, stop = next.next, next.next // skip the next vertex, which leads to less sliver triangles
+			continue
+		}
+		ear = next
+		if ear == stop { // no more ear is found
+			switch pass {
+			case 0: // 1) filter out the collinear or duplicate points
+				self.earcut_linked(filter_points(ear, nil), 1)
+			case 1: // 2) cure the local self-intersections
+				ear = self.cure_local_intersections(filter_points(ear, nil))
+				self.earcut_linked(ear, 2)
+			case 2: // 3) split the polygon into two, and triangulate them separately
+				self.split_earcut(ear)
+			}
+			break
+		}
+	}
+}
+
+func is_ear(ear *tri_node) bool {
+	a, b, c := ear.prev, ear, ear.next
+	if area(a, b, c) >= 0 {
+		return false // reflex
+	}
+	x0, y0 := math.Min(a.x, math.Min(b.x, c.x)), math.Min(a.y, math.Min(b.y, c.y))
+	x1, y1 := math.Max(a.x, math.Max(b.x, c.x)), math.Max(a.y, math.Max(b.y, c.y))
+	for p := c.next; p != a; p = p.next {
+		if p.x >= x0 && p.x <= x1 && p.y >= y0 && p.y <= y1 &&
+			point_in_triangle(a.x, a.y, b.x, b.y, c.x, c.y, p.x, p.y) && area(p.prev, p, p.next) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (self *triangulator) is_ear_hashed(ear *tri_node) bool {
+	a, b, c := ear.prev, ear, ear.next
+	if area(a, b, c) >= 0 {
+		return false // reflex
+	}
+	x0, y0 := math.Min(a.x, math.Min(b.x, c.x)), math.Min(a.y, math.Min(b.y, c.y))
+	x1, y1 := math.Max(a.x, math.Max(b.x, c.x)), math.Max(a.y, math.Max(b.y, c.y))
+	minz, maxz := self.z_order(x0, y0), self.z_order(x1, y1)
+	blocks := func(p *tri_node) bool {
+		return p.x >= x0 && p.x <= x1 && p.y >= y0 && p.y <= y1 && p != a && p != c &&
+			point_in_triangle(a.x, a.y, b.x, b.y, c.x, c.y, p.x, p.y) && area(p.prev, p, p.next) >= 0
+	}
+	// look for the points inside the triangle, in both directions of z-order
+	p, n := ear.prevz, ear.nextz
+	for p != nil && p.z >= minz && n != nil && n.z <= maxz {
+		if blocks(p) || blocks(n) {
+			return false
+		}
+		p, n = p.prevz, n.nextz
+	}
+	for ; p != nil && p.z >= minz; p = p.prevz {
+		if blocks(p) {
+			return false
+		}
+	}
+	for ; n != nil && n.z <= maxz; n = n.nextz {
+		if blocks(n) {
+			return false
+		}
+	}
+	return true
+}
+
+func (self *triangulator) cure_local_intersections(start *tri_node) *tri_node {
+	// Go through all the polygon nodes, and cure the small local self-intersections
+	p := start
+	for {
+		a, b := p.prev, p.next.next
+		if !equals(a, b) && intersects(a, p, p.next, b) && locally_inside(a, b) && locally_inside(b, a) {
+			self.triangles = append(self.triangles, [3]*tri_node{a, p, b})
+			remove_node(p)
+			remove_node(p.next)
+			p, start = b, b
+		}
+		p = p.next
+		if p == start {
+			break
+		}
+	}
+	return filter_points(p, nil)
+}
+
+func (self *triangulator) split_earcut(start *tri_node) {
+	// Look for a valid diagonal that divides the polygon into two
+	a := start
+	for {
+		for b := a.next.next; b != a.prev; b = b.next {
+			if a.i != b.i && is_valid_diagonal(a, b) {
+				c := split_polygon(a, b)
+				a, c = filter_points(a, a.next), filter_points(c, c.next)
+				self.earcut_linked(a, 0)
+				self.earcut_linked(c, 0)
+				return
+			}
+		}
+		a = a.next
+		if a == start {
+			break
+		}
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Eliminating Holes
+// ----------------------------------------------------------------------------
+
+func eliminate_hole(hole *tri_node, outer *tri_node) *tri_node {
+	// Connect the hole to the outer ring with a bridge (two overlapping edges)
+	bridge := find_hole_bridge(hole, outer)
+	if bridge == nil {
+		return outer
+	}
+	bridge_reverse := split_polygon(bridge, hole)
+	filter_points(bridge_reverse, bridge_reverse.next)
+	return filter_points(bridge, bridge.next)
+}
+
+func find_hole_bridge(hole *tri_node, outer *tri_node) *tri_node {
+	// Find the segment of the outer ring, which is the closest to the left of the hole point
+	p, hx, hy, qx := outer, hole.x, hole.y, math.Inf(-1)
+	var m *tri_node = nil
+	for {
+		if hy <= p.y && hy >= p.next.y && p.next.y != p.y {
+			x := p.x + (hy-p.y)*(p.next.x-p.x)/(p.next.y-p.y)
+			if x <= hx && x > qx {
+				qx = x
+				m = p
+				if p.next.x < p.x {
+					m = p.next
+				}
+				if x == hx {
+					return m // the hole touches the outer segment
+				}
+			}
+		}
+		p = p.next
+		if p == outer {
+			break
+		}
+	}
+	if m == nil {
+		return nil
+	}
+	// Look for the points inside the triangle of the hole point, the segment intersection and the endpoint,
+	//   and choose the one with the minimum angle with the ray as the connection point
+	stop, mx, my, tan_min := m, m.x, m.y, math.Inf(1)
+	for p = m; ; {
+		tx0, tx1 := qx, hx
+		if hy < my {
+			tx0, tx1 = hx, qx
+		}
+		if hx >= p.x && p.x >= mx && hx != p.x && point_in_triangle(tx0, hy, mx, my, tx1, hy, p.x, p.y) {
+			tan := math.Abs(hy-p.y) / (hx - p.x)
+			if locally_inside(p, hole) && (tan < tan_min || (tan == tan_min && (p.x > m.x || (p.x == m.x && sector_contains_sector(m, p))))) {
+				m, tan_min = p, tan
+			}
+		}
+		p = p.next
+		if p == stop {
+			break
+		}
+	}
+	return m
+}
+
+func sector_contains_sector(m *tri_node, p *tri_node) bool {
+	return area(m.prev, m, p.prev) < 0 && area(p.next, m, m.next) < 0
+}
+
+func get_leftmost(start *tri_node) *tri_node {
+	leftmost := start
+	for p := start.next; p != start; p = p.next {
+		if p.x < leftmost.x || (p.x == leftmost.x && p.y < leftmost.y) {
+			leftmost = p
+		}
+	}
+	return leftmost
+}
+
+// ----------------------------------------------------------------------------
+// Z-order Curve Hashing
+// ----------------------------------------------------------------------------
+
+func (self *triangulator) index_curve(start *tri_node) {
+	p := start
+	for {
+		if p.z == 0 {
+			p.z = self.z_order(p.x, p.y)
+		}
+		p.prevz, p.nextz = p.prev, p.next
+		p = p.next
+		if p == start {
+			break
+		}
+	}
+	p.prevz.nextz = nil
+	p.prevz = nil
+	sort_linked(p)
+}
+
+func (self *triangulator) z_order(x float64, y float64) int32 {
+	// z-order of the point, with its coordinates mapped to 15-bit integers
+	ix, iy := int32((x-self.minx)*self.inv_size), int32((y-self.miny)*self.inv_size)
+	spread := func(v int32) int32 {
+		v = (v | (v << 8)) & 0x00FF00FF
+		v = (v | (v << 4)) & 0x0F0F0F0F
+		v = (v | (v << 2)) & 0x33333333
+		v = (v | (v << 1)) & 0x55555555
+		return v
+	}
+	return spread(ix) | (spread(iy) << 1)
+}
+
+func sort_linked(list *tri_node) *tri_node {
+	// Merge sort of the linked list by z-order (Simon Tatham's algorithm)
+	for in_size := 1; ; in_size *= 2 {
+		p := list
+		var tail *tri_node = nil
+		list = nil
+		num_merges := 0
+		for p != nil {
+			num_merges++
+			q, p_size := p, 0
+			for i := 0; i < in_size && q != nil; i++ {
+				p_size++
+				q = q.nextz
+			}
+			q_size := in_size
+			for p_size > 0 || (q_size > 0 && q != nil) {
+				var e *tri_node
+				if p_size != 0 && (q_size == 0 || q == nil || p.z <= q.z) {
+					e, p = p, p.nextz
+					p_size--
+				} else {
+					e, q = q, q.nextz
+					q_size--
+				}
+				if tail != nil {
+					tail.nextz = e
+				} else {
+					list = e
+				}
+				e.prevz = tail
+				tail = e
+			}
+			p = q
+		}
+		tail.nextz = nil
+		if num_merges <= 1 {
+			return list
+		}
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Geometric Utilities
+// ----------------------------------------------------------------------------
+
+func area(p *tri_node, q *tri_node, r *tri_node) float64 {
+	// signed area of the triangle (negative, if counter-clockwise)
+	return (q.y-p.y)*(r.x-q.x) - (q.x-p.x)*(r.y-q.y)
+}
+
+func equals(p1 *tri_node, p2 *tri_node) bool {
+	return p1.x == p2.x && p1.y == p2.y
+}
+
+func point_in_triangle(ax, ay, bx, by, cx, cy, px, py float64) bool {
+	return (cx-px)*(ay-py) >= (ax-px)*(cy-py) && (ax-px)*(by-py) >= (bx-px)*(ay-py) && (bx-px)*(cy-py) >= (cx-px)*(by-py)
+}
+
+func is_valid_diagonal(a *tri_node, b *tri_node) bool {
+	// Check if the diagonal (a,b) lies inside the polygon, without intersecting any edge
+	return a.next.i != b.i && a.prev.i != b.i && !intersects_polygon(a, b) &&
+		((locally_inside(a, b) && locally_inside(b, a) && middle_inside(a, b) && (area(a.prev, a, b.prev) != 0 || area(a, b.prev, b) != 0)) ||
+			(equals(a, b) && area(a.prev, a, a.next) > 0 && area(b.prev, b, b.next) > 0))
+}
+
+func intersects(p1 *tri_node, q1 *tri_node, p2 *tri_node, q2 *tri_node) bool {
+	sign := func(v float64) int {
+		if v > 0 {
+			return 1
+		} else if v < 0 {
+			return -1
+		}
+		return 0
+	}
+	on_segment := func(p *tri_node, q *tri_node, r *tri_node) bool {
+		return q.x <= math.Max(p.x, r.x) && q.x >= math.Min(p.x, r.x) && q.y <= math.Max(p.y, r.y) && q.y >= math.Min(p.y, r.y)
+	}
+	o1, o2, o3, o4 := sign(area(p1, q1, p2)), sign(area(p1, q1, q2)), sign(area(p2, q2, p1)), sign(area(p2, q2, q1))
+	return (o1 != o2 && o3 != o4) ||
+		(o1 == 0 && on_segment(p1, p2, q1)) || (o2 == 0 && on_segment(p1, q2, q1)) ||
+		(o3 == 0 && on_segment(p2, p1, q2)) || (o4 == 0 && on_segment(p2, q1, q2))
+}
+
+func intersects_polygon(a *tri_node, b *tri_node) bool {
+	for p := a; ; {
+		if p.i != a.i && p.next.i != a.i && p.i != b.i && p.next.i != b.i && intersects(p, p.next, a, b) {
+			return true
+		}
+		p = p.next
+		if p == a {
+			return false
+		}
+	}
+}
+
+func locally_inside(a *tri_node, b *tri_node) bool {
+	if area(a.prev, a, a.next) < 0 {
+		return area(a, b, a.next) >= 0 && area(a, a.prev, b) >= 0
+	}
+	return area(a, b, a.prev) < 0 || area(a, a.next, b) < 0
+}
+
+func middle_inside(a *tri_node, b *tri_node) bool {
+	inside, px, py := false, (a.x+b.x)/2, (a.y+b.y)/2
+	for p := a; ; {
+		if ((p.y > py) != (p.next.y > py)) && p.next.y != p.y && (px < (p.next.x-p.x)*(py-p.y)/(p.next.y-p.y)+p.x) {
+			inside = !inside
+		}
+		p = p.next
+		if p == a {
+			return inside
+		}
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Linked List Utilities
+// ----------------------------------------------------------------------------
+
+func filter_points(start *tri_node, end *tri_node) *tri_node {
+	// Remove the duplicate or collinear points
+	if start == nil {
+		return start
+	}
+	if end == nil {
+		end = start
+	}
+	p := start
+	for {
+		again := false
+		if !p.steiner && (equals(p, p.next) || area(p.prev, p, p.next) == 0) {
+			remove_node(p)
+			p, end = p.prev, p.prev
+			if p == p.next {
+				break
+			}
+			again = true
+		} else {
+			p = p.next
+		}
+		if !again && p == end {
+			break
+		}
+	}
+	return end
+}
+
+func split_polygon(a *tri_node, b *tri_node) *tri_node {
+	// Link the two nodes with a bridge, splitting the polygon into two
+	//   (or merging the hole into the outer ring), and return the new node of 'b'
+	a2 := &tri_node{i: a.i, x: a.x, y: a.y}
+	b2 := &tri_node{i: b.i, x: b.x, y: b.y}
+	an, bp := a.next, b.prev
+	a.next, b.prev = b, a
+	a2.next, an.prev = an, a2
+	b2.next, a2.prev = a2, b2
+	bp.next, b2.prev = b2, bp
+	return b2
+}
+
+func insert_node(i int, xy [2]float32, last *tri_node) *tri_node {
+	p := &tri_node{i: i, x: float64(xy[0]), y: float64(xy[1])}
+	if last == nil {
+		p.prev, p.next = p, p
+	} else {
+		p.next, p.prev = last.next, last
+		last.next.prev = p
+		last.next = p
+	}
+	return p
+}
+
+func remove_node(p *tri_node) {
+	p.next.prev = p.prev
+	p.prev.next = p.next
+	if p.prevz != nil {
+		p.prevz.nextz = p.nextz
+	}
+	if p.nextz != nil {
+		p.nextz.prevz = p.prevz
+	}
+}
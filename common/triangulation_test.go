@@ -0,0 +1,205 @@
+package common
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"testing"
+)
+
+func TestTriangulatePolygon(t *testing.T) {
+	tests := []struct {
+		name  string
+		rings [][][2]float32
+		area  float64
+	}{
+		{"square", [][][2]float32{{{0, 0}, {1, 0}, {1, 1}, {0, 1}}}, 1},
+		{"clockwise square", [][][2]float32{{{0, 0}, {0, 1}, {1, 1}, {1, 0}}}, 1},
+		{"concave", [][][2]float32{{{0, 0}, {4, 0}, {4, 4}, {2, 1}, {0, 4}}}, 10},
+		{"collinear points", [][][2]float32{{{0, 0}, {1, 0}, {2, 0}, {3, 0}, {3, 1}, {3, 2}, {0, 2}, {0, 1}}}, 6},
+		{"duplicate points", [][][2]float32{{{0, 0}, {0, 0}, {2, 0}, {2, 2}, {2, 2}, {0, 2}, {0, 0}}}, 4},
+		{"square with hole", [][][2]float32{
+			{{0, 0}, {4, 0}, {4, 4}, {0, 4}},
+			{{1, 1}, {3, 1}, {3, 3}, {1, 3}}}, 12},
+		{"clockwise square with clockwise hole", [][][2]float32{
+			{{0, 0}, {0, 4}, {4, 4}, {4, 0}},
+			{{1, 1}, {1, 3}, {3, 3}, {3, 1}}}, 12},
+		{"hole touching the outer ring", [][][2]float32{
+			{{0, 0}, {4, 0}, {4, 4}, {0, 4}},
+			{{0, 1}, {2, 1}, {2, 3}, {0, 3}}}, 12},
+		{"hole touching the outer ring at a vertex", [][][2]float32{
+			{{0, 0}, {4, 0}, {4, 4}, {0, 4}},
+			{{2, 0}, {3, 2}, {1, 2}}}, 14},
+		{"two holes", [][][2]float32{
+			{{0, 0}, {6, 0}, {6, 3}, {0, 3}},
+			{{1, 1}, {2, 1}, {2, 2}, {1, 2}},
+			{{4, 1}, {5, 1}, {5, 2}, {4, 2}}}, 16},
+		{"self-touching ring", [][][2]float32{{{0, 0}, {2, 0}, {2, 2}, {1, 1}, {0, 2}, {1, 1}}}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := check_triangulation(tt.rings, TriangulatePolygon(tt.rings), tt.area, 1e-9); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestTriangulatePolygonWithNaturalEarth(t *testing.T) {
+	// Triangulate all the country polygons of NaturalEarth, where the area of the triangles
+	//   should be the same as the area of the polygon (outer ring minus holes)
+	for _, path := range []string{
+		"../assets/naturalearth/ne_110m_admin_0_countries/ne_110m_admin_0_countries.shp",
+		"../assets/naturalearth/ne_50m_admin_0_countries/ne_50m_admin_0_countries.shp",
+	} {
+		polygons, err := read_shapefile_polygons(path)
+		if err != nil {
+			t.Fatalf("failed to read %s : %v", path, err)
+		}
+		if len(polygons) == 0 {
+			t.Fatalf("no polygons found in %s", path)
+		}
+		for pidx, rings := range polygons {
+			area, tolerance := get_ring_area(rings[0]), 1e-6
+			for _, hole := range rings[1:] {
+				area -= get_ring_area(hole)
+			}
+			if has_crossing_edges(rings) { // (like South Sudan of 110m, whose area is not well defined)
+				t.Logf("%s : polygon %d has crossing edges (area not checked)", path, pidx)
+				tolerance = math.Inf(1)
+			}
+			if err := check_triangulation(rings, TriangulatePolygon(rings), area, tolerance); err != nil {
+				t.Errorf("%s : polygon %d (with %d rings) : %v", path, pidx, len(rings), err)
+			}
+		}
+	}
+}
+
+func check_triangulation(rings [][][2]float32, triangles [][3]int, area float64, tolerance float64) error {
+	// Check the indices & the orientation of the triangles, and compare their total area with the expected one
+	points := [][2]float32{}
+	for _, ring := range rings {
+		points = append(points, ring...)
+	}
+	sum := 0.0
+	for tidx, tri := range triangles {
+		for _, i := range tri {
+			if i < 0 || i >= len(points) {
+				return fmt.Errorf("triangle %d has invalid index %d", tidx, i)
+			}
+		}
+		a := get_ring_area([][2]float32{points[tri[0]], points[tri[1]], points[tri[2]]})
+		if tri[0] == tri[1] || tri[1] == tri[2] || tri[2] == tri[0] || a <= 0 {
+			return fmt.Errorf("triangle %d %v is degenerate or clockwise (area %g)", tidx, tri, a)
+		}
+		sum += a
+	}
+	if math.Abs(sum-area) > tolerance*math.Max(1, math.Abs(area)) {
+		return fmt.Errorf("area of %d triangles is %g, while the polygon has %g", len(triangles), sum, area)
+	}
+	return nil
+}
+
+func get_ring_area(ring [][2]float32) float64 {
+	// Area of the ring (absolute value, regardless of its winding)
+	sum := 0.0
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		sum += float64(ring[j][0])*float64(ring[i][1]) - float64(ring[i][0])*float64(ring[j][1])
+	}
+	return math.Abs(sum) / 2
+}
+
+func read_shapefile_polygons(path string) ([][][][2]float32, error) {
+	// Read the polygons (each with its outer ring followed by its holes) from the ESRI shapefile,
+	//   where outer rings are clockwise and holes are counter-clockwise.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 100 || binary.BigEndian.Uint32(data[0:4]) != 9994 {
+		return nil, fmt.Errorf("invalid shapefile header")
+	}
+	polygons := [][][][2]float32{}
+	for pos := 100; pos+8 <= len(data); {
+		content_length := int(binary.BigEndian.Uint32(data[pos+4:pos+8])) * 2 // in 16-bit words
+		content := data[pos+8 : pos+8+content_length]
+		pos += 8 + content_length
+		if shape_type := binary.LittleEndian.Uint32(content[0:4]); shape_type != 5 { // POLYGON
+			continue
+		}
+		nparts := int(binary.LittleEndian.Uint32(content[36:40]))
+		npoints := int(binary.LittleEndian.Uint32(content[40:44]))
+		parts := make([]int, nparts+1)
+		for i := 0; i < nparts; i++ {
+			parts[i] = int(binary.LittleEndian.Uint32(content[44+i*4 : 48+i*4]))
+		}
+		parts[nparts] = npoints
+		poff := 44 + nparts*4
+		outers, holes := [][][2]float32{}, [][][2]float32{}
+		for i := 0; i < nparts; i++ {
+			ring := [][2]float32{}
+			for k := parts[i]; k < parts[i+1]; k++ {
+				x := math.Float64frombits(binary.LittleEndian.Uint64(content[poff+k*16 : poff+k*16+8]))
+				y := math.Float64frombits(binary.LittleEndian.Uint64(content[poff+k*16+8 : poff+k*16+16]))
+				ring = append(ring, [2]float32{float32(x), float32(y)})
+			}
+			sum := 0.0 // (negative for clockwise rings)
+			for a, b := 0, len(ring)-1; a < len(ring); b, a = a, a+1 {
+				sum += float64(ring[b][0]-ring[a][0]) * float64(ring[a][1]+ring[b][1])
+			}
+			if sum < 0 {
+				outers = append(outers, ring)
+			} else {
+				holes = append(holes, ring)
+			}
+		}
+		rings_list := make([][][][2]float32, len(outers))
+		for i, outer := range outers {
+			rings_list[i] = [][][2]float32{outer}
+		}
+		for _, hole := range holes { // assign each hole to the outer ring containing it
+			for i, outer := range outers {
+				if is_point_in_ring(hole[0], outer) {
+					rings_list[i] = append(rings_list[i], hole)
+					break
+				}
+			}
+		}
+		polygons = append(polygons, rings_list...)
+	}
+	return polygons, nil
+}
+
+func is_point_in_ring(p [2]float32, ring [][2]float32) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a[1] > p[1]) != (b[1] > p[1]) && p[0] < (b[0]-a[0])*(p[1]-a[1])/(b[1]-a[1])+a[0] {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func has_crossing_edges(rings [][][2]float32) bool {
+	// Check whether any two edges of the rings cross each other (touching is not crossing)
+	edges := [][2][2]float32{}
+	for _, ring := range rings {
+		for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+			edges = append(edges, [2][2]float32{ring[j], ring[i]})
+		}
+	}
+	cross := func(o [2]float32, a [2]float32, b [2]float32) float64 {
+		return float64(a[0]-o[0])*float64(b[1]-o[1]) - float64(a[1]-o[1])*float64(b[0]-o[0])
+	}
+	for i := 0; i < len(edges); i++ {
+		for j := i + 1; j < len(edges); j++ {
+			a, b, c, d := edges[i][0], edges[i][1], edges[j][0], edges[j][1]
+			if cross(a, b, c)*cross(a, b, d) < 0 && cross(c, d, a)*cross(c, d, b) < 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
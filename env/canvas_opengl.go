@@ -0,0 +1,22 @@
+//go:build !js
+
+package env
+
+import (
+	"github.com/go4orward/gigl"
+	"github.com/go4orward/gigl/env/opengl41"
+)
+
+// NewCanvas creates the canvas for the environment selected by the build target :
+//   - native targets                     : OpenGL 4.1 canvas on a new window of 'width' x 'height' with 'title'
+//   - 'GOOS=js GOARCH=wasm'              : WebGL 1.0 canvas on the canvas element with 'canvas_id'
+//   - 'GOOS=js GOARCH=wasm -tags webgl2' : WebGL 2.0 canvas on the canvas element with 'canvas_id'
+//
+// Arguments not used by the environment are ignored (like 'canvas_id' for OpenGL, or 'width' for WebGL).
+func NewCanvas(canvas_id string, width int, height int, title string, resizable bool) (gigl.GLCanvas, error) {
+	canvas, err := opengl41.NewOpenGLCanvas(width, height, title, resizable)
+	if err != nil {
+		return nil, err // (not a nil pointer in non-nil interface)
+	}
+	return canvas, nil
+}
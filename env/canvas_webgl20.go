@@ -0,0 +1,18 @@
+//go:build js && wasm && webgl2
+
+package env
+
+import (
+	"github.com/go4orward/gigl"
+	"github.com/go4orward/gigl/env/webgl20"
+)
+
+// NewCanvas creates WebGL 2.0 canvas on the canvas element with 'canvas_id'.
+// (See 'canvas_opengl.go' for the other environments)
+func NewCanvas(canvas_id string, width int, height int, title string, resizable bool) (gigl.GLCanvas, error) {
+	canvas, err := webgl20.NewWebGLCanvas(canvas_id)
+	if err != nil {
+		return nil, err // (not a nil pointer in non-nil interface)
+	}
+	return canvas, nil
+}
@@ -2,11 +2,17 @@ package opengl41
 
 import (
 	"fmt"
+	"image"
+	"image/png"
 	"log"
+	"math"
+	"os"
+	"strings"
 
 	"github.com/go-gl/gl/v4.1-core/gl"
 	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/go4orward/gigl"
+	"github.com/go4orward/gigl/common"
 )
 
 type OpenGLCanvas struct {
@@ -14,6 +20,23 @@ type OpenGLCanvas struct {
 	window *glfw.Window            //
 	rc     *OpenGLRenderingContext //
 	paused bool                    //
+	frame  int                     // number of frames drawn
+
+	mouse_event_common_handler bool    // GLFW mouse callbacks are set
+	mouse_wheel_common_handler bool    // GLFW scroll callback is set
+	mouse_dragging             bool    //
+	mouse_sxy                  [2]int  // where the mouse button was pressed
+	mouse_pxy                  [2]int  // previous cursor position (for 'movementX/Y' of browsers)
+	mouse_click_xy             [2]int  // where the last click happened (for detecting double click)
+	mouse_click_time           float64 // when the last click happened (for detecting double click)
+	mouse_wheel_scale          float64 // in the range of [0 ~ 500(default) ~ 1000]
+	evthandler_for_click       func(canvasxy [2]int, keystat [4]bool)
+	evthandler_for_dblclick    func(canvasxy [2]int, keystat [4]bool)
+	evthandler_for_mouse_over  func(canvasxy [2]int, keystat [4]bool)
+	evthandler_for_mouse_drag  func(canvasxy [2]int, dxy [2]int, keystat [4]bool)
+	evthandler_for_zoom        func(canvasxy [2]int, scale float32, keystat [4]bool)
+	evthandler_for_scroll      func(canvasxy [2]int, dx int, dy int, keystat [4]bool)
+	evthandler_for_resize      func(w int, h int)
 }
 
 var glfw_initialized bool = false
@@ -26,7 +49,11 @@ func NewOpenGLCanvas(width int, height int, title string, resizable bool) (*Open
 		}
 		glfw_initialized = true
 	}
-	glfw.WindowHint(glfw.Resizable, glfw.False)
+	if resizable {
+		glfw.WindowHint(glfw.Resizable, glfw.True)
+	} else {
+		glfw.WindowHint(glfw.Resizable, glfw.False)
+	}
 	glfw.WindowHint(glfw.ContextVersionMajor, 4)
 	glfw.WindowHint(glfw.ContextVersionMinor, 1)
 	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
@@ -46,7 +73,19 @@ func NewOpenGLCanvas(width int, height int, title string, resizable bool) (*Open
 	// create WebGL context
 	self := OpenGLCanvas{window: window, wh: [2]int{width, height}}
 	self.rc = NewOpenGLRenderingContext(width, height)
-
+	self.mouse_wheel_scale = 500 // in the range of [0 ~ 500(default) ~ 1000]
+	if resizable {
+		self.window.SetFramebufferSizeCallback(func(w *glfw.Window, fbw int, fbh int) {
+			// update the viewport (in pixels) and the size of the canvas (in screen coordinates, like 'innerWidth' of browsers)
+			width, height := w.GetSize()
+			self.wh = [2]int{width, height}
+			self.rc.wh = [2]int{width, height}
+			self.rc.GLViewport(0, 0, fbw, fbh)
+			if self.evthandler_for_resize != nil {
+				self.evthandler_for_resize(width, height)
+			}
+		})
+	}
 	return &self, nil
 }
 
@@ -70,28 +109,186 @@ func (self *OpenGLCanvas) SwapBuffers() {
 // User Interactions (Event Handling)
 // ----------------------------------------------------------------------------
 
+// GLFW callbacks are translated into the events of browsers, so that the handlers receive
+// the same values as WebGLCanvas, like 'canvasxy' in screen coordinates, 'dxy' of 'movementX/Y',
+// and 'keystat' of [ altKey, ctrlKey, metaKey, shiftKey ].
+
+func (self *OpenGLCanvas) setup_mouse_event_common_handler() {
+	self.window.SetMouseButtonCallback(func(w *glfw.Window, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
+		cxy := self.get_cursor_xy()
+		keystat := get_keystat_from_mods(mods)
+		switch action {
+		case glfw.Press: // "mousedown"
+			self.mouse_dragging = true
+			self.mouse_sxy = cxy
+		case glfw.Release: // "mouseup", followed by "click" & "dblclick" (for the primary button only)
+			self.mouse_dragging = false
+			if button != glfw.MouseButtonLeft {
+				return
+			}
+			dx, dy := (cxy[0] - self.mouse_sxy[0]), (cxy[1] - self.mouse_sxy[1])
+			if dx < -3 || dx > +3 || dy < -3 || dy > +3 {
+				return // ignore
+			} else if self.evthandler_for_click != nil {
+				self.evthandler_for_click(cxy, keystat)
+			} else {
+				common.Logger.Info("click (%d %d) %v\n", cxy[0], cxy[1], keystat)
+			}
+			now := glfw.GetTime()
+			dx, dy = (cxy[0] - self.mouse_click_xy[0]), (cxy[1] - self.mouse_click_xy[1])
+			if now-self.mouse_click_time < 0.5 && dx >= -3 && dx <= +3 && dy >= -3 && dy <= +3 {
+				self.mouse_click_time = 0 // a triple click is not another double click
+				if self.evthandler_for_dblclick != nil {
+					self.evthandler_for_dblclick(cxy, keystat)
+				} else {
+					common.Logger.Info("dblclick (%d %d) %v\n", cxy[0], cxy[1], keystat)
+				}
+			} else {
+				self.mouse_click_xy, self.mouse_click_time = cxy, now
+			}
+		}
+	})
+	self.window.SetCursorPosCallback(func(w *glfw.Window, xpos float64, ypos float64) {
+		cxy := [2]int{int(xpos), int(ypos)}
+		dxy := [2]int{cxy[0] - self.mouse_pxy[0], cxy[1] - self.mouse_pxy[1]}
+		self.mouse_pxy = cxy
+		if self.mouse_dragging { // "mousemove" while dragging
+			keystat := self.get_keystat()
+			if self.evthandler_for_mouse_drag != nil {
+				self.evthandler_for_mouse_drag(cxy, dxy, keystat)
+			} else {
+				common.Logger.Info("mousemove (%d %d) with %v\n", dxy[0], dxy[1], keystat)
+			}
+		} else { // "mousemove"
+			if self.evthandler_for_mouse_over != nil {
+				self.evthandler_for_mouse_over(cxy, self.get_keystat())
+			}
+		}
+	})
+	self.window.SetCursorEnterCallback(func(w *glfw.Window, entered bool) {
+		if entered {
+			self.mouse_pxy = self.get_cursor_xy() // avoid a big jump of 'dxy' on the next move
+		} else { // "mouseleave"
+			self.mouse_dragging = false
+		}
+	})
+	self.mouse_pxy = self.get_cursor_xy()
+	self.mouse_event_common_handler = true
+}
+
 func (self *OpenGLCanvas) SetEventHandlerForClick(handler func(canvasxy [2]int, keystat [4]bool)) {
+	self.evthandler_for_click = handler
+	if !self.mouse_event_common_handler {
+		self.setup_mouse_event_common_handler()
+	}
 }
 
 func (self *OpenGLCanvas) SetEventHandlerForDoubleClick(handler func(canvasxy [2]int, keystat [4]bool)) {
+	self.evthandler_for_dblclick = handler
+	if !self.mouse_event_common_handler {
+		self.setup_mouse_event_common_handler()
+	}
 }
 
 func (self *OpenGLCanvas) SetEventHandlerForMouseOver(handler func(canvasxy [2]int, keystat [4]bool)) {
+	self.evthandler_for_mouse_over = handler
+	if !self.mouse_event_common_handler {
+		self.setup_mouse_event_common_handler()
+	}
 }
 
 func (self *OpenGLCanvas) SetEventHandlerForMouseDrag(handler func(canvasxy [2]int, dxy [2]int, keystat [4]bool)) {
+	self.evthandler_for_mouse_drag = handler
+	if !self.mouse_event_common_handler {
+		self.setup_mouse_event_common_handler()
+	}
+}
+
+func (self *OpenGLCanvas) setup_mouse_wheel_common_handler() {
+	// For zooming,   'handler()' is given 2nd argument of 'scale' in the range of [ 0.01 ~ 1(default) ~ 100.0 ]
+	// For scrolling, 'handler()' is given 2nd argument of 'delta' in the range of [ -200 ~ 0 ~ +200 ]
+	self.window.SetScrollCallback(func(w *glfw.Window, xoff float64, yoff float64) {
+		// GLFW gives +1/-1 for each notch of the wheel (positive for scrolling up/left),
+		// while browsers give 'deltaX/Y' in pixels (positive for scrolling down/right).
+		delta_x, delta_y := -xoff*100, -yoff*100
+		keystat := self.get_keystat()
+		if keystat[3] { // ZOOM, if SHIFT is was pressed
+			if self.evthandler_for_zoom != nil {
+				cxy := self.get_cursor_xy()
+				delta := delta_y
+				if delta == 0 { // SHIFT turns the vertical wheel into the horizontal one (on some platforms)
+					delta = delta_x
+				}
+				if math.Abs(delta) > 100 { // too big with a fast wheel or a touchpad
+					delta = delta * 0.1
+				}
+				self.mouse_wheel_scale += delta // [ 0 ~ 500(default) ~ 1000 ]
+				self.mouse_wheel_scale = float64(math.Max(0, math.Min(self.mouse_wheel_scale, 1000)))
+				scale_exp := (self.mouse_wheel_scale - 500.0) / 250.0 // [ -2 ~ 0(default) ~ +2 ]
+				scale := math.Pow(10, scale_exp)                      // [ 0.01 ~ 1(default) ~ 100.0 ]
+				self.evthandler_for_zoom(cxy, float32(scale), keystat)
+			}
+		} else { // SCROLL
+			if self.evthandler_for_scroll != nil {
+				cxy := self.get_cursor_xy()
+				self.evthandler_for_scroll(cxy, int(delta_x), int(delta_y), keystat)
+			}
+		}
+	})
+	self.mouse_wheel_common_handler = true
 }
 
 func (self *OpenGLCanvas) SetEventHandlerForZoom(handler func(canvasxy [2]int, scale float32, keystat [4]bool)) {
+	// 'scale' in the range of [ 0.01 ~ 1(default) ~ 100.0 ]
+	self.evthandler_for_zoom = handler
+	if !self.mouse_wheel_common_handler {
+		self.setup_mouse_wheel_common_handler()
+	}
 }
 
 func (self *OpenGLCanvas) SetEventHandlerForScroll(handler func(canvasxy [2]int, dx int, dy int, keystat [4]bool)) {
+	// 'scroll' in the range of [ -200 ~ 0 ~ +200 ] 	// (-): swipe_down, (+): swipe_up
+	self.evthandler_for_scroll = handler
+	if !self.mouse_wheel_common_handler {
+		self.setup_mouse_wheel_common_handler()
+	}
 }
 
 func (self *OpenGLCanvas) SetEventHandlerForKeyPress(handler func(key string, code string, keystat [4]bool)) {
+	self.window.SetKeyCallback(func(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
+		if handler != nil && (action == glfw.Press || action == glfw.Repeat) { // like 'keydown' (repeated while pressed)
+			keystat := get_keystat_from_mods(mods)
+			handler(get_key_name(key, scancode, keystat[3]), get_key_code(key), keystat)
+		}
+	})
 }
 
 func (self *OpenGLCanvas) SetEventHandlerForWindowResize(handler func(w int, h int)) {
+	// Note that the window can be resized only if it was created with 'resizable'
+	if handler != nil {
+		self.evthandler_for_resize = handler
+	} else {
+		self.evthandler_for_resize = func(w int, h int) { common.Logger.Info("window.resize %d %d\n", w, h) }
+	}
+}
+
+func (self *OpenGLCanvas) get_cursor_xy() [2]int {
+	x, y := self.window.GetCursorPos() // in screen coordinates (like 'clientX/Y' of browsers)
+	return [2]int{int(x), int(y)}
+}
+
+func (self *OpenGLCanvas) get_keystat() [4]bool {
+	// GLFW gives no modifiers to cursor & scroll callbacks, so check the keys themselves
+	is_pressed := func(key1 glfw.Key, key2 glfw.Key) bool {
+		return self.window.GetKey(key1) == glfw.Press || self.window.GetKey(key2) == glfw.Press
+	}
+	return [4]bool{
+		is_pressed(glfw.KeyLeftAlt, glfw.KeyRightAlt), is_pressed(glfw.KeyLeftControl, glfw.KeyRightControl),
+		is_pressed(glfw.KeyLeftSuper, glfw.KeyRightSuper), is_pressed(glfw.KeyLeftShift, glfw.KeyRightShift)}
+}
+
+func get_keystat_from_mods(mods glfw.ModifierKey) [4]bool {
+	return [4]bool{mods&glfw.ModAlt != 0, mods&glfw.ModControl != 0, mods&glfw.ModSuper != 0, mods&glfw.ModShift != 0}
 }
 
 // ----------------------------------------------------------------------------
@@ -105,6 +302,7 @@ func (self *OpenGLCanvas) Run(draw_handler func(now float64)) {
 		if draw_handler != nil && !self.paused {
 			now := glfw.GetTime()
 			draw_handler(now)
+			self.capture_if_requested()
 			self.window.SwapBuffers()
 		}
 		glfw.PollEvents()
@@ -118,6 +316,7 @@ func (self *OpenGLCanvas) RunOnce(draw_handler func(now float64)) {
 		if draw_handler != nil && first_time {
 			now := glfw.GetTime()
 			draw_handler(now)
+			self.capture_if_requested()
 			self.window.SwapBuffers()
 			first_time = false
 		}
@@ -132,3 +331,116 @@ func (self *OpenGLCanvas) Pause() {
 func (self *OpenGLCanvas) Resume() {
 	self.paused = false
 }
+
+// ----------------------------------------------------------------------------
+// Capturing Image
+// ----------------------------------------------------------------------------
+
+func (self *OpenGLCanvas) CaptureImage() (image.Image, error) {
+	// Capture the image rendered in the window.
+	// Call it inside the 'draw_handler' (after rendering), since the buffer is not valid after being swapped.
+	w, h := self.window.GetFramebufferSize() // it can be larger than window size (on HiDPI display)
+	return gigl.ReadImage(self.rc, w, h)
+}
+
+func (self *OpenGLCanvas) SaveCapturedImage(filepath string) error {
+	// Capture the image rendered in the window, and save it as a PNG file.
+	// Call it inside the 'draw_handler' (after rendering), since the buffer is not valid after being swapped.
+	img, err := self.CaptureImage()
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(filepath)
+	if err != nil {
+		return fmt.Errorf("Failed to save captured image : %v", err)
+	}
+	defer file.Close()
+	return png.Encode(file, img)
+}
+
+func (self *OpenGLCanvas) capture_if_requested() {
+	// Capture the image automatically, if requested by environment variables
+	//   like 'capture=xscreen.png capture_frame=30 go run ./tutorial/opengl_3d/opengl_3d.go'
+	//   (the window will be closed after saving the image)
+	self.frame++
+	filepath := self.rc.GetEnvVariable("capture", "string").(string)
+	frame := self.rc.GetEnvVariable("capture_frame", "int").(int)
+	if filepath == "" || self.frame < frame {
+		return
+	}
+	if err := self.SaveCapturedImage(filepath); err != nil {
+		common.Logger.Error("%v\n", err)
+	} else {
+		common.Logger.Info("Captured image saved to %q\n", filepath)
+	}
+	self.window.SetShouldClose(true)
+}
+
+// ----------------------------------------------------------------------------
+// Key Names (as 'key' & 'code' of KeyboardEvent in browsers)
+// ----------------------------------------------------------------------------
+
+func get_key_name(key glfw.Key, scancode int, shift bool) string {
+	if name, ok := glfw_key_names[key]; ok {
+		return name
+	}
+	name := glfw.GetKeyName(key, scancode) // printable keys (with the current keyboard layout)
+	if name == "" {
+		return "Unidentified"
+	} else if shift {
+		if len(name) == 1 && name[0] >= 'a' && name[0] <= 'z' {
+			return string(name[0] - 'a' + 'A')
+		} else if i := strings.Index(us_keys_unshifted, name); len(name) == 1 && i >= 0 {
+			return us_keys_shifted[i : i+1]
+		}
+	}
+	return name
+}
+
+func get_key_code(key glfw.Key) string {
+	switch {
+	case key >= glfw.KeyA && key <= glfw.KeyZ:
+		return "Key" + string(rune('A'+(key-glfw.KeyA)))
+	case key >= glfw.Key0 && key <= glfw.Key9:
+		return "Digit" + string(rune('0'+(key-glfw.Key0)))
+	case key >= glfw.KeyKP0 && key <= glfw.KeyKP9:
+		return "Numpad" + string(rune('0'+(key-glfw.KeyKP0)))
+	case key >= glfw.KeyF1 && key <= glfw.KeyF25:
+		return fmt.Sprintf("F%d", key-glfw.KeyF1+1)
+	}
+	if code, ok := glfw_key_codes[key]; ok {
+		return code
+	}
+	return "Unidentified"
+}
+
+const us_keys_unshifted = "`1234567890-=[]\\;',./"
+const us_keys_shifted = "~!@#$%^&*()_+{}|:\"<>?"
+
+var glfw_key_names = map[glfw.Key]string{
+	glfw.KeySpace: " ", glfw.KeyEscape: "Escape", glfw.KeyEnter: "Enter", glfw.KeyKPEnter: "Enter",
+	glfw.KeyTab: "Tab", glfw.KeyBackspace: "Backspace", glfw.KeyInsert: "Insert", glfw.KeyDelete: "Delete",
+	glfw.KeyRight: "ArrowRight", glfw.KeyLeft: "ArrowLeft", glfw.KeyDown: "ArrowDown", glfw.KeyUp: "ArrowUp",
+	glfw.KeyPageUp: "PageUp", glfw.KeyPageDown: "PageDown", glfw.KeyHome: "Home", glfw.KeyEnd: "End",
+	glfw.KeyCapsLock: "CapsLock", glfw.KeyScrollLock: "ScrollLock", glfw.KeyNumLock: "NumLock",
+	glfw.KeyPrintScreen: "PrintScreen", glfw.KeyPause: "Pause", glfw.KeyMenu: "ContextMenu",
+	glfw.KeyLeftShift: "Shift", glfw.KeyRightShift: "Shift", glfw.KeyLeftControl: "Control", glfw.KeyRightControl: "Control",
+	glfw.KeyLeftAlt: "Alt", glfw.KeyRightAlt: "Alt", glfw.KeyLeftSuper: "Meta", glfw.KeyRightSuper: "Meta",
+	glfw.KeyF1: "F1", glfw.KeyF2: "F2", glfw.KeyF3: "F3", glfw.KeyF4: "F4", glfw.KeyF5: "F5", glfw.KeyF6: "F6",
+	glfw.KeyF7: "F7", glfw.KeyF8: "F8", glfw.KeyF9: "F9", glfw.KeyF10: "F10", glfw.KeyF11: "F11", glfw.KeyF12: "F12",
+}
+
+var glfw_key_codes = map[glfw.Key]string{
+	glfw.KeySpace: "Space", glfw.KeyApostrophe: "Quote", glfw.KeyComma: "Comma", glfw.KeyMinus: "Minus",
+	glfw.KeyPeriod: "Period", glfw.KeySlash: "Slash", glfw.KeySemicolon: "Semicolon", glfw.KeyEqual: "Equal",
+	glfw.KeyLeftBracket: "BracketLeft", glfw.KeyBackslash: "Backslash", glfw.KeyRightBracket: "BracketRight", glfw.KeyGraveAccent: "Backquote",
+	glfw.KeyEscape: "Escape", glfw.KeyEnter: "Enter", glfw.KeyTab: "Tab", glfw.KeyBackspace: "Backspace",
+	glfw.KeyInsert: "Insert", glfw.KeyDelete: "Delete", glfw.KeyPageUp: "PageUp", glfw.KeyPageDown: "PageDown", glfw.KeyHome: "Home", glfw.KeyEnd: "End",
+	glfw.KeyRight: "ArrowRight", glfw.KeyLeft: "ArrowLeft", glfw.KeyDown: "ArrowDown", glfw.KeyUp: "ArrowUp",
+	glfw.KeyCapsLock: "CapsLock", glfw.KeyScrollLock: "ScrollLock", glfw.KeyNumLock: "NumLock",
+	glfw.KeyPrintScreen: "PrintScreen", glfw.KeyPause: "Pause", glfw.KeyMenu: "ContextMenu",
+	glfw.KeyKPDecimal: "NumpadDecimal", glfw.KeyKPDivide: "NumpadDivide", glfw.KeyKPMultiply: "NumpadMultiply",
+	glfw.KeyKPSubtract: "NumpadSubtract", glfw.KeyKPAdd: "NumpadAdd", glfw.KeyKPEnter: "NumpadEnter", glfw.KeyKPEqual: "NumpadEqual",
+	glfw.KeyLeftShift: "ShiftLeft", glfw.KeyRightShift: "ShiftRight", glfw.KeyLeftControl: "ControlLeft", glfw.KeyRightControl: "ControlRight",
+	glfw.KeyLeftAlt: "AltLeft", glfw.KeyRightAlt: "AltRight", glfw.KeyLeftSuper: "MetaLeft", glfw.KeyRightSuper: "MetaRight",
+}
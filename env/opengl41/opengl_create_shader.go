@@ -15,9 +15,11 @@ type OpenGLShader struct {
 	vshader_code   string                  // vertex   shader source code
 	fshader_code   string                  // fragment shader source code
 	shader_program uint32                  //
+	deleted        *bool                   // shared with its copies (true, after the shader program was deleted)
 	err            error                   //
 
 	gigl.GLShaderBinder
+	*gigl.RefCounter // shared with its copies, since they share the same shader program
 }
 
 // ----------------------------------------------------------------------------
@@ -27,7 +29,7 @@ type OpenGLShader struct {
 func create_shader(rc *OpenGLRenderingContext, vshader_source string, fshader_source string) (*OpenGLShader, error) {
 	// THIS CONSTRUCTOR FUNCTION IS NOT MEANT TO BE CALLED DIRECTLY BY USER.
 	// IT SHOULD BE CALLED BY 'OpenGLRenderingContext.CreateShader()'.
-	shader := OpenGLShader{rc: rc}
+	shader := OpenGLShader{rc: rc, RefCounter: &gigl.RefCounter{}, deleted: new(bool)}
 	shader.CreateShaderProgram(vshader_source, fshader_source)
 	shader.InitBindings()
 	return &shader, shader.err
@@ -40,11 +42,15 @@ func (self *OpenGLShader) CreateShaderProgram(vshader_source string, fshader_sou
 	c := self.rc.GetConstants()
 	var status, logLength int32
 	// vertex shader (uint32)
-	vshader_source = self.prepare_vshader_source(vshader_source) //
-	vshader := gl.CreateShader(c.VERTEX_SHADER)                  // Create a vertex shader object
-	vsource, vcfree := gl.Strs(vshader_source)                   // Get C-string of vertex shader source code
-	gl.ShaderSource(vshader, 1, vsource, nil)                    // Attach vertex shader source code
-	gl.CompileShader(vshader)                                    // Compile the vertex shader
+	vtrans, err := gigl.TranslateShaderSource(vshader_source, "vertex", gigl.GLSL_410)
+	if err != nil {
+		self.err = err
+		return
+	}
+	vshader := gl.CreateShader(c.VERTEX_SHADER)        // Create a vertex shader object
+	vsource, vcfree := gl.Strs(vtrans.Source + "\x00") // Get C-string of vertex shader source code
+	gl.ShaderSource(vshader, 1, vsource, nil)          // Attach vertex shader source code
+	gl.CompileShader(vshader)                          // Compile the vertex shader
 	vcfree()
 	gl.GetShaderiv(vshader, c.COMPILE_STATUS, &status)
 	if status == gl.FALSE {
@@ -52,15 +58,19 @@ func (self *OpenGLShader) CreateShaderProgram(vshader_source string, fshader_sou
 		logmsg := strings.Repeat("\x00", int(logLength+1))
 		gl.GetShaderInfoLog(vshader, logLength, nil, gl.Str(logmsg))
 		self.err = errors.New("VShader failed to compile")
-		fmt.Println("VShader failed to compile : " + strings.TrimSpace(logmsg))
+		fmt.Println("VShader failed to compile : " + vtrans.MapErrorLog(strings.TrimSpace(logmsg)))
 		return
 	}
 	// fragment shader (uint32)
-	fshader_source = self.prepare_fshader_source(fshader_source) //
-	fshader := gl.CreateShader(c.FRAGMENT_SHADER)                // Create fragment shader object
-	fsource, fcfree := gl.Strs(fshader_source)                   // Get C-string of fragment shader source code
-	gl.ShaderSource(fshader, 1, fsource, nil)                    // Attach fragment shader source code
-	gl.CompileShader(fshader)                                    // Compile the fragment shader
+	ftrans, err := gigl.TranslateShaderSource(fshader_source, "fragment", gigl.GLSL_410)
+	if err != nil {
+		self.err = err
+		return
+	}
+	fshader := gl.CreateShader(c.FRAGMENT_SHADER)      // Create fragment shader object
+	fsource, fcfree := gl.Strs(ftrans.Source + "\x00") // Get C-string of fragment shader source code
+	gl.ShaderSource(fshader, 1, fsource, nil)          // Attach fragment shader source code
+	gl.CompileShader(fshader)                          // Compile the fragment shader
 	fcfree()
 	gl.GetShaderiv(fshader, c.COMPILE_STATUS, &status)
 	if status == gl.FALSE {
@@ -68,7 +78,7 @@ func (self *OpenGLShader) CreateShaderProgram(vshader_source string, fshader_sou
 		logmsg := strings.Repeat("\x00", int(logLength+1))
 		gl.GetShaderInfoLog(fshader, logLength, nil, gl.Str(logmsg))
 		self.err = errors.New("FShader failed to compile")
-		fmt.Println("FShader failed to compile : " + strings.TrimSpace(logmsg))
+		fmt.Println("FShader failed to compile : " + ftrans.MapErrorLog(strings.TrimSpace(logmsg)))
 		return
 	}
 	// shader program (uint32)
@@ -90,7 +100,7 @@ func (self *OpenGLShader) CreateShaderProgram(vshader_source string, fshader_sou
 }
 
 func (self *OpenGLShader) IsReady() bool {
-	return self.shader_program != 0 && self.err == nil
+	return !*self.deleted && self.shader_program != 0 && self.err == nil
 }
 
 func (self *OpenGLShader) GetShaderProgram() any {
@@ -101,18 +111,13 @@ func (self *OpenGLShader) GetErr() error {
 	return self.err
 }
 
-func (self *OpenGLShader) prepare_vshader_source(source string) string {
-	source = strings.ReplaceAll(source, "attribute", "in")
-	source = strings.ReplaceAll(source, "varying", "out")
-	return "#version 410\n" + source + "\x00"
-}
-
-func (self *OpenGLShader) prepare_fshader_source(source string) string {
-	source = strings.ReplaceAll(source, "varying", "in")
-	source = strings.Replace(source, "void main", "out vec4 OUTPUT_COLOR;\nvoid main", 1)
-	source = strings.ReplaceAll(source, "gl_FragColor", "OUTPUT_COLOR")
-	source = strings.ReplaceAll(source, "texture2D", "texture")
-	return "#version 410\n" + source + "\x00"
+func (self *OpenGLShader) Dispose() {
+	// Delete the shader program (note that its copies cannot be used any more, either)
+	if self.shader_program != 0 && !*self.deleted {
+		self.rc.GLDeleteProgram(self.shader_program)
+	}
+	*self.deleted = true
+	self.shader_program = 0
 }
 
 // ----------------------------------------------------------------------------
@@ -127,7 +132,7 @@ func (self *OpenGLShader) CheckBindings() {
 	}
 	// check uniform locations (type: 'uint32')
 	for uname, utarget := range self.Uniforms {
-		location := gl.GetUniformLocation(self.shader_program, gl.Str(uname+"\x00")) // int32
+		location := gl.GetUniformLocation(self.shader_program, gl.Str(gigl.TranslateShaderIdentifier(uname)+"\x00")) // int32
 		if location < 0 {
 			self.err = fmt.Errorf("Uniform %q cannot be found in the shader program\n", uname)
 			common.Logger.Error(self.err.Error())
@@ -141,7 +146,7 @@ func (self *OpenGLShader) CheckBindings() {
 	}
 	// check attribute locations (type: 'uint32')
 	for aname, atarget := range self.Attributes {
-		location := gl.GetAttribLocation(self.shader_program, gl.Str(aname+"\x00")) // int32
+		location := gl.GetAttribLocation(self.shader_program, gl.Str(gigl.TranslateShaderIdentifier(aname)+"\x00")) // int32
 		if location < 0 {
 			self.err = fmt.Errorf("Attribute %q cannot be found in the shader program\n", aname)
 			common.Logger.Error(self.err.Error())
@@ -163,6 +168,8 @@ func (self *OpenGLShader) Copy() gigl.GLShader {
 	// create a new shader as a copy with empty binding
 	shader := OpenGLShader{rc: self.rc, vshader_code: self.vshader_code, fshader_code: self.fshader_code}
 	shader.shader_program = self.shader_program
+	shader.RefCounter = self.RefCounter
+	shader.deleted = self.deleted
 	// initialize shader bindings with empty map
 	shader.InitBindings()
 	return &shader
@@ -1,6 +1,9 @@
 package opengl41
 
 import (
+	"os"
+	"strconv"
+
 	"github.com/go-gl/gl/v4.1-core/gl"
 	"github.com/go4orward/gigl"
 )
@@ -18,13 +21,19 @@ func NewOpenGLRenderingContext(width int, height int) *OpenGLRenderingContext {
 	self.constants.BLEND = gl.BLEND
 	self.constants.BYTE = gl.BYTE
 	self.constants.CLAMP_TO_EDGE = gl.CLAMP_TO_EDGE
+	self.constants.COLOR_ATTACHMENT0 = gl.COLOR_ATTACHMENT0
 	self.constants.COLOR_BUFFER_BIT = gl.COLOR_BUFFER_BIT
 	self.constants.COMPILE_STATUS = gl.COMPILE_STATUS
+	self.constants.DEPTH_ATTACHMENT = gl.DEPTH_ATTACHMENT
 	self.constants.DEPTH_BUFFER_BIT = gl.DEPTH_BUFFER_BIT
+	self.constants.DEPTH_COMPONENT16 = gl.DEPTH_COMPONENT16
 	self.constants.DEPTH_TEST = gl.DEPTH_TEST
+	self.constants.DYNAMIC_DRAW = gl.DYNAMIC_DRAW
 	self.constants.ELEMENT_ARRAY_BUFFER = gl.ELEMENT_ARRAY_BUFFER
 	self.constants.FLOAT = gl.FLOAT
 	self.constants.FRAGMENT_SHADER = gl.FRAGMENT_SHADER
+	self.constants.FRAMEBUFFER = gl.FRAMEBUFFER
+	self.constants.FRAMEBUFFER_COMPLETE = gl.FRAMEBUFFER_COMPLETE
 	self.constants.LEQUAL = gl.LEQUAL
 	self.constants.LESS = gl.LESS
 	self.constants.LINEAR = gl.LINEAR
@@ -34,9 +43,11 @@ func NewOpenGLRenderingContext(width int, height int) *OpenGLRenderingContext {
 	self.constants.ONE = gl.ONE
 	self.constants.ONE_MINUS_SRC_ALPHA = gl.ONE_MINUS_SRC_ALPHA
 	self.constants.POINTS = gl.POINTS
+	self.constants.RENDERBUFFER = gl.RENDERBUFFER
 	self.constants.RGBA = gl.RGBA
 	self.constants.SRC_ALPHA = gl.SRC_ALPHA
 	self.constants.STATIC_DRAW = gl.STATIC_DRAW
+	self.constants.STREAM_DRAW = gl.STREAM_DRAW
 	self.constants.TEXTURE_2D = gl.TEXTURE_2D
 	self.constants.TEXTURE0 = gl.TEXTURE0
 	self.constants.TEXTURE1 = gl.TEXTURE1
@@ -60,13 +71,17 @@ func (self *OpenGLRenderingContext) GetConstants() *gigl.GLConstants {
 }
 
 func (self *OpenGLRenderingContext) GetEnvVariable(vname string, dtype string) interface{} {
+	// In OpenGL environment, 'EnvVariable' means environment variables of the process
+	value := os.Getenv(vname)
 	switch dtype {
 	case "int":
-		return 0
+		n, _ := strconv.Atoi(value)
+		return n
 	case "bool":
-		return false
+		b, _ := strconv.ParseBool(value)
+		return b
 	default:
-		return ""
+		return value
 	}
 }
 
@@ -95,10 +110,10 @@ func (self *OpenGLRenderingContext) CreateDataBufferVAO() *gigl.VAO {
 	gl.GenVertexArrays(1, &vao)
 	gl.BindVertexArray(vao)
 	// common.Logger.Trace("VAO (%T): %v\n", vao, vao)
-	return &gigl.VAO{}
+	return &gigl.VAO{VertexArray: vao}
 }
 
-func (self *OpenGLRenderingContext) CreateVtxDataBuffer(data_slice []float32) interface{} {
+func (self *OpenGLRenderingContext) CreateVtxDataBuffer(data_slice []float32, usage uint32) interface{} {
 	if data_slice == nil {
 		return nil
 	}
@@ -108,7 +123,7 @@ func (self *OpenGLRenderingContext) CreateVtxDataBuffer(data_slice []float32) in
 	var vbo uint32
 	gl.GenBuffers(1, &vbo)
 	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-	gl.BufferData(gl.ARRAY_BUFFER, len(data_slice)*4, gl.Ptr(data_slice), gl.STATIC_DRAW)
+	gl.BufferData(gl.ARRAY_BUFFER, len(data_slice)*4, gl.Ptr(data_slice), usage)
 	// common.Logger.Trace("  CreateDataBuffer() : type:%T  len:%d  =>  vbo:%v\n", data_slice, len(data_buffer), vbo)
 	return vbo
 }
@@ -137,18 +152,112 @@ func (self *OpenGLRenderingContext) GLBindBuffer(target uint32, buffer interface
 	}
 }
 
+func (self *OpenGLRenderingContext) GLBufferData(target uint32, data_slice []float32, usage uint32) {
+	// 'target' : c.ARRAY_BUFFER,  'usage' : c.STATIC_DRAW, c.DYNAMIC_DRAW or c.STREAM_DRAW
+	if len(data_slice) == 0 {
+		gl.BufferData(target, 0, nil, usage)
+	} else {
+		gl.BufferData(target, len(data_slice)*4, gl.Ptr(data_slice), usage)
+	}
+}
+
+func (self *OpenGLRenderingContext) GLBufferSubData(target uint32, offset_in_byte int, data_slice []float32) {
+	// 'target' : c.ARRAY_BUFFER
+	if len(data_slice) > 0 {
+		gl.BufferSubData(target, offset_in_byte, len(data_slice)*4, gl.Ptr(data_slice))
+	}
+}
+
+func (self *OpenGLRenderingContext) GLBindVertexArray(vertex_array interface{}) {
+	if vertex_array == nil {
+		gl.BindVertexArray(0)
+	} else {
+		gl.BindVertexArray(vertex_array.(uint32))
+	}
+}
+
 // ----------------------------------------------------------------------------
 // Binding Texture
 // ----------------------------------------------------------------------------
 
 func (self *OpenGLRenderingContext) GLActiveTexture(texture_unit int) {
-	// js_texture_unit := js.ValueOf(self.constants.TEXTURE0.(js.Value).Int() + texture_unit)
-	// gl.ActiveTexture(texture_unit)
+	gl.ActiveTexture(self.constants.TEXTURE0 + uint32(texture_unit))
 }
 
 func (self *OpenGLRenderingContext) GLBindTexture(target uint32, texture interface{}) {
 	// 'binding_target' : TEXTURE_2D
-	// self.context.Call("bindTexture", target.(js.Value), texture.(js.Value))
+	if texture == nil {
+		gl.BindTexture(target, 0)
+	} else {
+		gl.BindTexture(target, texture.(uint32))
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Framebuffer
+// ----------------------------------------------------------------------------
+
+func (self *OpenGLRenderingContext) CreateFramebuffer() interface{} {
+	var framebuffer uint32 // NON-ZERO values
+	gl.GenFramebuffers(1, &framebuffer)
+	return framebuffer
+}
+
+func (self *OpenGLRenderingContext) CreateRenderbuffer() interface{} {
+	var renderbuffer uint32 // NON-ZERO values
+	gl.GenRenderbuffers(1, &renderbuffer)
+	return renderbuffer
+}
+
+func (self *OpenGLRenderingContext) CreateTexture(width int, height int) interface{} {
+	// create an empty RGBA texture (NON-POWER-OF-2 size is allowed with CLAMP_TO_EDGE & LINEAR)
+	var texture uint32 // NON-ZERO values
+	gl.GenTextures(1, &texture)
+	gl.BindTexture(gl.TEXTURE_2D, texture)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA, int32(width), int32(height), 0, gl.RGBA, gl.UNSIGNED_BYTE, nil)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+	return texture
+}
+
+func (self *OpenGLRenderingContext) GLBindFramebuffer(target uint32, framebuffer interface{}) {
+	// 'target' : FRAMEBUFFER  ('framebuffer' nil means the window)
+	if framebuffer == nil {
+		gl.BindFramebuffer(target, 0)
+	} else {
+		gl.BindFramebuffer(target, framebuffer.(uint32))
+	}
+}
+
+func (self *OpenGLRenderingContext) GLBindRenderbuffer(target uint32, renderbuffer interface{}) {
+	// 'target' : RENDERBUFFER
+	if renderbuffer == nil {
+		gl.BindRenderbuffer(target, 0)
+	} else {
+		gl.BindRenderbuffer(target, renderbuffer.(uint32))
+	}
+}
+
+func (self *OpenGLRenderingContext) GLRenderbufferStorage(target uint32, internalformat uint32, width int, height int) {
+	gl.RenderbufferStorage(target, internalformat, int32(width), int32(height))
+}
+
+func (self *OpenGLRenderingContext) GLFramebufferTexture2D(target uint32, attachment uint32, textarget uint32, texture interface{}, level int) {
+	gl.FramebufferTexture2D(target, attachment, textarget, texture.(uint32), int32(level))
+}
+
+func (self *OpenGLRenderingContext) GLFramebufferRenderbuffer(target uint32, attachment uint32, renderbuffertarget uint32, renderbuffer interface{}) {
+	gl.FramebufferRenderbuffer(target, attachment, renderbuffertarget, renderbuffer.(uint32))
+}
+
+func (self *OpenGLRenderingContext) GLCheckFramebufferStatus(target uint32) uint32 {
+	return gl.CheckFramebufferStatus(target)
+}
+
+func (self *OpenGLRenderingContext) GLViewport(x int, y int, width int, height int) {
+	gl.Viewport(int32(x), int32(y), int32(width), int32(height))
 }
 
 // ----------------------------------------------------------------------------
@@ -266,6 +375,55 @@ func (self *OpenGLRenderingContext) GLDrawElementsInstanced(mode uint32, element
 	// }
 }
 
+// ----------------------------------------------------------------------------
+// Reading Pixels
+// ----------------------------------------------------------------------------
+
+func (self *OpenGLRenderingContext) GLReadPixels(x int, y int, width int, height int, format uint32, dtype uint32, pixels []uint8) {
+	// 'format' : RGBA,  'dtype' : UNSIGNED_BYTE
+	gl.ReadPixels(int32(x), int32(y), int32(width), int32(height), format, dtype, gl.Ptr(pixels))
+}
+
+// ----------------------------------------------------------------------------
+// Deleting Resources
+// ----------------------------------------------------------------------------
+
+func (self *OpenGLRenderingContext) GLDeleteBuffer(buffer interface{}) {
+	if handle, ok := buffer.(uint32); ok {
+		gl.DeleteBuffers(1, &handle)
+	}
+}
+
+func (self *OpenGLRenderingContext) GLDeleteVertexArray(vertex_array interface{}) {
+	if handle, ok := vertex_array.(uint32); ok {
+		gl.DeleteVertexArrays(1, &handle)
+	}
+}
+
+func (self *OpenGLRenderingContext) GLDeleteTexture(texture interface{}) {
+	if handle, ok := texture.(uint32); ok {
+		gl.DeleteTextures(1, &handle)
+	}
+}
+
+func (self *OpenGLRenderingContext) GLDeleteProgram(program interface{}) {
+	if handle, ok := program.(uint32); ok {
+		gl.DeleteProgram(handle)
+	}
+}
+
+func (self *OpenGLRenderingContext) GLDeleteFramebuffer(framebuffer interface{}) {
+	if handle, ok := framebuffer.(uint32); ok {
+		gl.DeleteFramebuffers(1, &handle)
+	}
+}
+
+func (self *OpenGLRenderingContext) GLDeleteRenderbuffer(renderbuffer interface{}) {
+	if handle, ok := renderbuffer.(uint32); ok {
+		gl.DeleteRenderbuffers(1, &handle)
+	}
+}
+
 // ----------------------------------------------------------------------------
 // OpenGL Extensions
 // ----------------------------------------------------------------------------
@@ -0,0 +1,191 @@
+package recording
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go4orward/gigl"
+	"github.com/go4orward/gigl/common"
+)
+
+type RecordingShader struct {
+	rc             *RecordingRenderingContext //
+	vshader_code   string                     // vertex   shader source code
+	fshader_code   string                     // fragment shader source code
+	shader_program uint32                     // fake handle of the shader program
+	deleted        *bool                      // shared with its copies (true, after the shader program was deleted)
+	uniforms       map[string]int32           // uniforms   declared in the source, with fake locations
+	attributes     map[string]int32           // attributes declared in the source, with fake locations
+	err            error                      //
+
+	gigl.GLShaderBinder
+	*gigl.RefCounter // shared with its copies, since they share the same shader program
+}
+
+type RecordingLocation struct {
+	Name  string // name of the uniform/attribute variable
+	Index int32  // fake location of the variable
+}
+
+func (self RecordingLocation) String() string {
+	return fmt.Sprintf("%s@%d", self.Name, self.Index)
+}
+
+// ----------------------------------------------------------------------------
+// Creating Shader
+// ----------------------------------------------------------------------------
+
+func create_shader(rc *RecordingRenderingContext, vshader_source string, fshader_source string) (*RecordingShader, error) {
+	// THIS CONSTRUCTOR FUNCTION IS NOT MEANT TO BE CALLED DIRECTLY BY USER.
+	// IT SHOULD BE CALLED BY 'RecordingRenderingContext.CreateShader()'.
+	shader := RecordingShader{rc: rc, RefCounter: &gigl.RefCounter{}, deleted: new(bool)}
+	shader.CreateShaderProgram(vshader_source, fshader_source)
+	shader.InitBindings()
+	return &shader, shader.err
+}
+
+var declaration_regexp = regexp.MustCompile(`\b(uniform|attribute)\s+(?:(?:lowp|mediump|highp)\s+)?\w+\s+(\w+)`)
+
+func (self *RecordingShader) CreateShaderProgram(vshader_source string, fshader_source string) {
+	// Nothing is compiled, but the uniforms & attributes declared in the source code
+	//   are collected, so that CheckBindings() can find their (fake) locations.
+	self.vshader_code = vshader_source
+	self.fshader_code = fshader_source
+	self.uniforms = map[string]int32{}
+	self.attributes = map[string]int32{}
+	self.err = nil
+	if !strings.Contains(vshader_source, "void main") {
+		self.err = errors.New("VShader failed to compile")
+		common.Logger.Error("VShader failed to compile : 'main()' not found\n")
+		return
+	}
+	if !strings.Contains(fshader_source, "void main") {
+		self.err = errors.New("FShader failed to compile")
+		common.Logger.Error("FShader failed to compile : 'main()' not found\n")
+		return
+	}
+	for _, source := range []string{vshader_source, fshader_source} {
+		for _, match := range declaration_regexp.FindAllStringSubmatch(source, -1) {
+			declared := self.attributes
+			if match[1] == "uniform" {
+				declared = self.uniforms
+			}
+			if _, ok := declared[match[2]]; !ok {
+				declared[match[2]] = int32(len(declared))
+			}
+		}
+	}
+	self.shader_program = self.rc.new_handle()
+}
+
+func (self *RecordingShader) IsReady() bool {
+	return !*self.deleted && self.shader_program != 0 && self.err == nil
+}
+
+func (self *RecordingShader) GetShaderProgram() any {
+	return self.shader_program
+}
+
+func (self *RecordingShader) GetErr() error {
+	return self.err
+}
+
+func (self *RecordingShader) Dispose() {
+	// Delete the shader program (note that its copies cannot be used any more, either)
+	if self.shader_program != 0 && !*self.deleted {
+		self.rc.GLDeleteProgram(self.shader_program)
+	}
+	*self.deleted = true
+	self.shader_program = 0
+}
+
+// ----------------------------------------------------------------------------
+// Shader Bindings
+// ----------------------------------------------------------------------------
+
+func (self *RecordingShader) CheckBindings() {
+	// check if the shader was properly built
+	if self.err != nil {
+		common.Logger.Error("ShaderProgram is not ready for CheckBindings (%v)\n", self.err)
+		return
+	}
+	// check uniform locations (type: 'RecordingLocation')
+	for uname, utarget := range self.Uniforms {
+		index, ok := self.uniforms[uname]
+		if !ok {
+			self.err = fmt.Errorf("Uniform %q cannot be found in the shader program\n", uname)
+			common.Logger.Error(self.err.Error())
+		} else if utarget.Target == nil {
+			self.err = fmt.Errorf("Invalid binding for uniform %q : %v \n", uname, utarget)
+			common.Logger.Error(self.err.Error())
+		} else {
+			utarget.Loc = RecordingLocation{Name: uname, Index: index} // save it as any
+		}
+		self.Uniforms[uname] = utarget
+	}
+	// check attribute locations (type: 'RecordingLocation')
+	for aname, atarget := range self.Attributes {
+		index, ok := self.attributes[aname]
+		if !ok {
+			self.err = fmt.Errorf("Attribute %q cannot be found in the shader program\n", aname)
+			common.Logger.Error(self.err.Error())
+		} else if atarget.Target == nil {
+			self.err = fmt.Errorf("Invalid binding for attribute %q : %v \n", aname, atarget)
+			common.Logger.Error(self.err.Error())
+		} else {
+			atarget.Loc = RecordingLocation{Name: aname, Index: index} // save it as any
+		}
+		self.Attributes[aname] = atarget
+	}
+}
+
+// ----------------------------------------------------------------------------
+//
+// ----------------------------------------------------------------------------
+
+func (self *RecordingShader) Copy() gigl.GLShader {
+	// create a new shader as a copy with empty binding
+	shader := RecordingShader{rc: self.rc, vshader_code: self.vshader_code, fshader_code: self.fshader_code}
+	shader.shader_program = self.shader_program
+	shader.RefCounter = self.RefCounter
+	shader.deleted = self.deleted
+	shader.uniforms = self.uniforms
+	shader.attributes = self.attributes
+	// initialize shader bindings with empty map
+	shader.InitBindings()
+	return &shader
+}
+
+func (self *RecordingShader) String() string {
+	vert, frag, prog := "X", "X", "X"
+	if self.err == nil || !strings.HasPrefix(self.err.Error(), "VShader") {
+		vert = "O"
+		if self.err == nil || !strings.HasPrefix(self.err.Error(), "FShader") {
+			frag = "O"
+			if self.err == nil {
+				prog = "O"
+			}
+		}
+	}
+	return fmt.Sprintf("Shader{V:%s F:%s P:%s Unf:%d Att:%d}", vert, frag, prog, len(self.Uniforms), len(self.Attributes))
+}
+
+func (self *RecordingShader) Summary() string {
+	summary := ""
+	if self.err == nil && self.shader_program != 0 {
+		summary += fmt.Sprintf("Shader  program:Y \n")
+	} else if self.err == nil && self.shader_program == 0 {
+		summary += fmt.Sprintf("Shader  program:N \n")
+	} else {
+		summary += fmt.Sprintf("Shader  with Error (%s)\n", self.err.Error())
+	}
+	for uname, ut := range self.Uniforms {
+		summary += fmt.Sprintf("    Uniform   %-10s: %s\n", uname, ut.String())
+	}
+	for aname, at := range self.Attributes {
+		summary += fmt.Sprintf("    Attribute %-10s: %s\n", aname, at.String())
+	}
+	return strings.TrimSuffix(summary, "\n")
+}
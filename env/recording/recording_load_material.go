@@ -0,0 +1,95 @@
+package recording
+
+import (
+	"math"
+	"time"
+
+	"github.com/go4orward/gigl"
+	"github.com/go4orward/gigl/g2d"
+)
+
+func load_material(rc *RecordingRenderingContext, material gigl.GLMaterial) error {
+	switch material.(type) {
+	case *g2d.MaterialColors:
+		// DO NOTHING
+	case *g2d.MaterialTexture:
+		mtex := material.(*g2d.MaterialTexture)
+		if !mtex.IsReady() && !mtex.IsLoaded() && !mtex.IsLoading() {
+			// get the pixel buffer, and the width & height of the texture
+			mtex.LoadTextureFromLocalFile()
+			for mtex.IsLoading() { // wait for it, to keep the recorded calls deterministic
+				time.Sleep(time.Millisecond)
+			}
+		}
+	case *g2d.MaterialGlowTexture:
+		mtex := material.(*g2d.MaterialGlowTexture)
+		if !mtex.IsReady() && !mtex.IsLoaded() {
+			// get the pixel buffer, and the width & height of the texture
+			mtex.LoadGlowTexture()
+		}
+	case *g2d.MaterialAlphabetTexture:
+		mtex := material.(*g2d.MaterialAlphabetTexture)
+		if !mtex.IsReady() && !mtex.IsLoaded() {
+			// get the width & height of the texture (without drawing the alphabet string)
+			measure_material_alphabet_texture(mtex)
+		}
+	}
+	return nil
+}
+
+func setup_material(rc *RecordingRenderingContext, material gigl.GLMaterial) error {
+	switch material.(type) {
+	case *g2d.MaterialColors:
+		// DO NOTHING
+	case *g2d.MaterialTexture:
+		mtex := material.(*g2d.MaterialTexture)
+		if !mtex.IsReady() && mtex.IsLoaded() {
+			wh := mtex.GetTextureWH()
+			mipmap := wh[0]&(wh[0]-1) == 0 && wh[1]&(wh[1]-1) == 0 // POWER-OF-2 width & height
+			mtex.SetTexture(create_texture(rc, wh, mipmap))
+		}
+	case *g2d.MaterialGlowTexture:
+		mtex := material.(*g2d.MaterialGlowTexture)
+		if !mtex.IsReady() && mtex.IsLoaded() {
+			mtex.SetTexture(create_texture(rc, mtex.GetTextureWH(), false))
+		}
+	case *g2d.MaterialAlphabetTexture:
+		mtex := material.(*g2d.MaterialAlphabetTexture)
+		if !mtex.IsReady() && mtex.IsLoaded() {
+			// record the same calls as WebGL does for the alphabet string drawn on the canvas
+			mtex.SetTexture(create_texture(rc, mtex.GetTextureWH(), false))
+		}
+	}
+	return nil
+}
+
+func create_texture(rc *RecordingRenderingContext, wh [2]int, mipmap bool) any {
+	// Record the same calls as WebGL does for creating a texture with its pixel buffer
+	c, texture := rc.GetConstants(), rc.new_handle()
+	rc.record("GLCreateTexture", texture)
+	rc.GLBindTexture(c.TEXTURE_2D, texture)
+	rc.record("GLTexImage2D", c.TEXTURE_2D, 0, c.RGBA, wh[0], wh[1], 0, c.RGBA, c.UNSIGNED_BYTE)
+	if mipmap {
+		rc.record("GLGenerateMipmap", c.TEXTURE_2D)
+	} else { // NON-POWER-OF-2 textures : CLAMP_TO_EDGE & NEAREST/LINEAR only
+		rc.record("GLTexParameteri", c.TEXTURE_2D, c.TEXTURE_WRAP_S, c.CLAMP_TO_EDGE)
+		rc.record("GLTexParameteri", c.TEXTURE_2D, c.TEXTURE_WRAP_T, c.CLAMP_TO_EDGE)
+		rc.record("GLTexParameteri", c.TEXTURE_2D, c.TEXTURE_MIN_FILTER, c.LINEAR)
+	}
+	return texture
+}
+
+// ----------------------------------------------------------------------------
+// Alphabet Texture  (for labels)
+// ----------------------------------------------------------------------------
+
+func measure_material_alphabet_texture(mab *g2d.MaterialAlphabetTexture) {
+	// Size of the alphabet string, as if it was drawn with a fixed-width font,
+	//   where 'fontsize' : 12=>(7.2x12.6), 16=>(9.6x16.8), 20=>(12x21), 24=>(14x25), 30=>(18x31), 40=>(24x42)
+	cwidth := float32(mab.GetFontSize()) * 0.6
+	cheight := float32(mab.GetFontSize()) * 1.05 // we need some more margin below the text
+	twidth := int(math.Floor(float64(cwidth) * float64(mab.GetAlaphabetLength())))
+	theight := int(cheight)
+	mab.SetTextureWH([2]int{twidth, theight})
+	mab.SetAlphabetWH([2]float32{cwidth, cheight})
+}
@@ -0,0 +1,461 @@
+package recording
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go4orward/gigl"
+)
+
+// RecordingRenderingContext is a headless GLRenderingContext, which does not draw anything.
+// Instead, it hands out fake handles for buffers/programs/textures, and records every call,
+// so that unit tests can check what g2d/g3d Renderers actually issue for a given scene.
+
+type RecordingRenderingContext struct {
+	constants    gigl.GLConstants // OpenGL constant values
+	wh           [2]int           // canvas width & height
+	last_handle  uint32           // last fake handle given to a buffer/program/texture
+	array_buffer uint32           // buffer bound to ARRAY_BUFFER
+	buffers      map[uint32]any   // data of buffers ([]float32 or []uint32), by handle
+	extensions   map[string]bool  // extensions that were set up
+	calls        []RecordedCall   // all the calls recorded so far
+}
+
+type RecordedCall struct {
+	Name string // name of the method (like "GLDrawElements")
+	Args []any  // arguments of the call
+}
+
+func (self RecordedCall) String() string {
+	args := make([]string, len(self.Args))
+	for i, arg := range self.Args {
+		args[i] = fmt.Sprintf("%v", arg)
+	}
+	return fmt.Sprintf("%s(%s)", self.Name, strings.Join(args, ", "))
+}
+
+func NewRecordingRenderingContext(width int, height int) *RecordingRenderingContext {
+	self := RecordingRenderingContext{}
+	self.wh = [2]int{width, height}
+	self.buffers = map[uint32]any{}
+	self.extensions = map[string]bool{}
+	// use OpenGL constant values
+	self.constants.ARRAY_BUFFER = 0x8892
+	self.constants.BLEND = 0x0BE2
+	self.constants.BYTE = 0x1400
+	self.constants.CLAMP_TO_EDGE = 0x812F
+	self.constants.COLOR_ATTACHMENT0 = 0x8CE0
+	self.constants.COLOR_BUFFER_BIT = 0x4000
+	self.constants.COMPILE_STATUS = 0x8B81
+	self.constants.DEPTH_ATTACHMENT = 0x8D00
+	self.constants.DEPTH_BUFFER_BIT = 0x0100
+	self.constants.DEPTH_COMPONENT16 = 0x81A5
+	self.constants.DEPTH_TEST = 0x0B71
+	self.constants.DYNAMIC_DRAW = 0x88E8
+	self.constants.ELEMENT_ARRAY_BUFFER = 0x8893
+	self.constants.FLOAT = 0x1406
+	self.constants.FRAGMENT_SHADER = 0x8B30
+	self.constants.FRAMEBUFFER = 0x8D40
+	self.constants.FRAMEBUFFER_COMPLETE = 0x8CD5
+	self.constants.LEQUAL = 0x0203
+	self.constants.LESS = 0x0201
+	self.constants.LINEAR = 0x2601
+	self.constants.LINES = 0x0001
+	self.constants.LINK_STATUS = 0x8B82
+	self.constants.NEAREST = 0x2600
+	self.constants.ONE = 0x0001
+	self.constants.ONE_MINUS_SRC_ALPHA = 0x0303
+	self.constants.POINTS = 0x0000
+	self.constants.RENDERBUFFER = 0x8D41
+	self.constants.RGBA = 0x1908
+	self.constants.SRC_ALPHA = 0x0302
+	self.constants.STATIC_DRAW = 0x88E4
+	self.constants.STREAM_DRAW = 0x88E0
+	self.constants.TEXTURE_2D = 0x0DE1
+	self.constants.TEXTURE0 = 0x84C0
+	self.constants.TEXTURE1 = 0x84C1
+	self.constants.TEXTURE_MIN_FILTER = 0x2801
+	self.constants.TEXTURE_WRAP_S = 0x2802
+	self.constants.TEXTURE_WRAP_T = 0x2803
+	self.constants.TRIANGLES = 0x0004
+	self.constants.UNSIGNED_BYTE = 0x1401
+	self.constants.UNSIGNED_INT = 0x1405
+	self.constants.UNSIGNED_SHORT = 0x1403
+	self.constants.VERTEX_SHADER = 0x8B31
+	return &self
+}
+
+func (self *RecordingRenderingContext) GetWH() [2]int {
+	return self.wh
+}
+
+func (self *RecordingRenderingContext) GetConstants() *gigl.GLConstants {
+	return &self.constants
+}
+
+func (self *RecordingRenderingContext) GetEnvVariable(vname string, dtype string) interface{} {
+	switch dtype {
+	case "int":
+		return 0
+	case "bool":
+		return false
+	default:
+		return ""
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Material & Shader
+// ----------------------------------------------------------------------------
+
+func (self *RecordingRenderingContext) LoadMaterial(material gigl.GLMaterial) error {
+	self.record("LoadMaterial", material.MaterialSummary())
+	return load_material(self, material)
+}
+
+func (self *RecordingRenderingContext) SetupMaterial(material gigl.GLMaterial) error {
+	self.record("SetupMaterial", material.MaterialSummary())
+	return setup_material(self, material)
+}
+
+func (self *RecordingRenderingContext) CreateShader(vertex_shader string, fragment_shader string) (gigl.GLShader, error) {
+	shader, err := create_shader(self, vertex_shader, fragment_shader)
+	self.record("CreateShader", shader.shader_program)
+	return shader, err
+}
+
+// ----------------------------------------------------------------------------
+// Data Buffer
+// ----------------------------------------------------------------------------
+
+func (self *RecordingRenderingContext) CreateDataBufferVAO() *gigl.VAO {
+	self.record("CreateDataBufferVAO")
+	return &gigl.VAO{}
+}
+
+func (self *RecordingRenderingContext) CreateVtxDataBuffer(data_slice []float32, usage uint32) interface{} {
+	if data_slice == nil {
+		return nil
+	}
+	vbo := self.new_handle()
+	self.buffers[vbo] = append([]float32{}, data_slice...) // keep a copy of the data
+	self.record("CreateVtxDataBuffer", len(data_slice), usage, vbo)
+	return vbo
+}
+
+func (self *RecordingRenderingContext) CreateIdxDataBuffer(data_slice []uint32) interface{} {
+	if data_slice == nil {
+		return nil
+	}
+	vbo := self.new_handle()
+	self.buffers[vbo] = append([]uint32{}, data_slice...) // keep a copy of the data
+	self.record("CreateIdxDataBuffer", len(data_slice), vbo)
+	return vbo
+}
+
+func (self *RecordingRenderingContext) GLBindBuffer(target uint32, buffer interface{}) {
+	// 'bind_target' : c.ARRAY_BUFFER or c.ELEMENT_ARRAY_BUFFER
+	self.record("GLBindBuffer", target, buffer)
+	if target == self.constants.ARRAY_BUFFER {
+		self.array_buffer, _ = buffer.(uint32)
+	}
+}
+
+func (self *RecordingRenderingContext) GLBufferData(target uint32, data_slice []float32, usage uint32) {
+	if _, ok := self.buffers[self.array_buffer]; ok && target == self.constants.ARRAY_BUFFER {
+		self.buffers[self.array_buffer] = append([]float32{}, data_slice...) // keep a copy of the data
+	}
+	self.record("GLBufferData", target, len(data_slice), usage)
+}
+
+func (self *RecordingRenderingContext) GLBufferSubData(target uint32, offset_in_byte int, data_slice []float32) {
+	if data, ok := self.buffers[self.array_buffer].([]float32); ok && target == self.constants.ARRAY_BUFFER {
+		if offset := offset_in_byte / 4; offset >= 0 && offset+len(data_slice) <= len(data) {
+			copy(data[offset:], data_slice)
+		}
+	}
+	self.record("GLBufferSubData", target, offset_in_byte, len(data_slice))
+}
+
+func (self *RecordingRenderingContext) GLBindVertexArray(vertex_array interface{}) {
+	self.record("GLBindVertexArray", vertex_array)
+}
+
+// ----------------------------------------------------------------------------
+// Binding Texture
+// ----------------------------------------------------------------------------
+
+func (self *RecordingRenderingContext) GLActiveTexture(texture_unit int) {
+	self.record("GLActiveTexture", texture_unit)
+}
+
+func (self *RecordingRenderingContext) GLBindTexture(target uint32, texture interface{}) {
+	// 'binding_target' : TEXTURE_2D
+	self.record("GLBindTexture", target, texture)
+}
+
+// ----------------------------------------------------------------------------
+// Framebuffer
+// ----------------------------------------------------------------------------
+
+func (self *RecordingRenderingContext) CreateFramebuffer() interface{} {
+	framebuffer := self.new_handle()
+	self.record("CreateFramebuffer", framebuffer)
+	return framebuffer
+}
+
+func (self *RecordingRenderingContext) CreateRenderbuffer() interface{} {
+	renderbuffer := self.new_handle()
+	self.record("CreateRenderbuffer", renderbuffer)
+	return renderbuffer
+}
+
+func (self *RecordingRenderingContext) CreateTexture(width int, height int) interface{} {
+	texture := self.new_handle()
+	self.record("CreateTexture", width, height, texture)
+	return texture
+}
+
+func (self *RecordingRenderingContext) GLBindFramebuffer(target uint32, framebuffer interface{}) {
+	self.record("GLBindFramebuffer", target, framebuffer)
+}
+
+func (self *RecordingRenderingContext) GLBindRenderbuffer(target uint32, renderbuffer interface{}) {
+	self.record("GLBindRenderbuffer", target, renderbuffer)
+}
+
+func (self *RecordingRenderingContext) GLRenderbufferStorage(target uint32, internalformat uint32, width int, height int) {
+	self.record("GLRenderbufferStorage", target, internalformat, width, height)
+}
+
+func (self *RecordingRenderingContext) GLFramebufferTexture2D(target uint32, attachment uint32, textarget uint32, texture interface{}, level int) {
+	self.record("GLFramebufferTexture2D", target, attachment, textarget, texture, level)
+}
+
+func (self *RecordingRenderingContext) GLFramebufferRenderbuffer(target uint32, attachment uint32, renderbuffertarget uint32, renderbuffer interface{}) {
+	self.record("GLFramebufferRenderbuffer", target, attachment, renderbuffertarget, renderbuffer)
+}
+
+func (self *RecordingRenderingContext) GLCheckFramebufferStatus(target uint32) uint32 {
+	self.record("GLCheckFramebufferStatus", target)
+	return self.constants.FRAMEBUFFER_COMPLETE
+}
+
+func (self *RecordingRenderingContext) GLViewport(x int, y int, width int, height int) {
+	self.record("GLViewport", x, y, width, height)
+}
+
+// ----------------------------------------------------------------------------
+// Binding Uniforms
+// ----------------------------------------------------------------------------
+
+func (self *RecordingRenderingContext) GLUniform1i(location interface{}, v0 int) {
+	self.record("GLUniform1i", location, v0)
+}
+
+func (self *RecordingRenderingContext) GLUniform1f(location interface{}, v0 float32) {
+	self.record("GLUniform1f", location, v0)
+}
+
+func (self *RecordingRenderingContext) GLUniform2f(location interface{}, v0 float32, v1 float32) {
+	self.record("GLUniform2f", location, v0, v1)
+}
+
+func (self *RecordingRenderingContext) GLUniform3f(location interface{}, v0 float32, v1 float32, v2 float32) {
+	self.record("GLUniform3f", location, v0, v1, v2)
+}
+
+func (self *RecordingRenderingContext) GLUniform4f(location interface{}, v0 float32, v1 float32, v2 float32, v3 float32) {
+	self.record("GLUniform4f", location, v0, v1, v2, v3)
+}
+
+func (self *RecordingRenderingContext) GLUniformMatrix3fv(location interface{}, transpose bool, values []float32) {
+	self.record("GLUniformMatrix3fv", location, transpose, append([]float32{}, values...))
+}
+
+func (self *RecordingRenderingContext) GLUniformMatrix4fv(location interface{}, transpose bool, values []float32) {
+	self.record("GLUniformMatrix4fv", location, transpose, append([]float32{}, values...))
+}
+
+// ----------------------------------------------------------------------------
+// Binding Attributes
+// ----------------------------------------------------------------------------
+
+func (self *RecordingRenderingContext) GLVertexAttribPointer(location interface{}, size int, dtype uint32, normalized bool, stride_in_byte int, offset_in_byte int) {
+	self.record("GLVertexAttribPointer", location, size, dtype, normalized, stride_in_byte, offset_in_byte)
+}
+
+func (self *RecordingRenderingContext) GLEnableVertexAttribArray(location interface{}) {
+	self.record("GLEnableVertexAttribArray", location)
+}
+
+func (self *RecordingRenderingContext) GLVertexAttribDivisor(location interface{}, divisor int) {
+	self.record("GLVertexAttribDivisor", location, divisor)
+}
+
+// ----------------------------------------------------------------------------
+// Preparing to Render
+// ----------------------------------------------------------------------------
+
+func (self *RecordingRenderingContext) GLClearColor(r float32, g float32, b float32, a float32) {
+	self.record("GLClearColor", r, g, b, a)
+}
+
+func (self *RecordingRenderingContext) GLClear(mask uint32) {
+	self.record("GLClear", mask)
+}
+
+func (self *RecordingRenderingContext) GLEnable(cap uint32) {
+	self.record("GLEnable", cap)
+}
+
+func (self *RecordingRenderingContext) GLDisable(cap uint32) {
+	self.record("GLDisable", cap)
+}
+
+func (self *RecordingRenderingContext) GLDepthFunc(ftn uint32) {
+	self.record("GLDepthFunc", ftn)
+}
+
+func (self *RecordingRenderingContext) GLBlendFunc(sfactor uint32, dfactor uint32) {
+	self.record("GLBlendFunc", sfactor, dfactor)
+}
+
+func (self *RecordingRenderingContext) GLUseProgram(shader_program interface{}) {
+	self.record("GLUseProgram", shader_program)
+}
+
+// ----------------------------------------------------------------------------
+// Rendering
+// ----------------------------------------------------------------------------
+
+func (self *RecordingRenderingContext) GLDrawArrays(mode uint32, first int, count int) {
+	// 'mode' : POINTS
+	self.record("GLDrawArrays", mode, first, count)
+}
+
+func (self *RecordingRenderingContext) GLDrawArraysInstanced(mode uint32, first int, count int, pose_count int) {
+	// 'mode' : POINTS
+	self.record("GLDrawArraysInstanced", mode, first, count, pose_count)
+}
+
+func (self *RecordingRenderingContext) GLDrawElements(mode uint32, count int, dtype uint32, offset int) {
+	// 'mode'  : LINES, TRIANGLES
+	// 'dtype' : UNSIGNED_INT
+	self.record("GLDrawElements", mode, count, dtype, offset)
+}
+
+func (self *RecordingRenderingContext) GLDrawElementsInstanced(mode uint32, element_count int, dtype uint32, offset int, pose_count int) {
+	// 'mode'  : LINES, TRIANGLES
+	// 'dtype' : UNSIGNED_INT
+	self.record("GLDrawElementsInstanced", mode, element_count, dtype, offset, pose_count)
+}
+
+// ----------------------------------------------------------------------------
+// Reading Pixels
+// ----------------------------------------------------------------------------
+
+func (self *RecordingRenderingContext) GLReadPixels(x int, y int, width int, height int, format uint32, dtype uint32, pixels []uint8) {
+	// nothing was actually drawn, so 'pixels' are left as they are
+	self.record("GLReadPixels", x, y, width, height, format, dtype)
+}
+
+// ----------------------------------------------------------------------------
+// Deleting Resources
+// ----------------------------------------------------------------------------
+
+func (self *RecordingRenderingContext) GLDeleteBuffer(buffer interface{}) {
+	if handle, ok := buffer.(uint32); ok {
+		delete(self.buffers, handle)
+	}
+	self.record("GLDeleteBuffer", buffer)
+}
+
+func (self *RecordingRenderingContext) GLDeleteVertexArray(vertex_array interface{}) {
+	self.record("GLDeleteVertexArray", vertex_array)
+}
+
+func (self *RecordingRenderingContext) GLDeleteTexture(texture interface{}) {
+	self.record("GLDeleteTexture", texture)
+}
+
+func (self *RecordingRenderingContext) GLDeleteProgram(program interface{}) {
+	self.record("GLDeleteProgram", program)
+}
+
+func (self *RecordingRenderingContext) GLDeleteFramebuffer(framebuffer interface{}) {
+	self.record("GLDeleteFramebuffer", framebuffer)
+}
+
+func (self *RecordingRenderingContext) GLDeleteRenderbuffer(renderbuffer interface{}) {
+	self.record("GLDeleteRenderbuffer", renderbuffer)
+}
+
+// ----------------------------------------------------------------------------
+// Extensions
+// ----------------------------------------------------------------------------
+
+func (self *RecordingRenderingContext) SetupExtension(extname string) {
+	// pretend that all the extensions ("UINT32", "ANGLE") are available
+	self.record("SetupExtension", extname)
+	self.extensions[extname] = true
+}
+
+func (self *RecordingRenderingContext) IsExtensionReady(extname string) bool {
+	return self.extensions[extname]
+}
+
+// ----------------------------------------------------------------------------
+// Inspecting Recorded Calls
+// ----------------------------------------------------------------------------
+
+func (self *RecordingRenderingContext) GetCalls() []RecordedCall {
+	return self.calls
+}
+
+func (self *RecordingRenderingContext) GetCallsByName(name string) []RecordedCall {
+	calls := []RecordedCall{}
+	for _, call := range self.calls {
+		if call.Name == name {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+func (self *RecordingRenderingContext) CountCalls(name string) int {
+	return len(self.GetCallsByName(name))
+}
+
+func (self *RecordingRenderingContext) ClearCalls() {
+	self.calls = nil
+}
+
+func (self *RecordingRenderingContext) GetBufferData(buffer any) any {
+	// Get the data ([]float32 or []uint32) of the buffer created with the given handle.
+	if handle, ok := buffer.(uint32); ok {
+		return self.buffers[handle]
+	}
+	return nil
+}
+
+func (self *RecordingRenderingContext) Summary() string {
+	summary := fmt.Sprintf("RecordingRenderingContext %dx%d with %d calls\n", self.wh[0], self.wh[1], len(self.calls))
+	for i, call := range self.calls {
+		summary += fmt.Sprintf("  %4d  %s\n", i, call.String())
+	}
+	return strings.TrimSuffix(summary, "\n")
+}
+
+// ----------------------------------------------------------------------------
+// private functions
+// ----------------------------------------------------------------------------
+
+func (self *RecordingRenderingContext) record(name string, args ...any) {
+	self.calls = append(self.calls, RecordedCall{Name: name, Args: args})
+}
+
+func (self *RecordingRenderingContext) new_handle() uint32 {
+	self.last_handle++ // NON-ZERO values, like OpenGL
+	return self.last_handle
+}
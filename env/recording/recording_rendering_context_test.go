@@ -0,0 +1,179 @@
+package recording
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/go4orward/gigl"
+	"github.com/go4orward/gigl/g2d"
+)
+
+func TestRecordingG2DScene(t *testing.T) {
+	// A rectangle with its faces & edges should be drawn by a draw call for each of them
+	rc := NewRecordingRenderingContext(100, 100)
+	c := rc.GetConstants()
+	geometry := g2d.NewGeometryRectangle(1.0)
+	geometry.BuildDataBuffers(true, true, true)
+	material := g2d.NewMaterialColors("#ff0000")
+	sobj := g2d.NewSceneObject(geometry, material, nil, g2d.NewShaderForMaterialColors(rc), g2d.NewShaderForMaterialColors(rc))
+	scene := g2d.NewScene("#ffffff").Add(sobj)
+	renderer := g2d.NewRenderer(rc)
+	renderer.Clear(scene)
+	renderer.RenderScene(scene, g2d.NewCamera(rc.GetWH(), 2.0, 1.0))
+	if n := rc.CountCalls("CreateShader"); n != 2 {
+		t.Errorf("%d shaders created (expected 2)", n)
+	}
+	if n := rc.CountCalls("GLUseProgram"); n != 2 {
+		t.Errorf("%d programs used (expected 2)", n)
+	}
+	draws := rc.GetCallsByName("GLDrawElements")
+	if len(draws) != 2 {
+		t.Fatalf("%d draw calls (expected 2) :\n%s", len(draws), rc.Summary())
+	}
+	if mode, count := draws[0].Args[0], draws[0].Args[1]; mode != c.TRIANGLES || count != 6 {
+		t.Errorf("faces drawn by %v", draws[0])
+	}
+	if mode, count := draws[1].Args[0], draws[1].Args[1]; mode != c.LINES || count != 6 {
+		t.Errorf("edges drawn by %v", draws[1])
+	}
+	for _, call := range rc.GetCallsByName("GLUniform4f") {
+		if call.Args[1] != float32(1) || call.Args[2] != float32(0) || call.Args[3] != float32(0) {
+			t.Errorf("color of the material was not set : %v", call)
+		}
+	}
+}
+
+func TestRecordingAlphabetTexture(t *testing.T) {
+	// Alphabet texture should be measured, and then created (only once) to be bound for the draw call
+	rc := NewRecordingRenderingContext(100, 100)
+	c := rc.GetConstants()
+	geometry := g2d.NewGeometryRectangle(1.0)
+	geometry.SetTextureUVs([][]float32{{0, 0}, {1, 0}, {1, 1}, {0, 1}})
+	geometry.BuildDataBuffers(true, false, true)
+	material := g2d.NewMaterialAlphabetTexture("Courier New", 20, "#ffffff", false)
+	sobj := g2d.NewSceneObject(geometry, material, nil, nil, g2d.NewShaderForMaterialTexture(rc))
+	scene := g2d.NewScene("#ffffff").Add(sobj)
+	renderer := g2d.NewRenderer(rc)
+	camera := g2d.NewCamera(rc.GetWH(), 2.0, 1.0)
+	for i := 0; i < 3; i++ { // (load, setup, and then bind the texture)
+		renderer.Clear(scene)
+		renderer.RenderScene(scene, camera)
+	}
+	if !material.IsReady() {
+		t.Fatalf("alphabet texture is not ready :\n%s", rc.Summary())
+	}
+	texture, wh := material.GetTexture(), material.GetTextureWH()
+	if wh != [2]int{12 * material.GetAlaphabetLength(), 21} {
+		t.Errorf("alphabet texture of size %v", wh)
+	}
+	if n := rc.CountCalls("GLCreateTexture"); n != 1 {
+		t.Errorf("%d textures created (expected 1)", n)
+	}
+	if calls := rc.GetCallsByName("GLTexImage2D"); len(calls) != 1 || calls[0].Args[3] != wh[0] || calls[0].Args[4] != wh[1] {
+		t.Errorf("texture image was not given with size %v : %v", wh, calls)
+	}
+	if n := rc.CountCalls("GLTexParameteri"); n != 3 {
+		t.Errorf("%d texture parameters set (expected 3)", n)
+	}
+	if binds := rc.GetCallsByName("GLBindTexture"); len(binds) != 2 || binds[1].Args[0] != c.TEXTURE_2D || binds[1].Args[1] != texture {
+		t.Errorf("texture was not bound for rendering : %v", binds)
+	}
+	if n := rc.CountCalls("GLDrawElements"); n != 3 {
+		t.Errorf("%d draw calls (expected 3)", n)
+	}
+	rc.ClearCalls()
+	sobj.Dispose(rc)
+	if calls := rc.GetCallsByName("GLDeleteTexture"); len(calls) != 1 || calls[0].Args[0] != texture {
+		t.Errorf("texture was not deleted : %v", calls)
+	}
+}
+
+func TestRecordingShaderCopy(t *testing.T) {
+	// Copies of a shader should not be ready any more, once its program was deleted
+	rc := NewRecordingRenderingContext(100, 100)
+	shader := g2d.NewShaderForMaterialColors(rc)
+	scopy := shader.Copy()
+	if !scopy.IsReady() {
+		t.Fatalf("copy of the shader is not ready")
+	}
+	shader.Dispose()
+	if shader.IsReady() || scopy.IsReady() {
+		t.Errorf("shader (%v) or its copy (%v) is still ready after disposed", shader.IsReady(), scopy.IsReady())
+	}
+	scopy.Dispose()
+	if n := rc.CountCalls("GLDeleteProgram"); n != 1 {
+		t.Errorf("shader program deleted %d times (expected 1)", n)
+	}
+}
+
+func TestRecordingRenderTarget(t *testing.T) {
+	// RenderTarget used as a material should not be deleted by the SceneObject, while it's still targeted
+	rc := NewRecordingRenderingContext(100, 100)
+	target, err := gigl.NewRenderTarget(rc, 64, 64)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	geometry := g2d.NewGeometryRectangle(1.0)
+	geometry.SetTextureUVs([][]float32{{0, 0}, {1, 0}, {1, 1}, {0, 1}})
+	geometry.BuildDataBuffers(true, false, true)
+	sobj1 := g2d.NewSceneObject(geometry, target, nil, nil, g2d.NewShaderForMaterialTexture(rc))
+	sobj2 := g2d.NewSceneObject(geometry, target, nil, nil, g2d.NewShaderForMaterialTexture(rc))
+	sobj1.Dispose(rc)
+	sobj2.Dispose(rc)
+	if n := rc.CountCalls("GLDeleteFramebuffer") + rc.CountCalls("GLDeleteTexture"); n != 0 || !target.IsReady() {
+		t.Errorf("RenderTarget was deleted by its SceneObjects :\n%s", rc.Summary())
+	}
+	target.Dispose(rc)
+	if n := rc.CountCalls("GLDeleteFramebuffer"); n != 1 || target.IsReady() {
+		t.Errorf("RenderTarget was not deleted by its owner :\n%s", rc.Summary())
+	}
+}
+
+func TestRecordingTextureMaterials(t *testing.T) {
+	// Every texture material should record the same calls for creating its texture
+	rc := NewRecordingRenderingContext(100, 100)
+	c := rc.GetConstants()
+	materials := []gigl.GLMaterialTexture{
+		g2d.NewMaterialTextureFromImageBytes("pow2.png", encode_png(t, 4, 8)),
+		g2d.NewMaterialTextureFromImageBytes("npot.png", encode_png(t, 3, 5)),
+		g2d.NewMaterialGlowTexture("#ff0000"),
+	}
+	for i, mipmap := range []bool{true, false, false} {
+		rc.ClearCalls()
+		material := materials[i]
+		rc.LoadMaterial(material)
+		rc.SetupMaterial(material)
+		if !material.IsReady() {
+			t.Fatalf("%s is not ready", material.MaterialSummary())
+		}
+		texture, wh := material.GetTexture(), material.GetTextureWH()
+		if calls := rc.GetCallsByName("GLCreateTexture"); len(calls) != 1 || calls[0].Args[0] != texture {
+			t.Errorf("texture of %s was not created : %v", material.MaterialSummary(), calls)
+		}
+		if binds := rc.GetCallsByName("GLBindTexture"); len(binds) != 1 || binds[0].Args[0] != c.TEXTURE_2D || binds[0].Args[1] != texture {
+			t.Errorf("texture of %s was not bound : %v", material.MaterialSummary(), binds)
+		}
+		if calls := rc.GetCallsByName("GLTexImage2D"); len(calls) != 1 || calls[0].Args[3] != wh[0] || calls[0].Args[4] != wh[1] {
+			t.Errorf("texture image of %s was not given with size %v : %v", material.MaterialSummary(), wh, calls)
+		}
+		if mipmap && (rc.CountCalls("GLGenerateMipmap") != 1 || rc.CountCalls("GLTexParameteri") != 0) {
+			t.Errorf("mipmap of %s was not generated :\n%s", material.MaterialSummary(), rc.Summary())
+		} else if !mipmap && (rc.CountCalls("GLGenerateMipmap") != 0 || rc.CountCalls("GLTexParameteri") != 3) {
+			t.Errorf("texture parameters of %s were not set :\n%s", material.MaterialSummary(), rc.Summary())
+		}
+	}
+}
+
+func encode_png(t *testing.T, width int, height int) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	buf := bytes.Buffer{}
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("%v", err)
+	}
+	return buf.Bytes()
+}
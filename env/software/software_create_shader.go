@@ -0,0 +1,256 @@
+package software
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go4orward/gigl"
+	"github.com/go4orward/gigl/common"
+)
+
+type SoftwareShader struct {
+	rc             *SoftwareRenderingContext //
+	vshader_code   string                    // vertex   shader source code
+	fshader_code   string                    // fragment shader source code
+	shader_program *software_program         // compiled shader program
+	deleted        *bool                     // shared with its copies (true, after the shader program was deleted)
+	err            error                     //
+
+	gigl.GLShaderBinder
+	*gigl.RefCounter // shared with its copies, since they share the same shader program
+}
+
+type software_program struct {
+	vshader    *glsl_shader_unit  // compiled vertex shader
+	fshader    *glsl_shader_unit  // compiled fragment shader
+	vexec      *glsl_exec         // execution context (with variable values) of vertex shader
+	fexec      *glsl_exec         // execution context (with variable values) of fragment shader
+	uniforms   []software_uniform // uniforms of both shaders (location is the index)
+	attributes []*glsl_symbol     // attributes of vertex shader (location is the index)
+	varyings   []software_varying // varyings linked between vertex & fragment shader
+	nvarying   int                // number of float32 values for all the varyings
+}
+
+type software_uniform struct {
+	name  string    // name of the uniform
+	typ   glsl_type // type of the uniform
+	vslot int       // slot in vertex   shader (-1, if not used)
+	fslot int       // slot in fragment shader (-1, if not used)
+}
+
+type software_varying struct {
+	vslot int // slot in vertex   shader
+	fslot int // slot in fragment shader
+	count int // number of float32 values
+}
+
+// ----------------------------------------------------------------------------
+// Creating Shader
+// ----------------------------------------------------------------------------
+
+func create_shader(rc *SoftwareRenderingContext, vshader_source string, fshader_source string) (*SoftwareShader, error) {
+	// THIS CONSTRUCTOR FUNCTION IS NOT MEANT TO BE CALLED DIRECTLY BY USER.
+	// IT SHOULD BE CALLED BY 'SoftwareRenderingContext.CreateShader()'.
+	shader := SoftwareShader{rc: rc, RefCounter: &gigl.RefCounter{}, deleted: new(bool)}
+	shader.CreateShaderProgram(vshader_source, fshader_source)
+	shader.InitBindings()
+	return &shader, shader.err
+}
+
+func (self *SoftwareShader) CreateShaderProgram(vshader_source string, fshader_source string) {
+	self.vshader_code = vshader_source
+	self.fshader_code = fshader_source
+	self.shader_program = nil
+	self.err = nil
+	// vertex shader
+	vshader, err := glsl_compile("vertex", vshader_source)
+	if err != nil {
+		self.err = errors.New("VShader failed to compile")
+		common.Logger.Error("VShader failed to compile : %v\n", err)
+		return
+	}
+	// fragment shader
+	fshader, err := glsl_compile("fragment", fshader_source)
+	if err != nil {
+		self.err = errors.New("FShader failed to compile")
+		common.Logger.Error("FShader failed to compile : %v\n", err)
+		return
+	}
+	// shader program (linking uniforms & varyings of both shaders)
+	program := &software_program{vshader: vshader, fshader: fshader}
+	program.vexec, program.fexec = vshader.new_exec(), fshader.new_exec()
+	for _, u := range vshader.uniforms {
+		program.uniforms = append(program.uniforms, software_uniform{name: u.name, typ: u.typ, vslot: u.slot, fslot: -1})
+	}
+	for _, u := range fshader.uniforms {
+		found := false
+		for i := range program.uniforms {
+			if program.uniforms[i].name == u.name {
+				if program.uniforms[i].typ != u.typ {
+					self.err = errors.New("ShaderProgram failed to link")
+					common.Logger.Error("ShaderProgram failed to link : uniform %q with different types\n", u.name)
+					return
+				}
+				program.uniforms[i].fslot, found = u.slot, true
+			}
+		}
+		if !found {
+			program.uniforms = append(program.uniforms, software_uniform{name: u.name, typ: u.typ, vslot: -1, fslot: u.slot})
+		}
+	}
+	program.attributes = vshader.attributes
+	for _, fv := range fshader.varyings {
+		vv, ok := vshader.globals[fv.name]
+		if !ok || vv.qualifier != "varying" || vv.typ != fv.typ {
+			self.err = errors.New("ShaderProgram failed to link")
+			common.Logger.Error("ShaderProgram failed to link : varying %q not matching\n", fv.name)
+			return
+		}
+		program.varyings = append(program.varyings, software_varying{vslot: vv.slot, fslot: fv.slot, count: fv.typ.count()})
+		program.nvarying += fv.typ.count()
+	}
+	self.shader_program = program
+}
+
+func (self *SoftwareShader) IsReady() bool {
+	return !*self.deleted && self.shader_program != nil && self.err == nil
+}
+
+func (self *SoftwareShader) GetShaderProgram() any {
+	return self.shader_program
+}
+
+func (self *SoftwareShader) GetErr() error {
+	return self.err
+}
+
+func (self *SoftwareShader) Dispose() {
+	// Delete the shader program (note that its copies cannot be used any more, either)
+	if self.shader_program != nil && !*self.deleted {
+		self.rc.GLDeleteProgram(self.shader_program)
+	}
+	*self.deleted = true
+	self.shader_program = nil
+}
+
+// ----------------------------------------------------------------------------
+// Shader Bindings
+// ----------------------------------------------------------------------------
+
+func (self *SoftwareShader) CheckBindings() {
+	// check if the shader was properly built
+	if self.err != nil {
+		common.Logger.Error("ShaderProgram is not ready for CheckBindings (%v)\n", self.err)
+		return
+	}
+	// check uniform locations (type: 'int32')
+	for uname, utarget := range self.Uniforms {
+		location := self.shader_program.get_uniform_location(uname)
+		if location < 0 {
+			self.err = fmt.Errorf("Uniform %q cannot be found in the shader program\n", uname)
+			common.Logger.Error(self.err.Error())
+		} else if utarget.Target == nil {
+			self.err = fmt.Errorf("Invalid binding for uniform %q : %v \n", uname, utarget)
+			common.Logger.Error(self.err.Error())
+		} else {
+			utarget.Loc = location // save it as any
+		}
+		self.Uniforms[uname] = utarget
+	}
+	// check attribute locations (type: 'int32')
+	for aname, atarget := range self.Attributes {
+		location := self.shader_program.get_attribute_location(aname)
+		if location < 0 {
+			self.err = fmt.Errorf("Attribute %q cannot be found in the shader program\n", aname)
+			common.Logger.Error(self.err.Error())
+		} else if atarget.Target == nil {
+			self.err = fmt.Errorf("Invalid binding for attribute %q : %v \n", aname, atarget)
+			common.Logger.Error(self.err.Error())
+		} else {
+			atarget.Loc = location // save it as any
+		}
+		self.Attributes[aname] = atarget
+	}
+}
+
+func (self *software_program) get_uniform_location(name string) int32 {
+	for i, u := range self.uniforms {
+		if u.name == name {
+			return int32(i)
+		}
+	}
+	return -1
+}
+
+func (self *software_program) get_attribute_location(name string) int32 {
+	for i, a := range self.attributes {
+		if a.name == name {
+			return int32(i)
+		}
+	}
+	return -1
+}
+
+func (self *software_program) set_uniform(location any, values ...float32) {
+	loc, ok := location.(int32)
+	if !ok || loc < 0 || int(loc) >= len(self.uniforms) {
+		return
+	}
+	u := self.uniforms[loc]
+	var v glsl_value
+	copy(v[:u.typ.count()], values)
+	if u.vslot >= 0 {
+		self.vexec.slots[u.vslot] = v
+	}
+	if u.fslot >= 0 {
+		self.fexec.slots[u.fslot] = v
+	}
+}
+
+// ----------------------------------------------------------------------------
+//
+// ----------------------------------------------------------------------------
+
+func (self *SoftwareShader) Copy() gigl.GLShader {
+	// create a new shader as a copy with empty binding
+	shader := SoftwareShader{rc: self.rc, vshader_code: self.vshader_code, fshader_code: self.fshader_code}
+	shader.shader_program = self.shader_program
+	shader.RefCounter = self.RefCounter
+	shader.deleted = self.deleted
+	// initialize shader bindings with empty map
+	shader.InitBindings()
+	return &shader
+}
+
+func (self *SoftwareShader) String() string {
+	vert, frag, prog := "X", "X", "X"
+	if self.err == nil || !strings.HasPrefix(self.err.Error(), "VShader") {
+		vert = "O"
+		if self.err == nil || !strings.HasPrefix(self.err.Error(), "FShader") {
+			frag = "O"
+			if self.err == nil {
+				prog = "O"
+			}
+		}
+	}
+	return fmt.Sprintf("Shader{V:%s F:%s P:%s Unf:%d Att:%d}", vert, frag, prog, len(self.Uniforms), len(self.Attributes))
+}
+
+func (self *SoftwareShader) Summary() string {
+	summary := ""
+	if self.err == nil && self.shader_program != nil {
+		summary += fmt.Sprintf("Shader  program:Y \n")
+	} else if self.err == nil && self.shader_program == nil {
+		summary += fmt.Sprintf("Shader  program:N \n")
+	} else {
+		summary += fmt.Sprintf("Shader  with Error (%s)\n", self.err.Error())
+	}
+	for uname, ut := range self.Uniforms {
+		summary += fmt.Sprintf("    Uniform   %-10s: %s\n", uname, ut.String())
+	}
+	for aname, at := range self.Attributes {
+		summary += fmt.Sprintf("    Attribute %-10s: %s\n", aname, at.String())
+	}
+	return strings.TrimSuffix(summary, "\n")
+}
@@ -0,0 +1,407 @@
+package software
+
+import (
+	"math"
+)
+
+// ----------------------------------------------------------------------------
+// GLSL Built-in Functions
+// ----------------------------------------------------------------------------
+
+type glsl_builtin func(p *glsl_parser, name string, args []*glsl_expr) *glsl_expr
+
+var glsl_builtins map[string]glsl_builtin
+
+func init() {
+	f64 := func(f func(float64) float64) func(float32) float32 {
+		return func(a float32) float32 { return float32(f(float64(a))) }
+	}
+	glsl_builtins = map[string]glsl_builtin{
+		// angle & trigonometry functions
+		"radians": glsl_gen1(func(a float32) float32 { return a * math.Pi / 180 }),
+		"degrees": glsl_gen1(func(a float32) float32 { return a * 180 / math.Pi }),
+		"sin":     glsl_gen1(f64(math.Sin)),
+		"cos":     glsl_gen1(f64(math.Cos)),
+		"tan":     glsl_gen1(f64(math.Tan)),
+		"asin":    glsl_gen1(f64(math.Asin)),
+		"acos":    glsl_gen1(f64(math.Acos)),
+		"atan":    glsl_builtin_atan,
+		// exponential functions
+		"pow":         glsl_gen2(func(a, b float32) float32 { return float32(math.Pow(float64(a), float64(b))) }),
+		"exp":         glsl_gen1(f64(math.Exp)),
+		"log":         glsl_gen1(f64(math.Log)),
+		"exp2":        glsl_gen1(f64(math.Exp2)),
+		"log2":        glsl_gen1(f64(math.Log2)),
+		"sqrt":        glsl_gen1(f64(math.Sqrt)),
+		"inversesqrt": glsl_gen1(func(a float32) float32 { return float32(1 / math.Sqrt(float64(a))) }),
+		// common functions
+		"abs":   glsl_gen1(f64(math.Abs)),
+		"sign":  glsl_gen1(glsl_sign),
+		"floor": glsl_gen1(f64(math.Floor)),
+		"ceil":  glsl_gen1(f64(math.Ceil)),
+		"fract": glsl_gen1(func(a float32) float32 { return a - float32(math.Floor(float64(a))) }),
+		"mod":   glsl_gen2(func(a, b float32) float32 { return a - b*float32(math.Floor(float64(a/b))) }),
+		"min":   glsl_gen2(func(a, b float32) float32 { return float32(math.Min(float64(a), float64(b))) }),
+		"max":   glsl_gen2(func(a, b float32) float32 { return float32(math.Max(float64(a), float64(b))) }),
+		"step": glsl_gen2(func(edge, a float32) float32 {
+			if a < edge {
+				return 0
+			}
+			return 1
+		}),
+		"clamp": glsl_gen3(func(a, lo, hi float32) float32 {
+			return float32(math.Min(math.Max(float64(a), float64(lo)), float64(hi)))
+		}),
+		"mix": glsl_gen3(func(a, b, t float32) float32 { return a*(1-t) + b*t }),
+		"smoothstep": glsl_gen3(func(e0, e1, a float32) float32 {
+			t := float32(math.Min(math.Max(float64((a-e0)/(e1-e0)), 0), 1))
+			return t * t * (3 - 2*t)
+		}),
+		// geometric functions
+		"length":      glsl_builtin_length,
+		"distance":    glsl_builtin_distance,
+		"dot":         glsl_builtin_dot,
+		"cross":       glsl_builtin_cross,
+		"normalize":   glsl_builtin_normalize,
+		"faceforward": glsl_builtin_faceforward,
+		"reflect":     glsl_builtin_reflect,
+		"refract":     glsl_builtin_refract,
+		// matrix functions
+		"matrixCompMult": glsl_builtin_matrix_comp_mult,
+		// vector relational functions
+		"lessThan":         glsl_relational(func(a, b float32) bool { return a < b }),
+		"lessThanEqual":    glsl_relational(func(a, b float32) bool { return a <= b }),
+		"greaterThan":      glsl_relational(func(a, b float32) bool { return a > b }),
+		"greaterThanEqual": glsl_relational(func(a, b float32) bool { return a >= b }),
+		"equal":            glsl_relational(func(a, b float32) bool { return a == b }),
+		"notEqual":         glsl_relational(func(a, b float32) bool { return a != b }),
+		"any":              glsl_builtin_any_all,
+		"all":              glsl_builtin_any_all,
+		"not":              glsl_builtin_not,
+		// texture lookup functions
+		"texture2D":     glsl_builtin_texture2D,
+		"texture2DLod":  glsl_builtin_texture2D,
+		"texture2DProj": glsl_builtin_texture2D,
+	}
+}
+
+func glsl_check_args(p *glsl_parser, name string, args []*glsl_expr, nargs int) {
+	if len(args) != nargs {
+		p.fail("'%s' requires %d arguments", name, nargs)
+	}
+	for _, arg := range args {
+		if arg.typ.base != 'f' || arg.typ.mat {
+			p.fail("invalid argument '%v' for '%s'", arg.typ, name)
+		}
+	}
+}
+
+func glsl_gen1(f func(a float32) float32) glsl_builtin {
+	// genType f(genType)
+	return func(p *glsl_parser, name string, args []*glsl_expr) *glsl_expr {
+		glsl_check_args(p, name, args, 1)
+		n, eval := args[0].typ.count(), args[0].eval
+		return &glsl_expr{typ: args[0].typ, eval: func(x *glsl_exec) glsl_value {
+			v := eval(x)
+			for i := 0; i < n; i++ {
+				v[i] = f(v[i])
+			}
+			return v
+		}}
+	}
+}
+
+func glsl_gen2(f func(a, b float32) float32) glsl_builtin {
+	// genType f(genType, genType), genType f(genType, float), or genType f(float, genType)
+	return func(p *glsl_parser, name string, args []*glsl_expr) *glsl_expr {
+		glsl_check_args(p, name, args, 2)
+		rtype, strides := glsl_broadcast(p, name, args)
+		n, aeval, beval := rtype.count(), args[0].eval, args[1].eval
+		as, bs := strides[0], strides[1]
+		return &glsl_expr{typ: rtype, eval: func(x *glsl_exec) (r glsl_value) {
+			a, b := aeval(x), beval(x)
+			for i := 0; i < n; i++ {
+				r[i] = f(a[i*as], b[i*bs])
+			}
+			return r
+		}}
+	}
+}
+
+func glsl_gen3(f func(a, b, c float32) float32) glsl_builtin {
+	// genType f(genType, genType, genType), with some of them possibly being float
+	return func(p *glsl_parser, name string, args []*glsl_expr) *glsl_expr {
+		glsl_check_args(p, name, args, 3)
+		rtype, strides := glsl_broadcast(p, name, args)
+		n, aeval, beval, ceval := rtype.count(), args[0].eval, args[1].eval, args[2].eval
+		as, bs, cs := strides[0], strides[1], strides[2]
+		return &glsl_expr{typ: rtype, eval: func(x *glsl_exec) (r glsl_value) {
+			a, b, c := aeval(x), beval(x), ceval(x)
+			for i := 0; i < n; i++ {
+				r[i] = f(a[i*as], b[i*bs], c[i*cs])
+			}
+			return r
+		}}
+	}
+}
+
+func glsl_broadcast(p *glsl_parser, name string, args []*glsl_expr) (glsl_type, []int) {
+	// find the result type, while scalar arguments are broadcasted (with stride 0)
+	rtype, strides := t_float, make([]int, len(args))
+	for _, arg := range args {
+		if !arg.typ.is_scalar() {
+			if !rtype.is_scalar() && rtype != arg.typ {
+				p.fail("mismatching arguments '%v' and '%v' for '%s'", rtype, arg.typ, name)
+			}
+			rtype = arg.typ
+		}
+	}
+	for i, arg := range args {
+		if !arg.typ.is_scalar() {
+			strides[i] = 1
+		}
+	}
+	return rtype, strides
+}
+
+func glsl_sign(a float32) float32 {
+	if a > 0 {
+		return 1
+	} else if a < 0 {
+		return -1
+	}
+	return 0
+}
+
+func glsl_builtin_atan(p *glsl_parser, name string, args []*glsl_expr) *glsl_expr {
+	if len(args) == 1 {
+		return glsl_gen1(func(a float32) float32 { return float32(math.Atan(float64(a))) })(p, name, args)
+	}
+	return glsl_gen2(func(y, x float32) float32 { return float32(math.Atan2(float64(y), float64(x))) })(p, name, args)
+}
+
+// ----------------------------------------------------------------------------
+// Geometric Functions
+// ----------------------------------------------------------------------------
+
+func glsl_dot(a *glsl_value, b *glsl_value, n int) float32 {
+	sum := float32(0)
+	for i := 0; i < n; i++ {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+func glsl_check_same_args(p *glsl_parser, name string, args []*glsl_expr, nargs int) {
+	glsl_check_args(p, name, args, nargs)
+	for _, arg := range args {
+		if arg.typ != args[0].typ {
+			p.fail("mismatching arguments '%v' and '%v' for '%s'", args[0].typ, arg.typ, name)
+		}
+	}
+}
+
+func glsl_builtin_length(p *glsl_parser, name string, args []*glsl_expr) *glsl_expr {
+	glsl_check_args(p, name, args, 1)
+	n, eval := args[0].typ.count(), args[0].eval
+	return &glsl_expr{typ: t_float, eval: func(x *glsl_exec) glsl_value {
+		v := eval(x)
+		return glsl_value{float32(math.Sqrt(float64(glsl_dot(&v, &v, n))))}
+	}}
+}
+
+func glsl_builtin_distance(p *glsl_parser, name string, args []*glsl_expr) *glsl_expr {
+	glsl_check_same_args(p, name, args, 2)
+	n, aeval, beval := args[0].typ.count(), args[0].eval, args[1].eval
+	return &glsl_expr{typ: t_float, eval: func(x *glsl_exec) glsl_value {
+		a, b := aeval(x), beval(x)
+		for i := 0; i < n; i++ {
+			a[i] -= b[i]
+		}
+		return glsl_value{float32(math.Sqrt(float64(glsl_dot(&a, &a, n))))}
+	}}
+}
+
+func glsl_builtin_dot(p *glsl_parser, name string, args []*glsl_expr) *glsl_expr {
+	glsl_check_same_args(p, name, args, 2)
+	n, aeval, beval := args[0].typ.count(), args[0].eval, args[1].eval
+	return &glsl_expr{typ: t_float, eval: func(x *glsl_exec) glsl_value {
+		a, b := aeval(x), beval(x)
+		return glsl_value{glsl_dot(&a, &b, n)}
+	}}
+}
+
+func glsl_builtin_cross(p *glsl_parser, name string, args []*glsl_expr) *glsl_expr {
+	glsl_check_same_args(p, name, args, 2)
+	if args[0].typ.size != 3 {
+		p.fail("'%s' requires 'vec3' arguments", name)
+	}
+	aeval, beval := args[0].eval, args[1].eval
+	return &glsl_expr{typ: args[0].typ, eval: func(x *glsl_exec) glsl_value {
+		a, b := aeval(x), beval(x)
+		return glsl_value{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+	}}
+}
+
+func glsl_builtin_normalize(p *glsl_parser, name string, args []*glsl_expr) *glsl_expr {
+	glsl_check_args(p, name, args, 1)
+	n, eval := args[0].typ.count(), args[0].eval
+	return &glsl_expr{typ: args[0].typ, eval: func(x *glsl_exec) glsl_value {
+		v := eval(x)
+		if length := float32(math.Sqrt(float64(glsl_dot(&v, &v, n)))); length > 0 {
+			for i := 0; i < n; i++ {
+				v[i] /= length
+			}
+		}
+		return v
+	}}
+}
+
+func glsl_builtin_faceforward(p *glsl_parser, name string, args []*glsl_expr) *glsl_expr {
+	glsl_check_same_args(p, name, args, 3)
+	n, neval, ieval, reval := args[0].typ.count(), args[0].eval, args[1].eval, args[2].eval
+	return &glsl_expr{typ: args[0].typ, eval: func(x *glsl_exec) glsl_value {
+		nv, iv, rv := neval(x), ieval(x), reval(x)
+		if glsl_dot(&rv, &iv, n) >= 0 {
+			for i := 0; i < n; i++ {
+				nv[i] = -nv[i]
+			}
+		}
+		return nv
+	}}
+}
+
+func glsl_builtin_reflect(p *glsl_parser, name string, args []*glsl_expr) *glsl_expr {
+	glsl_check_same_args(p, name, args, 2)
+	n, ieval, neval := args[0].typ.count(), args[0].eval, args[1].eval
+	return &glsl_expr{typ: args[0].typ, eval: func(x *glsl_exec) glsl_value {
+		iv, nv := ieval(x), neval(x)
+		d := 2 * glsl_dot(&nv, &iv, n)
+		for i := 0; i < n; i++ {
+			iv[i] -= d * nv[i]
+		}
+		return iv
+	}}
+}
+
+func glsl_builtin_refract(p *glsl_parser, name string, args []*glsl_expr) *glsl_expr {
+	glsl_check_args(p, name, args, 3)
+	if args[0].typ != args[1].typ || args[2].typ != t_float {
+		p.fail("invalid arguments for '%s'", name)
+	}
+	n, ieval, neval, eeval := args[0].typ.count(), args[0].eval, args[1].eval, args[2].eval
+	return &glsl_expr{typ: args[0].typ, eval: func(x *glsl_exec) (r glsl_value) {
+		iv, nv, eta := ieval(x), neval(x), eeval(x)[0]
+		d := glsl_dot(&nv, &iv, n)
+		k := 1 - eta*eta*(1-d*d)
+		if k < 0 {
+			return r
+		}
+		s := eta*d + float32(math.Sqrt(float64(k)))
+		for i := 0; i < n; i++ {
+			r[i] = eta*iv[i] - s*nv[i]
+		}
+		return r
+	}}
+}
+
+func glsl_builtin_matrix_comp_mult(p *glsl_parser, name string, args []*glsl_expr) *glsl_expr {
+	if len(args) != 2 || !args[0].typ.mat || args[0].typ != args[1].typ {
+		p.fail("invalid arguments for '%s'", name)
+	}
+	n, aeval, beval := args[0].typ.count(), args[0].eval, args[1].eval
+	return &glsl_expr{typ: args[0].typ, eval: func(x *glsl_exec) glsl_value {
+		a, b := aeval(x), beval(x)
+		for i := 0; i < n; i++ {
+			a[i] *= b[i]
+		}
+		return a
+	}}
+}
+
+// ----------------------------------------------------------------------------
+// Vector Relational Functions
+// ----------------------------------------------------------------------------
+
+func glsl_relational(f func(a, b float32) bool) glsl_builtin {
+	return func(p *glsl_parser, name string, args []*glsl_expr) *glsl_expr {
+		if len(args) != 2 || !args[0].typ.is_vector() || args[0].typ != args[1].typ {
+			p.fail("invalid arguments for '%s'", name)
+		}
+		n, aeval, beval := args[0].typ.size, args[0].eval, args[1].eval
+		return &glsl_expr{typ: t_bool.vector_of(n), eval: func(x *glsl_exec) (r glsl_value) {
+			a, b := aeval(x), beval(x)
+			for i := 0; i < n; i++ {
+				if f(a[i], b[i]) {
+					r[i] = 1
+				}
+			}
+			return r
+		}}
+	}
+}
+
+func glsl_builtin_any_all(p *glsl_parser, name string, args []*glsl_expr) *glsl_expr {
+	if len(args) != 1 || args[0].typ.base != 'b' || !args[0].typ.is_vector() {
+		p.fail("invalid arguments for '%s'", name)
+	}
+	n, eval, is_all := args[0].typ.size, args[0].eval, name == "all"
+	return &glsl_expr{typ: t_bool, eval: func(x *glsl_exec) glsl_value {
+		v := eval(x)
+		for i := 0; i < n; i++ {
+			if (v[i] != 0) != is_all {
+				return glsl_value{1 - glsl_b2f(is_all)}
+			}
+		}
+		return glsl_value{glsl_b2f(is_all)}
+	}}
+}
+
+func glsl_builtin_not(p *glsl_parser, name string, args []*glsl_expr) *glsl_expr {
+	if len(args) != 1 || args[0].typ.base != 'b' || !args[0].typ.is_vector() {
+		p.fail("invalid arguments for '%s'", name)
+	}
+	n, eval := args[0].typ.size, args[0].eval
+	return &glsl_expr{typ: args[0].typ, eval: func(x *glsl_exec) glsl_value {
+		v := eval(x)
+		for i := 0; i < n; i++ {
+			v[i] = 1 - v[i]
+		}
+		return v
+	}}
+}
+
+func glsl_b2f(b bool) float32 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// ----------------------------------------------------------------------------
+// Texture Lookup Functions
+// ----------------------------------------------------------------------------
+
+func glsl_builtin_texture2D(p *glsl_parser, name string, args []*glsl_expr) *glsl_expr {
+	// 'texture2D(sampler, uv [,bias])', 'texture2DLod(sampler, uv, lod)', or 'texture2DProj(sampler, uvq [,bias])'
+	//   (Note that bias/lod is ignored, since mipmaps are not used)
+	if len(args) < 2 || len(args) > 3 || args[0].typ != t_sampler || args[1].typ.base != 'f' || args[1].typ.mat {
+		p.fail("invalid arguments for '%s'", name)
+	}
+	is_proj := name == "texture2DProj"
+	if (!is_proj && args[1].typ.size != 2) || (is_proj && args[1].typ.size < 3) || (name == "texture2DLod" && len(args) != 3) {
+		p.fail("invalid arguments for '%s'", name)
+	}
+	seval, ceval, q := args[0].eval, args[1].eval, args[1].typ.size-1
+	return &glsl_expr{typ: glsl_type_names["vec4"], eval: func(x *glsl_exec) glsl_value {
+		unit, uv := int(seval(x)[0]), ceval(x)
+		if is_proj && uv[q] != 0 {
+			uv[0], uv[1] = uv[0]/uv[q], uv[1]/uv[q]
+		}
+		if x.texture == nil {
+			return glsl_value{0, 0, 0, 1}
+		}
+		rgba := x.texture(unit, uv[0], uv[1])
+		return glsl_value{rgba[0], rgba[1], rgba[2], rgba[3]}
+	}}
+}
@@ -0,0 +1,1403 @@
+package software
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// This is a small interpreter for GLSL ES 1.00 (the shading language of WebGL 1.0),
+//   which is the GLSL version of all the shader codes written for GIGL.
+// The source code is compiled (in a single pass) into a tree of Go closures,
+//   which can be executed for each vertex or fragment by the software rasterizer.
+// Structs and arrays are not supported.
+
+// ----------------------------------------------------------------------------
+// GLSL Types & Values
+// ----------------------------------------------------------------------------
+
+type glsl_type struct {
+	base byte // 'f': float, 'i': int, 'b': bool, 's': sampler, 'v': void
+	size int  // number of components (1~4), or number of columns of a matrix
+	mat  bool // true for matrices
+}
+
+var (
+	t_void    = glsl_type{base: 'v'}
+	t_float   = glsl_type{base: 'f', size: 1}
+	t_int     = glsl_type{base: 'i', size: 1}
+	t_bool    = glsl_type{base: 'b', size: 1}
+	t_sampler = glsl_type{base: 's', size: 1}
+)
+
+var glsl_type_names = map[string]glsl_type{
+	"void": t_void, "float": t_float, "int": t_int, "bool": t_bool,
+	"vec2": {'f', 2, false}, "vec3": {'f', 3, false}, "vec4": {'f', 4, false},
+	"ivec2": {'i', 2, false}, "ivec3": {'i', 3, false}, "ivec4": {'i', 4, false},
+	"bvec2": {'b', 2, false}, "bvec3": {'b', 3, false}, "bvec4": {'b', 4, false},
+	"mat2": {'f', 2, true}, "mat3": {'f', 3, true}, "mat4": {'f', 4, true},
+	"sampler2D": t_sampler, "samplerCube": t_sampler,
+}
+
+func (self glsl_type) count() int {
+	// number of float32 values used for the type
+	if self.mat {
+		return self.size * self.size
+	}
+	return self.size
+}
+
+func (self glsl_type) is_scalar() bool {
+	return !self.mat && self.size == 1
+}
+
+func (self glsl_type) is_vector() bool {
+	return !self.mat && self.size > 1
+}
+
+func (self glsl_type) vector_of(size int) glsl_type {
+	return glsl_type{base: self.base, size: size}
+}
+
+func (self glsl_type) String() string {
+	for name, t := range glsl_type_names {
+		if t == self && name != "samplerCube" {
+			return name
+		}
+	}
+	return "unknown"
+}
+
+type glsl_value [16]float32 // every value (up to 'mat4') is kept in 16 float32s
+
+// ----------------------------------------------------------------------------
+// Compiled Expressions, Statements & Symbols
+// ----------------------------------------------------------------------------
+
+type glsl_exec struct {
+	slots     []glsl_value                                    // values of all the variables
+	retval    glsl_value                                      // value returned by the last function call
+	discarded bool                                            // true, if the fragment was discarded
+	texture   func(unit int, u float32, v float32) [4]float32 // texture sampler
+}
+
+type glsl_flow int
+
+const (
+	flow_next     glsl_flow = iota // continue with the next statement
+	flow_break                     // 'break'
+	flow_continue                  // 'continue'
+	flow_return                    // 'return'
+	flow_discard                   // 'discard'
+)
+
+type glsl_expr struct {
+	typ   glsl_type                     // type of the expression
+	eval  func(x *glsl_exec) glsl_value // evaluation of the expression
+	lval  *glsl_lvalue                  // (optional) where to store, if the expression is assignable
+	konst *glsl_value                   // (optional) constant value of the expression
+}
+
+type glsl_lvalue struct {
+	slot  int                    // slot of the variable
+	off   func(x *glsl_exec) int // (optional) dynamic offset of the components (for indexing with variables)
+	comps []int                  // components of the variable
+}
+
+type glsl_symbol struct {
+	name      string    // name of the variable
+	typ       glsl_type // type of the variable
+	slot      int       // slot for the value of the variable
+	qualifier string    // "uniform", "attribute", "varying", "const", "builtin" or ""
+}
+
+type glsl_function struct {
+	name   string                       // name of the function
+	ret    glsl_type                    // return type
+	params []glsl_param                 // parameters
+	body   func(x *glsl_exec) glsl_flow // compiled body of the function (nil for prototypes)
+}
+
+type glsl_param struct {
+	typ       glsl_type // type of the parameter
+	slot      int       // slot for the value of the parameter
+	qualifier string    // "in", "out", or "inout"
+}
+
+type glsl_shader_unit struct {
+	stage      string                  // "vertex" or "fragment"
+	nslots     int                     // number of slots for variables
+	globals    map[string]*glsl_symbol // global variables (including built-in variables)
+	uniforms   []*glsl_symbol          // uniforms   in the order of declaration
+	attributes []*glsl_symbol          // attributes in the order of declaration
+	varyings   []*glsl_symbol          // varyings   in the order of declaration
+	inits      []func(x *glsl_exec)    // initializers of global variables
+	main       *glsl_function          // 'void main()'
+}
+
+func (self *glsl_shader_unit) new_exec() *glsl_exec {
+	return &glsl_exec{slots: make([]glsl_value, self.nslots)}
+}
+
+func (self *glsl_shader_unit) run(x *glsl_exec) bool {
+	// Run the shader, and return false if it was discarded.
+	x.discarded = false
+	for _, init := range self.inits {
+		init(x)
+	}
+	self.main.body(x)
+	return !x.discarded
+}
+
+func (self *glsl_shader_unit) get_slot(name string) int {
+	if symbol, ok := self.globals[name]; ok {
+		return symbol.slot
+	}
+	return -1
+}
+
+// ----------------------------------------------------------------------------
+// GLSL Compiler
+// ----------------------------------------------------------------------------
+
+type glsl_parser struct {
+	tokens    []glsl_token                // tokens of the source code
+	pos       int                         // position of the current token
+	unit      *glsl_shader_unit           // shader being compiled
+	scopes    []map[string]*glsl_symbol   // stack of scopes (the first one is global)
+	functions map[string][]*glsl_function // user-defined functions
+	function  *glsl_function              // function being compiled
+}
+
+type glsl_error struct {
+	err error
+}
+
+func glsl_compile(stage string, source string) (unit *glsl_shader_unit, err error) {
+	tokens, err := glsl_tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+	self := glsl_parser{tokens: tokens}
+	self.unit = &glsl_shader_unit{stage: stage, globals: map[string]*glsl_symbol{}}
+	self.scopes = []map[string]*glsl_symbol{self.unit.globals}
+	self.functions = map[string][]*glsl_function{}
+	defer func() {
+		if r := recover(); r != nil {
+			if gerr, ok := r.(glsl_error); ok {
+				unit, err = nil, gerr.err
+			} else {
+				panic(r)
+			}
+		}
+	}()
+	// built-in variables
+	if stage == "vertex" {
+		self.declare("gl_Position", glsl_type_names["vec4"], "builtin")
+		self.declare("gl_PointSize", t_float, "builtin")
+	} else {
+		self.declare("gl_FragColor", glsl_type_names["vec4"], "builtin")
+		self.declare("gl_FragCoord", glsl_type_names["vec4"], "builtin")
+		self.declare("gl_PointCoord", glsl_type_names["vec2"], "builtin")
+		self.declare("gl_FrontFacing", t_bool, "builtin")
+	}
+	// global declarations & function definitions
+	for self.peek().kind != tk_eof {
+		self.parse_global_declaration()
+	}
+	for _, fn := range self.functions["main"] {
+		if len(fn.params) == 0 && fn.body != nil {
+			self.unit.main = fn
+		}
+	}
+	if self.unit.main == nil {
+		return nil, fmt.Errorf("%s shader : 'void main()' not found", stage)
+	}
+	return self.unit, nil
+}
+
+func (self *glsl_parser) fail(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	panic(glsl_error{fmt.Errorf("%s shader line %d : %s", self.unit.stage, self.peek().line, msg)})
+}
+
+func (self *glsl_parser) peek() glsl_token {
+	return self.tokens[self.pos]
+}
+
+func (self *glsl_parser) peek_at(n int) glsl_token {
+	if self.pos+n < len(self.tokens) {
+		return self.tokens[self.pos+n]
+	}
+	return self.tokens[len(self.tokens)-1]
+}
+
+func (self *glsl_parser) next() glsl_token {
+	token := self.tokens[self.pos]
+	if token.kind != tk_eof {
+		self.pos++
+	}
+	return token
+}
+
+func (self *glsl_parser) accept(text string) bool {
+	if token := self.peek(); token.kind != tk_eof && token.kind != tk_number && token.text == text {
+		self.pos++
+		return true
+	}
+	return false
+}
+
+func (self *glsl_parser) expect(text string) {
+	if !self.accept(text) {
+		self.fail("expected '%s', but found %v", text, self.peek())
+	}
+}
+
+func (self *glsl_parser) expect_ident() string {
+	token := self.next()
+	if token.kind != tk_ident {
+		self.fail("expected identifier, but found %v", token)
+	}
+	return token.text
+}
+
+func (self *glsl_parser) declare(name string, typ glsl_type, qualifier string) *glsl_symbol {
+	scope := self.scopes[len(self.scopes)-1]
+	if _, ok := scope[name]; ok && name != "" {
+		self.fail("'%s' redeclared", name)
+	}
+	symbol := &glsl_symbol{name: name, typ: typ, slot: self.unit.nslots, qualifier: qualifier}
+	self.unit.nslots++
+	scope[name] = symbol
+	return symbol
+}
+
+func (self *glsl_parser) lookup(name string) *glsl_symbol {
+	for i := len(self.scopes) - 1; i >= 0; i-- {
+		if symbol, ok := self.scopes[i][name]; ok {
+			return symbol
+		}
+	}
+	return nil
+}
+
+func (self *glsl_parser) is_type_name(text string) bool {
+	_, ok := glsl_type_names[text]
+	return ok
+}
+
+func (self *glsl_parser) parse_type() glsl_type {
+	for self.accept("highp") || self.accept("mediump") || self.accept("lowp") {
+	}
+	token := self.next()
+	typ, ok := glsl_type_names[token.text]
+	if token.kind != tk_ident || !ok {
+		if token.text == "struct" {
+			self.fail("structs are not supported")
+		}
+		self.fail("unknown type %v", token)
+	}
+	return typ
+}
+
+// ----------------------------------------------------------------------------
+// Declarations
+// ----------------------------------------------------------------------------
+
+func (self *glsl_parser) parse_global_declaration() {
+	if self.accept(";") {
+		return
+	}
+	if self.accept("precision") { // like 'precision mediump float;'
+		for !self.accept(";") {
+			if self.next().kind == tk_eof {
+				self.fail("unterminated precision statement")
+			}
+		}
+		return
+	}
+	qualifier := ""
+	for {
+		if self.accept("invariant") {
+			continue
+		} else if text := self.peek().text; text == "const" || text == "uniform" || text == "attribute" || text == "varying" {
+			qualifier = self.next().text
+			continue
+		}
+		break
+	}
+	typ := self.parse_type()
+	name := self.expect_ident()
+	if self.peek().text == "(" {
+		if qualifier != "" {
+			self.fail("invalid qualifier '%s' for function '%s'", qualifier, name)
+		}
+		self.parse_function(typ, name)
+		return
+	}
+	switch qualifier {
+	case "attribute":
+		if self.unit.stage != "vertex" {
+			self.fail("attribute '%s' is only allowed in vertex shader", name)
+		}
+	case "varying":
+		if typ.base != 'f' {
+			self.fail("varying '%s' should be float-based", name)
+		}
+	}
+	for {
+		if self.peek().text == "[" {
+			self.fail("arrays are not supported ('%s')", name)
+		}
+		var init *glsl_expr = nil
+		if self.accept("=") {
+			init = self.parse_assignment()
+			self.check_assignable(typ, init.typ)
+		} else if qualifier == "const" {
+			self.fail("const '%s' requires initializer", name)
+		}
+		symbol := self.declare(name, typ, qualifier)
+		switch qualifier {
+		case "uniform":
+			self.unit.uniforms = append(self.unit.uniforms, symbol)
+		case "attribute":
+			self.unit.attributes = append(self.unit.attributes, symbol)
+		case "varying":
+			self.unit.varyings = append(self.unit.varyings, symbol)
+		}
+		if init != nil {
+			slot, eval := symbol.slot, init.eval
+			self.unit.inits = append(self.unit.inits, func(x *glsl_exec) { x.slots[slot] = eval(x) })
+		}
+		if !self.accept(",") {
+			break
+		}
+		name = self.expect_ident()
+	}
+	self.expect(";")
+}
+
+func (self *glsl_parser) parse_function(ret glsl_type, name string) {
+	self.expect("(")
+	self.scopes = append(self.scopes, map[string]*glsl_symbol{})
+	defer func() { self.scopes = self.scopes[:len(self.scopes)-1] }()
+	params := []glsl_param{}
+	if self.peek().text == "void" && self.peek_at(1).text == ")" {
+		self.next()
+	}
+	for !self.accept(")") {
+		if len(params) > 0 {
+			self.expect(",")
+		}
+		qualifier := "in"
+		for {
+			if self.accept("const") {
+				continue
+			} else if text := self.peek().text; text == "in" || text == "out" || text == "inout" {
+				qualifier = self.next().text
+				continue
+			}
+			break
+		}
+		ptype := self.parse_type()
+		pname := ""
+		if self.peek().kind == tk_ident {
+			pname = self.expect_ident()
+		}
+		if self.peek().text == "[" {
+			self.fail("arrays are not supported ('%s')", pname)
+		}
+		symbol := self.declare(pname, ptype, "")
+		params = append(params, glsl_param{typ: ptype, slot: symbol.slot, qualifier: qualifier})
+	}
+	// find the prototype declared already
+	var fn *glsl_function = nil
+	for _, f := range self.functions[name] {
+		if len(f.params) == len(params) {
+			same := true
+			for i := range params {
+				same = same && f.params[i].typ == params[i].typ
+			}
+			if same {
+				fn = f
+			}
+		}
+	}
+	if fn == nil {
+		fn = &glsl_function{name: name, ret: ret, params: params}
+		self.functions[name] = append(self.functions[name], fn)
+	} else {
+		if fn.ret != ret {
+			self.fail("function '%s' redeclared with different return type", name)
+		}
+		fn.params = params // parameter slots of the definition are used
+	}
+	if self.accept(";") { // prototype only
+		return
+	}
+	if fn.body != nil {
+		self.fail("function '%s' redefined", name)
+	}
+	self.function = fn
+	body := self.parse_block(false)
+	self.function = nil
+	fn.body = body
+}
+
+func (self *glsl_parser) parse_local_declaration() func(x *glsl_exec) glsl_flow {
+	is_const := false
+	for {
+		if self.accept("const") {
+			is_const = true
+			continue
+		}
+		break
+	}
+	typ := self.parse_type()
+	inits := []func(x *glsl_exec){}
+	for {
+		name := self.expect_ident()
+		if self.peek().text == "[" {
+			self.fail("arrays are not supported ('%s')", name)
+		}
+		var init *glsl_expr = nil
+		if self.accept("=") {
+			init = self.parse_assignment()
+			self.check_assignable(typ, init.typ)
+		} else if is_const {
+			self.fail("const '%s' requires initializer", name)
+		}
+		symbol := self.declare(name, typ, "") // declared after the initializer (like 'float x = x;')
+		slot := symbol.slot
+		if init != nil {
+			eval := init.eval
+			inits = append(inits, func(x *glsl_exec) { x.slots[slot] = eval(x) })
+		} else {
+			inits = append(inits, func(x *glsl_exec) { x.slots[slot] = glsl_value{} })
+		}
+		if !self.accept(",") {
+			break
+		}
+	}
+	self.expect(";")
+	return func(x *glsl_exec) glsl_flow {
+		for _, init := range inits {
+			init(x)
+		}
+		return flow_next
+	}
+}
+
+func (self *glsl_parser) check_assignable(to glsl_type, from glsl_type) {
+	if to != from {
+		self.fail("cannot convert from '%v' to '%v'", from, to)
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Statements
+// ----------------------------------------------------------------------------
+
+func (self *glsl_parser) parse_block(new_scope bool) func(x *glsl_exec) glsl_flow {
+	self.expect("{")
+	if new_scope {
+		self.scopes = append(self.scopes, map[string]*glsl_symbol{})
+		defer func() { self.scopes = self.scopes[:len(self.scopes)-1] }()
+	}
+	stmts := []func(x *glsl_exec) glsl_flow{}
+	for !self.accept("}") {
+		if self.peek().kind == tk_eof {
+			self.fail("unexpected end of source")
+		}
+		stmts = append(stmts, self.parse_statement())
+	}
+	return func(x *glsl_exec) glsl_flow {
+		for _, stmt := range stmts {
+			if f := stmt(x); f != flow_next {
+				return f
+			}
+		}
+		return flow_next
+	}
+}
+
+func (self *glsl_parser) parse_substatement() func(x *glsl_exec) glsl_flow {
+	// statement with its own scope (like the body of 'if' or 'for')
+	self.scopes = append(self.scopes, map[string]*glsl_symbol{})
+	defer func() { self.scopes = self.scopes[:len(self.scopes)-1] }()
+	return self.parse_statement()
+}
+
+func (self *glsl_parser) parse_statement() func(x *glsl_exec) glsl_flow {
+	token := self.peek()
+	switch token.text {
+	case "{":
+		return self.parse_block(true)
+	case ";":
+		self.next()
+		return func(x *glsl_exec) glsl_flow { return flow_next }
+	case "if":
+		self.next()
+		self.expect("(")
+		cond := self.parse_condition()
+		self.expect(")")
+		then_stmt := self.parse_substatement()
+		if self.accept("else") {
+			else_stmt := self.parse_substatement()
+			return func(x *glsl_exec) glsl_flow {
+				if cond(x)[0] != 0 {
+					return then_stmt(x)
+				}
+				return else_stmt(x)
+			}
+		}
+		return func(x *glsl_exec) glsl_flow {
+			if cond(x)[0] != 0 {
+				return then_stmt(x)
+			}
+			return flow_next
+		}
+	case "for":
+		self.next()
+		self.expect("(")
+		self.scopes = append(self.scopes, map[string]*glsl_symbol{})
+		defer func() { self.scopes = self.scopes[:len(self.scopes)-1] }()
+		init := self.parse_simple_statement()
+		var cond func(x *glsl_exec) glsl_value = nil
+		if !self.accept(";") {
+			cond = self.parse_condition()
+			self.expect(";")
+		}
+		var step func(x *glsl_exec) glsl_value = nil
+		if self.peek().text != ")" {
+			step = self.parse_expression().eval
+		}
+		self.expect(")")
+		body := self.parse_substatement()
+		return func(x *glsl_exec) glsl_flow {
+			for init(x); cond == nil || cond(x)[0] != 0; {
+				if f := body(x); f == flow_break {
+					break
+				} else if f == flow_return || f == flow_discard {
+					return f
+				}
+				if step != nil {
+					step(x)
+				}
+			}
+			return flow_next
+		}
+	case "while":
+		self.next()
+		self.expect("(")
+		cond := self.parse_condition()
+		self.expect(")")
+		body := self.parse_substatement()
+		return func(x *glsl_exec) glsl_flow {
+			for cond(x)[0] != 0 {
+				if f := body(x); f == flow_break {
+					break
+				} else if f == flow_return || f == flow_discard {
+					return f
+				}
+			}
+			return flow_next
+		}
+	case "do":
+		self.next()
+		body := self.parse_substatement()
+		self.expect("while")
+		self.expect("(")
+		cond := self.parse_condition()
+		self.expect(")")
+		self.expect(";")
+		return func(x *glsl_exec) glsl_flow {
+			for {
+				if f := body(x); f == flow_break {
+					break
+				} else if f == flow_return || f == flow_discard {
+					return f
+				}
+				if cond(x)[0] == 0 {
+					break
+				}
+			}
+			return flow_next
+		}
+	case "return":
+		self.next()
+		if self.function == nil {
+			self.fail("'return' outside of function")
+		}
+		if self.accept(";") {
+			if self.function.ret != t_void {
+				self.fail("function '%s' should return a value", self.function.name)
+			}
+			return func(x *glsl_exec) glsl_flow { return flow_return }
+		}
+		value := self.parse_expression()
+		self.check_assignable(self.function.ret, value.typ)
+		self.expect(";")
+		return func(x *glsl_exec) glsl_flow {
+			x.retval = value.eval(x)
+			return flow_return
+		}
+	case "break":
+		self.next()
+		self.expect(";")
+		return func(x *glsl_exec) glsl_flow { return flow_break }
+	case "continue":
+		self.next()
+		self.expect(";")
+		return func(x *glsl_exec) glsl_flow { return flow_continue }
+	case "discard":
+		self.next()
+		self.expect(";")
+		if self.unit.stage != "fragment" {
+			self.fail("'discard' is only allowed in fragment shader")
+		}
+		return func(x *glsl_exec) glsl_flow {
+			x.discarded = true
+			return flow_discard
+		}
+	}
+	return self.parse_simple_statement()
+}
+
+func (self *glsl_parser) parse_simple_statement() func(x *glsl_exec) glsl_flow {
+	// declaration or expression statement, terminated by ';'
+	token := self.peek()
+	if token.kind == tk_ident && (token.text == "const" || token.text == "highp" || token.text == "mediump" || token.text == "lowp" ||
+		(self.is_type_name(token.text) && self.peek_at(1).kind == tk_ident)) {
+		return self.parse_local_declaration()
+	}
+	if self.accept(";") {
+		return func(x *glsl_exec) glsl_flow { return flow_next }
+	}
+	eval := self.parse_expression().eval
+	self.expect(";")
+	return func(x *glsl_exec) glsl_flow {
+		eval(x)
+		return flow_next
+	}
+}
+
+func (self *glsl_parser) parse_condition() func(x *glsl_exec) glsl_value {
+	cond := self.parse_expression()
+	if cond.typ != t_bool {
+		self.fail("condition should be 'bool', but found '%v'", cond.typ)
+	}
+	return cond.eval
+}
+
+// ----------------------------------------------------------------------------
+// Expressions
+// ----------------------------------------------------------------------------
+
+func (self *glsl_parser) parse_expression() *glsl_expr {
+	expr := self.parse_assignment()
+	for self.accept(",") { // sequence operator
+		first, second := expr, self.parse_assignment()
+		expr = &glsl_expr{typ: second.typ, eval: func(x *glsl_exec) glsl_value {
+			first.eval(x)
+			return second.eval(x)
+		}}
+	}
+	return expr
+}
+
+func (self *glsl_parser) parse_assignment() *glsl_expr {
+	lhs := self.parse_ternary()
+	op := self.peek().text
+	if self.peek().kind != tk_op || (op != "=" && op != "+=" && op != "-=" && op != "*=" && op != "/=") {
+		return lhs
+	}
+	self.next()
+	if lhs.lval == nil {
+		self.fail("left side of '%s' is not assignable", op)
+	}
+	rhs := self.parse_assignment()
+	lval := lhs.lval
+	if op == "=" {
+		self.check_assignable(lhs.typ, rhs.typ)
+		reval := rhs.eval
+		return &glsl_expr{typ: lhs.typ, eval: func(x *glsl_exec) glsl_value {
+			v := reval(x)
+			lval.store(x, &v)
+			return v
+		}}
+	}
+	rtype, arith := self.arithmetic(op[:1], lhs.typ, rhs.typ)
+	self.check_assignable(lhs.typ, rtype)
+	leval, reval := lhs.eval, rhs.eval
+	return &glsl_expr{typ: lhs.typ, eval: func(x *glsl_exec) glsl_value {
+		a, b := leval(x), reval(x)
+		v := arith(&a, &b)
+		lval.store(x, &v)
+		return v
+	}}
+}
+
+func (self *glsl_parser) parse_ternary() *glsl_expr {
+	cond := self.parse_binary(0)
+	if !self.accept("?") {
+		return cond
+	}
+	if cond.typ != t_bool {
+		self.fail("condition should be 'bool', but found '%v'", cond.typ)
+	}
+	a := self.parse_expression()
+	self.expect(":")
+	b := self.parse_assignment()
+	if a.typ != b.typ {
+		self.fail("mismatching types '%v' and '%v' for '?:'", a.typ, b.typ)
+	}
+	ceval, aeval, beval := cond.eval, a.eval, b.eval
+	return &glsl_expr{typ: a.typ, eval: func(x *glsl_exec) glsl_value {
+		if ceval(x)[0] != 0 {
+			return aeval(x)
+		}
+		return beval(x)
+	}}
+}
+
+var glsl_binary_precedence = [][]string{
+	{"||"}, {"^^"}, {"&&"}, {"==", "!="}, {"<", ">", "<=", ">="}, {"+", "-"}, {"*", "/"},
+}
+
+func (self *glsl_parser) parse_binary(level int) *glsl_expr {
+	if level >= len(glsl_binary_precedence) {
+		return self.parse_unary()
+	}
+	lhs := self.parse_binary(level + 1)
+	for {
+		token, found := self.peek(), false
+		for _, op := range glsl_binary_precedence[level] {
+			found = found || (token.kind == tk_op && token.text == op)
+		}
+		if !found {
+			return lhs
+		}
+		self.next()
+		rhs := self.parse_binary(level + 1)
+		lhs = self.binary(token.text, lhs, rhs)
+	}
+}
+
+func (self *glsl_parser) binary(op string, lhs *glsl_expr, rhs *glsl_expr) *glsl_expr {
+	leval, reval := lhs.eval, rhs.eval
+	switch op {
+	case "||", "&&", "^^":
+		if lhs.typ != t_bool || rhs.typ != t_bool {
+			self.fail("operands of '%s' should be 'bool'", op)
+		}
+		var eval func(x *glsl_exec) glsl_value
+		switch op {
+		case "||":
+			eval = func(x *glsl_exec) glsl_value {
+				if leval(x)[0] != 0 || reval(x)[0] != 0 {
+					return glsl_value{1}
+				}
+				return glsl_value{0}
+			}
+		case "&&":
+			eval = func(x *glsl_exec) glsl_value {
+				if leval(x)[0] != 0 && reval(x)[0] != 0 {
+					return glsl_value{1}
+				}
+				return glsl_value{0}
+			}
+		default:
+			eval = func(x *glsl_exec) glsl_value {
+				if (leval(x)[0] != 0) != (reval(x)[0] != 0) {
+					return glsl_value{1}
+				}
+				return glsl_value{0}
+			}
+		}
+		return &glsl_expr{typ: t_bool, eval: eval}
+	case "==", "!=":
+		if lhs.typ != rhs.typ {
+			self.fail("mismatching types '%v' and '%v' for '%s'", lhs.typ, rhs.typ, op)
+		}
+		n, equal := lhs.typ.count(), float32(1)
+		if op == "!=" {
+			equal = 0
+		}
+		return &glsl_expr{typ: t_bool, eval: func(x *glsl_exec) glsl_value {
+			a, b := leval(x), reval(x)
+			for i := 0; i < n; i++ {
+				if a[i] != b[i] {
+					return glsl_value{1 - equal}
+				}
+			}
+			return glsl_value{equal}
+		}}
+	case "<", ">", "<=", ">=":
+		if !lhs.typ.is_scalar() || lhs.typ != rhs.typ || lhs.typ.base == 'b' {
+			self.fail("operands of '%s' should be scalars of the same type", op)
+		}
+		var compare func(a, b float32) bool
+		switch op {
+		case "<":
+			compare = func(a, b float32) bool { return a < b }
+		case ">":
+			compare = func(a, b float32) bool { return a > b }
+		case "<=":
+			compare = func(a, b float32) bool { return a <= b }
+		default:
+			compare = func(a, b float32) bool { return a >= b }
+		}
+		return &glsl_expr{typ: t_bool, eval: func(x *glsl_exec) glsl_value {
+			if compare(leval(x)[0], reval(x)[0]) {
+				return glsl_value{1}
+			}
+			return glsl_value{0}
+		}}
+	}
+	rtype, arith := self.arithmetic(op, lhs.typ, rhs.typ)
+	return &glsl_expr{typ: rtype, eval: func(x *glsl_exec) glsl_value {
+		a, b := leval(x), reval(x)
+		return arith(&a, &b)
+	}}
+}
+
+func (self *glsl_parser) arithmetic(op string, ta glsl_type, tb glsl_type) (glsl_type, func(a, b *glsl_value) glsl_value) {
+	// Arithmetic operations ('+', '-', '*', '/') on scalars, vectors and matrices.
+	if ta.base != tb.base || (ta.base != 'f' && ta.base != 'i') {
+		self.fail("invalid operands '%v' and '%v' for '%s'", ta, tb, op)
+	}
+	if op == "*" && ta.mat && tb.mat { // matrix * matrix
+		if ta.size != tb.size {
+			self.fail("mismatching matrices '%v' and '%v' for '*'", ta, tb)
+		}
+		n := ta.size
+		return ta, func(a, b *glsl_value) (r glsl_value) {
+			for c := 0; c < n; c++ {
+				for row := 0; row < n; row++ {
+					sum := float32(0)
+					for k := 0; k < n; k++ {
+						sum += a[k*n+row] * b[c*n+k]
+					}
+					r[c*n+row] = sum
+				}
+			}
+			return r
+		}
+	} else if op == "*" && ta.mat && tb.is_vector() { // matrix * column_vector
+		if ta.size != tb.size {
+			self.fail("mismatching matrix '%v' and vector '%v' for '*'", ta, tb)
+		}
+		n := ta.size
+		return tb, func(a, b *glsl_value) (r glsl_value) {
+			for row := 0; row < n; row++ {
+				sum := float32(0)
+				for c := 0; c < n; c++ {
+					sum += a[c*n+row] * b[c]
+				}
+				r[row] = sum
+			}
+			return r
+		}
+	} else if op == "*" && ta.is_vector() && tb.mat { // row_vector * matrix
+		if ta.size != tb.size {
+			self.fail("mismatching vector '%v' and matrix '%v' for '*'", ta, tb)
+		}
+		n := ta.size
+		return ta, func(a, b *glsl_value) (r glsl_value) {
+			for c := 0; c < n; c++ {
+				sum := float32(0)
+				for row := 0; row < n; row++ {
+					sum += a[row] * b[c*n+row]
+				}
+				r[c] = sum
+			}
+			return r
+		}
+	}
+	// component-wise operations (with a scalar operand broadcasted)
+	rtype := ta
+	if ta.is_scalar() {
+		rtype = tb
+	} else if !tb.is_scalar() && ta != tb {
+		self.fail("mismatching operands '%v' and '%v' for '%s'", ta, tb, op)
+	}
+	n, as, bs, is_int := rtype.count(), 1, 1, rtype.base == 'i'
+	if ta.is_scalar() {
+		as = 0
+	}
+	if tb.is_scalar() {
+		bs = 0
+	}
+	switch op {
+	case "+":
+		return rtype, func(a, b *glsl_value) (r glsl_value) {
+			for i := 0; i < n; i++ {
+				r[i] = a[i*as] + b[i*bs]
+			}
+			return r
+		}
+	case "-":
+		return rtype, func(a, b *glsl_value) (r glsl_value) {
+			for i := 0; i < n; i++ {
+				r[i] = a[i*as] - b[i*bs]
+			}
+			return r
+		}
+	case "*":
+		return rtype, func(a, b *glsl_value) (r glsl_value) {
+			for i := 0; i < n; i++ {
+				r[i] = a[i*as] * b[i*bs]
+			}
+			return r
+		}
+	default:
+		return rtype, func(a, b *glsl_value) (r glsl_value) {
+			for i := 0; i < n; i++ {
+				if is_int {
+					if b[i*bs] != 0 {
+						r[i] = float32(int32(a[i*as]) / int32(b[i*bs]))
+					}
+				} else {
+					r[i] = a[i*as] / b[i*bs]
+				}
+			}
+			return r
+		}
+	}
+}
+
+func (self *glsl_parser) parse_unary() *glsl_expr {
+	token := self.peek()
+	if token.kind != tk_op {
+		return self.parse_postfix()
+	}
+	switch token.text {
+	case "+":
+		self.next()
+		return self.parse_unary()
+	case "-":
+		self.next()
+		operand := self.parse_unary()
+		if operand.typ.base != 'f' && operand.typ.base != 'i' {
+			self.fail("invalid operand '%v' for '-'", operand.typ)
+		}
+		n, eval := operand.typ.count(), operand.eval
+		expr := &glsl_expr{typ: operand.typ, eval: func(x *glsl_exec) glsl_value {
+			v := eval(x)
+			for i := 0; i < n; i++ {
+				v[i] = -v[i]
+			}
+			return v
+		}}
+		if operand.konst != nil {
+			k := expr.eval(nil)
+			expr.konst = &k
+			expr.eval = func(x *glsl_exec) glsl_value { return k }
+		}
+		return expr
+	case "!":
+		self.next()
+		operand := self.parse_unary()
+		if operand.typ != t_bool {
+			self.fail("invalid operand '%v' for '!'", operand.typ)
+		}
+		eval := operand.eval
+		return &glsl_expr{typ: t_bool, eval: func(x *glsl_exec) glsl_value {
+			if eval(x)[0] != 0 {
+				return glsl_value{0}
+			}
+			return glsl_value{1}
+		}}
+	case "++", "--":
+		self.next()
+		operand := self.parse_unary()
+		return self.increment(operand, token.text, true)
+	}
+	return self.parse_postfix()
+}
+
+func (self *glsl_parser) increment(operand *glsl_expr, op string, prefix bool) *glsl_expr {
+	if operand.lval == nil || operand.typ.base == 'b' || operand.typ.base == 's' {
+		self.fail("invalid operand for '%s'", op)
+	}
+	delta := float32(1)
+	if op == "--" {
+		delta = -1
+	}
+	n, eval, lval := operand.typ.count(), operand.eval, operand.lval
+	return &glsl_expr{typ: operand.typ, eval: func(x *glsl_exec) glsl_value {
+		old := eval(x)
+		v := old
+		for i := 0; i < n; i++ {
+			v[i] += delta
+		}
+		lval.store(x, &v)
+		if prefix {
+			return v
+		}
+		return old
+	}}
+}
+
+func (self *glsl_parser) parse_postfix() *glsl_expr {
+	expr := self.parse_primary()
+	for {
+		switch {
+		case self.accept("["):
+			index := self.parse_expression()
+			self.expect("]")
+			expr = self.index(expr, index)
+		case self.accept("."):
+			expr = self.swizzle(expr, self.expect_ident())
+		case self.peek().text == "++" || self.peek().text == "--":
+			expr = self.increment(expr, self.next().text, false)
+		default:
+			return expr
+		}
+	}
+}
+
+func (self *glsl_parser) index(expr *glsl_expr, index *glsl_expr) *glsl_expr {
+	if index.typ != t_int {
+		self.fail("index should be 'int', but found '%v'", index.typ)
+	}
+	if expr.typ.is_scalar() || expr.typ.base == 's' {
+		self.fail("'%v' cannot be indexed", expr.typ)
+	}
+	size, stride, rtype := expr.typ.size, 1, expr.typ.vector_of(1)
+	if expr.typ.mat {
+		stride, rtype = size, expr.typ.vector_of(size)
+	}
+	eval, ieval := expr.eval, index.eval
+	if index.konst != nil { // constant index
+		i := int(index.konst[0])
+		if i < 0 || i >= size {
+			self.fail("index %d out of range for '%v'", i, expr.typ)
+		}
+		result := &glsl_expr{typ: rtype, eval: func(x *glsl_exec) (r glsl_value) {
+			v := eval(x)
+			copy(r[:stride], v[i*stride:])
+			return r
+		}}
+		if expr.lval != nil {
+			comps := expr.lval.comps[i*stride : (i+1)*stride]
+			result.lval = &glsl_lvalue{slot: expr.lval.slot, off: expr.lval.off, comps: comps}
+		}
+		return result
+	}
+	clamp := func(x *glsl_exec) int { // dynamic index (clamped to avoid out-of-range access)
+		i := int(ieval(x)[0])
+		if i < 0 {
+			return 0
+		} else if i >= size {
+			return size - 1
+		}
+		return i
+	}
+	result := &glsl_expr{typ: rtype, eval: func(x *glsl_exec) (r glsl_value) {
+		v := eval(x)
+		copy(r[:stride], v[clamp(x)*stride:])
+		return r
+	}}
+	if lval := expr.lval; lval != nil && is_identity_comps(lval.comps) {
+		base_off := lval.off
+		result.lval = &glsl_lvalue{slot: lval.slot, comps: identity_comps(stride), off: func(x *glsl_exec) int {
+			off := clamp(x) * stride
+			if base_off != nil {
+				off += base_off(x)
+			}
+			return off
+		}}
+	}
+	return result
+}
+
+func (self *glsl_parser) swizzle(expr *glsl_expr, fields string) *glsl_expr {
+	if expr.typ.mat || expr.typ.base == 's' || len(fields) > 4 {
+		self.fail("invalid swizzle '.%s' for '%v'", fields, expr.typ)
+	}
+	idx := make([]int, len(fields))
+	for i, ch := range fields {
+		k := -1
+		for _, set := range []string{"xyzw", "rgba", "stpq"} {
+			if j := strings.IndexRune(set, ch); j >= 0 && (i == 0 || strings.IndexByte(set, fields[0]) >= 0) {
+				k = j
+			}
+		}
+		if k < 0 || k >= expr.typ.size {
+			self.fail("invalid swizzle '.%s' for '%v'", fields, expr.typ)
+		}
+		idx[i] = k
+	}
+	eval := expr.eval
+	result := &glsl_expr{typ: expr.typ.vector_of(len(idx)), eval: func(x *glsl_exec) (r glsl_value) {
+		v := eval(x)
+		for i, k := range idx {
+			r[i] = v[k]
+		}
+		return r
+	}}
+	if expr.lval != nil {
+		comps := make([]int, len(idx))
+		for i, k := range idx {
+			comps[i] = expr.lval.comps[k]
+		}
+		result.lval = &glsl_lvalue{slot: expr.lval.slot, off: expr.lval.off, comps: comps}
+	}
+	return result
+}
+
+func (self *glsl_parser) parse_primary() *glsl_expr {
+	token := self.next()
+	switch token.kind {
+	case tk_number:
+		var v glsl_value
+		typ := t_float
+		if strings.HasPrefix(token.text, "0x") || strings.HasPrefix(token.text, "0X") {
+			n, err := strconv.ParseInt(token.text[2:], 16, 64)
+			if err != nil {
+				self.fail("invalid number '%s'", token.text)
+			}
+			v[0], typ = float32(n), t_int
+		} else if strings.ContainsAny(token.text, ".eE") {
+			f, err := strconv.ParseFloat(token.text, 32)
+			if err != nil {
+				self.fail("invalid number '%s'", token.text)
+			}
+			v[0] = float32(f)
+		} else {
+			n, err := strconv.ParseInt(token.text, 10, 64)
+			if err != nil {
+				self.fail("invalid number '%s'", token.text)
+			}
+			v[0], typ = float32(n), t_int
+		}
+		return &glsl_expr{typ: typ, konst: &v, eval: func(x *glsl_exec) glsl_value { return v }}
+	case tk_ident:
+		switch token.text {
+		case "true":
+			v := glsl_value{1}
+			return &glsl_expr{typ: t_bool, konst: &v, eval: func(x *glsl_exec) glsl_value { return v }}
+		case "false":
+			v := glsl_value{0}
+			return &glsl_expr{typ: t_bool, konst: &v, eval: func(x *glsl_exec) glsl_value { return v }}
+		}
+		if self.peek().text == "(" {
+			self.next()
+			args := []*glsl_expr{}
+			if self.peek().text == "void" && self.peek_at(1).text == ")" {
+				self.next()
+			}
+			for !self.accept(")") {
+				if len(args) > 0 {
+					self.expect(",")
+				}
+				args = append(args, self.parse_assignment())
+			}
+			return self.call(token.text, args)
+		}
+		symbol := self.lookup(token.text)
+		if symbol == nil {
+			self.fail("'%s' undeclared", token.text)
+		}
+		slot := symbol.slot
+		expr := &glsl_expr{typ: symbol.typ, eval: func(x *glsl_exec) glsl_value { return x.slots[slot] }}
+		if symbol.qualifier != "uniform" && symbol.qualifier != "const" && symbol.qualifier != "attribute" &&
+			!(symbol.qualifier == "varying" && self.unit.stage == "fragment") {
+			expr.lval = &glsl_lvalue{slot: slot, comps: identity_comps(symbol.typ.count())}
+		}
+		return expr
+	case tk_op:
+		if token.text == "(" {
+			expr := self.parse_expression()
+			self.expect(")")
+			return expr
+		}
+	}
+	self.pos--
+	self.fail("unexpected %v", token)
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+// Function Calls
+// ----------------------------------------------------------------------------
+
+func (self *glsl_parser) call(name string, args []*glsl_expr) *glsl_expr {
+	if typ, ok := glsl_type_names[name]; ok {
+		return self.construct(typ, args)
+	}
+	if fns, ok := self.functions[name]; ok {
+		for _, fn := range fns {
+			if len(fn.params) != len(args) {
+				continue
+			}
+			matched := true
+			for i := range args {
+				matched = matched && fn.params[i].typ == args[i].typ
+			}
+			if matched {
+				return self.call_function(fn, args)
+			}
+		}
+		self.fail("no matching overload for function '%s'", name)
+	}
+	if builtin, ok := glsl_builtins[name]; ok {
+		return builtin(self, name, args)
+	}
+	self.fail("function '%s' undeclared", name)
+	return nil
+}
+
+func (self *glsl_parser) call_function(fn *glsl_function, args []*glsl_expr) *glsl_expr {
+	if len(args) > 8 {
+		self.fail("too many arguments for '%s'", fn.name)
+	}
+	evals := make([]func(x *glsl_exec) glsl_value, len(args))
+	lvals := make([]*glsl_lvalue, len(args))
+	for i, arg := range args {
+		evals[i] = arg.eval
+		if fn.params[i].qualifier != "in" {
+			if arg.lval == nil {
+				self.fail("argument %d of '%s' should be assignable", i+1, fn.name)
+			}
+			lvals[i] = arg.lval
+		}
+	}
+	return &glsl_expr{typ: fn.ret, eval: func(x *glsl_exec) glsl_value {
+		var values [8]glsl_value // evaluate all the arguments first, before setting parameters
+		for i, eval := range evals {
+			values[i] = eval(x)
+		}
+		for i, param := range fn.params {
+			x.slots[param.slot] = values[i]
+		}
+		if fn.body == nil {
+			panic(fmt.Sprintf("function '%s' is declared but not defined", fn.name))
+		}
+		fn.body(x)
+		retval := x.retval
+		for i, lval := range lvals {
+			if lval != nil {
+				v := x.slots[fn.params[i].slot]
+				lval.store(x, &v)
+			}
+		}
+		return retval
+	}}
+}
+
+func (self *glsl_parser) construct(typ glsl_type, args []*glsl_expr) *glsl_expr {
+	// Constructors like 'vec3(1.0)', 'vec4(xyz, 1.0)', 'mat3(m4)', or 'float(i)'
+	if typ == t_void || typ.base == 's' || len(args) == 0 {
+		self.fail("invalid constructor '%v'", typ)
+	}
+	n := typ.count()
+	conv := func(f float32) float32 { return f }
+	switch typ.base {
+	case 'i':
+		conv = func(f float32) float32 { return float32(int32(f)) }
+	case 'b':
+		conv = func(f float32) float32 {
+			if f != 0 {
+				return 1
+			}
+			return 0
+		}
+	}
+	if len(args) == 1 && args[0].typ.is_scalar() { // fill all the components (or the diagonal of a matrix)
+		eval, size, is_mat := args[0].eval, typ.size, typ.mat
+		return &glsl_expr{typ: typ, eval: func(x *glsl_exec) (r glsl_value) {
+			f := conv(eval(x)[0])
+			if is_mat {
+				for i := 0; i < size; i++ {
+					r[i*size+i] = f
+				}
+			} else {
+				for i := 0; i < n; i++ {
+					r[i] = f
+				}
+			}
+			return r
+		}}
+	}
+	if len(args) == 1 && args[0].typ.mat && typ.mat { // matrix from matrix
+		eval, src, dst := args[0].eval, args[0].typ.size, typ.size
+		return &glsl_expr{typ: typ, eval: func(x *glsl_exec) (r glsl_value) {
+			m := eval(x)
+			for c := 0; c < dst; c++ {
+				for row := 0; row < dst; row++ {
+					if c < src && row < src {
+						r[c*dst+row] = m[c*src+row]
+					} else if c == row {
+						r[c*dst+row] = 1
+					}
+				}
+			}
+			return r
+		}}
+	}
+	// concatenate the components of all the arguments
+	total := 0
+	for _, arg := range args {
+		if arg.typ.base == 's' || arg.typ == t_void {
+			self.fail("invalid argument '%v' for constructor '%v'", arg.typ, typ)
+		}
+		if total >= n {
+			self.fail("too many arguments for constructor '%v'", typ)
+		}
+		total += arg.typ.count()
+	}
+	if total < n {
+		self.fail("not enough arguments for constructor '%v'", typ)
+	}
+	evals, counts := make([]func(x *glsl_exec) glsl_value, len(args)), make([]int, len(args))
+	for i, arg := range args {
+		evals[i], counts[i] = arg.eval, arg.typ.count()
+	}
+	return &glsl_expr{typ: typ, eval: func(x *glsl_exec) (r glsl_value) {
+		k := 0
+		for i, eval := range evals {
+			v := eval(x)
+			for j := 0; j < counts[i] && k < n; j++ {
+				r[k] = conv(v[j])
+				k++
+			}
+		}
+		return r
+	}}
+}
+
+// ----------------------------------------------------------------------------
+// Assignment
+// ----------------------------------------------------------------------------
+
+func (self *glsl_lvalue) store(x *glsl_exec, v *glsl_value) {
+	slot := &x.slots[self.slot]
+	off := 0
+	if self.off != nil {
+		off = self.off(x)
+	}
+	for k, c := range self.comps {
+		slot[off+c] = v[k]
+	}
+}
+
+func identity_comps(n int) []int {
+	comps := make([]int, n)
+	for i := range comps {
+		comps[i] = i
+	}
+	return comps
+}
+
+func is_identity_comps(comps []int) bool {
+	for i, c := range comps {
+		if c != i {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,131 @@
+package software
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ----------------------------------------------------------------------------
+// GLSL Tokens
+// ----------------------------------------------------------------------------
+
+const (
+	tk_eof    = iota // end of the source code
+	tk_ident         // identifier or keyword (like 'vec3', 'uniform', 'main')
+	tk_number        // number literal (like '1', '0.5', '1e-3', '0x1F')
+	tk_op            // operator or punctuation (like '+', '+=', '(', ';')
+)
+
+type glsl_token struct {
+	kind int    // tk_eof, tk_ident, tk_number, or tk_op
+	text string // text of the token
+	line int    // line number in the source code (starting from 1)
+}
+
+func (self glsl_token) String() string {
+	if self.kind == tk_eof {
+		return "end of source"
+	}
+	return fmt.Sprintf("'%s'", self.text)
+}
+
+var glsl_operators = []string{ // longer operators should come first
+	"++", "--", "+=", "-=", "*=", "/=", "==", "!=", "<=", ">=", "&&", "||", "^^",
+	"(", ")", "[", "]", "{", "}", ".", ",", ";", ":", "?", "+", "-", "*", "/", "<", ">", "=", "!",
+}
+
+// ----------------------------------------------------------------------------
+// GLSL Lexer
+// ----------------------------------------------------------------------------
+
+func glsl_tokenize(source string) ([]glsl_token, error) {
+	// Split GLSL ES 1.00 source code into tokens, skipping comments and preprocessor directives.
+	tokens := []glsl_token{}
+	line, line_start := 1, true
+	for i := 0; i < len(source); {
+		ch := source[i]
+		switch {
+		case ch == '\n':
+			line, line_start = line+1, true
+			i++
+			continue
+		case ch == ' ' || ch == '\t' || ch == '\r':
+			i++
+			continue
+		case ch == '#' && line_start: // preprocessor directive (like '#version 100') is ignored
+			for i < len(source) && source[i] != '\n' {
+				i++
+			}
+			continue
+		case strings.HasPrefix(source[i:], "//"):
+			for i < len(source) && source[i] != '\n' {
+				i++
+			}
+			continue
+		case strings.HasPrefix(source[i:], "/*"):
+			end := strings.Index(source[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d : unterminated comment", line)
+			}
+			line += strings.Count(source[i:i+2+end], "\n")
+			i += 2 + end + 2
+			continue
+		}
+		line_start = false
+		switch {
+		case is_glsl_letter(ch):
+			j := i + 1
+			for j < len(source) && (is_glsl_letter(source[j]) || is_glsl_digit(source[j])) {
+				j++
+			}
+			tokens = append(tokens, glsl_token{kind: tk_ident, text: source[i:j], line: line})
+			i = j
+		case is_glsl_digit(ch) || (ch == '.' && i+1 < len(source) && is_glsl_digit(source[i+1])):
+			j := i
+			if strings.HasPrefix(source[i:], "0x") || strings.HasPrefix(source[i:], "0X") {
+				j += 2
+				for j < len(source) && strings.IndexByte("0123456789abcdefABCDEF", source[j]) >= 0 {
+					j++
+				}
+			} else {
+				for j < len(source) && (is_glsl_digit(source[j]) || source[j] == '.') {
+					j++
+				}
+				if j < len(source) && (source[j] == 'e' || source[j] == 'E') {
+					j++
+					if j < len(source) && (source[j] == '+' || source[j] == '-') {
+						j++
+					}
+					for j < len(source) && is_glsl_digit(source[j]) {
+						j++
+					}
+				}
+			}
+			tokens = append(tokens, glsl_token{kind: tk_number, text: source[i:j], line: line})
+			i = j
+		default:
+			matched := ""
+			for _, op := range glsl_operators {
+				if strings.HasPrefix(source[i:], op) {
+					matched = op
+					break
+				}
+			}
+			if matched == "" {
+				return nil, fmt.Errorf("line %d : unexpected character '%c'", line, ch)
+			}
+			tokens = append(tokens, glsl_token{kind: tk_op, text: matched, line: line})
+			i += len(matched)
+		}
+	}
+	tokens = append(tokens, glsl_token{kind: tk_eof, line: line})
+	return tokens, nil
+}
+
+func is_glsl_letter(ch byte) bool {
+	return (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || ch == '_'
+}
+
+func is_glsl_digit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
@@ -0,0 +1,79 @@
+package software
+
+import (
+	"time"
+
+	"github.com/go4orward/gigl"
+	"github.com/go4orward/gigl/g2d"
+)
+
+func load_material(rc *SoftwareRenderingContext, material gigl.GLMaterial) error {
+	switch material.(type) {
+	case *g2d.MaterialColors:
+		// DO NOTHING
+	case *g2d.MaterialTexture:
+		mtex := material.(*g2d.MaterialTexture)
+		if !mtex.IsReady() && !mtex.IsLoaded() && !mtex.IsLoading() {
+			// get the pixel buffer, and the width & height of the texture
+			mtex.LoadTextureFromLocalFile()
+			for mtex.IsLoading() { // wait for it, since a single frame is usually rendered at a time
+				time.Sleep(time.Millisecond)
+			}
+			// set it up right away, so that the texture is bound even for the first frame
+			setup_material(rc, material)
+		}
+	case *g2d.MaterialGlowTexture:
+		mtex := material.(*g2d.MaterialGlowTexture)
+		if !mtex.IsReady() && !mtex.IsLoaded() {
+			// get the pixel buffer, and the width & height of the texture
+			mtex.LoadGlowTexture()
+			setup_material(rc, material)
+		}
+	case *g2d.MaterialAlphabetTexture:
+		mtex := material.(*g2d.MaterialAlphabetTexture)
+		if !mtex.IsReady() && !mtex.IsLoaded() {
+			// get the pixel buffer (drawn with a bitmap font), and the width & height of the texture
+			mtex.LoadAlphabetTexture()
+			setup_material(rc, material)
+		}
+	}
+	return nil
+}
+
+func setup_material(rc *SoftwareRenderingContext, material gigl.GLMaterial) error {
+	c := rc.GetConstants()
+	switch material.(type) {
+	case *g2d.MaterialColors:
+		// DO NOTHING
+	case *g2d.MaterialTexture:
+		mtex := material.(*g2d.MaterialTexture)
+		if !mtex.IsReady() && mtex.IsLoaded() {
+			pixbuf, wh := mtex.GetTexturePixbuf(), mtex.GetTextureWH()
+			texture := rc.create_texture(pixbuf, wh)
+			if wh[0]&(wh[0]-1) != 0 || wh[1]&(wh[1]-1) != 0 { // NON-POWER-OF-2 textures : CLAMP_TO_EDGE
+				rc.textures[texture].wrap = [2]uint32{c.CLAMP_TO_EDGE, c.CLAMP_TO_EDGE}
+			}
+			rc.texture_units[rc.texture_unit] = texture
+			mtex.SetTexture(texture)
+		}
+	case *g2d.MaterialGlowTexture:
+		mtex := material.(*g2d.MaterialGlowTexture)
+		if !mtex.IsReady() && mtex.IsLoaded() {
+			pixbuf, wh := mtex.GetTexturePixbuf(), mtex.GetTextureWH()
+			texture := rc.create_texture(pixbuf, wh)
+			rc.textures[texture].wrap = [2]uint32{c.CLAMP_TO_EDGE, c.CLAMP_TO_EDGE}
+			rc.texture_units[rc.texture_unit] = texture
+			mtex.SetTexture(texture)
+		}
+	case *g2d.MaterialAlphabetTexture:
+		mtex := material.(*g2d.MaterialAlphabetTexture)
+		if !mtex.IsReady() && mtex.IsLoaded() {
+			pixbuf, wh := mtex.GetTexturePixbuf(), mtex.GetTextureWH()
+			texture := rc.create_texture(pixbuf, wh)
+			rc.textures[texture].wrap = [2]uint32{c.CLAMP_TO_EDGE, c.CLAMP_TO_EDGE}
+			rc.texture_units[rc.texture_unit] = texture
+			mtex.SetTexture(texture)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,509 @@
+package software
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// ----------------------------------------------------------------------------
+// Vertex Processing
+// ----------------------------------------------------------------------------
+
+type software_vertex struct {
+	pos   [4]float32 // gl_Position (in clip space)
+	psize float32    // gl_PointSize
+	vary  []float32  // values of all the varyings
+}
+
+type software_window_vertex struct {
+	xyz  [3]float32 // window coordinates (x, y in pixels, and z as depth in [0,1])
+	iw   float32    // 1/w for perspective-correct interpolation
+	vary []float32  // values of all the varyings
+}
+
+func (self *SoftwareRenderingContext) draw(mode uint32, first int, count int, indices []uint32, instance_count int) {
+	program := self.program
+	if program == nil || count <= 0 {
+		return
+	}
+	ninstances := instance_count
+	if ninstances < 1 {
+		ninstances = 1
+	}
+	nverts := first + count
+	for _, idx := range indices {
+		if int(idx) >= nverts {
+			nverts = int(idx) + 1
+		}
+	}
+	for instance := 0; instance < ninstances; instance++ {
+		cache := make([]*software_vertex, nverts) // each vertex is processed only once (for each instance)
+		vertex := func(k int) *software_vertex {
+			idx := first + k
+			if indices != nil {
+				idx = int(indices[k])
+			}
+			if cache[idx] == nil {
+				cache[idx] = self.process_vertex(program, idx, instance)
+			}
+			return cache[idx]
+		}
+		switch mode {
+		case self.constants.TRIANGLES:
+			for k := 0; k+2 < count; k += 3 {
+				self.draw_triangle(program, vertex(k), vertex(k+1), vertex(k+2))
+			}
+		case 0x0005: // TRIANGLE_STRIP
+			for k := 0; k+2 < count; k++ {
+				if k%2 == 0 {
+					self.draw_triangle(program, vertex(k), vertex(k+1), vertex(k+2))
+				} else {
+					self.draw_triangle(program, vertex(k+1), vertex(k), vertex(k+2))
+				}
+			}
+		case 0x0006: // TRIANGLE_FAN
+			for k := 1; k+1 < count; k++ {
+				self.draw_triangle(program, vertex(0), vertex(k), vertex(k+1))
+			}
+		case self.constants.LINES:
+			for k := 0; k+1 < count; k += 2 {
+				self.draw_line(program, vertex(k), vertex(k+1))
+			}
+		case 0x0003: // LINE_STRIP
+			for k := 0; k+1 < count; k++ {
+				self.draw_line(program, vertex(k), vertex(k+1))
+			}
+		case 0x0002: // LINE_LOOP
+			for k := 0; k < count && count > 1; k++ {
+				self.draw_line(program, vertex(k), vertex((k+1)%count))
+			}
+		case self.constants.POINTS:
+			for k := 0; k < count; k++ {
+				self.draw_point(program, vertex(k))
+			}
+		}
+	}
+}
+
+func (self *SoftwareRenderingContext) process_vertex(program *software_program, idx int, instance int) *software_vertex {
+	// run the vertex shader for the vertex, after fetching its attributes
+	x := program.vexec
+	for loc, symbol := range program.attributes {
+		if loc < len(self.attributes) {
+			x.slots[symbol.slot] = self.fetch_attribute(&self.attributes[loc], idx, instance)
+		}
+	}
+	x.slots[program.vshader.get_slot("gl_Position")] = glsl_value{}
+	x.slots[program.vshader.get_slot("gl_PointSize")] = glsl_value{1}
+	program.vshader.run(x)
+	v := &software_vertex{vary: make([]float32, 0, program.nvarying)}
+	pos := x.slots[program.vshader.get_slot("gl_Position")]
+	v.pos = [4]float32{pos[0], pos[1], pos[2], pos[3]}
+	v.psize = x.slots[program.vshader.get_slot("gl_PointSize")][0]
+	for _, vary := range program.varyings {
+		v.vary = append(v.vary, x.slots[vary.vslot][:vary.count]...)
+	}
+	return v
+}
+
+func (self *SoftwareRenderingContext) fetch_attribute(a *software_attribute, idx int, instance int) glsl_value {
+	value := glsl_value{0, 0, 0, 1} // default value for missing components
+	if !a.enabled {
+		return value
+	}
+	if a.divisor > 0 {
+		idx = instance / a.divisor
+	}
+	nbytes := 4
+	switch a.dtype {
+	case self.constants.BYTE, self.constants.UNSIGNED_BYTE:
+		nbytes = 1
+	case self.constants.UNSIGNED_SHORT, 0x1402: // SHORT
+		nbytes = 2
+	}
+	stride := a.stride
+	if stride == 0 {
+		stride = a.size * nbytes
+	}
+	data := self.buffers[a.buffer]
+	start := a.offset + idx*stride
+	if start < 0 || start+a.size*nbytes > len(data) {
+		return value
+	}
+	for i := 0; i < a.size; i++ {
+		b := data[start+i*nbytes:]
+		switch a.dtype {
+		case self.constants.FLOAT:
+			value[i] = math.Float32frombits(binary.LittleEndian.Uint32(b))
+		case self.constants.BYTE:
+			value[i] = normalize_int(float32(int8(b[0])), 127, a.normalized)
+		case self.constants.UNSIGNED_BYTE:
+			value[i] = normalize_int(float32(b[0]), 255, a.normalized)
+		case 0x1402: // SHORT
+			value[i] = normalize_int(float32(int16(binary.LittleEndian.Uint16(b))), 32767, a.normalized)
+		case self.constants.UNSIGNED_SHORT:
+			value[i] = normalize_int(float32(binary.LittleEndian.Uint16(b)), 65535, a.normalized)
+		case 0x1404: // INT
+			value[i] = float32(int32(binary.LittleEndian.Uint32(b)))
+		case self.constants.UNSIGNED_INT:
+			value[i] = float32(binary.LittleEndian.Uint32(b))
+		}
+	}
+	return value
+}
+
+func normalize_int(v float32, max float32, normalized bool) float32 {
+	if !normalized {
+		return v
+	}
+	if v = v / max; v < -1 {
+		return -1
+	}
+	return v
+}
+
+// ----------------------------------------------------------------------------
+// Primitive Clipping & Rasterization
+// ----------------------------------------------------------------------------
+
+const near_w_epsilon = 1e-6
+
+func clip_distance(v *software_vertex) float32 {
+	// distance to the near clipping plane (z = -w), which is positive inside
+	return v.pos[2] + v.pos[3]
+}
+
+func interpolate_vertex(a *software_vertex, b *software_vertex, t float32) *software_vertex {
+	v := &software_vertex{psize: a.psize, vary: make([]float32, len(a.vary))}
+	for i := 0; i < 4; i++ {
+		v.pos[i] = a.pos[i] + (b.pos[i]-a.pos[i])*t
+	}
+	for i := range a.vary {
+		v.vary[i] = a.vary[i] + (b.vary[i]-a.vary[i])*t
+	}
+	return v
+}
+
+func (self *SoftwareRenderingContext) to_window(v *software_vertex) software_window_vertex {
+	vx, vy, vw, vh := float32(self.viewport[0]), float32(self.viewport[1]), float32(self.viewport[2]), float32(self.viewport[3])
+	iw := 1 / v.pos[3]
+	return software_window_vertex{
+		xyz:  [3]float32{vx + (v.pos[0]*iw+1)*vw/2, vy + (v.pos[1]*iw+1)*vh/2, (v.pos[2]*iw + 1) / 2},
+		iw:   iw,
+		vary: v.vary,
+	}
+}
+
+func (self *SoftwareRenderingContext) draw_triangle(program *software_program, v0, v1, v2 *software_vertex) {
+	// clip the triangle against the near plane (Sutherland-Hodgman), and rasterize it as a triangle fan
+	polygon := []*software_vertex{v0, v1, v2}
+	clipped := make([]*software_vertex, 0, 4)
+	for i, a := range polygon {
+		b := polygon[(i+1)%len(polygon)]
+		da, db := clip_distance(a), clip_distance(b)
+		if da >= 0 && a.pos[3] > near_w_epsilon {
+			clipped = append(clipped, a)
+		}
+		if (da >= 0) != (db >= 0) {
+			if v := interpolate_vertex(a, b, da/(da-db)); v.pos[3] > near_w_epsilon {
+				clipped = append(clipped, v)
+			}
+		}
+	}
+	if len(clipped) < 3 {
+		return
+	}
+	wv0 := self.to_window(clipped[0])
+	for i := 1; i+1 < len(clipped); i++ {
+		self.rasterize_triangle(program, wv0, self.to_window(clipped[i]), self.to_window(clipped[i+1]))
+	}
+}
+
+func edge_function(a [3]float32, b [3]float32, px float32, py float32) float32 {
+	return (px-a[0])*(b[1]-a[1]) - (py-a[1])*(b[0]-a[0])
+}
+
+func is_owner_edge(a [3]float32, b [3]float32) bool {
+	// tie-breaking rule for pixels exactly on an edge shared by two triangles
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	return dy > 0 || (dy == 0 && dx < 0)
+}
+
+func (self *SoftwareRenderingContext) rasterize_triangle(program *software_program, v0, v1, v2 software_window_vertex) {
+	area := edge_function(v0.xyz, v1.xyz, v2.xyz[0], v2.xyz[1])
+	if area == 0 || math.IsNaN(float64(area)) {
+		return
+	}
+	front_facing := area < 0 // counter-clockwise in window coordinates (with Y pointing up)
+	if area < 0 {
+		v1, v2, area = v2, v1, -area
+	}
+	bounds := self.drawing_bounds()
+	xmin := max(bounds[0], int(math.Floor(float64(min3(v0.xyz[0], v1.xyz[0], v2.xyz[0])))))
+	xmax := min(bounds[2], int(math.Ceil(float64(max3(v0.xyz[0], v1.xyz[0], v2.xyz[0])))))
+	ymin := max(bounds[1], int(math.Floor(float64(min3(v0.xyz[1], v1.xyz[1], v2.xyz[1])))))
+	ymax := min(bounds[3], int(math.Ceil(float64(max3(v0.xyz[1], v1.xyz[1], v2.xyz[1])))))
+	own0, own1, own2 := is_owner_edge(v1.xyz, v2.xyz), is_owner_edge(v2.xyz, v0.xyz), is_owner_edge(v0.xyz, v1.xyz)
+	vary := make([]float32, program.nvarying)
+	for py := ymin; py <= ymax; py++ {
+		for px := xmin; px <= xmax; px++ {
+			cx, cy := float32(px)+0.5, float32(py)+0.5
+			e0 := edge_function(v1.xyz, v2.xyz, cx, cy)
+			e1 := edge_function(v2.xyz, v0.xyz, cx, cy)
+			e2 := edge_function(v0.xyz, v1.xyz, cx, cy)
+			if e0 < 0 || e1 < 0 || e2 < 0 || (e0 == 0 && !own0) || (e1 == 0 && !own1) || (e2 == 0 && !own2) {
+				continue
+			}
+			l0, l1, l2 := e0/area, e1/area, e2/area
+			z := l0*v0.xyz[2] + l1*v1.xyz[2] + l2*v2.xyz[2]
+			iw := l0*v0.iw + l1*v1.iw + l2*v2.iw
+			p0, p1, p2 := l0*v0.iw/iw, l1*v1.iw/iw, l2*v2.iw/iw // perspective-correct weights
+			for i := range vary {
+				vary[i] = p0*v0.vary[i] + p1*v1.vary[i] + p2*v2.vary[i]
+			}
+			self.process_fragment(program, px, py, z, iw, vary, [2]float32{}, front_facing)
+		}
+	}
+}
+
+func (self *SoftwareRenderingContext) draw_line(program *software_program, v0, v1 *software_vertex) {
+	// clip the line against the near plane, and rasterize it (1 pixel wide)
+	d0, d1 := clip_distance(v0), clip_distance(v1)
+	if d0 < 0 && d1 < 0 {
+		return
+	} else if d0 < 0 {
+		v0 = interpolate_vertex(v0, v1, d0/(d0-d1))
+	} else if d1 < 0 {
+		v1 = interpolate_vertex(v0, v1, d0/(d0-d1))
+	}
+	if v0.pos[3] <= near_w_epsilon || v1.pos[3] <= near_w_epsilon {
+		return
+	}
+	a, b := self.to_window(v0), self.to_window(v1)
+	dx, dy := b.xyz[0]-a.xyz[0], b.xyz[1]-a.xyz[1]
+	steps := int(math.Ceil(math.Max(math.Abs(float64(dx)), math.Abs(float64(dy)))))
+	if steps == 0 {
+		steps = 1
+	}
+	bounds := self.drawing_bounds()
+	vary := make([]float32, program.nvarying)
+	for i := 0; i < steps; i++ { // the last pixel is not drawn, like OpenGL
+		t := (float32(i) + 0.5) / float32(steps)
+		px, py := int(math.Floor(float64(a.xyz[0]+dx*t))), int(math.Floor(float64(a.xyz[1]+dy*t)))
+		if px < bounds[0] || px > bounds[2] || py < bounds[1] || py > bounds[3] {
+			continue
+		}
+		z := a.xyz[2] + (b.xyz[2]-a.xyz[2])*t
+		iw := a.iw + (b.iw-a.iw)*t
+		p0, p1 := (1-t)*a.iw/iw, t*b.iw/iw // perspective-correct weights
+		for k := range vary {
+			vary[k] = p0*a.vary[k] + p1*b.vary[k]
+		}
+		self.process_fragment(program, px, py, z, iw, vary, [2]float32{}, true)
+	}
+}
+
+func (self *SoftwareRenderingContext) draw_point(program *software_program, v *software_vertex) {
+	// rasterize the point as a square of 'gl_PointSize' pixels
+	if v.pos[3] <= near_w_epsilon || v.pos[2] < -v.pos[3] || v.pos[2] > v.pos[3] {
+		return
+	}
+	wv := self.to_window(v)
+	size := v.psize
+	if size < 1 {
+		size = 1
+	}
+	x0, y0 := wv.xyz[0]-size/2, wv.xyz[1]-size/2
+	bounds := self.drawing_bounds()
+	xmin, xmax := max(bounds[0], int(math.Floor(float64(x0)+0.5))), min(bounds[2], int(math.Ceil(float64(x0+size)-0.5)))
+	ymin, ymax := max(bounds[1], int(math.Floor(float64(y0)+0.5))), min(bounds[3], int(math.Ceil(float64(y0+size)-0.5)))
+	for py := ymin; py <= ymax; py++ {
+		for px := xmin; px <= xmax; px++ {
+			s := (float32(px) + 0.5 - x0) / size
+			t := 1 - (float32(py)+0.5-y0)/size // 'gl_PointCoord' starts from the top
+			if s < 0 || s >= 1 || t <= 0 || t > 1 {
+				continue
+			}
+			self.process_fragment(program, px, py, wv.xyz[2], wv.iw, wv.vary, [2]float32{s, t}, true)
+		}
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Fragment Processing
+// ----------------------------------------------------------------------------
+
+func (self *SoftwareRenderingContext) process_fragment(program *software_program, px int, py int, z float32, iw float32, vary []float32, point_coord [2]float32, front_facing bool) {
+	if z < 0 || z > 1 { // clipped by near/far planes
+		return
+	}
+	// run the fragment shader
+	x, fshader := program.fexec, program.fshader
+	x.texture = self.sample_texture
+	k := 0
+	for _, v := range program.varyings {
+		copy(x.slots[v.fslot][:v.count], vary[k:k+v.count])
+		k += v.count
+	}
+	x.slots[fshader.get_slot("gl_FragCoord")] = glsl_value{float32(px) + 0.5, float32(py) + 0.5, z, iw}
+	x.slots[fshader.get_slot("gl_PointCoord")] = glsl_value{point_coord[0], point_coord[1]}
+	x.slots[fshader.get_slot("gl_FrontFacing")] = glsl_value{glsl_b2f(front_facing)}
+	x.slots[fshader.get_slot("gl_FragColor")] = glsl_value{}
+	if !fshader.run(x) {
+		return
+	}
+	// depth test
+	fb := self.framebuffer
+	idx := py*fb.wh[0] + px
+	if self.capabilities[self.constants.DEPTH_TEST] {
+		if !self.depth_test(z, fb.depth[idx]) {
+			return
+		}
+		fb.depth[idx] = z
+	}
+	// blending
+	fc := x.slots[fshader.get_slot("gl_FragColor")]
+	src := [4]float32{clamp01(fc[0]), clamp01(fc[1]), clamp01(fc[2]), clamp01(fc[3])}
+	dst := fb.color[idx*4 : idx*4+4]
+	if self.capabilities[self.constants.BLEND] {
+		sf := self.blend_factor(self.blend_func[0], src, dst)
+		df := self.blend_factor(self.blend_func[1], src, dst)
+		for i := 0; i < 4; i++ {
+			dst[i] = clamp01(src[i]*sf[i] + dst[i]*df[i])
+		}
+	} else {
+		copy(dst, src[:])
+	}
+}
+
+func (self *SoftwareRenderingContext) depth_test(z float32, depth float32) bool {
+	switch self.depth_func {
+	case 0x0200: // NEVER
+		return false
+	case self.constants.LESS:
+		return z < depth
+	case 0x0202: // EQUAL
+		return z == depth
+	case self.constants.LEQUAL:
+		return z <= depth
+	case 0x0204: // GREATER
+		return z > depth
+	case 0x0205: // NOTEQUAL
+		return z != depth
+	case 0x0206: // GEQUAL
+		return z >= depth
+	default: // ALWAYS
+		return true
+	}
+}
+
+func (self *SoftwareRenderingContext) blend_factor(factor uint32, src [4]float32, dst []float32) [4]float32 {
+	switch factor {
+	case 0: // ZERO
+		return [4]float32{0, 0, 0, 0}
+	case self.constants.ONE:
+		return [4]float32{1, 1, 1, 1}
+	case 0x0300: // SRC_COLOR
+		return src
+	case 0x0301: // ONE_MINUS_SRC_COLOR
+		return [4]float32{1 - src[0], 1 - src[1], 1 - src[2], 1 - src[3]}
+	case self.constants.SRC_ALPHA:
+		return [4]float32{src[3], src[3], src[3], src[3]}
+	case self.constants.ONE_MINUS_SRC_ALPHA:
+		return [4]float32{1 - src[3], 1 - src[3], 1 - src[3], 1 - src[3]}
+	case 0x0304: // DST_ALPHA
+		return [4]float32{dst[3], dst[3], dst[3], dst[3]}
+	case 0x0305: // ONE_MINUS_DST_ALPHA
+		return [4]float32{1 - dst[3], 1 - dst[3], 1 - dst[3], 1 - dst[3]}
+	case 0x0306: // DST_COLOR
+		return [4]float32{dst[0], dst[1], dst[2], dst[3]}
+	case 0x0307: // ONE_MINUS_DST_COLOR
+		return [4]float32{1 - dst[0], 1 - dst[1], 1 - dst[2], 1 - dst[3]}
+	default:
+		return [4]float32{1, 1, 1, 1}
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Texture Sampling
+// ----------------------------------------------------------------------------
+
+func (self *SoftwareRenderingContext) sample_texture(unit int, u float32, v float32) [4]float32 {
+	if unit < 0 || unit >= len(self.texture_units) {
+		return [4]float32{0, 0, 0, 1}
+	}
+	texture, ok := self.textures[self.texture_units[unit]]
+	if !ok || texture.wh[0] <= 0 || texture.wh[1] <= 0 {
+		return [4]float32{0, 0, 0, 1} // incomplete texture
+	}
+	tw, th := texture.wh[0], texture.wh[1]
+	if texture.mag_filter == self.constants.NEAREST {
+		return texture.texel(self.wrap(texture.wrap[0], int(math.Floor(float64(u*float32(tw)))), tw),
+			self.wrap(texture.wrap[1], int(math.Floor(float64(v*float32(th)))), th))
+	}
+	// bilinear filtering
+	fu, fv := u*float32(tw)-0.5, v*float32(th)-0.5
+	x0, y0 := int(math.Floor(float64(fu))), int(math.Floor(float64(fv)))
+	tx, ty := fu-float32(x0), fv-float32(y0)
+	xa, xb := self.wrap(texture.wrap[0], x0, tw), self.wrap(texture.wrap[0], x0+1, tw)
+	ya, yb := self.wrap(texture.wrap[1], y0, th), self.wrap(texture.wrap[1], y0+1, th)
+	c00, c10, c01, c11 := texture.texel(xa, ya), texture.texel(xb, ya), texture.texel(xa, yb), texture.texel(xb, yb)
+	var rgba [4]float32
+	for i := 0; i < 4; i++ {
+		rgba[i] = (c00[i]*(1-tx)+c10[i]*tx)*(1-ty) + (c01[i]*(1-tx)+c11[i]*tx)*ty
+	}
+	return rgba
+}
+
+func (self *SoftwareRenderingContext) wrap(mode uint32, i int, n int) int {
+	if mode == self.constants.CLAMP_TO_EDGE {
+		if i < 0 {
+			return 0
+		} else if i >= n {
+			return n - 1
+		}
+		return i
+	}
+	i = i % n // REPEAT
+	if i < 0 {
+		i += n
+	}
+	return i
+}
+
+func (self *software_texture) texel(x int, y int) [4]float32 {
+	p := self.pixbuf[(y*self.wh[0]+x)*4:]
+	return [4]float32{float32(p[0]) / 255, float32(p[1]) / 255, float32(p[2]) / 255, float32(p[3]) / 255}
+}
+
+// ----------------------------------------------------------------------------
+// private functions
+// ----------------------------------------------------------------------------
+
+func (self *SoftwareRenderingContext) drawing_bounds() [4]int {
+	// pixels to be drawn (xmin, ymin, xmax, ymax), within both the viewport and the framebuffer
+	v, wh := self.viewport, self.framebuffer.wh
+	return [4]int{max(0, v[0]), max(0, v[1]), min(wh[0], v[0]+v[2]) - 1, min(wh[1], v[1]+v[3]) - 1}
+}
+
+func min(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func min3(a, b, c float32) float32 {
+	return float32(math.Min(float64(a), math.Min(float64(b), float64(c))))
+}
+
+func max3(a, b, c float32) float32 {
+	return float32(math.Max(float64(a), math.Max(float64(b), float64(c))))
+}
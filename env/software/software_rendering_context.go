@@ -0,0 +1,644 @@
+package software
+
+import (
+	"encoding/binary"
+	"image"
+	"math"
+
+	"github.com/go4orward/gigl"
+)
+
+// SoftwareRenderingContext is a GLRenderingContext that runs entirely on the CPU,
+// with shaders executed by a small GLSL ES 1.00 interpreter.
+// It needs neither GPU nor display, and the rendered result can be taken as image.RGBA.
+
+type SoftwareRenderingContext struct {
+	constants gigl.GLConstants // OpenGL constant values
+	wh        [2]int           // canvas width & height
+
+	default_framebuffer *software_framebuffer // framebuffer of the canvas
+	framebuffer         *software_framebuffer // framebuffer being drawn (default or offscreen)
+	viewport            [4]int                // x, y, width, height
+	clear_color         [4]float32            //
+
+	capabilities map[uint32]bool // enabled capabilities (DEPTH_TEST, BLEND)
+	depth_func   uint32          // LESS, LEQUAL, ...
+	blend_func   [2]uint32       // source & destination factors
+
+	last_handle    uint32                           // last handle given to a buffer/texture/framebuffer
+	buffers        map[uint32][]byte                // data buffers, by handle
+	textures       map[uint32]*software_texture     // textures, by handle
+	framebuffers   map[uint32]*software_framebuffer // offscreen framebuffers, by handle
+	renderbuffers  map[uint32][2]int                // renderbuffers (only their sizes), by handle
+	renderbuffer   uint32                           // renderbuffer bound to RENDERBUFFER
+	array_buffer   uint32                           // buffer bound to ARRAY_BUFFER
+	element_buffer uint32                           // buffer bound to ELEMENT_ARRAY_BUFFER
+	attributes     [16]software_attribute           // vertex attribute arrays, by location
+	texture_unit   int                              // active texture unit
+	texture_units  [8]uint32                        // textures bound to texture units
+	program        *software_program                // shader program in use
+	extensions     map[string]bool                  // extensions that were set up
+}
+
+type software_attribute struct {
+	enabled    bool   //
+	buffer     uint32 // buffer bound to ARRAY_BUFFER when the pointer was set
+	size       int    // number of components (1~4)
+	dtype      uint32 // FLOAT, BYTE, UNSIGNED_BYTE, UNSIGNED_SHORT, ...
+	normalized bool   //
+	stride     int    // stride in bytes
+	offset     int    // offset in bytes
+	divisor    int    // 0 for vertices, and N for instances
+}
+
+type software_framebuffer struct {
+	wh      [2]int    // width & height
+	color   []float32 // RGBA color of pixels (rows from bottom to top, like OpenGL)
+	depth   []float32 // depth of pixels
+	texture uint32    // texture attached as COLOR_ATTACHMENT0 (0 for the canvas)
+}
+
+type software_texture struct {
+	wh         [2]int  // width & height
+	pixbuf     []uint8 // RGBA pixels (the first row is for v=0)
+	wrap       [2]uint32
+	mag_filter uint32
+}
+
+func NewSoftwareRenderingContext(width int, height int) *SoftwareRenderingContext {
+	self := SoftwareRenderingContext{}
+	self.wh = [2]int{width, height}
+	self.default_framebuffer = new_software_framebuffer([2]int{width, height})
+	self.framebuffer = self.default_framebuffer
+	self.viewport = [4]int{0, 0, width, height}
+	self.capabilities = map[uint32]bool{}
+	self.buffers = map[uint32][]byte{}
+	self.textures = map[uint32]*software_texture{}
+	self.framebuffers = map[uint32]*software_framebuffer{}
+	self.renderbuffers = map[uint32][2]int{}
+	self.extensions = map[string]bool{}
+	// use OpenGL constant values
+	self.constants.ARRAY_BUFFER = 0x8892
+	self.constants.BLEND = 0x0BE2
+	self.constants.BYTE = 0x1400
+	self.constants.CLAMP_TO_EDGE = 0x812F
+	self.constants.COLOR_ATTACHMENT0 = 0x8CE0
+	self.constants.COLOR_BUFFER_BIT = 0x4000
+	self.constants.COMPILE_STATUS = 0x8B81
+	self.constants.DEPTH_ATTACHMENT = 0x8D00
+	self.constants.DEPTH_BUFFER_BIT = 0x0100
+	self.constants.DEPTH_COMPONENT16 = 0x81A5
+	self.constants.DEPTH_TEST = 0x0B71
+	self.constants.DYNAMIC_DRAW = 0x88E8
+	self.constants.ELEMENT_ARRAY_BUFFER = 0x8893
+	self.constants.FLOAT = 0x1406
+	self.constants.FRAGMENT_SHADER = 0x8B30
+	self.constants.FRAMEBUFFER = 0x8D40
+	self.constants.FRAMEBUFFER_COMPLETE = 0x8CD5
+	self.constants.LEQUAL = 0x0203
+	self.constants.LESS = 0x0201
+	self.constants.LINEAR = 0x2601
+	self.constants.LINES = 0x0001
+	self.constants.LINK_STATUS = 0x8B82
+	self.constants.NEAREST = 0x2600
+	self.constants.ONE = 0x0001
+	self.constants.ONE_MINUS_SRC_ALPHA = 0x0303
+	self.constants.POINTS = 0x0000
+	self.constants.RENDERBUFFER = 0x8D41
+	self.constants.RGBA = 0x1908
+	self.constants.SRC_ALPHA = 0x0302
+	self.constants.STATIC_DRAW = 0x88E4
+	self.constants.STREAM_DRAW = 0x88E0
+	self.constants.TEXTURE_2D = 0x0DE1
+	self.constants.TEXTURE0 = 0x84C0
+	self.constants.TEXTURE1 = 0x84C1
+	self.constants.TEXTURE_MIN_FILTER = 0x2801
+	self.constants.TEXTURE_WRAP_S = 0x2802
+	self.constants.TEXTURE_WRAP_T = 0x2803
+	self.constants.TRIANGLES = 0x0004
+	self.constants.UNSIGNED_BYTE = 0x1401
+	self.constants.UNSIGNED_INT = 0x1405
+	self.constants.UNSIGNED_SHORT = 0x1403
+	self.constants.VERTEX_SHADER = 0x8B31
+	self.depth_func = self.constants.LESS
+	self.blend_func = [2]uint32{self.constants.ONE, 0} // (ONE, ZERO)
+	return &self
+}
+
+func (self *SoftwareRenderingContext) GetWH() [2]int {
+	return self.wh
+}
+
+func (self *SoftwareRenderingContext) GetConstants() *gigl.GLConstants {
+	return &self.constants
+}
+
+func (self *SoftwareRenderingContext) GetEnvVariable(vname string, dtype string) interface{} {
+	switch dtype {
+	case "int":
+		return 0
+	case "bool":
+		return false
+	default:
+		return ""
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Rendered Image
+// ----------------------------------------------------------------------------
+
+func (self *SoftwareRenderingContext) GetImage() *image.RGBA {
+	// Get the image rendered on the canvas (even if an offscreen framebuffer is being drawn)
+	framebuffer := self.framebuffer
+	self.framebuffer = self.default_framebuffer
+	defer func() { self.framebuffer = framebuffer }()
+	img, _ := gigl.ReadImage(self, self.wh[0], self.wh[1])
+	return img
+}
+
+// ----------------------------------------------------------------------------
+// Material & Shader
+// ----------------------------------------------------------------------------
+
+func (self *SoftwareRenderingContext) LoadMaterial(material gigl.GLMaterial) error {
+	return load_material(self, material)
+}
+
+func (self *SoftwareRenderingContext) SetupMaterial(material gigl.GLMaterial) error {
+	return setup_material(self, material)
+}
+
+func (self *SoftwareRenderingContext) CreateShader(vertex_shader string, fragment_shader string) (gigl.GLShader, error) {
+	return create_shader(self, vertex_shader, fragment_shader)
+}
+
+// ----------------------------------------------------------------------------
+// Data Buffer
+// ----------------------------------------------------------------------------
+
+func (self *SoftwareRenderingContext) CreateDataBufferVAO() *gigl.VAO {
+	return &gigl.VAO{}
+}
+
+func (self *SoftwareRenderingContext) CreateVtxDataBuffer(data_slice []float32, usage uint32) interface{} {
+	// 'usage' is ignored, since the data is always in the memory
+	if data_slice == nil {
+		return nil
+	}
+	self.last_handle++
+	self.buffers[self.last_handle] = float32_to_bytes(data_slice)
+	return self.last_handle
+}
+
+func (self *SoftwareRenderingContext) CreateIdxDataBuffer(data_slice []uint32) interface{} {
+	if data_slice == nil {
+		return nil
+	}
+	data := make([]byte, len(data_slice)*4) // keep the data in bytes (little endian), like GPU memory
+	for i, v := range data_slice {
+		binary.LittleEndian.PutUint32(data[i*4:], v)
+	}
+	self.last_handle++
+	self.buffers[self.last_handle] = data
+	return self.last_handle
+}
+
+func (self *SoftwareRenderingContext) GLBindBuffer(target uint32, buffer interface{}) {
+	// 'bind_target' : c.ARRAY_BUFFER or c.ELEMENT_ARRAY_BUFFER
+	handle := uint32(0)
+	if buffer != nil {
+		handle = buffer.(uint32)
+	}
+	switch target {
+	case self.constants.ARRAY_BUFFER:
+		self.array_buffer = handle
+	case self.constants.ELEMENT_ARRAY_BUFFER:
+		self.element_buffer = handle
+	}
+}
+
+func (self *SoftwareRenderingContext) GLBufferData(target uint32, data_slice []float32, usage uint32) {
+	if handle := self.get_bound_buffer(target); handle != 0 {
+		self.buffers[handle] = float32_to_bytes(data_slice)
+	}
+}
+
+func (self *SoftwareRenderingContext) GLBufferSubData(target uint32, offset_in_byte int, data_slice []float32) {
+	if data, ok := self.buffers[self.get_bound_buffer(target)]; ok && offset_in_byte >= 0 && offset_in_byte+len(data_slice)*4 <= len(data) {
+		copy(data[offset_in_byte:], float32_to_bytes(data_slice))
+	}
+}
+
+func (self *SoftwareRenderingContext) get_bound_buffer(target uint32) uint32 {
+	switch target {
+	case self.constants.ARRAY_BUFFER:
+		return self.array_buffer
+	case self.constants.ELEMENT_ARRAY_BUFFER:
+		return self.element_buffer
+	default:
+		return 0
+	}
+}
+
+func float32_to_bytes(data_slice []float32) []byte {
+	data := make([]byte, len(data_slice)*4) // keep the data in bytes (little endian), like GPU memory
+	for i, v := range data_slice {
+		binary.LittleEndian.PutUint32(data[i*4:], math.Float32bits(v))
+	}
+	return data
+}
+
+func (self *SoftwareRenderingContext) GLBindVertexArray(vertex_array interface{}) {
+	// DO NOTHING, since attributes are bound again for every drawing
+}
+
+// ----------------------------------------------------------------------------
+// Binding Texture
+// ----------------------------------------------------------------------------
+
+func (self *SoftwareRenderingContext) GLActiveTexture(texture_unit int) {
+	if texture_unit >= 0 && texture_unit < len(self.texture_units) {
+		self.texture_unit = texture_unit
+	}
+}
+
+func (self *SoftwareRenderingContext) GLBindTexture(target uint32, texture interface{}) {
+	// 'binding_target' : TEXTURE_2D
+	handle := uint32(0)
+	if texture != nil {
+		handle = texture.(uint32)
+	}
+	self.texture_units[self.texture_unit] = handle
+}
+
+func (self *SoftwareRenderingContext) create_texture(pixbuf []uint8, wh [2]int) uint32 {
+	texture := &software_texture{wh: wh, pixbuf: pixbuf}
+	texture.wrap = [2]uint32{0x2901, 0x2901} // REPEAT
+	texture.mag_filter = self.constants.LINEAR
+	self.last_handle++
+	self.textures[self.last_handle] = texture
+	return self.last_handle
+}
+
+// ----------------------------------------------------------------------------
+// Framebuffer
+// ----------------------------------------------------------------------------
+
+func (self *SoftwareRenderingContext) CreateFramebuffer() interface{} {
+	self.last_handle++
+	self.framebuffers[self.last_handle] = &software_framebuffer{}
+	return self.last_handle
+}
+
+func (self *SoftwareRenderingContext) CreateRenderbuffer() interface{} {
+	self.last_handle++
+	self.renderbuffers[self.last_handle] = [2]int{0, 0}
+	return self.last_handle
+}
+
+func (self *SoftwareRenderingContext) CreateTexture(width int, height int) interface{} {
+	texture := self.create_texture(make([]uint8, width*height*4), [2]int{width, height})
+	self.textures[texture].wrap = [2]uint32{self.constants.CLAMP_TO_EDGE, self.constants.CLAMP_TO_EDGE}
+	return texture
+}
+
+func (self *SoftwareRenderingContext) GLBindFramebuffer(target uint32, framebuffer interface{}) {
+	// 'target' : FRAMEBUFFER
+	self.resolve_framebuffer() // the rendered pixels should be available as texture
+	self.framebuffer = self.default_framebuffer
+	if framebuffer != nil {
+		if fb, ok := self.framebuffers[framebuffer.(uint32)]; ok {
+			self.framebuffer = fb
+		}
+	}
+}
+
+func (self *SoftwareRenderingContext) GLBindRenderbuffer(target uint32, renderbuffer interface{}) {
+	// 'target' : RENDERBUFFER
+	self.renderbuffer = 0
+	if renderbuffer != nil {
+		self.renderbuffer = renderbuffer.(uint32)
+	}
+}
+
+func (self *SoftwareRenderingContext) GLRenderbufferStorage(target uint32, internalformat uint32, width int, height int) {
+	if _, ok := self.renderbuffers[self.renderbuffer]; ok {
+		self.renderbuffers[self.renderbuffer] = [2]int{width, height}
+	}
+}
+
+func (self *SoftwareRenderingContext) GLFramebufferTexture2D(target uint32, attachment uint32, textarget uint32, texture interface{}, level int) {
+	fb := self.framebuffer
+	if fb == self.default_framebuffer || attachment != self.constants.COLOR_ATTACHMENT0 || texture == nil {
+		return
+	}
+	if t, ok := self.textures[texture.(uint32)]; ok {
+		*fb = *new_software_framebuffer(t.wh)
+		fb.texture = texture.(uint32)
+	}
+}
+
+func (self *SoftwareRenderingContext) GLFramebufferRenderbuffer(target uint32, attachment uint32, renderbuffertarget uint32, renderbuffer interface{}) {
+	// DO NOTHING, since depth values are kept in the framebuffer itself
+}
+
+func (self *SoftwareRenderingContext) GLCheckFramebufferStatus(target uint32) uint32 {
+	if self.framebuffer == self.default_framebuffer || self.framebuffer.texture != 0 {
+		return self.constants.FRAMEBUFFER_COMPLETE
+	}
+	return 0x8CD7 // FRAMEBUFFER_INCOMPLETE_MISSING_ATTACHMENT
+}
+
+func (self *SoftwareRenderingContext) GLViewport(x int, y int, width int, height int) {
+	self.viewport = [4]int{x, y, width, height}
+}
+
+func new_software_framebuffer(wh [2]int) *software_framebuffer {
+	fb := software_framebuffer{wh: wh}
+	fb.color = make([]float32, wh[0]*wh[1]*4)
+	fb.depth = make([]float32, wh[0]*wh[1])
+	for i := range fb.depth {
+		fb.depth[i] = 1.0
+	}
+	return &fb
+}
+
+func (self *SoftwareRenderingContext) resolve_framebuffer() {
+	// copy the pixels of the offscreen framebuffer into its texture (both of their rows are from bottom to top)
+	fb := self.framebuffer
+	if texture, ok := self.textures[fb.texture]; ok && fb != self.default_framebuffer {
+		for i, v := range fb.color {
+			texture.pixbuf[i] = uint8(math.Round(float64(clamp01(v)) * 255))
+		}
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Binding Uniforms
+// ----------------------------------------------------------------------------
+
+func (self *SoftwareRenderingContext) GLUniform1i(location interface{}, v0 int) {
+	if self.program != nil {
+		self.program.set_uniform(location, float32(v0))
+	}
+}
+
+func (self *SoftwareRenderingContext) GLUniform1f(location interface{}, v0 float32) {
+	if self.program != nil {
+		self.program.set_uniform(location, v0)
+	}
+}
+
+func (self *SoftwareRenderingContext) GLUniform2f(location interface{}, v0 float32, v1 float32) {
+	if self.program != nil {
+		self.program.set_uniform(location, v0, v1)
+	}
+}
+
+func (self *SoftwareRenderingContext) GLUniform3f(location interface{}, v0 float32, v1 float32, v2 float32) {
+	if self.program != nil {
+		self.program.set_uniform(location, v0, v1, v2)
+	}
+}
+
+func (self *SoftwareRenderingContext) GLUniform4f(location interface{}, v0 float32, v1 float32, v2 float32, v3 float32) {
+	if self.program != nil {
+		self.program.set_uniform(location, v0, v1, v2, v3)
+	}
+}
+
+func (self *SoftwareRenderingContext) GLUniformMatrix3fv(location interface{}, transpose bool, values []float32) {
+	if self.program != nil {
+		self.program.set_uniform(location, transpose_if(transpose, values, 3)...)
+	}
+}
+
+func (self *SoftwareRenderingContext) GLUniformMatrix4fv(location interface{}, transpose bool, values []float32) {
+	if self.program != nil {
+		self.program.set_uniform(location, transpose_if(transpose, values, 4)...)
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Binding Attributes
+// ----------------------------------------------------------------------------
+
+func (self *SoftwareRenderingContext) GLVertexAttribPointer(location interface{}, size int, dtype uint32, normalized bool, stride_in_byte int, offset_in_byte int) {
+	if loc := int(location.(int32)); loc >= 0 && loc < len(self.attributes) {
+		a := &self.attributes[loc]
+		a.buffer, a.size, a.dtype, a.normalized = self.array_buffer, size, dtype, normalized
+		a.stride, a.offset = stride_in_byte, offset_in_byte
+	}
+}
+
+func (self *SoftwareRenderingContext) GLEnableVertexAttribArray(location interface{}) {
+	if loc := int(location.(int32)); loc >= 0 && loc < len(self.attributes) {
+		self.attributes[loc].enabled = true
+	}
+}
+
+func (self *SoftwareRenderingContext) GLVertexAttribDivisor(location interface{}, divisor int) {
+	if loc := int(location.(int32)); loc >= 0 && loc < len(self.attributes) {
+		self.attributes[loc].divisor = divisor
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Preparing to Render
+// ----------------------------------------------------------------------------
+
+func (self *SoftwareRenderingContext) GLClearColor(r float32, g float32, b float32, a float32) {
+	self.clear_color = [4]float32{r, g, b, a}
+}
+
+func (self *SoftwareRenderingContext) GLClear(mask uint32) {
+	fb := self.framebuffer
+	if mask&self.constants.COLOR_BUFFER_BIT != 0 {
+		for i := 0; i < len(fb.color); i += 4 {
+			copy(fb.color[i:i+4], self.clear_color[:])
+		}
+	}
+	if mask&self.constants.DEPTH_BUFFER_BIT != 0 {
+		for i := range fb.depth {
+			fb.depth[i] = 1.0
+		}
+	}
+}
+
+func (self *SoftwareRenderingContext) GLEnable(cap uint32) {
+	self.capabilities[cap] = true
+}
+
+func (self *SoftwareRenderingContext) GLDisable(cap uint32) {
+	self.capabilities[cap] = false
+}
+
+func (self *SoftwareRenderingContext) GLDepthFunc(ftn uint32) {
+	self.depth_func = ftn
+}
+
+func (self *SoftwareRenderingContext) GLBlendFunc(sfactor uint32, dfactor uint32) {
+	self.blend_func = [2]uint32{sfactor, dfactor}
+}
+
+func (self *SoftwareRenderingContext) GLUseProgram(shader_program interface{}) {
+	self.program, _ = shader_program.(*software_program)
+}
+
+// ----------------------------------------------------------------------------
+// Rendering
+// ----------------------------------------------------------------------------
+
+func (self *SoftwareRenderingContext) GLDrawArrays(mode uint32, first int, count int) {
+	// 'mode' : POINTS
+	self.draw(mode, first, count, nil, 0)
+}
+
+func (self *SoftwareRenderingContext) GLDrawArraysInstanced(mode uint32, first int, count int, pose_count int) {
+	// 'mode' : POINTS
+	self.draw(mode, first, count, nil, pose_count)
+}
+
+func (self *SoftwareRenderingContext) GLDrawElements(mode uint32, count int, dtype uint32, offset int) {
+	// 'mode'  : LINES, TRIANGLES
+	// 'dtype' : UNSIGNED_INT
+	self.draw(mode, 0, count, self.read_indices(count, dtype, offset), 0)
+}
+
+func (self *SoftwareRenderingContext) GLDrawElementsInstanced(mode uint32, element_count int, dtype uint32, offset int, pose_count int) {
+	// 'mode'  : LINES, TRIANGLES
+	// 'dtype' : UNSIGNED_INT
+	self.draw(mode, 0, element_count, self.read_indices(element_count, dtype, offset), pose_count)
+}
+
+func (self *SoftwareRenderingContext) read_indices(count int, dtype uint32, offset int) []uint32 {
+	data := self.buffers[self.element_buffer]
+	indices := make([]uint32, 0, count)
+	for i := 0; i < count; i++ {
+		switch dtype {
+		case self.constants.UNSIGNED_INT:
+			if offset+i*4+4 <= len(data) {
+				indices = append(indices, binary.LittleEndian.Uint32(data[offset+i*4:]))
+			}
+		case self.constants.UNSIGNED_SHORT:
+			if offset+i*2+2 <= len(data) {
+				indices = append(indices, uint32(binary.LittleEndian.Uint16(data[offset+i*2:])))
+			}
+		case self.constants.UNSIGNED_BYTE:
+			if offset+i < len(data) {
+				indices = append(indices, uint32(data[offset+i]))
+			}
+		}
+	}
+	return indices
+}
+
+// ----------------------------------------------------------------------------
+// Reading Pixels
+// ----------------------------------------------------------------------------
+
+func (self *SoftwareRenderingContext) GLReadPixels(x int, y int, width int, height int, format uint32, dtype uint32, pixels []uint8) {
+	// 'format' : RGBA,  'dtype' : UNSIGNED_BYTE  (only this combination is supported)
+	fb := self.framebuffer
+	for j := 0; j < height; j++ {
+		for i := 0; i < width; i++ {
+			fx, fy, idx := x+i, y+j, (j*width+i)*4
+			if fx < 0 || fx >= fb.wh[0] || fy < 0 || fy >= fb.wh[1] || idx+4 > len(pixels) {
+				continue
+			}
+			c := fb.color[(fy*fb.wh[0]+fx)*4:]
+			for k := 0; k < 4; k++ {
+				pixels[idx+k] = uint8(math.Round(float64(clamp01(c[k])) * 255))
+			}
+		}
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Deleting Resources
+// ----------------------------------------------------------------------------
+
+func (self *SoftwareRenderingContext) GLDeleteBuffer(buffer interface{}) {
+	if handle, ok := buffer.(uint32); ok {
+		delete(self.buffers, handle)
+	}
+}
+
+func (self *SoftwareRenderingContext) GLDeleteVertexArray(vertex_array interface{}) {
+	// DO NOTHING, since native VAO is not used
+}
+
+func (self *SoftwareRenderingContext) GLDeleteTexture(texture interface{}) {
+	if handle, ok := texture.(uint32); ok {
+		delete(self.textures, handle)
+		for i := range self.texture_units {
+			if self.texture_units[i] == handle {
+				self.texture_units[i] = 0
+			}
+		}
+	}
+}
+
+func (self *SoftwareRenderingContext) GLDeleteProgram(program interface{}) {
+	if p, ok := program.(*software_program); ok && p == self.program {
+		self.program = nil
+	}
+}
+
+func (self *SoftwareRenderingContext) GLDeleteFramebuffer(framebuffer interface{}) {
+	if handle, ok := framebuffer.(uint32); ok {
+		if self.framebuffer == self.framebuffers[handle] {
+			self.framebuffer = self.default_framebuffer // deleting the bound framebuffer binds the canvas again
+		}
+		delete(self.framebuffers, handle)
+	}
+}
+
+func (self *SoftwareRenderingContext) GLDeleteRenderbuffer(renderbuffer interface{}) {
+	if handle, ok := renderbuffer.(uint32); ok {
+		delete(self.renderbuffers, handle)
+		if self.renderbuffer == handle {
+			self.renderbuffer = 0
+		}
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Extensions
+// ----------------------------------------------------------------------------
+
+func (self *SoftwareRenderingContext) SetupExtension(extname string) {
+	// all the extensions ("UINT32", "ANGLE") are supported
+	self.extensions[extname] = true
+}
+
+func (self *SoftwareRenderingContext) IsExtensionReady(extname string) bool {
+	return self.extensions[extname]
+}
+
+// ----------------------------------------------------------------------------
+// private functions
+// ----------------------------------------------------------------------------
+
+func transpose_if(transpose bool, values []float32, n int) []float32 {
+	if !transpose {
+		return values
+	}
+	transposed := make([]float32, n*n)
+	for c := 0; c < n; c++ {
+		for r := 0; r < n; r++ {
+			transposed[c*n+r] = values[r*n+c]
+		}
+	}
+	return transposed
+}
+
+func clamp01(f float32) float32 {
+	if f < 0 {
+		return 0
+	} else if f > 1 {
+		return 1
+	}
+	return f
+}
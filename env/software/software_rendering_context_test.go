@@ -0,0 +1,69 @@
+package software
+
+import (
+	"testing"
+
+	"github.com/go4orward/gigl/g2d"
+)
+
+func TestOverlayLabelLayer(t *testing.T) {
+	// Labels should be rendered with the alphabet texture, where spaces leave no pixels
+	tests := []struct {
+		text     string
+		outlined bool
+		painted  bool
+	}{
+		{"HELLO", false, true},
+		{"HELLO", true, true},
+		{"     ", false, false},
+	}
+	for _, tt := range tests {
+		rc := NewSoftwareRenderingContext(200, 100)
+		layer := g2d.NewOverlayLabelLayer(rc, 20, tt.outlined)
+		layer.AddTextLabel(tt.text, [2]float32{0, 0}, "#ff0000", "CENTER")
+		scene := g2d.NewScene("#ffffff").AddOverlay(layer)
+		renderer := g2d.NewRenderer(rc)
+		renderer.Clear(scene)
+		renderer.RenderScene(scene, g2d.NewCamera(rc.GetWH(), 2.0, 1.0))
+		img := rc.GetImage()
+		red, dark, bbox := 0, 0, [4]int{200, 100, 0, 0}
+		for y := 0; y < 100; y++ {
+			for x := 0; x < 200; x++ {
+				r, g, b, _ := img.At(x, y).RGBA()
+				if r > 0xc000 && g < 0x4000 && b < 0x4000 {
+					red++
+					bbox = [4]int{min_int(bbox[0], x), min_int(bbox[1], y), max_int(bbox[2], x), max_int(bbox[3], y)}
+				} else if r < 0x4000 && g < 0x4000 && b < 0x4000 {
+					dark++
+				}
+			}
+		}
+		if !tt.painted {
+			if red > 0 || dark > 0 {
+				t.Errorf("label %q (outlined:%t) : %d red & %d dark pixels (expected none)", tt.text, tt.outlined, red, dark)
+			}
+			continue
+		}
+		// five characters of 12x21 pixels, with its glyphs (5x7 bitmap) scaled up
+		if red < 100 || bbox[2]-bbox[0] < 40 || bbox[2]-bbox[0] > 60 || bbox[3]-bbox[1] < 10 || bbox[3]-bbox[1] > 21 {
+			t.Errorf("label %q (outlined:%t) : %d red pixels in %v", tt.text, tt.outlined, red, bbox)
+		}
+		if tt.outlined != (dark > 0) {
+			t.Errorf("label %q (outlined:%t) : %d pixels of black outline", tt.text, tt.outlined, dark)
+		}
+	}
+}
+
+func min_int(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max_int(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
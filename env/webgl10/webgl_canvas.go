@@ -1,8 +1,11 @@
 package webgl10
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"image"
+	"image/png"
 	"math"
 	"syscall/js"
 
@@ -30,6 +33,7 @@ type WebGLCanvas struct {
 	wasm_handler_for_draw      js.Func
 	user_handler_for_draw      func(now float64)
 	paused                     bool
+	frame                      int // number of frames drawn
 }
 
 func NewWebGLCanvas(canvas_id string) (*WebGLCanvas, error) {
@@ -64,6 +68,10 @@ func NewWebGLCanvas(canvas_id string) (*WebGLCanvas, error) {
 	return &self, nil
 }
 
+func (self *WebGLCanvas) GetWH() [2]int {
+	return self.wh
+}
+
 func (self *WebGLCanvas) GetRenderingContext() gigl.GLRenderingContext {
 	return (self.rc)
 }
@@ -269,6 +277,7 @@ func (self *WebGLCanvas) Run(draw_handler func(now float64)) {
 			if self.user_handler_for_draw != nil && !self.paused {
 				now := args[0].Float() // DOMHighResTimeStamp similar to performance.now()
 				self.user_handler_for_draw(now)
+				self.capture_if_requested()
 			}
 			js.Global().Call("requestAnimationFrame", self.wasm_handler_for_draw)
 			return nil
@@ -294,6 +303,7 @@ func (self *WebGLCanvas) RunOnce(draw_handler func(now float64)) {
 			if self.user_handler_for_draw != nil {
 				now := args[0].Float() // DOMHighResTimeStamp similar to performance.now()
 				self.user_handler_for_draw(now)
+				self.capture_if_requested()
 			}
 			return nil
 		})
@@ -309,3 +319,61 @@ func (self *WebGLCanvas) Pause() {
 func (self *WebGLCanvas) Resume() {
 	self.paused = false
 }
+
+// ----------------------------------------------------------------------------
+// Capturing Image
+// ----------------------------------------------------------------------------
+
+func (self *WebGLCanvas) CaptureImage() (image.Image, error) {
+	// Capture the image rendered on the canvas.
+	// Call it inside the 'draw_handler' (after rendering), since the drawing buffer is cleared
+	// after being composited by the browser (unless 'preserveDrawingBuffer' is set).
+	w, h := self.canvas.Get("width").Int(), self.canvas.Get("height").Int()
+	return gigl.ReadImage(self.rc, w, h)
+}
+
+func (self *WebGLCanvas) DownloadCapturedImage(filename string) error {
+	// Capture the image rendered on the canvas, and let the browser download it as a PNG file.
+	// Call it inside the 'draw_handler' (after rendering), like CaptureImage().
+	img, err := self.CaptureImage()
+	if err != nil {
+		return err
+	}
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, img); err != nil {
+		return fmt.Errorf("Failed to encode captured image : %v", err)
+	}
+	js_bytes := js.Global().Get("Uint8Array").New(buffer.Len())
+	js.CopyBytesToJS(js_bytes, buffer.Bytes())
+	blob := js.Global().Get("Blob").New([]interface{}{js_bytes}, map[string]interface{}{"type": "image/png"})
+	url := js.Global().Get("URL").Call("createObjectURL", blob)
+	anchor := js.Global().Get("document").Call("createElement", "a")
+	anchor.Set("href", url)
+	anchor.Set("download", filename)
+	anchor.Call("click")
+	js.Global().Get("URL").Call("revokeObjectURL", url)
+	return nil
+}
+
+func (self *WebGLCanvas) capture_if_requested() {
+	// Capture the image automatically, if requested by query parameters of the URL
+	//   like 'http://localhost:8080/?capture=xscreen_webgl3d.png&capture_frame=30'
+	self.frame++
+	filename := self.rc.GetEnvVariable("capture", "string").(string)
+	frame := self.rc.GetEnvVariable("capture_frame", "int").(int)
+	if filename == "" || self.frame != max_int(frame, 1) {
+		return
+	}
+	if err := self.DownloadCapturedImage(filename); err != nil {
+		common.Logger.Error("%v\n", err)
+	} else {
+		common.Logger.Info("Captured image downloaded as %q\n", filename)
+	}
+}
+
+func max_int(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -14,9 +14,11 @@ type WebGLShader struct {
 	vshader_code   string                 // vertex   shader source code
 	fshader_code   string                 // fragment shader source code
 	shader_program js.Value               //
+	deleted        *bool                  // shared with its copies (true, after the shader program was deleted)
 	err            error                  //
 
 	gigl.GLShaderBinder
+	*gigl.RefCounter // shared with its copies, since they share the same shader program
 }
 
 // ----------------------------------------------------------------------------
@@ -26,7 +28,7 @@ type WebGLShader struct {
 func create_shader(rc *WebGLRenderingContext, vshader_source string, fshader_source string) (gigl.GLShader, error) {
 	// THIS CONSTRUCTOR FUNCTION IS NOT MEANT TO BE CALLED DIRECTLY BY USER.
 	// IT SHOULD BE CALLED BY 'WebGLRenderingContext.CreateShader()'.
-	shader := WebGLShader{rc: rc}
+	shader := WebGLShader{rc: rc, RefCounter: &gigl.RefCounter{}, deleted: new(bool)}
 	shader.CreateShaderProgram(vshader_source, fshader_source)
 	shader.InitBindings()
 	return &shader, shader.err
@@ -70,7 +72,7 @@ func (self *WebGLShader) CreateShaderProgram(vshader_source string, fshader_sour
 }
 
 func (self *WebGLShader) IsReady() bool {
-	return !self.shader_program.IsNull() && self.err == nil
+	return !*self.deleted && !self.shader_program.IsNull() && self.err == nil
 }
 
 func (self *WebGLShader) GetShaderProgram() any {
@@ -81,6 +83,15 @@ func (self *WebGLShader) GetErr() error {
 	return self.err
 }
 
+func (self *WebGLShader) Dispose() {
+	// Delete the shader program (note that its copies cannot be used any more, either)
+	if self.shader_program.Truthy() && !*self.deleted {
+		self.rc.GLDeleteProgram(self.shader_program)
+	}
+	*self.deleted = true
+	self.shader_program = js.Null()
+}
+
 // ----------------------------------------------------------------------------
 // Shader Bindings
 // ----------------------------------------------------------------------------
@@ -130,6 +141,8 @@ func (self *WebGLShader) Copy() gigl.GLShader {
 	// (so that the same 'shader_program' can be shared among different rendering targets)
 	shader := WebGLShader{rc: self.rc, vshader_code: self.vshader_code, fshader_code: self.fshader_code}
 	shader.shader_program = self.shader_program
+	shader.RefCounter = self.RefCounter
+	shader.deleted = self.deleted
 	// initialize shader bindings with empty map
 	shader.InitBindings()
 	return &shader
@@ -37,13 +37,19 @@ func NewWebGLRenderingContext(canvas js.Value) (*WebGLRenderingContext, error) {
 	self.constants.BLEND = uint32(context.Get("BLEND").Int())
 	self.constants.BYTE = uint32(context.Get("BYTE").Int())
 	self.constants.CLAMP_TO_EDGE = uint32(context.Get("CLAMP_TO_EDGE").Int())
+	self.constants.COLOR_ATTACHMENT0 = uint32(context.Get("COLOR_ATTACHMENT0").Int())
 	self.constants.COLOR_BUFFER_BIT = uint32(context.Get("COLOR_BUFFER_BIT").Int())
 	self.constants.COMPILE_STATUS = uint32(context.Get("COMPILE_STATUS").Int())
+	self.constants.DEPTH_ATTACHMENT = uint32(context.Get("DEPTH_ATTACHMENT").Int())
 	self.constants.DEPTH_BUFFER_BIT = uint32(context.Get("DEPTH_BUFFER_BIT").Int())
+	self.constants.DEPTH_COMPONENT16 = uint32(context.Get("DEPTH_COMPONENT16").Int())
 	self.constants.DEPTH_TEST = uint32(context.Get("DEPTH_TEST").Int())
+	self.constants.DYNAMIC_DRAW = uint32(context.Get("DYNAMIC_DRAW").Int())
 	self.constants.ELEMENT_ARRAY_BUFFER = uint32(context.Get("ELEMENT_ARRAY_BUFFER").Int())
 	self.constants.FLOAT = uint32(context.Get("FLOAT").Int())
 	self.constants.FRAGMENT_SHADER = uint32(context.Get("FRAGMENT_SHADER").Int())
+	self.constants.FRAMEBUFFER = uint32(context.Get("FRAMEBUFFER").Int())
+	self.constants.FRAMEBUFFER_COMPLETE = uint32(context.Get("FRAMEBUFFER_COMPLETE").Int())
 	self.constants.LEQUAL = uint32(context.Get("LEQUAL").Int())
 	self.constants.LESS = uint32(context.Get("LESS").Int())
 	self.constants.LINEAR = uint32(context.Get("LINEAR").Int())
@@ -53,9 +59,11 @@ func NewWebGLRenderingContext(canvas js.Value) (*WebGLRenderingContext, error) {
 	self.constants.ONE = uint32(context.Get("ONE").Int())
 	self.constants.ONE_MINUS_SRC_ALPHA = uint32(context.Get("ONE_MINUS_SRC_ALPHA").Int())
 	self.constants.POINTS = uint32(context.Get("POINTS").Int())
+	self.constants.RENDERBUFFER = uint32(context.Get("RENDERBUFFER").Int())
 	self.constants.RGBA = uint32(context.Get("RGBA").Int())
 	self.constants.SRC_ALPHA = uint32(context.Get("SRC_ALPHA").Int())
 	self.constants.STATIC_DRAW = uint32(context.Get("STATIC_DRAW").Int())
+	self.constants.STREAM_DRAW = uint32(context.Get("STREAM_DRAW").Int())
 	self.constants.TEXTURE_2D = uint32(context.Get("TEXTURE_2D").Int())
 	self.constants.TEXTURE0 = uint32(context.Get("TEXTURE0").Int())
 	self.constants.TEXTURE1 = uint32(context.Get("TEXTURE1").Int())
@@ -131,7 +139,7 @@ func (self *WebGLRenderingContext) CreateDataBufferVAO() *gigl.VAO {
 	return &gigl.VAO{}
 }
 
-func (self *WebGLRenderingContext) CreateVtxDataBuffer(data_slice []float32) interface{} {
+func (self *WebGLRenderingContext) CreateVtxDataBuffer(data_slice []float32, usage uint32) interface{} {
 	// 'target' : c.ARRAY_BUFFER or c.ELEMENT_ARRAY_BUFFER
 	if data_slice == nil {
 		return nil
@@ -140,7 +148,7 @@ func (self *WebGLRenderingContext) CreateVtxDataBuffer(data_slice []float32) int
 	buffer := self.context.Call("createBuffer")
 	self.context.Call("bindBuffer", js.ValueOf(c.ARRAY_BUFFER), buffer)
 	var js_typed_array = self.ConvertGoSliceToJsTypedArray(data_slice)
-	self.context.Call("bufferData", js.ValueOf(c.ARRAY_BUFFER), js_typed_array, js.ValueOf(c.STATIC_DRAW))
+	self.context.Call("bufferData", js.ValueOf(c.ARRAY_BUFFER), js_typed_array, js.ValueOf(usage))
 	self.context.Call("bindBuffer", js.ValueOf(c.ARRAY_BUFFER), nil)
 	return buffer
 }
@@ -172,6 +180,24 @@ func (self *WebGLRenderingContext) GLBindBuffer(target uint32, buffer interface{
 	}
 }
 
+func (self *WebGLRenderingContext) GLBufferData(target uint32, data_slice []float32, usage uint32) {
+	// 'target' : c.ARRAY_BUFFER,  'usage' : c.STATIC_DRAW, c.DYNAMIC_DRAW or c.STREAM_DRAW
+	var js_typed_array = self.ConvertGoSliceToJsTypedArray(data_slice)
+	self.context.Call("bufferData", js.ValueOf(target), js_typed_array, js.ValueOf(usage))
+}
+
+func (self *WebGLRenderingContext) GLBufferSubData(target uint32, offset_in_byte int, data_slice []float32) {
+	// 'target' : c.ARRAY_BUFFER
+	if len(data_slice) > 0 {
+		var js_typed_array = self.ConvertGoSliceToJsTypedArray(data_slice)
+		self.context.Call("bufferSubData", js.ValueOf(target), js.ValueOf(offset_in_byte), js_typed_array)
+	}
+}
+
+func (self *WebGLRenderingContext) GLBindVertexArray(vertex_array interface{}) {
+	// DO NOTHING, since WebGL1 has no native VAO (without 'OES_vertex_array_object' extension)
+}
+
 // ----------------------------------------------------------------------------
 // Binding Texture
 // ----------------------------------------------------------------------------
@@ -185,6 +211,69 @@ func (self *WebGLRenderingContext) GLBindTexture(target uint32, texture interfac
 	self.context.Call("bindTexture", js.ValueOf(target), texture.(js.Value)) // 'binding_target' : TEXTURE_2D
 }
 
+// ----------------------------------------------------------------------------
+// Framebuffer
+// ----------------------------------------------------------------------------
+
+func (self *WebGLRenderingContext) CreateFramebuffer() interface{} {
+	return self.context.Call("createFramebuffer")
+}
+
+func (self *WebGLRenderingContext) CreateRenderbuffer() interface{} {
+	return self.context.Call("createRenderbuffer")
+}
+
+func (self *WebGLRenderingContext) CreateTexture(width int, height int) interface{} {
+	// create an empty RGBA texture (NON-POWER-OF-2 size is allowed with CLAMP_TO_EDGE & LINEAR)
+	c := self.GetConstants()
+	texture := self.context.Call("createTexture")
+	self.context.Call("bindTexture", js.ValueOf(c.TEXTURE_2D), texture)
+	self.context.Call("texImage2D", js.ValueOf(c.TEXTURE_2D), 0, js.ValueOf(c.RGBA), width, height, 0, js.ValueOf(c.RGBA), js.ValueOf(c.UNSIGNED_BYTE), js.Null())
+	self.context.Call("texParameteri", js.ValueOf(c.TEXTURE_2D), js.ValueOf(c.TEXTURE_WRAP_S), js.ValueOf(c.CLAMP_TO_EDGE))
+	self.context.Call("texParameteri", js.ValueOf(c.TEXTURE_2D), js.ValueOf(c.TEXTURE_WRAP_T), js.ValueOf(c.CLAMP_TO_EDGE))
+	self.context.Call("texParameteri", js.ValueOf(c.TEXTURE_2D), js.ValueOf(c.TEXTURE_MIN_FILTER), js.ValueOf(c.LINEAR))
+	self.context.Call("bindTexture", js.ValueOf(c.TEXTURE_2D), js.Null())
+	return texture
+}
+
+func (self *WebGLRenderingContext) GLBindFramebuffer(target uint32, framebuffer interface{}) {
+	// 'target' : FRAMEBUFFER  ('framebuffer' nil means the canvas)
+	if framebuffer == nil {
+		self.context.Call("bindFramebuffer", js.ValueOf(target), js.Null())
+	} else {
+		self.context.Call("bindFramebuffer", js.ValueOf(target), framebuffer.(js.Value))
+	}
+}
+
+func (self *WebGLRenderingContext) GLBindRenderbuffer(target uint32, renderbuffer interface{}) {
+	// 'target' : RENDERBUFFER
+	if renderbuffer == nil {
+		self.context.Call("bindRenderbuffer", js.ValueOf(target), js.Null())
+	} else {
+		self.context.Call("bindRenderbuffer", js.ValueOf(target), renderbuffer.(js.Value))
+	}
+}
+
+func (self *WebGLRenderingContext) GLRenderbufferStorage(target uint32, internalformat uint32, width int, height int) {
+	self.context.Call("renderbufferStorage", js.ValueOf(target), js.ValueOf(internalformat), width, height)
+}
+
+func (self *WebGLRenderingContext) GLFramebufferTexture2D(target uint32, attachment uint32, textarget uint32, texture interface{}, level int) {
+	self.context.Call("framebufferTexture2D", js.ValueOf(target), js.ValueOf(attachment), js.ValueOf(textarget), texture.(js.Value), level)
+}
+
+func (self *WebGLRenderingContext) GLFramebufferRenderbuffer(target uint32, attachment uint32, renderbuffertarget uint32, renderbuffer interface{}) {
+	self.context.Call("framebufferRenderbuffer", js.ValueOf(target), js.ValueOf(attachment), js.ValueOf(renderbuffertarget), renderbuffer.(js.Value))
+}
+
+func (self *WebGLRenderingContext) GLCheckFramebufferStatus(target uint32) uint32 {
+	return uint32(self.context.Call("checkFramebufferStatus", js.ValueOf(target)).Int())
+}
+
+func (self *WebGLRenderingContext) GLViewport(x int, y int, width int, height int) {
+	self.context.Call("viewport", x, y, width, height)
+}
+
 // ----------------------------------------------------------------------------
 // Binding Uniforms
 // ----------------------------------------------------------------------------
@@ -300,6 +389,55 @@ func (self *WebGLRenderingContext) GLDrawElementsInstanced(mode uint32, element_
 	}
 }
 
+// ----------------------------------------------------------------------------
+// Reading Pixels
+// ----------------------------------------------------------------------------
+
+func (self *WebGLRenderingContext) GLReadPixels(x int, y int, width int, height int, format uint32, dtype uint32, pixels []uint8) {
+	// 'format' : RGBA,  'dtype' : UNSIGNED_BYTE
+	js_pixels := js.Global().Get("Uint8Array").New(len(pixels))
+	self.context.Call("readPixels", x, y, width, height, js.ValueOf(format), js.ValueOf(dtype), js_pixels)
+	js.CopyBytesToGo(pixels, js_pixels)
+}
+
+// ----------------------------------------------------------------------------
+// Deleting Resources
+// ----------------------------------------------------------------------------
+
+func (self *WebGLRenderingContext) GLDeleteBuffer(buffer interface{}) {
+	if buffer != nil {
+		self.context.Call("deleteBuffer", buffer.(js.Value))
+	}
+}
+
+func (self *WebGLRenderingContext) GLDeleteVertexArray(vertex_array interface{}) {
+	// DO NOTHING, since native VAO is not supported
+}
+
+func (self *WebGLRenderingContext) GLDeleteTexture(texture interface{}) {
+	if texture != nil {
+		self.context.Call("deleteTexture", texture.(js.Value))
+	}
+}
+
+func (self *WebGLRenderingContext) GLDeleteProgram(program interface{}) {
+	if program != nil {
+		self.context.Call("deleteProgram", program.(js.Value))
+	}
+}
+
+func (self *WebGLRenderingContext) GLDeleteFramebuffer(framebuffer interface{}) {
+	if framebuffer != nil {
+		self.context.Call("deleteFramebuffer", framebuffer.(js.Value))
+	}
+}
+
+func (self *WebGLRenderingContext) GLDeleteRenderbuffer(renderbuffer interface{}) {
+	if renderbuffer != nil {
+		self.context.Call("deleteRenderbuffer", renderbuffer.(js.Value))
+	}
+}
+
 // ----------------------------------------------------------------------------
 // WebGL Extensions
 // ----------------------------------------------------------------------------
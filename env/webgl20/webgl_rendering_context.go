@@ -0,0 +1,546 @@
+package webgl20
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"syscall/js"
+	"unsafe"
+
+	"github.com/go4orward/gigl"
+)
+
+type WebGLRenderingContext struct {
+	context   js.Value         // WebGL2RenderingContext
+	constants gigl.GLConstants // WebGL constant values
+	wh        [2]int           // canvas width & height
+}
+
+func NewWebGLRenderingContext(canvas js.Value) (*WebGLRenderingContext, error) {
+	context := canvas.Call("getContext", "webgl2")
+	if context.IsUndefined() || context.IsNull() {
+		return nil, errors.New("WebGL2 not supported")
+	}
+	self := WebGLRenderingContext{context: context}
+	// Note that UINT32 index and geometry instancing are supported natively by WebGL2 (no extension needed).
+	self.wh[0] = canvas.Get("clientWidth").Int()
+	self.wh[1] = canvas.Get("clientHeight").Int()
+	// get WebGL constants
+	self.constants.ARRAY_BUFFER = uint32(context.Get("ARRAY_BUFFER").Int())
+	self.constants.BLEND = uint32(context.Get("BLEND").Int())
+	self.constants.BYTE = uint32(context.Get("BYTE").Int())
+	self.constants.CLAMP_TO_EDGE = uint32(context.Get("CLAMP_TO_EDGE").Int())
+	self.constants.COLOR_ATTACHMENT0 = uint32(context.Get("COLOR_ATTACHMENT0").Int())
+	self.constants.COLOR_BUFFER_BIT = uint32(context.Get("COLOR_BUFFER_BIT").Int())
+	self.constants.COMPILE_STATUS = uint32(context.Get("COMPILE_STATUS").Int())
+	self.constants.DEPTH_ATTACHMENT = uint32(context.Get("DEPTH_ATTACHMENT").Int())
+	self.constants.DEPTH_BUFFER_BIT = uint32(context.Get("DEPTH_BUFFER_BIT").Int())
+	self.constants.DEPTH_COMPONENT16 = uint32(context.Get("DEPTH_COMPONENT16").Int())
+	self.constants.DEPTH_TEST = uint32(context.Get("DEPTH_TEST").Int())
+	self.constants.DYNAMIC_DRAW = uint32(context.Get("DYNAMIC_DRAW").Int())
+	self.constants.ELEMENT_ARRAY_BUFFER = uint32(context.Get("ELEMENT_ARRAY_BUFFER").Int())
+	self.constants.FLOAT = uint32(context.Get("FLOAT").Int())
+	self.constants.FRAGMENT_SHADER = uint32(context.Get("FRAGMENT_SHADER").Int())
+	self.constants.FRAMEBUFFER = uint32(context.Get("FRAMEBUFFER").Int())
+	self.constants.FRAMEBUFFER_COMPLETE = uint32(context.Get("FRAMEBUFFER_COMPLETE").Int())
+	self.constants.LEQUAL = uint32(context.Get("LEQUAL").Int())
+	self.constants.LESS = uint32(context.Get("LESS").Int())
+	self.constants.LINEAR = uint32(context.Get("LINEAR").Int())
+	self.constants.LINES = uint32(context.Get("LINES").Int())
+	self.constants.LINK_STATUS = uint32(context.Get("LINK_STATUS").Int())
+	self.constants.NEAREST = uint32(context.Get("NEAREST").Int())
+	self.constants.ONE = uint32(context.Get("ONE").Int())
+	self.constants.ONE_MINUS_SRC_ALPHA = uint32(context.Get("ONE_MINUS_SRC_ALPHA").Int())
+	self.constants.POINTS = uint32(context.Get("POINTS").Int())
+	self.constants.RENDERBUFFER = uint32(context.Get("RENDERBUFFER").Int())
+	self.constants.RGBA = uint32(context.Get("RGBA").Int())
+	self.constants.SRC_ALPHA = uint32(context.Get("SRC_ALPHA").Int())
+	self.constants.STATIC_DRAW = uint32(context.Get("STATIC_DRAW").Int())
+	self.constants.STREAM_DRAW = uint32(context.Get("STREAM_DRAW").Int())
+	self.constants.TEXTURE_2D = uint32(context.Get("TEXTURE_2D").Int())
+	self.constants.TEXTURE0 = uint32(context.Get("TEXTURE0").Int())
+	self.constants.TEXTURE1 = uint32(context.Get("TEXTURE1").Int())
+	self.constants.TEXTURE_MIN_FILTER = uint32(context.Get("TEXTURE_MIN_FILTER").Int())
+	self.constants.TEXTURE_WRAP_S = uint32(context.Get("TEXTURE_WRAP_S").Int())
+	self.constants.TEXTURE_WRAP_T = uint32(context.Get("TEXTURE_WRAP_T").Int())
+	self.constants.TRIANGLES = uint32(context.Get("TRIANGLES").Int())
+	self.constants.UNSIGNED_BYTE = uint32(context.Get("UNSIGNED_BYTE").Int())
+	self.constants.UNSIGNED_INT = uint32(context.Get("UNSIGNED_INT").Int())
+	self.constants.UNSIGNED_SHORT = uint32(context.Get("UNSIGNED_SHORT").Int())
+	self.constants.VERTEX_SHADER = uint32(context.Get("VERTEX_SHADER").Int())
+	return &self, nil
+}
+
+func (self *WebGLRenderingContext) GetWH() [2]int {
+	return self.wh
+}
+
+func (self *WebGLRenderingContext) GetConstants() *gigl.GLConstants {
+	return &self.constants
+}
+
+func (self *WebGLRenderingContext) GetEnvVariable(vname string, dtype string) interface{} {
+	// In WebGL environment, 'EnvVariable' means QueryParameters of the current URL path
+	href := js.Global().Get("window").Get("location").Get("href").String()
+	url := js.Global().Get("URL").New(href)
+	param := url.Get("searchParams").Call("get", vname)
+	switch dtype {
+	case "int":
+		if param.IsNull() {
+			return 0
+		} else {
+			n, _ := strconv.Atoi(param.String())
+			return n
+		}
+	case "bool":
+		if param.IsNull() {
+			return false
+		} else {
+			b, _ := strconv.ParseBool(param.String())
+			return b
+		}
+	default:
+		if param.IsNull() {
+			return ""
+		} else {
+			return param.String()
+		}
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Material & Shader
+// ----------------------------------------------------------------------------
+
+func (self *WebGLRenderingContext) LoadMaterial(material gigl.GLMaterial) error {
+	return load_material(self, material)
+}
+
+func (self *WebGLRenderingContext) SetupMaterial(material gigl.GLMaterial) error {
+	return setup_material(self, material)
+}
+
+func (self *WebGLRenderingContext) CreateShader(vertex_shader string, fragment_shader string) (gigl.GLShader, error) {
+	return create_shader(self, vertex_shader, fragment_shader)
+}
+
+// ----------------------------------------------------------------------------
+// WebGL DataBuffer
+// ----------------------------------------------------------------------------
+
+func (self *WebGLRenderingContext) CreateDataBufferVAO() *gigl.VAO {
+	vertex_array := self.context.Call("createVertexArray") // native VAO of WebGL2
+	return &gigl.VAO{VertexArray: vertex_array}
+}
+
+func (self *WebGLRenderingContext) CreateVtxDataBuffer(data_slice []float32, usage uint32) interface{} {
+	// 'target' : c.ARRAY_BUFFER or c.ELEMENT_ARRAY_BUFFER
+	if data_slice == nil {
+		return nil
+	}
+	c := self.GetConstants()
+	buffer := self.context.Call("createBuffer")
+	self.context.Call("bindBuffer", js.ValueOf(c.ARRAY_BUFFER), buffer)
+	var js_typed_array = self.ConvertGoSliceToJsTypedArray(data_slice)
+	self.context.Call("bufferData", js.ValueOf(c.ARRAY_BUFFER), js_typed_array, js.ValueOf(usage))
+	self.context.Call("bindBuffer", js.ValueOf(c.ARRAY_BUFFER), nil)
+	return buffer
+}
+
+func (self *WebGLRenderingContext) CreateIdxDataBuffer(data_slice []uint32) interface{} {
+	// 'target' : c.ARRAY_BUFFER or c.ELEMENT_ARRAY_BUFFER
+	if data_slice == nil {
+		return nil
+	}
+	c := self.GetConstants()
+	buffer := self.context.Call("createBuffer")
+	self.context.Call("bindBuffer", js.ValueOf(c.ELEMENT_ARRAY_BUFFER), buffer)
+	var js_typed_array = self.ConvertGoSliceToJsTypedArray(data_slice)
+	self.context.Call("bufferData", js.ValueOf(c.ELEMENT_ARRAY_BUFFER), js_typed_array, js.ValueOf(c.STATIC_DRAW))
+	self.context.Call("bindBuffer", js.ValueOf(c.ELEMENT_ARRAY_BUFFER), nil)
+	return buffer
+}
+
+// ----------------------------------------------------------------------------
+// Binding DataBuffer
+// ----------------------------------------------------------------------------
+
+func (self *WebGLRenderingContext) GLBindBuffer(target uint32, buffer interface{}) {
+	// 'bind_target' : c.ARRAY_BUFFER or c.ELEMENT_ARRAY_BUFFER
+	if buffer == nil {
+		self.context.Call("bindBuffer", js.ValueOf(target), js.Null())
+	} else {
+		self.context.Call("bindBuffer", js.ValueOf(target), buffer.(js.Value))
+	}
+}
+
+func (self *WebGLRenderingContext) GLBufferData(target uint32, data_slice []float32, usage uint32) {
+	// 'target' : c.ARRAY_BUFFER,  'usage' : c.STATIC_DRAW, c.DYNAMIC_DRAW or c.STREAM_DRAW
+	var js_typed_array = self.ConvertGoSliceToJsTypedArray(data_slice)
+	self.context.Call("bufferData", js.ValueOf(target), js_typed_array, js.ValueOf(usage))
+}
+
+func (self *WebGLRenderingContext) GLBufferSubData(target uint32, offset_in_byte int, data_slice []float32) {
+	// 'target' : c.ARRAY_BUFFER
+	if len(data_slice) > 0 {
+		var js_typed_array = self.ConvertGoSliceToJsTypedArray(data_slice)
+		self.context.Call("bufferSubData", js.ValueOf(target), js.ValueOf(offset_in_byte), js_typed_array)
+	}
+}
+
+func (self *WebGLRenderingContext) GLBindVertexArray(vertex_array interface{}) {
+	if vertex_array == nil {
+		self.context.Call("bindVertexArray", js.Null())
+	} else {
+		self.context.Call("bindVertexArray", vertex_array.(js.Value))
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Binding Texture
+// ----------------------------------------------------------------------------
+
+func (self *WebGLRenderingContext) GLActiveTexture(texture_unit int) {
+	js_texture_unit := js.ValueOf(js.ValueOf(self.constants.TEXTURE0 + uint32(texture_unit)))
+	self.context.Call("activeTexture", js_texture_unit)
+}
+
+func (self *WebGLRenderingContext) GLBindTexture(target uint32, texture interface{}) {
+	self.context.Call("bindTexture", js.ValueOf(target), texture.(js.Value)) // 'binding_target' : TEXTURE_2D
+}
+
+// ----------------------------------------------------------------------------
+// Framebuffer
+// ----------------------------------------------------------------------------
+
+func (self *WebGLRenderingContext) CreateFramebuffer() interface{} {
+	return self.context.Call("createFramebuffer")
+}
+
+func (self *WebGLRenderingContext) CreateRenderbuffer() interface{} {
+	return self.context.Call("createRenderbuffer")
+}
+
+func (self *WebGLRenderingContext) CreateTexture(width int, height int) interface{} {
+	// create an empty RGBA texture (NON-POWER-OF-2 size is allowed with CLAMP_TO_EDGE & LINEAR)
+	c := self.GetConstants()
+	texture := self.context.Call("createTexture")
+	self.context.Call("bindTexture", js.ValueOf(c.TEXTURE_2D), texture)
+	self.context.Call("texImage2D", js.ValueOf(c.TEXTURE_2D), 0, js.ValueOf(c.RGBA), width, height, 0, js.ValueOf(c.RGBA), js.ValueOf(c.UNSIGNED_BYTE), js.Null())
+	self.context.Call("texParameteri", js.ValueOf(c.TEXTURE_2D), js.ValueOf(c.TEXTURE_WRAP_S), js.ValueOf(c.CLAMP_TO_EDGE))
+	self.context.Call("texParameteri", js.ValueOf(c.TEXTURE_2D), js.ValueOf(c.TEXTURE_WRAP_T), js.ValueOf(c.CLAMP_TO_EDGE))
+	self.context.Call("texParameteri", js.ValueOf(c.TEXTURE_2D), js.ValueOf(c.TEXTURE_MIN_FILTER), js.ValueOf(c.LINEAR))
+	self.context.Call("bindTexture", js.ValueOf(c.TEXTURE_2D), js.Null())
+	return texture
+}
+
+func (self *WebGLRenderingContext) GLBindFramebuffer(target uint32, framebuffer interface{}) {
+	// 'target' : FRAMEBUFFER  ('framebuffer' nil means the canvas)
+	if framebuffer == nil {
+		self.context.Call("bindFramebuffer", js.ValueOf(target), js.Null())
+	} else {
+		self.context.Call("bindFramebuffer", js.ValueOf(target), framebuffer.(js.Value))
+	}
+}
+
+func (self *WebGLRenderingContext) GLBindRenderbuffer(target uint32, renderbuffer interface{}) {
+	// 'target' : RENDERBUFFER
+	if renderbuffer == nil {
+		self.context.Call("bindRenderbuffer", js.ValueOf(target), js.Null())
+	} else {
+		self.context.Call("bindRenderbuffer", js.ValueOf(target), renderbuffer.(js.Value))
+	}
+}
+
+func (self *WebGLRenderingContext) GLRenderbufferStorage(target uint32, internalformat uint32, width int, height int) {
+	self.context.Call("renderbufferStorage", js.ValueOf(target), js.ValueOf(internalformat), width, height)
+}
+
+func (self *WebGLRenderingContext) GLFramebufferTexture2D(target uint32, attachment uint32, textarget uint32, texture interface{}, level int) {
+	self.context.Call("framebufferTexture2D", js.ValueOf(target), js.ValueOf(attachment), js.ValueOf(textarget), texture.(js.Value), level)
+}
+
+func (self *WebGLRenderingContext) GLFramebufferRenderbuffer(target uint32, attachment uint32, renderbuffertarget uint32, renderbuffer interface{}) {
+	self.context.Call("framebufferRenderbuffer", js.ValueOf(target), js.ValueOf(attachment), js.ValueOf(renderbuffertarget), renderbuffer.(js.Value))
+}
+
+func (self *WebGLRenderingContext) GLCheckFramebufferStatus(target uint32) uint32 {
+	return uint32(self.context.Call("checkFramebufferStatus", js.ValueOf(target)).Int())
+}
+
+func (self *WebGLRenderingContext) GLViewport(x int, y int, width int, height int) {
+	self.context.Call("viewport", x, y, width, height)
+}
+
+// ----------------------------------------------------------------------------
+// Binding Uniforms
+// ----------------------------------------------------------------------------
+
+func (self *WebGLRenderingContext) GLUniform1i(location interface{}, v0 int) {
+	self.context.Call("uniform1i", location.(js.Value), v0)
+}
+
+func (self *WebGLRenderingContext) GLUniform1f(location interface{}, v0 float32) {
+	self.context.Call("uniform1f", location.(js.Value), v0)
+}
+
+func (self *WebGLRenderingContext) GLUniform2f(location interface{}, v0 float32, v1 float32) {
+	self.context.Call("uniform2f", location.(js.Value), v0, v1)
+}
+
+func (self *WebGLRenderingContext) GLUniform3f(location interface{}, v0 float32, v1 float32, v2 float32) {
+	self.context.Call("uniform3f", location.(js.Value), v0, v1, v2)
+}
+
+func (self *WebGLRenderingContext) GLUniform4f(location interface{}, v0 float32, v1 float32, v2 float32, v3 float32) {
+	self.context.Call("uniform4f", location.(js.Value), v0, v1, v2, v3)
+}
+
+func (self *WebGLRenderingContext) GLUniformMatrix3fv(location interface{}, transpose bool, values []float32) {
+	js_typed_array := self.ConvertGoSliceToJsTypedArray(values) // converted to JavaScript 'Float32Array'
+	self.context.Call("uniformMatrix3fv", location.(js.Value), transpose, js_typed_array)
+}
+
+func (self *WebGLRenderingContext) GLUniformMatrix4fv(location interface{}, transpose bool, values []float32) {
+	js_typed_array := self.ConvertGoSliceToJsTypedArray(values) // converted to JavaScript 'Float32Array'
+	self.context.Call("uniformMatrix4fv", location.(js.Value), transpose, js_typed_array)
+}
+
+// ----------------------------------------------------------------------------
+// Binding Attributes
+// ----------------------------------------------------------------------------
+
+func (self *WebGLRenderingContext) GLVertexAttribPointer(location interface{}, size int, dtype uint32, normalized bool, stride_in_byte int, offset_in_byte int) {
+	self.context.Call("vertexAttribPointer", location.(js.Value), size, js.ValueOf(dtype), normalized, stride_in_byte, offset_in_byte)
+}
+
+func (self *WebGLRenderingContext) GLEnableVertexAttribArray(location interface{}) {
+	self.context.Call("enableVertexAttribArray", location.(js.Value))
+}
+
+func (self *WebGLRenderingContext) GLVertexAttribDivisor(location interface{}, divisor int) {
+	self.context.Call("vertexAttribDivisor", location.(js.Value), divisor)
+}
+
+// ----------------------------------------------------------------------------
+// Preparing to Render
+// ----------------------------------------------------------------------------
+
+func (self *WebGLRenderingContext) GLClearColor(r float32, g float32, b float32, a float32) {
+	self.context.Call("clearColor", r, g, b, a)
+}
+
+func (self *WebGLRenderingContext) GLClear(mask uint32) {
+	self.context.Call("clear", js.ValueOf(mask))
+}
+
+func (self *WebGLRenderingContext) GLEnable(cap uint32) {
+	self.context.Call("enable", js.ValueOf(cap))
+}
+
+func (self *WebGLRenderingContext) GLDisable(cap uint32) {
+	self.context.Call("disable", js.ValueOf(cap))
+}
+
+func (self *WebGLRenderingContext) GLDepthFunc(ftn uint32) {
+	self.context.Call("depthFunc", js.ValueOf(ftn))
+}
+
+func (self *WebGLRenderingContext) GLBlendFunc(sfactor uint32, dfactor uint32) {
+	self.context.Call("blendFunc", js.ValueOf(sfactor), js.ValueOf(dfactor))
+}
+
+func (self *WebGLRenderingContext) GLUseProgram(shader_program interface{}) {
+	self.context.Call("useProgram", shader_program.(js.Value))
+}
+
+// ----------------------------------------------------------------------------
+// Rendering
+// ----------------------------------------------------------------------------
+
+func (self *WebGLRenderingContext) GLDrawArrays(mode uint32, first int, count int) {
+	// 'mode' : POINTS
+	self.context.Call("drawArrays", js.ValueOf(mode), first, count)
+}
+
+func (self *WebGLRenderingContext) GLDrawArraysInstanced(mode uint32, first int, count int, pose_count int) {
+	// 'mode' : POINTS
+	self.context.Call("drawArraysInstanced", js.ValueOf(mode), first, count, pose_count)
+}
+
+func (self *WebGLRenderingContext) GLDrawElements(mode uint32, count int, dtype uint32, offset int) {
+	// 'mode'  : LINES, TRIANGLES
+	// 'dtype' : UNSIGNED_INT
+	self.context.Call("drawElements", js.ValueOf(mode), count, js.ValueOf(dtype), offset)
+}
+
+func (self *WebGLRenderingContext) GLDrawElementsInstanced(mode uint32, element_count int, dtype uint32, offset int, pose_count int) {
+	// 'mode'  : LINES, TRIANGLES
+	// 'dtype' : UNSIGNED_INT
+	self.context.Call("drawElementsInstanced", js.ValueOf(mode), element_count, js.ValueOf(dtype), offset, pose_count)
+}
+
+// ----------------------------------------------------------------------------
+// Reading Pixels
+// ----------------------------------------------------------------------------
+
+func (self *WebGLRenderingContext) GLReadPixels(x int, y int, width int, height int, format uint32, dtype uint32, pixels []uint8) {
+	// 'format' : RGBA,  'dtype' : UNSIGNED_BYTE
+	js_pixels := js.Global().Get("Uint8Array").New(len(pixels))
+	self.context.Call("readPixels", x, y, width, height, js.ValueOf(format), js.ValueOf(dtype), js_pixels)
+	js.CopyBytesToGo(pixels, js_pixels)
+}
+
+// ----------------------------------------------------------------------------
+// Deleting Resources
+// ----------------------------------------------------------------------------
+
+func (self *WebGLRenderingContext) GLDeleteBuffer(buffer interface{}) {
+	if buffer != nil {
+		self.context.Call("deleteBuffer", buffer.(js.Value))
+	}
+}
+
+func (self *WebGLRenderingContext) GLDeleteVertexArray(vertex_array interface{}) {
+	if vertex_array != nil {
+		self.context.Call("deleteVertexArray", vertex_array.(js.Value))
+	}
+}
+
+func (self *WebGLRenderingContext) GLDeleteTexture(texture interface{}) {
+	if texture != nil {
+		self.context.Call("deleteTexture", texture.(js.Value))
+	}
+}
+
+func (self *WebGLRenderingContext) GLDeleteProgram(program interface{}) {
+	if program != nil {
+		self.context.Call("deleteProgram", program.(js.Value))
+	}
+}
+
+func (self *WebGLRenderingContext) GLDeleteFramebuffer(framebuffer interface{}) {
+	if framebuffer != nil {
+		self.context.Call("deleteFramebuffer", framebuffer.(js.Value))
+	}
+}
+
+func (self *WebGLRenderingContext) GLDeleteRenderbuffer(renderbuffer interface{}) {
+	if renderbuffer != nil {
+		self.context.Call("deleteRenderbuffer", renderbuffer.(js.Value))
+	}
+}
+
+// ----------------------------------------------------------------------------
+// WebGL Extensions
+// ----------------------------------------------------------------------------
+
+func (self *WebGLRenderingContext) SetupExtension(extname string) {
+	// DO NOTHING, since the features of the extensions of WebGL1 are part of WebGL2
+}
+
+func (self *WebGLRenderingContext) IsExtensionReady(extname string) bool {
+	switch extname {
+	case "UINT32": // UINT32 index, to drawElements() with large number of vertices
+		return true
+	case "ANGLE": // geometry instancing
+		return true
+	}
+	return false
+}
+
+// ----------------------------------------------------------------------------
+// private functions
+// ----------------------------------------------------------------------------
+
+func (self *WebGLRenderingContext) ConvertGoSliceToJsTypedArray(a interface{}) js.Value {
+	// Since js.TypedArrayOf() of Go1.11 is no longer supported (due to WASM memory issue),
+	// we have to use js.CopyBytesToJS() instead. (Now it runs fine with Go1.15.7, Feb 5 2021)
+	//   Ref: syscall/js: replace TypedArrayOf with CopyBytesToGo/CopyBytesToJS
+	//   Ref: https://github.com/golang/go/issues/31980  	("js.TypedArrayOf is impossible to use correctly")
+	//   Ref: https://go-review.googlesource.com/c/go/+/177537/
+	//   Ref: https://github.com/golang/go/issues/32402  	(solution provided by 'hajimehoshi')
+	//   Ref: https://github.com/nuberu/webgl				(Golang WebAssembly wrapper for WebGL)
+	// Note that this solution sacrifices performance. (WebGL renderer's frame rate will be OK, though)
+	// We hope Go/WebAssembly will sort out this issue in the future.
+	switch a := a.(type) {
+	case []int8:
+		b := js.Global().Get("Uint8Array").New(len(a))
+		slice_head := (*reflect.SliceHeader)(unsafe.Pointer(&a))
+		byte_slice := *(*[]byte)(unsafe.Pointer(slice_head))
+		js.CopyBytesToJS(b, byte_slice)
+		return js.Global().Get("Int8Array").New(b.Get("buffer"), b.Get("byteOffset"), b.Get("byteLength"))
+	case []int16:
+		b := js.Global().Get("Uint8Array").New(len(a) * 2)
+		slice_head := (*reflect.SliceHeader)(unsafe.Pointer(&a))
+		slice_head.Len *= 2
+		slice_head.Cap *= 2
+		byte_slice := *(*[]byte)(unsafe.Pointer(slice_head))
+		js.CopyBytesToJS(b, byte_slice)
+		return js.Global().Get("Int16Array").New(b.Get("buffer"), b.Get("byteOffset"), b.Get("byteLength").Int()/2)
+	case []int32:
+		b := js.Global().Get("Uint8Array").New(len(a) * 4)
+		slice_head := (*reflect.SliceHeader)(unsafe.Pointer(&a))
+		slice_head.Len *= 4
+		slice_head.Cap *= 4
+		byte_slice := *(*[]byte)(unsafe.Pointer(slice_head))
+		js.CopyBytesToJS(b, byte_slice)
+		return js.Global().Get("Int32Array").New(b.Get("buffer"), b.Get("byteOffset"), b.Get("byteLength").Int()/4)
+	case []int64:
+		b := js.Global().Get("Uint8Array").New(len(a) * 8)
+		slice_head := (*reflect.SliceHeader)(unsafe.Pointer(&a))
+		slice_head.Len *= 8
+		slice_head.Cap *= 8
+		byte_slice := *(*[]byte)(unsafe.Pointer(slice_head))
+		js.CopyBytesToJS(b, byte_slice)
+		return js.Global().Get("BigInt64Array").New(b.Get("buffer"), b.Get("byteOffset"), b.Get("byteLength").Int()/8)
+	case []uint8:
+		b := js.Global().Get("Uint8Array").New(len(a))
+		js.CopyBytesToJS(b, a)
+		return b
+	case []uint16:
+		b := js.Global().Get("Uint8Array").New(len(a) * 2)
+		slice_head := (*reflect.SliceHeader)(unsafe.Pointer(&a))
+		slice_head.Len *= 2
+		slice_head.Cap *= 2
+		byte_slice := *(*[]byte)(unsafe.Pointer(slice_head))
+		js.CopyBytesToJS(b, byte_slice)
+		return js.Global().Get("Uint16Array").New(b.Get("buffer"), b.Get("byteOffset"), b.Get("byteLength").Int()/2)
+	case []uint32:
+		b := js.Global().Get("Uint8Array").New(len(a) * 4)
+		slice_head := (*reflect.SliceHeader)(unsafe.Pointer(&a))
+		slice_head.Len *= 4
+		slice_head.Cap *= 4
+		byte_slice := *(*[]byte)(unsafe.Pointer(slice_head))
+		js.CopyBytesToJS(b, byte_slice)
+		return js.Global().Get("Uint32Array").New(b.Get("buffer"), b.Get("byteOffset"), b.Get("byteLength").Int()/4)
+	case []uint64:
+		b := js.Global().Get("Uint8Array").New(len(a) * 4)
+		slice_head := (*reflect.SliceHeader)(unsafe.Pointer(&a))
+		slice_head.Len *= 8
+		slice_head.Cap *= 8
+		byte_slice := *(*[]byte)(unsafe.Pointer(slice_head))
+		js.CopyBytesToJS(b, byte_slice)
+		return js.Global().Get("BigUint64Array").New(b.Get("buffer"), b.Get("byteOffset"), b.Get("byteLength").Int()/8)
+	case []float32:
+		b := js.Global().Get("Uint8Array").New(len(a) * 4)
+		slice_head := (*reflect.SliceHeader)(unsafe.Pointer(&a))
+		slice_head.Len *= 4
+		slice_head.Cap *= 4
+		byte_slice := *(*[]byte)(unsafe.Pointer(slice_head))
+		// ShowArrayInfo(byte_slice)
+		js.CopyBytesToJS(b, byte_slice)
+		return js.Global().Get("Float32Array").New(b.Get("buffer"), b.Get("byteOffset"), b.Get("byteLength").Int()/4)
+	case []float64:
+		b := js.Global().Get("Uint8Array").New(len(a) * 8)
+		slice_head := (*reflect.SliceHeader)(unsafe.Pointer(&a))
+		slice_head.Len *= 8
+		slice_head.Cap *= 8
+		byte_slice := *(*[]byte)(unsafe.Pointer(slice_head))
+		js.CopyBytesToJS(b, byte_slice)
+		return js.Global().Get("Float64Array").New(b.Get("buffer"), b.Get("byteOffset"), b.Get("byteLength").Int()/8)
+	default:
+		panic(fmt.Sprintf("Unexpected value at ConvertGoSliceToJsTypedArray(): %T", a))
+	}
+}
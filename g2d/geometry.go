@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"math"
 
+	"github.com/go4orward/gigl"
 	"github.com/go4orward/gigl/common"
+	cst "github.com/go4orward/gigl/common/constants"
 )
 
 // ----------------------------------------------------------------------------
@@ -16,6 +18,7 @@ type Geometry struct {
 	edges [][]uint32
 	faces [][]uint32
 	tuvs  [][]float32 // texture uv coordinates (PER_FACE [nfaces][6] or PER_VERT [nverts][2])
+	fgrps [][2]int    // face groups, as ranges [start, end) of face indices of the merged geometries
 
 	dbuffer_vpoint      []float32 // data buffer for vertex points : COORD[] + (UV[2]) + (NORMAL[3])
 	dbuffer_fpoint      []float32 // data buffer for PER_FACE vertex points : COORD[3] + (UV[2]) + (NORMAL[3])
@@ -27,6 +30,11 @@ type Geometry struct {
 	// Note that, for PER_FACE texture UV-coordinates, vertices are duplicated for each face
 	fpoint_vidx_list  []uint32 // index of vertex_list of each face after PER_FACE data duplication
 	fpoint_vert_total int      // total count of vertices after PER_FACE data duplication
+
+	// Note that changes of the data buffers are tracked, to be uploaded again (without rebuilding)
+	dbuffer_vpoint_tracker gigl.BufferTracker // changes of 'dbuffer_vpoint'
+	dbuffer_fpoint_tracker gigl.BufferTracker // changes of 'dbuffer_fpoint'
+	dbuffer_usage          cst.BufferUsage    // usage hint for the data buffers (StaticDraw by default)
 }
 
 func NewGeometry() *Geometry {
@@ -41,6 +49,7 @@ func (self *Geometry) Clear(geom bool, data_buf bool, webgl_buf bool) *Geometry
 		self.edges = [][]uint32{}
 		self.faces = [][]uint32{}
 		self.tuvs = [][]float32{}
+		self.fgrps = nil
 	}
 	if data_buf || geom {
 		self.dbuffer_vpoint = nil
@@ -52,6 +61,10 @@ func (self *Geometry) Clear(geom bool, data_buf bool, webgl_buf bool) *Geometry
 		self.fpoint_vidx_list = nil
 		self.fpoint_vert_total = 0
 	}
+	if webgl_buf { // buffers of RenderingContext have to be built again
+		self.dbuffer_vpoint_tracker.MarkRebuilt()
+		self.dbuffer_fpoint_tracker.MarkRebuilt()
+	}
 	return self
 }
 
@@ -99,6 +112,18 @@ func (self *Geometry) SetFaces(faces [][]uint32) *Geometry {
 	return self
 }
 
+func (self *Geometry) GetVertices() [][2]float32 {
+	return self.verts
+}
+
+func (self *Geometry) GetEdges() [][]uint32 {
+	return self.edges
+}
+
+func (self *Geometry) GetFaces() [][]uint32 {
+	return self.faces
+}
+
 func (self *Geometry) AddVertex(coords [2]float32) uint32 {
 	vidx := len(self.verts)
 	self.verts = append(self.verts, coords)
@@ -117,6 +142,22 @@ func (self *Geometry) AddFace(face []uint32) uint32 {
 	return uint32(fidx)
 }
 
+func (self *Geometry) AddPolygonWithHoles(outer [][2]float32, holes [][][2]float32) []uint32 {
+	// Add the polygon (outer ring with its holes) as triangular faces, and return the indices of the new faces.
+	//   Rings may be given in any winding order, with collinear or duplicate points, or touching each other.
+	rings := append([][][2]float32{outer}, holes...)
+	triangles := common.TriangulatePolygon(rings)
+	vidx_stt := uint32(len(self.verts))
+	for _, ring := range rings {
+		self.verts = append(self.verts, ring...)
+	}
+	fidx_list := make([]uint32, len(triangles))
+	for i, t := range triangles {
+		fidx_list[i] = self.AddFace([]uint32{vidx_stt + uint32(t[0]), vidx_stt + uint32(t[1]), vidx_stt + uint32(t[2])})
+	}
+	return fidx_list
+}
+
 // ----------------------------------------------------------------------------
 // Transformation of Vertex Coordinates
 // ----------------------------------------------------------------------------
@@ -163,30 +204,140 @@ func (self *Geometry) AppyMatrix(matrix *common.Matrix3) *Geometry {
 // Merge
 // ----------------------------------------------------------------------------
 
-func (self *Geometry) Merge(g *Geometry) *Geometry {
+func (self *Geometry) Merge(g *Geometry, matrix ...*common.Matrix3) *Geometry {
+	// Merge the geometry (optionally transformed by the matrix), with its texture UVs.
+	// If PER_VERT and PER_FACE layouts are mixed, texture UVs are merged as PER_FACE (for each face corner),
+	//   and missing texture UVs are set to (0,0).
+	// The faces of each merged geometry are recorded as a face group (see GetFaceGroups()).
+	other := g.copy_geometry() // 'g' is not changed
+	if len(matrix) > 0 && matrix[0] != nil {
+		other.apply_matrix_with_winding(matrix[0])
+	}
 	self.Clear(false, true, true)
-	vcount := uint32(len(self.verts))
-	for _, v := range g.verts {
-		self.AddVertex(v)
+	vcount, fcount := uint32(len(self.verts)), len(self.faces)
+	if len(self.fgrps) == 0 && fcount > 0 {
+		self.fgrps = [][2]int{{0, fcount}}
+	}
+	if len(other.fgrps) > 0 {
+		for _, fgrp := range other.fgrps {
+			self.fgrps = append(self.fgrps, [2]int{fcount + fgrp[0], fcount + fgrp[1]})
+		}
+	} else if len(other.faces) > 0 {
+		self.fgrps = append(self.fgrps, [2]int{fcount, fcount + len(other.faces)})
+	}
+	if len(self.verts) == 0 { // nothing to be converted
+		self.verts, self.edges, self.faces, self.tuvs = other.verts, other.edges, other.faces, other.tuvs
+		return self
+	}
+	// texture UVs (PER_FACE, unless none of them is PER_FACE)
+	self_tmode, other_tmode := self.get_texture_mode(), other.get_texture_mode()
+	if self_tmode != other_tmode {
+		tmode := "FACE"
+		if self_tmode != "FACE" && other_tmode != "FACE" {
+			tmode = "VERTEX"
+		}
+		for _, geom := range []*Geometry{self, other} {
+			geom.convert_texture_uvs(tmode)
+		}
 	}
-	for _, e := range g.edges {
+	// vertices, edges, faces and texture UVs
+	self.verts = append(self.verts, other.verts...)
+	for _, e := range other.edges {
 		new_edge := make([]uint32, len(e))
 		for i := 0; i < len(new_edge); i++ {
 			new_edge[i] = e[i] + vcount
 		}
 		self.AddEdge(new_edge)
 	}
-	for _, f := range g.faces {
+	for _, f := range other.faces {
 		new_face := make([]uint32, len(f))
 		for i := 0; i < len(new_face); i++ {
 			new_face[i] = f[i] + vcount
 		}
 		self.AddFace(new_face)
 	}
-	// TODO: texture UV coordinates
+	self.tuvs = append(self.tuvs, other.tuvs...)
 	return self
 }
 
+func (self *Geometry) GetFaceGroups() [][2]int {
+	// Get the face groups of the merged geometries, as ranges [start, end) of face indices
+	//   (nil, if nothing was merged)
+	return self.fgrps
+}
+
+func (self *Geometry) copy_geometry() *Geometry {
+	g := NewGeometry()
+	g.verts = append(g.verts, self.verts...)
+	for _, e := range self.edges {
+		g.edges = append(g.edges, append([]uint32{}, e...))
+	}
+	for _, f := range self.faces {
+		g.faces = append(g.faces, append([]uint32{}, f...))
+	}
+	for _, tuv := range self.tuvs {
+		g.tuvs = append(g.tuvs, append([]float32{}, tuv...))
+	}
+	g.fgrps = append(g.fgrps, self.fgrps...)
+	return g
+}
+
+func (self *Geometry) apply_matrix_with_winding(m *common.Matrix3) {
+	// Transform the vertices, and reverse the faces if mirrored (to keep them counter-clockwise)
+	for i := 0; i < len(self.verts); i++ {
+		self.verts[i] = m.MultiplyVector2(self.verts[i])
+	}
+	e := m.GetElements() // COLUMN-MAJOR
+	if e[0]*e[4]-e[3]*e[1] < 0 {
+		tuv_per_face := self.get_texture_mode() == "FACE"
+		for fidx, face := range self.faces {
+			for i, j := 0, len(face)-1; i < j; i, j = i+1, j-1 {
+				face[i], face[j] = face[j], face[i]
+				if tuv_per_face && 2*j+1 < len(self.tuvs[fidx]) {
+					tuv := self.tuvs[fidx]
+					tuv[2*i], tuv[2*i+1], tuv[2*j], tuv[2*j+1] = tuv[2*j], tuv[2*j+1], tuv[2*i], tuv[2*i+1]
+				}
+			}
+		}
+	}
+}
+
+func (self *Geometry) get_texture_mode() string {
+	if len(self.tuvs) > 0 && len(self.tuvs) == len(self.faces) && len(self.tuvs[0]) >= 6 {
+		return "FACE"
+	} else if len(self.tuvs) > 0 && len(self.tuvs) == len(self.verts) && len(self.tuvs[0]) == 2 {
+		return "VERTEX"
+	}
+	return ""
+}
+
+func (self *Geometry) convert_texture_uvs(tmode string) {
+	// Convert texture UVs into PER_VERT or PER_FACE (with (0,0) for the missing ones)
+	switch self.get_texture_mode() {
+	case tmode:
+	case "VERTEX": // to PER_FACE
+		tuvs := make([][]float32, len(self.faces))
+		for fidx, face := range self.faces {
+			for _, vidx := range face {
+				tuvs[fidx] = append(tuvs[fidx], self.tuvs[vidx]...)
+			}
+		}
+		self.tuvs = tuvs
+	case "":
+		if tmode == "VERTEX" {
+			self.tuvs = make([][]float32, len(self.verts))
+			for vidx := range self.tuvs {
+				self.tuvs[vidx] = []float32{0, 0}
+			}
+		} else {
+			self.tuvs = make([][]float32, len(self.faces))
+			for fidx, face := range self.faces {
+				self.tuvs[fidx] = make([]float32, 2*len(face))
+			}
+		}
+	}
+}
+
 // ----------------------------------------------------------------------------
 // Texture UV coordinates
 // ----------------------------------------------------------------------------
@@ -234,41 +385,21 @@ func (self *Geometry) get_reverse(face_vlist []uint32) []uint32 {
 	return new_vlist
 }
 
-func (self *Geometry) splice_indices(a []uint32, pos int, delete_count int, new_entries ...uint32) []uint32 {
-	head := a[0:pos]
-	tail := a[pos+delete_count:]
-	return append(append(head, new_entries...), tail...)
-}
-
 func (self *Geometry) get_triangulation(face_vlist []uint32) [][]uint32 {
-	vindices := make([]uint32, len(face_vlist))
-	copy(vindices, face_vlist)
-	new_faces := make([][]uint32, 0)
-	vidx, vcount := 0, len(vindices)
-	iterations, max_iterations := 0, 10*len(vindices)
-	for vcount > 3 && iterations < max_iterations {
-		i0, i1, i2 := vidx, (vidx+1)%vcount, (vidx+2)%vcount
-		v0, v1, v2 := self.verts[vindices[i0]], self.verts[vindices[i1]], self.verts[vindices[i2]]
-		if IsCCW(v0, v1, v2) {
-			point_inside := false
-			for j := 0; j < vcount; j++ {
-				if j != i0 && j != i1 && j != i2 && IsPointInside(self.verts[vindices[j]], v0, v1, v2) {
-					point_inside = true
-					break
-				}
-			}
-			if !point_inside {
-				new_faces = append(new_faces, []uint32{vindices[i0], vindices[i1], vindices[i2]})
-				vindices = self.splice_indices(vindices, i1, 1)
-			}
-		}
-		vcount = len(vindices)
-		vidx = (vidx + 1) % vcount
-		iterations++
+	// Triangulate the face (which may be concave), and return the vertex indices of the triangles (in CCW order)
+	if len(face_vlist) <= 3 {
+		return [][]uint32{face_vlist}
+	}
+	ring := make([][2]float32, len(face_vlist))
+	for i, vidx := range face_vlist {
+		ring[i] = self.verts[vidx]
 	}
-	new_faces = append(new_faces, vindices)
-	if iterations == max_iterations {
-		common.Logger.Error("failed to traiangulate : %v => %v\n", face_vlist, new_faces)
+	new_faces := make([][]uint32, 0, len(face_vlist)-2)
+	for _, t := range common.TriangulatePolygon([][][2]float32{ring}) {
+		new_faces = append(new_faces, []uint32{face_vlist[t[0]], face_vlist[t[1]], face_vlist[t[2]]})
+	}
+	if len(new_faces) == 0 {
+		common.Logger.Warn("failed to triangulate : %v\n", face_vlist)
 	}
 	return new_faces
 }
@@ -408,6 +539,7 @@ func (self *Geometry) BuildDataBuffers(for_points bool, for_lines bool, for_face
 				tpos += 3
 			}
 		}
+		self.dbuffer_face = self.dbuffer_face[:tpos] // degenerate triangles may have been skipped
 	} else {
 		self.dbuffer_face = nil
 	}
@@ -457,13 +589,13 @@ func (self *Geometry) GetVtxBuffer(draw_mode int) []float32 {
 	}
 }
 
-func (self *Geometry) GetVtxBufferInfo(draw_mode int) [5]int {
+func (self *Geometry) GetVtxBufferInfo(draw_mode int) [6]int {
 	if draw_mode == 3 && self.dbuffer_fpoint != nil {
 		pinfo := self.dbuffer_fpoint_info // use extra vertex buffer (built for FACE drawing)s
-		return [5]int{(len(self.dbuffer_fpoint) / pinfo[0]), pinfo[0], pinfo[1], pinfo[2], 0}
+		return [6]int{(len(self.dbuffer_fpoint) / pinfo[0]), pinfo[0], pinfo[1], pinfo[2], 0, 0}
 	} else {
 		pinfo := self.dbuffer_vpoint_info // use original vertex buffer
-		return [5]int{(len(self.dbuffer_vpoint) / pinfo[0]), pinfo[0], pinfo[1], pinfo[2], 0}
+		return [6]int{(len(self.dbuffer_vpoint) / pinfo[0]), pinfo[0], pinfo[1], pinfo[2], 0, 0}
 	}
 }
 
@@ -488,3 +620,55 @@ func (self *Geometry) GetIdxBufferCount(mode int) int {
 		return 0 // n
 	}
 }
+
+func (self *Geometry) GetVtxBufferTracker(draw_mode int) *gigl.BufferTracker {
+	if draw_mode == 3 && self.dbuffer_fpoint != nil {
+		return &self.dbuffer_fpoint_tracker // use extra vertex buffer (built for FACE drawing)
+	} else {
+		return &self.dbuffer_vpoint_tracker // use original vertex buffer
+	}
+}
+
+func (self *Geometry) GetDataBufferUsage() cst.BufferUsage {
+	return self.dbuffer_usage
+}
+
+// ----------------------------------------------------------------------------
+// Updating Data Buffer (without rebuilding)
+// ----------------------------------------------------------------------------
+
+func (self *Geometry) SetDataBufferUsage(usage cst.BufferUsage) *Geometry {
+	// Set usage hint (StaticDraw/DynamicDraw/StreamDraw), if the vertices will be updated repeatedly
+	self.dbuffer_usage = usage
+	return self
+}
+
+func (self *Geometry) UpdateVertices(start int, vertices [][2]float32) *Geometry {
+	// Change the coordinates of the vertices from 'start', and update the data buffers in place,
+	//   so that only the changed values are uploaded again, when the geometry is rendered next time.
+	// Note that topology, texture UVs and normal vectors are not changed (rebuild the data buffers for them).
+	end := start + len(vertices)
+	if start < 0 || end > len(self.verts) {
+		common.Logger.Error("Failed to UpdateVertices() : invalid range [%d:%d] for %d vertices\n", start, end, len(self.verts))
+		return self
+	}
+	copy(self.verts[start:end], vertices)
+	if pinfo := self.dbuffer_vpoint_info; self.dbuffer_vpoint != nil && len(self.dbuffer_vpoint) >= end*pinfo[0] {
+		for vidx := start; vidx < end; vidx++ {
+			self.buffer_copy_xy(self.dbuffer_vpoint, pinfo, vidx, vidx)
+		}
+		self.dbuffer_vpoint_tracker.MarkChanged(start*pinfo[0], end*pinfo[0])
+	}
+	if pinfo := self.dbuffer_fpoint_info; self.dbuffer_fpoint != nil {
+		for fidx, face_vlist := range self.faces { // vertices are duplicated for each face
+			for i := 0; i < len(face_vlist); i++ {
+				if vidx := int(face_vlist[i]); vidx >= start && vidx < end {
+					new_vidx := self.get_fpoint_new_vidx(fidx, i)
+					self.buffer_copy_xy(self.dbuffer_fpoint, pinfo, new_vidx, vidx)
+					self.dbuffer_fpoint_tracker.MarkChanged(new_vidx*pinfo[0], new_vidx*pinfo[0]+2)
+				}
+			}
+		}
+	}
+	return self
+}
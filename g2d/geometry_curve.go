@@ -0,0 +1,74 @@
+package g2d
+
+import (
+	"github.com/go4orward/gigl/common"
+)
+
+// ----------------------------------------------------------------------------
+// Curves (tessellated into edges or faces)
+// ----------------------------------------------------------------------------
+
+func NewGeometryCurve(curves []*common.Curve, tolerance float32, camera *Camera) *Geometry {
+	// Curves (on XY plane) tessellated into polyline edges, one for each curve, within the 'tolerance'.
+	// If 'camera' is given, the tolerance is in pixels on the screen (rather than in world units).
+	geometry := NewGeometry()
+	for _, curve := range curves {
+		points := GetCurvePoints([]*common.Curve{curve}, tolerance, camera)
+		if len(points) < 2 {
+			continue
+		}
+		edge := make([]uint32, len(points))
+		for i, p := range points {
+			edge[i] = geometry.AddVertex(p)
+		}
+		if curve.IsClosed() {
+			edge = append(edge, edge[0])
+		}
+		geometry.AddEdge(edge)
+	}
+	return geometry
+}
+
+func NewGeometryCurveFilled(curves []*common.Curve, tolerance float32, camera *Camera) *Geometry {
+	// Region enclosed by the curves (on XY plane) connected one after another, as triangulated faces,
+	//   with its outline as an edge. (For holes, use GetCurvePoints() and AddPolygonWithHoles())
+	// If 'camera' is given, the tolerance is in pixels on the screen (rather than in world units).
+	geometry := NewGeometry()
+	outline := GetCurvePoints(curves, tolerance, camera)
+	if len(outline) < 3 {
+		return geometry
+	}
+	geometry.AddPolygonWithHoles(outline, nil)
+	edge := make([]uint32, len(outline)+1)
+	for i := range outline {
+		edge[i] = uint32(i)
+	}
+	geometry.AddEdge(edge) // closing edge back to the first vertex
+	return geometry
+}
+
+func GetCurvePoints(curves []*common.Curve, tolerance float32, camera *Camera) [][2]float32 {
+	// Points of the curves (on XY plane) connected one after another, tessellated within the 'tolerance',
+	//   where the shared end points of the consecutive curves (and the last one, if it's closing) are not repeated.
+	// If 'camera' is given, the tolerance is in pixels on the screen (rather than in world units).
+	var project func(xyz [3]float32) [2]float32
+	if camera != nil {
+		hw, hh := float32(camera.wh[0])/2, float32(camera.wh[1])/2
+		project = func(xyz [3]float32) [2]float32 {
+			cxy := camera.ProjectWorldToClip([2]float32{xyz[0], xyz[1]})
+			return [2]float32{cxy[0] * hw, cxy[1] * hh}
+		}
+	}
+	points := [][2]float32{}
+	for _, curve := range curves {
+		for _, p := range curve.Tessellate(tolerance, project) {
+			if len(points) == 0 || NewV2dBySub(points[len(points)-1], [2]float32{p[0], p[1]}).Length() > 1e-6 {
+				points = append(points, [2]float32{p[0], p[1]})
+			}
+		}
+	}
+	if len(points) > 2 && NewV2dBySub(points[len(points)-1], points[0]).Length() <= 1e-6 {
+		points = points[:len(points)-1]
+	}
+	return points
+}
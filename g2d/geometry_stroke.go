@@ -0,0 +1,175 @@
+package g2d
+
+import (
+	"math"
+)
+
+// ----------------------------------------------------------------------------
+// Stroke (thick polyline)
+// ----------------------------------------------------------------------------
+
+const stroke_miter_limit = 4  // maximum length of the miter (in the unit of the half width), beyond which it's beveled
+const stroke_offset_range = 4 // range of the offsets encoded as texture UVs (in the unit of the half width)
+
+func NewGeometryStroke(polyline [][2]float32, width float32, join string, cap string, closed bool) *Geometry {
+	// Stroke of the polyline with the width (in world units), as triangular faces,
+	//   with "MITER", "ROUND" or "BEVEL" join, and "BUTT", "ROUND" or "SQUARE" cap (for an open polyline).
+	geometry := NewGeometry()
+	vmap := map[stroke_point]uint32{}
+	for _, t := range get_stroke_triangles(polyline, join, cap, closed) {
+		face := make([]uint32, 3)
+		for i, p := range t {
+			vidx, ok := vmap[p]
+			if !ok {
+				vidx = geometry.AddVertex([2]float32{p.center[0] + p.offset[0]*width/2, p.center[1] + p.offset[1]*width/2})
+				vmap[p] = vidx
+			}
+			face[i] = vidx
+		}
+		geometry.AddFace(face)
+	}
+	return geometry
+}
+
+func NewGeometryStrokeForPixelWidth(polyline [][2]float32, join string, cap string, closed bool) *Geometry {
+	// Stroke of the polyline to be rendered with a constant width in pixels, regardless of the zoom of the camera
+	//   (by the shader of NewShaderForStrokeInPixels(), which gives the width in pixels).
+	// Vertices are on the polyline, and their offsets (in the unit of the half width) are encoded as texture UVs.
+	geometry := NewGeometry()
+	vmap := map[stroke_point]uint32{}
+	tuvs := [][]float32{}
+	for _, t := range get_stroke_triangles(polyline, join, cap, closed) {
+		face := make([]uint32, 3)
+		for i, p := range t {
+			vidx, ok := vmap[p]
+			if !ok {
+				vidx = geometry.AddVertex(p.center)
+				tuvs = append(tuvs, []float32{ // [-4 ~ +4] => [0 ~ 1]
+					0.5 + 0.5*p.offset[0]/stroke_offset_range, 0.5 + 0.5*p.offset[1]/stroke_offset_range})
+				vmap[p] = vidx
+			}
+			face[i] = vidx
+		}
+		geometry.AddFace(face)
+	}
+	if len(tuvs) > 0 {
+		geometry.SetTextureUVs(tuvs)
+	}
+	return geometry
+}
+
+type stroke_point struct {
+	center [2]float32 // point on the polyline
+	offset [2]float32 // offset from the point (in the unit of the half width)
+}
+
+func get_stroke_triangles(polyline [][2]float32, join string, cap string, closed bool) [][3]stroke_point {
+	// Triangles (in counter-clockwise order) of the segments, the joins and the caps of the stroke,
+	//   where the segments are overlapping on the inner side of the joins.
+	points := [][2]float32{}
+	for _, p := range polyline {
+		if len(points) == 0 || p != points[len(points)-1] {
+			points = append(points, p)
+		}
+	}
+	if closed && len(points) > 1 && points[0] == points[len(points)-1] {
+		points = points[:len(points)-1]
+	}
+	n := len(points)
+	if n < 2 || (closed && n < 3) {
+		return nil
+	}
+	nsegs := n - 1
+	if closed {
+		nsegs = n
+	}
+	dirs, norms := make([][2]float32, nsegs), make([][2]float32, nsegs)
+	for s := 0; s < nsegs; s++ {
+		d := NewV2dBySub(points[(s+1)%n], points[s]).Normalize()
+		dirs[s], norms[s] = *d, [2]float32{-d[1], d[0]} // normal on the left side
+	}
+	triangles := [][3]stroke_point{}
+	add_fan := func(center [2]float32, offsets ...[2]float32) {
+		for i := 0; i+1 < len(offsets); i++ {
+			a, b := offsets[i], offsets[i+1]
+			if cross := a[0]*b[1] - a[1]*b[0]; cross > -1e-6 && cross < 1e-6 {
+				continue // skip degenerate triangle (like the bevel of a U-turn)
+			} else if cross < 0 {
+				a, b = b, a
+			}
+			triangles = append(triangles, [3]stroke_point{{center, [2]float32{0, 0}}, {center, a}, {center, b}})
+		}
+	}
+	// segments
+	for s := 0; s < nsegs; s++ {
+		p0, p1 := points[s], points[(s+1)%n]
+		left, right := norms[s], [2]float32{-norms[s][0], -norms[s][1]}
+		a, b, c, d := stroke_point{p0, right}, stroke_point{p1, right}, stroke_point{p1, left}, stroke_point{p0, left}
+		triangles = append(triangles, [3]stroke_point{a, b, c}, [3]stroke_point{a, c, d})
+	}
+	// joins
+	for i := 0; i < n; i++ {
+		if !closed && (i == 0 || i == n-1) {
+			continue
+		}
+		s0, s1 := (i+nsegs-1)%nsegs, i%nsegs
+		d0, d1, n0, n1 := V2d(dirs[s0]), V2d(dirs[s1]), norms[s0], norms[s1]
+		cross := d0.Cross(&d1)
+		if cross > -1e-6 && cross < 1e-6 && d0.Dot(&d1) > 0 {
+			continue // straight
+		}
+		side := float32(1) // outer side of the join (left, for turning right)
+		if cross > 0 {
+			side = -1
+		}
+		o0, o1 := [2]float32{n0[0] * side, n0[1] * side}, [2]float32{n1[0] * side, n1[1] * side}
+		switch join {
+		case "ROUND":
+			add_fan(points[i], get_stroke_arc(o0, o1, dirs[s0])...)
+		case "MITER":
+			if k := 1 + n0[0]*n1[0] + n0[1]*n1[1]; k > 2/(stroke_miter_limit*stroke_miter_limit) {
+				add_fan(points[i], o0, [2]float32{(o0[0] + o1[0]) / k, (o0[1] + o1[1]) / k}, o1)
+			} else {
+				add_fan(points[i], o0, o1)
+			}
+		default: // "BEVEL"
+			add_fan(points[i], o0, o1)
+		}
+	}
+	// caps
+	if !closed && (cap == "ROUND" || cap == "SQUARE") {
+		ends := [][3][2]float32{ // point, outward direction, and normal of the ends
+			{points[0], {-dirs[0][0], -dirs[0][1]}, norms[0]},
+			{points[n-1], dirs[nsegs-1], norms[nsegs-1]}}
+		for _, e := range ends {
+			p, d, l, r := e[0], e[1], e[2], [2]float32{-e[2][0], -e[2][1]}
+			if cap == "ROUND" {
+				add_fan(p, get_stroke_arc(l, r, d)...)
+			} else {
+				add_fan(p, l, [2]float32{l[0] + d[0], l[1] + d[1]}, [2]float32{r[0] + d[0], r[1] + d[1]}, r)
+			}
+		}
+	}
+	return triangles
+}
+
+func get_stroke_arc(o0 [2]float32, o1 [2]float32, through [2]float32) [][2]float32 {
+	// Points on the unit circle from 'o0' to 'o1' (the shorter way, or the way through 'through' for a half circle)
+	a0, a1 := math.Atan2(float64(o0[1]), float64(o0[0])), math.Atan2(float64(o1[1]), float64(o1[0]))
+	delta := math.Mod(a1-a0+3*math.Pi, 2*math.Pi) - math.Pi // (-PI ~ PI)
+	if math.Abs(delta) > math.Pi-1e-3 {
+		if o0[0]*through[1]-o0[1]*through[0] >= 0 { // counter-clockwise from 'o0' to 'through'
+			delta = math.Pi
+		} else {
+			delta = -math.Pi
+		}
+	}
+	nsteps := int(math.Ceil(math.Abs(delta) / (math.Pi / 16)))
+	arc := make([][2]float32, nsteps+1)
+	for i := 0; i <= nsteps; i++ {
+		a := a0 + delta*float64(i)/float64(nsteps)
+		arc[i] = [2]float32{float32(math.Cos(a)), float32(math.Sin(a))}
+	}
+	arc[0], arc[nsteps] = o0, o1 // (exactly the same points, to be shared)
+	return arc
+}
@@ -0,0 +1,119 @@
+package g2d_test
+
+import (
+	"testing"
+
+	"github.com/go4orward/gigl/env/software"
+	"github.com/go4orward/gigl/g2d"
+)
+
+func TestStrokeInPixelsKeepsWidthUnderZoom(t *testing.T) {
+	// A horizontal stroke of 10 pixels should cover 10 rows of pixels, regardless of the zoom of the camera,
+	//   while its length (in world units) should grow with the zoom.
+	rc := software.NewSoftwareRenderingContext(200, 200)
+	geometry := g2d.NewGeometryStrokeForPixelWidth([][2]float32{{-0.2, 0}, {0.2, 0}}, "MITER", "BUTT", false)
+	geometry.BuildDataBuffers(true, false, true)
+	material := g2d.NewMaterialColors("#000000")
+	shader := g2d.NewShaderForStrokeInPixels(rc, 10.0)
+	scene := g2d.NewScene("#ffffff").Add(g2d.NewSceneObject(geometry, material, nil, nil, shader))
+	renderer := g2d.NewRenderer(rc)
+	prev_cols := 0
+	for _, zoom := range []float32{1, 2, 4} {
+		camera := g2d.NewCamera(rc.GetWH(), 2.0, zoom)
+		renderer.Clear(scene)
+		renderer.RenderScene(scene, camera)
+		img := rc.GetImage()
+		rows, cols := 0, 0
+		for i := 0; i < 200; i++ {
+			if r, _, _, _ := img.At(100, i).RGBA(); r < 0x8000 {
+				rows++
+			}
+			if r, _, _, _ := img.At(i, 100).RGBA(); r < 0x8000 {
+				cols++
+			}
+		}
+		if rows < 9 || rows > 11 {
+			t.Errorf("stroke width at zoom %v : %d pixels (expected 10)", zoom, rows)
+		}
+		if cols <= prev_cols {
+			t.Errorf("stroke length at zoom %v : %d pixels (expected longer than %d)", zoom, cols, prev_cols)
+		}
+		prev_cols = cols
+	}
+}
+
+func TestStrokeTriangles(t *testing.T) {
+	// Triangles of the stroke (of width 0.2) should be counter-clockwise, and cover (or not) the given points,
+	//   where the polyline turns left at (1,0), and its first end is at (0,0).
+	lshape := [][2]float32{{0, 0}, {1, 0}, {1, 1}}
+	square := [][2]float32{{0, 0}, {1, 0}, {1, 1}, {0, 1}}
+	tests := []struct {
+		name      string
+		polyline  [][2]float32
+		join      string
+		cap       string
+		closed    bool
+		triangles int          // number of triangles
+		covered   [][2]float32 // points covered by the stroke
+		uncovered [][2]float32 // points not covered by the stroke
+	}{
+		{"MITER join", lshape, "MITER", "BUTT", false, 6,
+			[][2]float32{{0.5, 0.09}, {0.5, -0.09}, {1.09, -0.09}}, [][2]float32{{1.11, -0.11}}},
+		{"ROUND join", lshape, "ROUND", "BUTT", false, 4 + 8,
+			[][2]float32{{1.06, -0.06}, {1.09, 0}, {1, -0.09}}, [][2]float32{{1.09, -0.09}}},
+		{"BEVEL join", lshape, "BEVEL", "BUTT", false, 5,
+			[][2]float32{{1.04, -0.04}}, [][2]float32{{1.06, -0.06}}},
+		{"BUTT cap", lshape, "MITER", "BUTT", false, 6,
+			[][2]float32{{0.01, 0.09}, {1.09, 0.99}}, [][2]float32{{-0.01, 0}, {1, 1.01}}},
+		{"ROUND cap", lshape, "MITER", "ROUND", false, 6 + 2*16,
+			[][2]float32{{-0.09, 0}, {-0.06, 0.06}, {1, 1.09}}, [][2]float32{{-0.09, 0.09}, {-0.11, 0}, {1.09, 1.09}}},
+		{"SQUARE cap", lshape, "MITER", "SQUARE", false, 6 + 2*3,
+			[][2]float32{{-0.09, 0.09}, {-0.09, -0.09}, {1.09, 1.09}}, [][2]float32{{-0.11, 0}, {1, 1.11}}},
+		{"open square", square, "MITER", "BUTT", false, 3*2 + 2*2,
+			[][2]float32{{1.09, -0.09}, {1.09, 1.09}, {0.01, 1.09}}, [][2]float32{{0, 0.5}, {-0.01, 1.05}, {-0.09, -0.09}}},
+		{"closed square", square, "MITER", "ROUND", true, 4*2 + 4*2,
+			[][2]float32{{0, 0.5}, {-0.09, -0.09}, {-0.09, 1.09}, {1.09, 1.09}}, [][2]float32{{0.5, 0.5}, {-0.11, -0.11}}},
+		{"closed square repeating the first point", append(square, square[0]), "BEVEL", "BUTT", true, 4*2 + 4,
+			[][2]float32{{0, 0.5}, {-0.04, -0.04}}, [][2]float32{{-0.06, -0.06}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			geometry := g2d.NewGeometryStroke(tt.polyline, 0.2, tt.join, tt.cap, tt.closed)
+			verts, faces := geometry.GetVertices(), geometry.GetFaces()
+			if len(faces) != tt.triangles {
+				t.Errorf("%d triangles (expected %d)", len(faces), tt.triangles)
+			}
+			is_covered := func(p [2]float32) bool {
+				for _, f := range faces {
+					inside := true
+					for i := 0; i < 3; i++ {
+						a, b := verts[f[i]], verts[f[(i+1)%3]]
+						if (b[0]-a[0])*(p[1]-a[1])-(b[1]-a[1])*(p[0]-a[0]) < 0 {
+							inside = false
+						}
+					}
+					if inside {
+						return true
+					}
+				}
+				return false
+			}
+			for fidx, f := range faces {
+				a, b, c := verts[f[0]], verts[f[1]], verts[f[2]]
+				if area := (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0]); area <= 0 {
+					t.Errorf("triangle %d %v is degenerate or clockwise", fidx, f)
+				}
+			}
+			for _, p := range tt.covered {
+				if !is_covered(p) {
+					t.Errorf("point %v is not covered", p)
+				}
+			}
+			for _, p := range tt.uncovered {
+				if is_covered(p) {
+					t.Errorf("point %v is covered", p)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,351 @@
+package g2d
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/go4orward/gigl/common"
+)
+
+// ----------------------------------------------------------------------------
+// Validation
+// ----------------------------------------------------------------------------
+
+type Issue = common.GeometryIssue
+
+func (self *Geometry) Validate() []Issue {
+	// Find the problems of the geometry, which may break BuildDataBuffers() or make it render garbage.
+	//   Topology (duplicate faces, non-manifold edges, winding & orientation) is checked
+	//   only if all the vertex indices of the faces are valid.
+	issues := []Issue{}
+	nverts := uint32(len(self.verts))
+	for eidx, edge := range self.edges {
+		if i := get_invalid_index(edge, nverts); i >= 0 {
+			issues = append(issues, common.NewGeometryIssue("INDEX_OUT_OF_RANGE", "EDGE", eidx, fmt.Sprintf("has vertex index %d (with %d vertices)", edge[i], nverts)))
+		}
+	}
+	indices_ok := true
+	for fidx, face := range self.faces {
+		if i := get_invalid_index(face, nverts); i >= 0 {
+			issues = append(issues, common.NewGeometryIssue("INDEX_OUT_OF_RANGE", "FACE", fidx, fmt.Sprintf("has vertex index %d (with %d vertices)", face[i], nverts)))
+			indices_ok = false
+		} else if self.is_degenerate_face(face) {
+			issues = append(issues, common.NewGeometryIssue("DEGENERATE_FACE", "FACE", fidx, "has less than 3 distinct vertices or zero area"))
+		} else if self.get_signed_area(face) < 0 {
+			issues = append(issues, common.NewGeometryIssue("INVERTED_ORIENTATION", "FACE", fidx, "is clockwise"))
+		}
+	}
+	issues = append(issues, self.validate_attributes()...)
+	if !indices_ok {
+		return issues
+	}
+	for _, fidx := range common.GetDuplicateFaces(self.faces) {
+		issues = append(issues, common.NewGeometryIssue("DUPLICATE_FACE", "FACE", fidx, "has the same vertices as a previous face"))
+	}
+	issues = append(issues, common.ValidateFaceTopology(self.faces)...)
+	return issues
+}
+
+func (self *Geometry) validate_attributes() []Issue {
+	// Check the texture UVs against HasTextureFor()
+	issues := []Issue{}
+	switch self.get_texture_layout() {
+	case "VERTEX":
+		for vidx, tuv := range self.tuvs {
+			if len(tuv) != 2 {
+				issues = append(issues, common.NewGeometryIssue("TEXTURE_UV_SIZE", "TEXTURE", vidx, fmt.Sprintf("has %d values for a vertex", len(tuv))))
+			}
+		}
+	case "FACE":
+		for fidx, tuv := range self.tuvs {
+			if len(tuv) != 2*len(self.faces[fidx]) {
+				issues = append(issues, common.NewGeometryIssue("TEXTURE_UV_SIZE", "TEXTURE", fidx, fmt.Sprintf("has %d values for a face of %d vertices", len(tuv), len(self.faces[fidx]))))
+			}
+		}
+	default:
+		if len(self.tuvs) > 0 {
+			issues = append(issues, common.NewGeometryIssue("TEXTURE_UV_COUNT", "TEXTURE", -1,
+				fmt.Sprintf("%d texture UVs match neither %d vertices nor %d faces", len(self.tuvs), len(self.verts), len(self.faces))))
+		}
+	}
+	return issues
+}
+
+func (self *Geometry) get_texture_layout() string {
+	// Layout of the texture UVs by their count, even if some of them have the wrong size
+	//   (with the size of the first one deciding between the two, just like HasTextureFor())
+	if len(self.tuvs) == 0 {
+		return ""
+	} else if len(self.tuvs) == len(self.verts) && (len(self.tuvs) != len(self.faces) || len(self.tuvs[0]) == 2) {
+		return "VERTEX"
+	} else if len(self.tuvs) == len(self.faces) {
+		return "FACE"
+	}
+	return ""
+}
+
+func (self *Geometry) is_degenerate_face(face []uint32) bool {
+	// Check if the face has less than 3 distinct vertices, or (almost) zero area
+	distinct := map[uint32]bool{}
+	for _, vidx := range face {
+		distinct[vidx] = true
+	}
+	if len(distinct) < 3 {
+		return true
+	}
+	max_length := float32(0)
+	for i, vidx := range face {
+		if length := NewV2dBySub(self.verts[vidx], self.verts[face[(i+1)%len(face)]]).Length(); length > max_length {
+			max_length = length
+		}
+	}
+	area := self.get_signed_area(face)
+	return 2*area <= 1e-6*max_length*max_length && 2*area >= -1e-6*max_length*max_length
+}
+
+func (self *Geometry) get_signed_area(face []uint32) float32 {
+	// Signed area of the face (positive, if it's counter-clockwise)
+	area := float32(0)
+	for i, j := 0, len(face)-1; i < len(face); j, i = i, i+1 {
+		a, b := self.verts[face[j]], self.verts[face[i]]
+		area += (a[0]*b[1] - b[0]*a[1]) / 2
+	}
+	return area
+}
+
+func get_invalid_index(vlist []uint32, nverts uint32) int {
+	for i, vidx := range vlist {
+		if vidx >= nverts {
+			return i
+		}
+	}
+	return -1
+}
+
+// ----------------------------------------------------------------------------
+// Repair
+// ----------------------------------------------------------------------------
+
+func (self *Geometry) Repair(opts *common.RepairOptions) []Issue {
+	// Repair the geometry with the options (all of them by default, if 'opts' is nil),
+	//   and return the issues remaining after the repair (like non-manifold edges).
+	if opts == nil {
+		opts = common.NewRepairOptions()
+	}
+	if opts.FixIndices {
+		self.remove_invalid_indices()
+	}
+	indices_ok := true
+	for _, face := range self.faces {
+		indices_ok = indices_ok && get_invalid_index(face, uint32(len(self.verts))) < 0
+	}
+	if opts.FixAttributes {
+		self.repair_attributes()
+	}
+	if opts.WeldVertices && indices_ok {
+		self.weld_vertices(opts.WeldTolerance)
+	}
+	if opts.RemoveDegenerateFaces && indices_ok {
+		self.remove_degenerate_faces()
+	}
+	if opts.RemoveDuplicateFaces && indices_ok {
+		keep := make([]bool, len(self.faces))
+		for fidx := range keep {
+			keep[fidx] = true
+		}
+		for _, fidx := range common.GetDuplicateFaces(self.faces) {
+			keep[fidx] = false
+		}
+		self.remove_faces(keep)
+	}
+	if opts.FixWinding && indices_ok {
+		for fidx, face := range self.faces { // all the faces are expected to be counter-clockwise
+			if self.get_signed_area(face) < 0 {
+				self.flip_face(fidx)
+			}
+		}
+	}
+	self.Clear(false, true, true)
+	return self.Validate()
+}
+
+func (self *Geometry) remove_invalid_indices() {
+	// Remove the edges and the faces with out-of-range vertex indices
+	nverts := uint32(len(self.verts))
+	edges := [][]uint32{}
+	for _, edge := range self.edges {
+		if get_invalid_index(edge, nverts) < 0 {
+			edges = append(edges, edge)
+		}
+	}
+	self.edges = edges
+	keep := make([]bool, len(self.faces))
+	for fidx, face := range self.faces {
+		keep[fidx] = get_invalid_index(face, nverts) < 0
+	}
+	self.remove_faces(keep)
+}
+
+func (self *Geometry) repair_attributes() {
+	// Fix the size of texture UVs (or remove them, if their count matches neither vertices nor faces)
+	switch self.get_texture_layout() {
+	case "VERTEX":
+		for vidx, tuv := range self.tuvs {
+			self.tuvs[vidx] = get_resized_uv(tuv, 2)
+		}
+	case "FACE":
+		for fidx, tuv := range self.tuvs {
+			self.tuvs[fidx] = get_resized_uv(tuv, 2*len(self.faces[fidx]))
+		}
+	default:
+		self.tuvs = [][]float32{}
+	}
+}
+
+func get_resized_uv(tuv []float32, size int) []float32 {
+	// Truncate the texture UV values, or pad them with zeros
+	if len(tuv) >= size {
+		return tuv[:size]
+	}
+	return append(tuv, make([]float32, size-len(tuv))...)
+}
+
+func (self *Geometry) weld_vertices(tolerance float32) {
+	// Weld the vertices within the tolerance (using a grid of the size of the tolerance),
+	//   only if their PER_VERT texture UVs are the same.
+	tmode := self.get_texture_mode()
+	get_cell := func(p [2]float32) [2]float32 {
+		if tolerance <= 0 {
+			return p
+		}
+		return [2]float32{float32(math.Floor(float64(p[0] / tolerance))), float32(math.Floor(float64(p[1] / tolerance)))}
+	}
+	can_weld := func(a uint32, b uint32) bool {
+		if NewV2dBySub(self.verts[a], self.verts[b]).Length() > tolerance {
+			return false
+		} else if tmode == "VERTEX" && (self.tuvs[a][0] != self.tuvs[b][0] || self.tuvs[a][1] != self.tuvs[b][1]) {
+			return false
+		}
+		return true
+	}
+	grid := map[[2]float32][]uint32{} // vertices kept in each cell
+	remap := make([]uint32, len(self.verts))
+	welded := 0
+	for vidx := range self.verts {
+		remap[vidx] = uint32(vidx)
+		cell := get_cell(self.verts[vidx])
+		span := float32(1)
+		if tolerance <= 0 {
+			span = 0
+		}
+		for dx := -span; dx <= span && remap[vidx] == uint32(vidx); dx++ {
+			for dy := -span; dy <= span && remap[vidx] == uint32(vidx); dy++ {
+				for _, kidx := range grid[[2]float32{cell[0] + dx, cell[1] + dy}] {
+					if can_weld(kidx, uint32(vidx)) {
+						remap[vidx] = kidx
+						welded++
+						break
+					}
+				}
+			}
+		}
+		if remap[vidx] == uint32(vidx) {
+			grid[cell] = append(grid[cell], uint32(vidx))
+		}
+	}
+	if welded == 0 {
+		return
+	}
+	// remove the welded vertices
+	new_vidx := make([]uint32, len(self.verts))
+	nverts := 0
+	for vidx := range self.verts {
+		if remap[vidx] == uint32(vidx) {
+			new_vidx[vidx] = uint32(nverts)
+			self.verts[nverts] = self.verts[vidx]
+			if tmode == "VERTEX" {
+				self.tuvs[nverts] = self.tuvs[vidx]
+			}
+			nverts++
+		} else {
+			new_vidx[vidx] = new_vidx[remap[vidx]]
+		}
+	}
+	if tmode == "VERTEX" {
+		self.tuvs = self.tuvs[:nverts]
+	}
+	self.verts = self.verts[:nverts]
+	for _, vlists := range [][][]uint32{self.edges, self.faces} {
+		for _, vlist := range vlists {
+			for i, vidx := range vlist {
+				vlist[i] = new_vidx[vidx]
+			}
+		}
+	}
+}
+
+func (self *Geometry) remove_degenerate_faces() {
+	// Remove the repeated vertices of the faces (like the ones left by welding),
+	//   and then remove the faces with less than 3 distinct vertices or zero area.
+	tuv_per_face := self.get_texture_mode() == "FACE"
+	keep := make([]bool, len(self.faces))
+	for fidx, face := range self.faces {
+		new_face, new_tuv := []uint32{}, []float32{}
+		for i, vidx := range face {
+			if len(face) > 1 && vidx == face[(i+1)%len(face)] {
+				continue
+			}
+			new_face = append(new_face, vidx)
+			if tuv_per_face && 2*i+2 <= len(self.tuvs[fidx]) {
+				new_tuv = append(new_tuv, self.tuvs[fidx][2*i:2*i+2]...)
+			}
+		}
+		self.faces[fidx] = new_face
+		if tuv_per_face {
+			self.tuvs[fidx] = new_tuv
+		}
+		keep[fidx] = !self.is_degenerate_face(new_face)
+	}
+	self.remove_faces(keep)
+}
+
+func (self *Geometry) remove_faces(keep []bool) {
+	// Remove the faces (with their PER_FACE texture UVs), while updating the face groups
+	tuv_per_face := self.get_texture_layout() == "FACE"
+	new_fidx := make([]int, len(self.faces)+1) // new index of each face (or the next remaining one)
+	nfaces := 0
+	for fidx, face := range self.faces {
+		new_fidx[fidx] = nfaces
+		if keep[fidx] {
+			self.faces[nfaces] = face
+			if tuv_per_face {
+				self.tuvs[nfaces] = self.tuvs[fidx]
+			}
+			nfaces++
+		}
+	}
+	new_fidx[len(self.faces)] = nfaces
+	if nfaces == len(self.faces) {
+		return
+	}
+	if tuv_per_face {
+		self.tuvs = self.tuvs[:nfaces]
+	}
+	self.faces = self.faces[:nfaces]
+	for i, fgrp := range self.fgrps {
+		self.fgrps[i] = [2]int{new_fidx[fgrp[0]], new_fidx[fgrp[1]]}
+	}
+}
+
+func (self *Geometry) flip_face(fidx int) {
+	// Reverse the order of the vertices (with PER_FACE texture UVs)
+	face := self.faces[fidx]
+	tuv_per_face := self.get_texture_mode() == "FACE"
+	for i, j := 0, len(face)-1; i < j; i, j = i+1, j-1 {
+		face[i], face[j] = face[j], face[i]
+		if tuv_per_face {
+			tuv := self.tuvs[fidx]
+			tuv[2*i], tuv[2*i+1], tuv[2*j], tuv[2*j+1] = tuv[2*j], tuv[2*j+1], tuv[2*i], tuv[2*i+1]
+		}
+	}
+}
@@ -1,9 +1,10 @@
 package g2d
 
 import (
-	"errors"
 	"fmt"
+	"math"
 
+	"github.com/go4orward/gigl"
 	"github.com/go4orward/gigl/common"
 )
 
@@ -12,11 +13,13 @@ type MaterialAlphabetTexture struct {
 	font_size       int        // font size (12, 16, 21, 25, etc)
 	font_rgb        [3]float32 // font color
 	font_outlined   bool       // flag to use outlined font
+	pixbuf          []uint8    // pixel buffer of the alphabet string (only if it's drawn without a canvas)
 	texture         any        // texture
 	texture_wh      [2]int     // texture size
 	texture_loading bool       //
 	alphabet_cwh    [2]float32 // character width & height of ALPHABET_STRING
 	err             error      //
+	gigl.RefCounter            // number of SceneObjects using this material
 }
 
 func NewMaterialAlphabetTexture(fontfamily string, fontsize int, color string, outlined bool) *MaterialAlphabetTexture {
@@ -36,8 +39,48 @@ func (self *MaterialAlphabetTexture) MaterialSummary() string {
 }
 
 func (self *MaterialAlphabetTexture) LoadAlphabetTexture() {
-	self.texture_loading = true
-	self.err = errors.New("NOT IMPLEMENTED YET")
+	// Draw the alphabet string into the pixel buffer with a fixed-width 5x7 bitmap font,
+	//   for the environments without a font renderer (like canvas of the web browsers).
+	// 'fontsize' : 12=>(7.2x12.6), 16=>(9.6x16.8), 20=>(12x21), 24=>(14x25), 30=>(18x31), 40=>(24x42)
+	if self.font_size <= 0 {
+		self.err = fmt.Errorf("invalid font size %d", self.font_size)
+		common.Logger.Error("Failed to LoadAlphabetTexture() : %v\n", self.err)
+		return
+	}
+	alen := self.GetAlaphabetLength()
+	cwidth := float32(self.font_size) * 0.6
+	cheight := float32(self.font_size) * 1.05 // we need some more margin below the text
+	twidth, theight := int(math.Floor(float64(cwidth)*float64(alen))), int(cheight)
+	is_on := func(x int, y int) bool { // whether the pixel is on the glyph of its character
+		if x < 0 || x >= twidth || y < 0 || y >= theight {
+			return false
+		}
+		cpos := (float32(x) + 0.5) / (float32(twidth) / float32(alen)) // (character index & position in it)
+		cidx := int(cpos)
+		gx := int((cpos - float32(cidx)) * 6)              // 5 columns + 1 spacing
+		gy := int((float32(y)+0.5)/float32(theight)*9) - 1 // 1 margin + 7 rows + 1 margin
+		if cidx >= alen || gx >= 5 || gy < 0 || gy >= 7 {
+			return false
+		}
+		return alphabet_font_5x7[cidx][gx]&(1<<gy) != 0
+	}
+	pixbuf := make([]uint8, twidth*theight*4)
+	for y := 0; y < theight; y++ {
+		for x := 0; x < twidth; x++ {
+			idx := (y*twidth + x) * 4
+			if is_on(x, y) { // interior (Note that WHITE can be multiplied with other colors later)
+				pixbuf[idx+0] = uint8(self.font_rgb[0] * 255)
+				pixbuf[idx+1] = uint8(self.font_rgb[1] * 255)
+				pixbuf[idx+2] = uint8(self.font_rgb[2] * 255)
+				pixbuf[idx+3] = 255
+			} else if self.font_outlined && (is_on(x-1, y) || is_on(x+1, y) || is_on(x, y-1) || is_on(x, y+1)) {
+				pixbuf[idx+3] = 255 // BLACK outline
+			}
+		}
+	}
+	self.pixbuf = pixbuf
+	self.texture_wh = [2]int{twidth, theight}
+	self.alphabet_cwh = [2]float32{cwidth, cheight}
 }
 
 // ----------------------------------------------------------------------------
@@ -45,7 +88,7 @@ func (self *MaterialAlphabetTexture) LoadAlphabetTexture() {
 // ----------------------------------------------------------------------------
 
 func (self *MaterialAlphabetTexture) GetTexturePixbuf() []uint8 {
-	return nil
+	return self.pixbuf
 }
 
 func (self *MaterialAlphabetTexture) GetTextureWH() [2]int {
@@ -92,6 +135,15 @@ func (self *MaterialAlphabetTexture) IsLoading() bool {
 	return self.texture_loading
 }
 
+func (self *MaterialAlphabetTexture) Dispose(rc gigl.GLRenderingContext) {
+	// Delete the texture (it will be drawn again, if necessary)
+	if self.texture != nil {
+		rc.GLDeleteTexture(self.texture)
+		self.texture = nil
+	}
+	self.texture_wh = [2]int{0, 0}
+}
+
 func (self *MaterialAlphabetTexture) SetError(err error) {
 	self.err = err
 }
@@ -4,15 +4,17 @@ import (
 	"fmt"
 	"math"
 
+	"github.com/go4orward/gigl"
 	"github.com/go4orward/gigl/common"
 )
 
 type MaterialGlowTexture struct {
-	glow_rgb   [3]float32 //
-	pixbuf     []uint8    //
-	texture    any        // texture (js.Value for WebGL, uint32 for OpenGL)
-	texture_wh [2]int     // texture size
-	err        error      //
+	glow_rgb        [3]float32 //
+	pixbuf          []uint8    //
+	texture         any        // texture (js.Value for WebGL, uint32 for OpenGL)
+	texture_wh      [2]int     // texture size
+	err             error      //
+	gigl.RefCounter            // number of SceneObjects using this material
 }
 
 func NewMaterialGlowTexture(color any) *MaterialGlowTexture {
@@ -81,6 +83,14 @@ func (self *MaterialGlowTexture) IsLoading() bool {
 	return false
 }
 
+func (self *MaterialGlowTexture) Dispose(rc gigl.GLRenderingContext) {
+	// Delete the texture (the glow pixels are kept, and it will be set up again if necessary)
+	if self.texture != nil {
+		rc.GLDeleteTexture(self.texture)
+		self.texture = nil
+	}
+}
+
 // ----------------------------------------------------------------------------
 // Loading Texture Image
 // ----------------------------------------------------------------------------
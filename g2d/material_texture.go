@@ -12,6 +12,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/go4orward/gigl"
 	"github.com/go4orward/gigl/common"
 )
 
@@ -23,6 +24,7 @@ type MaterialTexture struct {
 	texture_rgb     [3]float32 // extra RGB color to be multiplied with the texture
 	texture_loading bool       // true, only if texture is being loaded
 	err             error      //
+	gigl.RefCounter            // number of SceneObjects using this material
 }
 
 func NewMaterialTexture(filepath string, color ...string) *MaterialTexture {
@@ -35,6 +37,21 @@ func NewMaterialTexture(filepath string, color ...string) *MaterialTexture {
 	return &mtex
 }
 
+func NewMaterialTextureFromImageBytes(name string, imgbytes []byte, color ...string) *MaterialTexture {
+	// Material with the texture image already in memory (like the ones embedded in a model file),
+	//   where the extension of 'name' (like "image.png") decides the image format.
+	mtex := NewMaterialTexture(name, color...)
+	pixbuf, wh, err := mtex.decode_pixels_from_image_bytes(imgbytes, filepath.Ext(name))
+	if err != nil {
+		mtex.err = fmt.Errorf("texture %q failed to decode (%v)", name, err)
+		common.Logger.Error("%v\n", mtex.err)
+	} else {
+		mtex.pixbuf = pixbuf
+		mtex.texture_wh = wh
+	}
+	return mtex
+}
+
 func (self *MaterialTexture) MaterialSummary() string {
 	if self.IsReady() || self.IsLoaded() {
 		return fmt.Sprintf("MaterialTexture %dx%d %q", self.texture_wh[0], self.texture_wh[1], self.image_filepath)
@@ -96,6 +113,14 @@ func (self *MaterialTexture) IsLoading() bool {
 	return self.texture_loading
 }
 
+func (self *MaterialTexture) Dispose(rc gigl.GLRenderingContext) {
+	// Delete the texture (the image pixels are kept, and it will be set up again if necessary)
+	if self.texture != nil {
+		rc.GLDeleteTexture(self.texture)
+		self.texture = nil
+	}
+}
+
 // ----------------------------------------------------------------------------
 // Loading Texture Image
 // ----------------------------------------------------------------------------
@@ -177,7 +202,7 @@ func (self *MaterialTexture) decode_pixels_from_image_bytes(imgbytes []byte, ext
 	switch ext {
 	case ".png", ".PNG":
 		img, err = png.Decode(bytes.NewBuffer(imgbytes))
-	case ".jpg", ".JPG":
+	case ".jpg", ".JPG", ".jpeg", ".JPEG":
 		img, err = jpeg.Decode(bytes.NewBuffer(imgbytes))
 	default:
 		return nil, [2]int{}, fmt.Errorf("invalid texture image format %q", ext)
@@ -6,4 +6,5 @@ import (
 
 type Overlay interface {
 	Render(pvm *common.Matrix3)
+	Dispose() // delete GPU resources of the layer
 }
@@ -112,6 +112,22 @@ func (self *OverlayLabelLayer) Render(pvm *common.Matrix3) {
 	}
 }
 
+func (self *OverlayLabelLayer) Dispose() {
+	// 'Overlay' interface function, to delete GPU resources of all the labels
+	for _, label := range self.Labels {
+		if label.bkgobj != nil {
+			label.bkgobj.Dispose(self.rc)
+			label.bkgobj = nil
+		}
+		if label.txtobj != nil {
+			label.txtobj.Dispose(self.rc)
+			label.txtobj = nil
+		}
+	}
+	self.Labels = make([]*OverlayLabel, 0)
+	self.alphabet_shader = nil // its shader program was disposed with the last label
+}
+
 func (self *OverlayLabelLayer) Summary() string {
 	summary := "OverlayLabelLayer\n"
 	summary += fmt.Sprintf("  ALPHABET : ")
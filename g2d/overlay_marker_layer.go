@@ -26,6 +26,14 @@ func (self *OverlayMarkerLayer) Render(pvm *common.Matrix3) {
 	}
 }
 
+func (self *OverlayMarkerLayer) Dispose() {
+	// 'Overlay' interface function, to delete GPU resources of all the markers
+	for _, marker := range self.Markers {
+		marker.Dispose(self.rc)
+	}
+	self.Markers = make([]*SceneObject, 0)
+}
+
 // ----------------------------------------------------------------------------
 // Managing Markers
 // ----------------------------------------------------------------------------
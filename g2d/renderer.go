@@ -12,8 +12,9 @@ import (
 )
 
 type Renderer struct {
-	rc   gigl.GLRenderingContext //
-	axes *SceneObject            //
+	rc     gigl.GLRenderingContext //
+	axes   *SceneObject            //
+	target *gigl.RenderTarget      // offscreen RenderTarget (nil for the canvas)
 }
 
 func NewRenderer(rc gigl.GLRenderingContext) *Renderer {
@@ -21,6 +22,33 @@ func NewRenderer(rc gigl.GLRenderingContext) *Renderer {
 	return &renderer
 }
 
+// ----------------------------------------------------------------------------
+// RenderTarget
+// ----------------------------------------------------------------------------
+
+func (self *Renderer) SetRenderTarget(target *gigl.RenderTarget) *Renderer {
+	// Render into the offscreen RenderTarget from now on, or into the canvas again if 'target' is nil.
+	// Note that the camera should have the same aspect ratio as the RenderTarget.
+	if target != nil {
+		target.Bind()
+	} else if self.target != nil {
+		self.target.Unbind()
+	}
+	self.target = target
+	return self
+}
+
+func (self *Renderer) GetRenderTarget() *gigl.RenderTarget {
+	return self.target
+}
+
+func (self *Renderer) get_wh() [2]int {
+	if self.target != nil {
+		return self.target.GetWH()
+	}
+	return self.rc.GetWH()
+}
+
 // ----------------------------------------------------------------------------
 // Clear
 // ----------------------------------------------------------------------------
@@ -97,28 +125,51 @@ func (self *Renderer) RenderSceneObject(scnobj *SceneObject, pvm *common.Matrix3
 	if scnobj.vao == nil {
 		scnobj.vao = rc.CreateDataBufferVAO()
 	}
+	vtracker, ftracker := geom.GetVtxBufferTracker(0), geom.GetVtxBufferTracker(1)
+	if scnobj.vao.VertBuffer != nil && (vtracker.IsRebuiltSince(scnobj.vao.VertBufferVer) || (scnobj.vao.FvtxBuffer != nil && ftracker.IsRebuiltSince(scnobj.vao.FvtxBufferVer))) {
+		scnobj.vao.DisposeGeometryBuffers(rc) // the geometry was rebuilt, and its buffers have to be created again
+	}
+	usage := c.GetBufferUsage(geom.GetDataBufferUsage())
 	if scnobj.vao.VertBuffer == nil && scnobj.vao.FvtxBuffer == nil {
 		// create data buffers & buffer information for RenderingContext, and save them in VAO
-		scnobj.vao.VertBuffer = rc.CreateVtxDataBuffer(geom.GetVtxBuffer(0))
+		scnobj.vao.VertBuffer = rc.CreateVtxDataBuffer(geom.GetVtxBuffer(0), usage)
 		scnobj.vao.VertBufferInfo = geom.GetVtxBufferInfo(0)
+		scnobj.vao.VertBufferVer = vtracker.GetVersion()
 		if geom.GetIdxBuffer(2) != nil {
 			scnobj.vao.EdgeBuffer = rc.CreateIdxDataBuffer(geom.GetIdxBuffer(2))
 			scnobj.vao.EdgeBufferCount = geom.GetIdxBufferCount(2)
 		}
 		if geom.GetIdxBuffer(3) != nil {
 			if geom.GetVtxBuffer(1) != nil {
-				scnobj.vao.FvtxBuffer = rc.CreateVtxDataBuffer(geom.GetVtxBuffer(1))
+				scnobj.vao.FvtxBuffer = rc.CreateVtxDataBuffer(geom.GetVtxBuffer(1), usage)
 				scnobj.vao.FvtxBufferInfo = geom.GetVtxBufferInfo(1)
+				scnobj.vao.FvtxBufferVer = ftracker.GetVersion()
 			}
 			scnobj.vao.FaceBuffer = rc.CreateIdxDataBuffer(geom.GetIdxBuffer(3))
 			scnobj.vao.FaceBufferCount = geom.GetIdxBufferCount(3)
 		}
+	} else {
+		// upload only the vertex data changed (by Geometry.UpdateVertices())
+		scnobj.vao.VertBufferVer = vtracker.UploadChanges(rc, scnobj.vao.VertBuffer, scnobj.vao.VertBufferVer, geom.GetVtxBuffer(0), usage)
+		scnobj.vao.FvtxBufferVer = ftracker.UploadChanges(rc, scnobj.vao.FvtxBuffer, scnobj.vao.FvtxBufferVer, geom.GetVtxBuffer(1), usage)
 	}
-	if scnobj.vao.InstanceBuffer == nil && scnobj.instance_buffer != nil {
-		scnobj.vao.InstanceBuffer = rc.CreateVtxDataBuffer(scnobj.instance_buffer)
+	if scnobj.instance_buffer == nil {
+		if scnobj.vao.InstanceBuffer != nil { // instance poses were cleared
+			rc.GLDeleteBuffer(scnobj.vao.InstanceBuffer)
+			scnobj.vao.InstanceBuffer, scnobj.vao.InstanceBufferVer = nil, 0
+		}
+	} else if scnobj.vao.InstanceBuffer == nil || scnobj.instance_change.IsRebuiltSince(scnobj.vao.InstanceBufferVer) {
+		if scnobj.vao.InstanceBuffer != nil {
+			rc.GLDeleteBuffer(scnobj.vao.InstanceBuffer)
+		}
+		scnobj.vao.InstanceBuffer = rc.CreateVtxDataBuffer(scnobj.instance_buffer, c.GetBufferUsage(scnobj.instance_usage))
+		scnobj.vao.InstanceBufferVer = scnobj.instance_change.GetVersion()
 		if !self.rc.IsExtensionReady("ANGLE") {
 			self.rc.SetupExtension("ANGLE")
 		}
+	} else {
+		// upload only the instance poses changed (by SetInstancePoseValues() or SetInstanceColorValues())
+		scnobj.vao.InstanceBufferVer = scnobj.instance_change.UploadChanges(rc, scnobj.vao.InstanceBuffer, scnobj.vao.InstanceBufferVer, scnobj.instance_buffer, c.GetBufferUsage(scnobj.instance_usage))
 	}
 	// R3: Render the object with FACE shader
 	if scnobj.FShader != nil && scnobj.FShader.IsReady() {
@@ -158,6 +209,7 @@ func (self *Renderer) render_scene_object_with_shader(scnobj *SceneObject, pvm *
 		return errors.New("Failed to render SceneObject() : shader has error")
 	}
 	rc.GLUseProgram(shader.GetShaderProgram())
+	rc.GLBindVertexArray(scnobj.vao.VertexArray) // native VAO of the SceneObject (nil, if not supported)
 	// 2. bind the uniforms of the shader program
 	for uname, utarget := range shader.GetUniformBindings() {
 		if err := self.bind_uniform(uname, utarget, draw_mode, scnobj, pvm); err != nil {
@@ -266,7 +318,7 @@ func (self *Renderer) bind_uniform(uname string, ut gigl.BindTarget,
 			}
 			return nil
 		case "renderer.aspect": // vec2
-			wh := rc.GetWH()
+			wh := self.get_wh()
 			rc.GLUniform2f(ut.Loc, float32(wh[0]), float32(wh[1]))
 			return nil
 		case "renderer.pvm": // mat3
@@ -318,8 +370,8 @@ func (self *Renderer) bind_attribute(aname string, at gigl.BindTarget,
 	case "geometry.textuv": // 2 * uint16 in 4 bytes (1 float32)
 		buffer, binfo := scnobj.vao.GetVtxBuffer(0, 1) // [4]int{ nverts, stride, size, offset }
 		rc.GLBindBuffer(c.ARRAY_BUFFER, buffer)
-		rc.GLVertexAttribPointer(at.Loc, binfo[2], c.UNSIGNED_SHORT, true, binfo[1]*4, binfo[3]*4)
-		rc.GLEnableVertexAttribArray(at.Loc)
+		rc.GLVertexAttribPointer(at.Loc, 2, c.UNSIGNED_SHORT, true, binfo[1]*4, binfo[3]*4)
+		rc.GLEnableVertexAttribArray(at.Loc) // note that 'size' (binfo[2]) is 1 as 'float32', while its 2 'uint16' will be used
 		if rc.IsExtensionReady("ANGLE") {
 			// context.ext_angle.vertexAttribDivisorANGLE(attribute_loc, divisor);
 			rc.GLVertexAttribDivisor(at.Loc, 0) // divisor == 0
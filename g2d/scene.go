@@ -3,6 +3,7 @@ package g2d
 import (
 	"fmt"
 
+	"github.com/go4orward/gigl"
 	"github.com/go4orward/gigl/common"
 )
 
@@ -90,6 +91,22 @@ func (self *Scene) GetBBoxSizeCenter(renew bool) ([2][2]float32, [2]float32, [2]
 	return self.bbox, self.bbox.Shape(), self.bbox.Center()
 }
 
+func (self *Scene) Remove(scnobj ...*SceneObject) *Scene {
+	// Remove SceneObjects from the scene (call SceneObject.Dispose() to delete their GPU resources)
+	objects := make([]*SceneObject, 0, len(self.objects))
+	for _, obj := range self.objects {
+		removed := false
+		for i := 0; i < len(scnobj) && !removed; i++ {
+			removed = (obj == scnobj[i])
+		}
+		if !removed {
+			objects = append(objects, obj)
+		}
+	}
+	self.objects = objects
+	return self
+}
+
 // ----------------------------------------------------------------------------
 // Managing OverlayLayers
 // ----------------------------------------------------------------------------
@@ -100,3 +117,19 @@ func (self *Scene) AddOverlay(overlay ...Overlay) *Scene {
 	}
 	return self
 }
+
+// ----------------------------------------------------------------------------
+// Disposing GPU Resources
+// ----------------------------------------------------------------------------
+
+func (self *Scene) Dispose(rc gigl.GLRenderingContext) {
+	// Delete the GPU resources of all the SceneObjects & Overlays, and empty the scene.
+	for _, scnobj := range self.objects {
+		scnobj.Dispose(rc)
+	}
+	for _, overlay := range self.overlays {
+		overlay.Dispose()
+	}
+	self.objects = make([]*SceneObject, 0)
+	self.overlays = make([]Overlay, 0)
+}
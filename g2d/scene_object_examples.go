@@ -25,6 +25,16 @@ func NewSceneObject_HexagonWireframe(rc gigl.GLRenderingContext) *SceneObject {
 	return NewSceneObject(geometry, material, nil, shader, nil) // set up the scene object (draw EDGES only)
 }
 
+func NewSceneObject_StrokeInPixels(rc gigl.GLRenderingContext) *SceneObject {
+	// This example creates a zigzag stroke, to be rendered with constant width of 8 pixels regardless of zoom
+	polyline := [][2]float32{{-0.6, -0.3}, {-0.3, 0.3}, {0, -0.3}, {0.3, 0.3}, {0.6, -0.3}}
+	geometry := NewGeometryStrokeForPixelWidth(polyline, "ROUND", "ROUND", false) // offsets are encoded as texture UVs
+	geometry.BuildDataBuffers(true, false, true)                                  // build data buffers for vertices and faces
+	material := NewMaterialColors("#0088ff")
+	shader := NewShaderForStrokeInPixels(rc, 8.0)               // create shader, with the width in pixels
+	return NewSceneObject(geometry, material, nil, nil, shader) // set up the scene object (draw FACES only)
+}
+
 func NewSceneObject_RectangleInstancesExample(rc gigl.GLRenderingContext) *SceneObject {
 	// This example creates 200*80 instances of a single geometry, each with its own position and color
 	geometry := NewGeometryRectangle(0.8)        // create a rectangle of size 1.0
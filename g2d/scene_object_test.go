@@ -0,0 +1,65 @@
+package g2d_test
+
+import (
+	"testing"
+
+	"github.com/go4orward/gigl/env/software"
+	"github.com/go4orward/gigl/g2d"
+)
+
+func TestSceneObjectsSharingShader(t *testing.T) {
+	// The shader shared by two SceneObjects should be disposed only when both of them are disposed
+	rc := software.NewSoftwareRenderingContext(100, 100)
+	shader := g2d.NewShaderForMaterialColors(rc)
+	material := g2d.NewMaterialColors("#ff0000")
+	new_sobj := func(x float32) *g2d.SceneObject {
+		geometry := g2d.NewGeometryRectangle(0.5)
+		geometry.Translate(x, 0)
+		geometry.BuildDataBuffers(true, false, true)
+		return g2d.NewSceneObject(geometry, material, nil, nil, shader)
+	}
+	sobj1, sobj2 := new_sobj(-0.5), new_sobj(+0.5)
+	camera := g2d.NewCamera(rc.GetWH(), 2.0, 1.0)
+	renderer := g2d.NewRenderer(rc)
+	count_red_pixels := func(sobj *g2d.SceneObject) int {
+		scene := g2d.NewScene("#ffffff").Add(sobj)
+		renderer.Clear(scene)
+		renderer.RenderScene(scene, camera)
+		img, count := rc.GetImage(), 0
+		for y := 0; y < 100; y++ {
+			for x := 0; x < 100; x++ {
+				if r, g, _, _ := img.At(x, y).RGBA(); r > 0x8000 && g < 0x8000 {
+					count++
+				}
+			}
+		}
+		return count
+	}
+	if count_red_pixels(sobj1) == 0 || count_red_pixels(sobj2) == 0 {
+		t.Fatalf("SceneObjects were not rendered")
+	}
+	sobj1.Dispose(rc)
+	sobj1.Dispose(rc) // (disposing twice should not release the shader twice)
+	if !shader.IsReady() {
+		t.Fatalf("shared shader was disposed, while it's still used by another SceneObject")
+	}
+	if count_red_pixels(sobj2) == 0 {
+		t.Errorf("SceneObject was not rendered, after the other one sharing its shader was disposed")
+	}
+	sobj2.Dispose(rc)
+	if shader.IsReady() {
+		t.Errorf("shared shader was not disposed, after all of its users were disposed")
+	}
+}
+
+func TestSceneObjectWithUnretainedShader(t *testing.T) {
+	// The shader that was not retained by the SceneObject (set after its creation) is not owned by it
+	rc := software.NewSoftwareRenderingContext(100, 100)
+	shader := g2d.NewShaderForMaterialColors(rc)
+	sobj := g2d.NewSceneObject(g2d.NewGeometryRectangle(0.5), nil, nil, nil, nil)
+	sobj.FShader = shader
+	sobj.Dispose(rc)
+	if !shader.IsReady() {
+		t.Errorf("shader was disposed by the SceneObject, which didn't retain it")
+	}
+}
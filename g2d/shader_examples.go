@@ -113,3 +113,37 @@ func NewShaderForInstancePoseColor(rc gigl.GLRenderingContext) gigl.GLShader {
 	shader.CheckBindings()                                                 // check validity of the shader
 	return shader
 }
+
+func NewShaderForStrokeInPixels(rc gigl.GLRenderingContext, width_in_pixels float32) gigl.GLShader {
+	// Shader for the stroke (thick polyline) of NewGeometryStrokeForPixelWidth(),
+	//   which keeps the width of the stroke constant in pixels, regardless of the zoom of the camera.
+	var vertex_shader_code = `
+		precision mediump float;
+		uniform   mat3 pvm;			// Projection * View * Model matrix
+		uniform   vec2 asp;			// aspect ratio, w : h
+		uniform   float lw;			// line width in pixels
+		attribute vec2 xy;			// XY coordinates (on the polyline)
+		attribute vec2 off;			// offset from the polyline (encoded as UV coordinates)
+		void main() {
+			vec2 o = (off * 2.0 - 1.0) * 4.0;						// offset in the unit of the half width
+			vec3 p = pvm * vec3(xy.x, xy.y, 1.0);					// position in CLIP space
+			vec2 d = (pvm * vec3(o.x, o.y, 0.0)).xy;				// offset in CLIP space
+			float s = length((pvm * vec3(1.0, 0.0, 0.0)).xy * asp / 2.0);	// pixels per world unit
+			gl_Position = vec4(p.xy + d * (lw / 2.0) / s, 0.0, 1.0);
+		}`
+	var fragment_shader_code = `
+		precision mediump float;
+		uniform vec4 color;			// color RGBA
+		void main() { 
+			gl_FragColor = color;
+		}`
+	shader, _ := rc.CreateShader(vertex_shader_code, fragment_shader_code)
+	shader.SetBindingForUniform(cst.Mat3, "pvm", "renderer.pvm")            // Proj*View*Model matrix
+	shader.SetBindingForUniform(cst.Vec2, "asp", "renderer.aspect")         // AspectRatio
+	shader.SetBindingForUniform(cst.Vec1, "lw", []float32{width_in_pixels}) // line width in pixels
+	shader.SetBindingForUniform(cst.Vec4, "color", "material.color")        // material color
+	shader.SetBindingForAttribute(cst.Vec2, "xy", "geometry.coords")        // point coordinates
+	shader.SetBindingForAttribute(cst.Vec2, "off", "geometry.textuv")       // offsets (encoded as UV coordinates)
+	shader.CheckBindings()                                                  // check validity of the shader
+	return shader
+}
@@ -258,6 +258,25 @@ func (self *Camera) RotateAroundPoint(distance float32, h_angle float32, v_angle
 	return self
 }
 
+// ----------------------------------------------------------------------------
+// Projection
+// ----------------------------------------------------------------------------
+
+func (self *Camera) project_world_to_screen(xyz [3]float32) [2]float32 {
+	// Projection of the point from WORLD space to SCREEN space (in pixels, with the origin at the center)
+	cxyz := self.viewmatrix.MultiplyVector3(xyz)
+	e := self.projmatrix.GetElements()
+	x := e[0]*cxyz[0] + e[4]*cxyz[1] + e[8]*cxyz[2] + e[12] // COLUMN-MAJOR
+	y := e[1]*cxyz[0] + e[5]*cxyz[1] + e[9]*cxyz[2] + e[13]
+	w := e[3]*cxyz[0] + e[7]*cxyz[1] + e[11]*cxyz[2] + e[15]
+	if w < 1e-6 && w > -1e-6 {
+		w = 1e-6 // (on the camera plane)
+	} else if w < 0 {
+		w = -w // (behind the camera)
+	}
+	return [2]float32{x / w * float32(self.ip.WH[0]) / 2, y / w * float32(self.ip.WH[1]) / 2}
+}
+
 // ----------------------------------------------------------------------------
 // Testing
 // ----------------------------------------------------------------------------
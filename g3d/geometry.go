@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"math"
 
+	"github.com/go4orward/gigl"
 	"github.com/go4orward/gigl/common"
+	cst "github.com/go4orward/gigl/common/constants"
 )
 
 // ----------------------------------------------------------------------------
@@ -17,17 +19,24 @@ type Geometry struct {
 	faces [][]uint32   // faces
 	tuvs  [][]float32  // texture uv coordinates (PER_FACE [nfaces][6] or PER_VERT [nverts][2])
 	norms [][3]float32 // normal vectors (PER_FACE [nfaces][3] or PER_VERT [nverts][3])
+	tans  [][4]float32 // tangent vectors with the sign of bitangent (PER_VERT [nverts][4])
+	fgrps [][2]int     // face groups, as ranges [start, end) of face indices of the merged geometries
 
-	dbuffer_vpoint      []float32 // data buffer for vertex points : COORD[] + (UV[2]) + (NORMAL[3])
+	dbuffer_vpoint      []float32 // data buffer for vertex points : COORD[] + (UV[2]) + (NORMAL[3]) + (TANGENT[4])
 	dbuffer_fpoint      []float32 // data buffer for PER_FACE vertex points : COORD[3] + (UV[2]) + (NORMAL[3])
 	dbuffer_line        []uint32  // data buffer for edge lines     : list of vertex indices
 	dbuffer_face        []uint32  // data buffer for face triangles : list of vertex indices
-	dbuffer_vpoint_info [4]int    // data buffer info : [ stride, xyz_size, uv_size, normal_size ]
-	dbuffer_fpoint_info [4]int    // data buffer info : [ stride, xyz_size, uv_size, normal_size ]
+	dbuffer_vpoint_info [5]int    // data buffer info : [ stride, xyz_size, uv_size, normal_size, tangent_size ]
+	dbuffer_fpoint_info [5]int    // data buffer info : [ stride, xyz_size, uv_size, normal_size, tangent_size ]
 
 	// Note that, for PER_FACE texture UV-coordinates and normal vectors, vertices are duplicated for each face
 	fpoint_vidx_list  []uint32 // index of vertex_list of each face after PER_FACE data duplication
 	fpoint_vert_total int      // total count of vertices after PER_FACE data duplication
+
+	// Note that changes of the data buffers are tracked, to be uploaded again (without rebuilding)
+	dbuffer_vpoint_tracker gigl.BufferTracker // changes of 'dbuffer_vpoint'
+	dbuffer_fpoint_tracker gigl.BufferTracker // changes of 'dbuffer_fpoint'
+	dbuffer_usage          cst.BufferUsage    // usage hint for the data buffers (StaticDraw by default)
 }
 
 func NewGeometry() *Geometry {
@@ -43,17 +52,23 @@ func (self *Geometry) Clear(geom bool, data_buf bool, webgl_buf bool) *Geometry
 		self.faces = [][]uint32{}
 		self.tuvs = [][]float32{}
 		self.norms = [][3]float32{}
+		self.tans = nil
+		self.fgrps = nil
 	}
 	if geom || data_buf {
 		self.dbuffer_vpoint = nil
 		self.dbuffer_fpoint = nil
 		self.dbuffer_line = nil
 		self.dbuffer_face = nil
-		self.dbuffer_fpoint_info = [4]int{0, 0, 0, 0}
-		self.dbuffer_vpoint_info = [4]int{0, 0, 0, 0}
+		self.dbuffer_fpoint_info = [5]int{0, 0, 0, 0, 0}
+		self.dbuffer_vpoint_info = [5]int{0, 0, 0, 0, 0}
 		self.fpoint_vidx_list = nil
 		self.fpoint_vert_total = 0
 	}
+	if webgl_buf { // buffers of RenderingContext have to be built again
+		self.dbuffer_vpoint_tracker.MarkRebuilt()
+		self.dbuffer_fpoint_tracker.MarkRebuilt()
+	}
 	return self
 }
 
@@ -110,6 +125,18 @@ func (self *Geometry) SetFaces(faces [][]uint32) *Geometry {
 	return self
 }
 
+func (self *Geometry) GetVertices() [][3]float32 {
+	return self.verts
+}
+
+func (self *Geometry) GetEdges() [][]uint32 {
+	return self.edges
+}
+
+func (self *Geometry) GetFaces() [][]uint32 {
+	return self.faces
+}
+
 func (self *Geometry) AddVertex(coords [3]float32) uint32 {
 	vidx := len(self.verts)
 	self.verts = append(self.verts, coords)
@@ -128,6 +155,29 @@ func (self *Geometry) AddFace(face []uint32) uint32 {
 	return uint32(fidx)
 }
 
+func (self *Geometry) AddPolygonWithHoles(outer [][3]float32, holes [][][3]float32) []uint32 {
+	// Add the planar polygon (outer ring with its holes) as triangular faces, and return the indices of the new faces.
+	//   The faces are facing the side from which the outer ring looks counter-clockwise,
+	//   while holes may be given in any winding order, with collinear or duplicate points.
+	rings := append([][][3]float32{outer}, holes...)
+	project := get_projection_to_plane(get_polygon_normal(outer))
+	rings2d := make([][][2]float32, len(rings))
+	vidx_stt := uint32(len(self.verts))
+	for i, ring := range rings {
+		rings2d[i] = make([][2]float32, len(ring))
+		for j, xyz := range ring {
+			rings2d[i][j] = project(xyz)
+		}
+		self.verts = append(self.verts, ring...)
+	}
+	triangles := common.TriangulatePolygon(rings2d)
+	fidx_list := make([]uint32, len(triangles))
+	for i, t := range triangles {
+		fidx_list[i] = self.AddFace([]uint32{vidx_stt + uint32(t[0]), vidx_stt + uint32(t[1]), vidx_stt + uint32(t[2])})
+	}
+	return fidx_list
+}
+
 // ----------------------------------------------------------------------------
 // Transformation of Vertex Coordinates
 // ----------------------------------------------------------------------------
@@ -174,30 +224,271 @@ func (self *Geometry) AppyMatrix4(m *common.Matrix4) *Geometry {
 // Merge
 // ----------------------------------------------------------------------------
 
-func (self *Geometry) Merge(g *Geometry) *Geometry {
+func (self *Geometry) Merge(g *Geometry, matrix ...*common.Matrix4) *Geometry {
+	// Merge the geometry (optionally transformed by the matrix), with its texture UVs and normal vectors.
+	// If PER_VERT and PER_FACE layouts are mixed, texture UVs are merged as PER_FACE (for each face corner),
+	//   and normals as PER_VERT (after vertices of PER_FACE normals are duplicated for each face).
+	// Missing texture UVs are set to (0,0), and missing normals are calculated from the faces.
+	// The faces of each merged geometry are recorded as a face group (see GetFaceGroups()).
+	other := g.copy_geometry() // 'g' is not changed
+	if len(matrix) > 0 && matrix[0] != nil {
+		other.apply_matrix_with_normals(matrix[0])
+	}
 	self.Clear(false, true, true)
-	nverts := uint32(len(self.verts))
-	for _, v := range g.verts {
-		self.AddVertex(v)
+	self.tans = nil // tangents have to be built again
+	nverts, nfaces := uint32(len(self.verts)), len(self.faces)
+	if len(self.fgrps) == 0 && nfaces > 0 {
+		self.fgrps = [][2]int{{0, nfaces}}
+	}
+	if len(other.fgrps) > 0 {
+		for _, fgrp := range other.fgrps {
+			self.fgrps = append(self.fgrps, [2]int{nfaces + fgrp[0], nfaces + fgrp[1]})
+		}
+	} else if len(other.faces) > 0 {
+		self.fgrps = append(self.fgrps, [2]int{nfaces, nfaces + len(other.faces)})
+	}
+	if len(self.verts) == 0 { // nothing to be converted
+		self.verts, self.edges, self.faces, self.tuvs, self.norms = other.verts, other.edges, other.faces, other.tuvs, other.norms
+		return self
+	}
+	// normal vectors (PER_VERT, unless none of them is PER_VERT)
+	self_nmode, other_nmode := self.get_normal_mode(), other.get_normal_mode()
+	if self_nmode != other_nmode {
+		nmode := "VERTEX"
+		if self_nmode != "VERTEX" && other_nmode != "VERTEX" {
+			nmode = "FACE"
+		}
+		for _, geom := range []*Geometry{self, other} {
+			geom.convert_normals(nmode)
+		}
+		nverts = uint32(len(self.verts))
+	}
+	// texture UVs (PER_FACE, unless none of them is PER_FACE)
+	self_tmode, other_tmode := self.get_texture_mode(), other.get_texture_mode()
+	if self_tmode != other_tmode {
+		tmode := "FACE"
+		if self_tmode != "FACE" && other_tmode != "FACE" {
+			tmode = "VERTEX"
+		}
+		for _, geom := range []*Geometry{self, other} {
+			geom.convert_texture_uvs(tmode)
+		}
 	}
-	for _, e := range g.edges {
+	// vertices, edges, faces, texture UVs and normals
+	self.verts = append(self.verts, other.verts...)
+	for _, e := range other.edges {
 		new_edge := make([]uint32, len(e))
 		for i := 0; i < len(e); i++ {
 			new_edge[i] = nverts + e[i]
 		}
 		self.AddEdge(new_edge)
 	}
-	for _, f := range g.faces {
+	for _, f := range other.faces {
 		new_face := make([]uint32, len(f))
 		for i := 0; i < len(f); i++ {
 			new_face[i] = nverts + f[i]
 		}
 		self.AddFace(new_face)
 	}
-	// TODO: texture UV coordinates and normal vectors
+	self.tuvs = append(self.tuvs, other.tuvs...)
+	self.norms = append(self.norms, other.norms...)
 	return self
 }
 
+func (self *Geometry) GetFaceGroups() [][2]int {
+	// Get the face groups of the merged geometries, as ranges [start, end) of face indices
+	//   (nil, if nothing was merged)
+	return self.fgrps
+}
+
+func (self *Geometry) copy_geometry() *Geometry {
+	g := NewGeometry()
+	g.verts = append(g.verts, self.verts...)
+	for _, e := range self.edges {
+		g.edges = append(g.edges, append([]uint32{}, e...))
+	}
+	for _, f := range self.faces {
+		g.faces = append(g.faces, append([]uint32{}, f...))
+	}
+	for _, tuv := range self.tuvs {
+		g.tuvs = append(g.tuvs, append([]float32{}, tuv...))
+	}
+	g.norms = append(g.norms, self.norms...)
+	g.fgrps = append(g.fgrps, self.fgrps...)
+	return g
+}
+
+func (self *Geometry) apply_matrix_with_normals(m *common.Matrix4) {
+	// Transform the vertices and the normals (with the cofactor matrix, which is the inverse transpose scaled by the determinant)
+	e := m.GetElements() // COLUMN-MAJOR
+	a := [3][3]float32{{e[0], e[4], e[8]}, {e[1], e[5], e[9]}, {e[2], e[6], e[10]}}
+	c := [3][3]float32{}
+	for r := 0; r < 3; r++ {
+		for k := 0; k < 3; k++ {
+			r1, r2, k1, k2 := (r+1)%3, (r+2)%3, (k+1)%3, (k+2)%3
+			c[r][k] = a[r1][k1]*a[r2][k2] - a[r1][k2]*a[r2][k1]
+		}
+	}
+	det := a[0][0]*c[0][0] + a[0][1]*c[0][1] + a[0][2]*c[0][2]
+	for i := 0; i < len(self.verts); i++ {
+		self.verts[i] = m.MultiplyVector3(self.verts[i])
+	}
+	for i, n := range self.norms {
+		nv := V3d{c[0][0]*n[0] + c[0][1]*n[1] + c[0][2]*n[2], c[1][0]*n[0] + c[1][1]*n[1] + c[1][2]*n[2], c[2][0]*n[0] + c[2][1]*n[1] + c[2][2]*n[2]}
+		if det < 0 {
+			nv.Scale(-1, -1, -1)
+		}
+		self.norms[i] = *nv.Normalize()
+	}
+	if det < 0 { // mirrored, so the faces have to be reversed to keep them facing outward
+		tuv_per_face := self.get_texture_mode() == "FACE"
+		for fidx, face := range self.faces {
+			for i, j := 0, len(face)-1; i < j; i, j = i+1, j-1 {
+				face[i], face[j] = face[j], face[i]
+				if tuv_per_face && 2*j+1 < len(self.tuvs[fidx]) {
+					tuv := self.tuvs[fidx]
+					tuv[2*i], tuv[2*i+1], tuv[2*j], tuv[2*j+1] = tuv[2*j], tuv[2*j+1], tuv[2*i], tuv[2*i+1]
+				}
+			}
+		}
+	}
+}
+
+func (self *Geometry) get_normal_mode() string {
+	if self.HasNormalFor("FACE") { // (just like BuildDataBuffers())
+		return "FACE"
+	} else if self.HasNormalFor("VERTEX") {
+		return "VERTEX"
+	}
+	return ""
+}
+
+func (self *Geometry) get_texture_mode() string {
+	if self.HasTextureFor("FACE") && len(self.tuvs) == len(self.faces) {
+		return "FACE"
+	} else if self.HasTextureFor("VERTEX") && len(self.tuvs) == len(self.verts) {
+		return "VERTEX"
+	}
+	return ""
+}
+
+func (self *Geometry) get_vertex_normals() [][3]float32 {
+	// Average of the normals of the faces sharing each vertex (just like GetVertexNormal())
+	sums, counts := make([]V3d, len(self.verts)), make([]int, len(self.verts))
+	for fidx, face := range self.faces {
+		fnormal := self.GetFaceNormal(fidx)
+		for i, vidx := range face {
+			if i > 0 && face[0] == vidx {
+				continue
+			}
+			sums[vidx].Add(&V3d{fnormal[0], fnormal[1], fnormal[2]})
+			counts[vidx]++
+		}
+	}
+	normals := make([][3]float32, len(self.verts))
+	for vidx := range normals {
+		if counts[vidx] > 0 {
+			normals[vidx] = *sums[vidx].Normalize()
+		}
+	}
+	return normals
+}
+
+func (self *Geometry) convert_normals(nmode string) {
+	// Convert normal vectors into PER_VERT or PER_FACE (calculating the missing ones)
+	switch self.get_normal_mode() {
+	case nmode:
+	case "FACE": // to PER_VERT
+		self.split_vertices_for_face_normals()
+	case "":
+		if nmode == "VERTEX" {
+			self.norms = self.get_vertex_normals()
+		} else {
+			self.BuildNormalsForFace()
+		}
+	}
+}
+
+func (self *Geometry) split_vertices_for_face_normals() {
+	// Convert PER_FACE normals into PER_VERT normals, by adding new vertices for each face corner
+	//   (the original vertices are kept for the edges, with their averaged normals)
+	face_normals := self.norms
+	self.norms = self.get_vertex_normals()
+	tuv_per_vert := self.get_texture_mode() == "VERTEX"
+	for fidx, face := range self.faces {
+		for i, vidx := range face {
+			face[i] = self.AddVertex(self.verts[vidx])
+			self.norms = append(self.norms, face_normals[fidx])
+			if tuv_per_vert {
+				self.tuvs = append(self.tuvs, self.tuvs[vidx])
+			}
+		}
+	}
+}
+
+func (self *Geometry) split_vertices_for_face_uvs() {
+	// Convert PER_FACE texture UVs into PER_VERT texture UVs, by adding new vertices
+	//   only for the face corners with different UVs (on the UV seams)
+	type corner_key struct {
+		vidx uint32
+		uv   [2]float32
+	}
+	face_tuvs := self.tuvs
+	nverts, nmode := len(self.verts), self.get_normal_mode()
+	self.tuvs = make([][]float32, nverts)
+	corners := map[corner_key]uint32{}
+	for fidx, face := range self.faces {
+		for i, vidx := range face {
+			key := corner_key{vidx, [2]float32{face_tuvs[fidx][2*i], face_tuvs[fidx][2*i+1]}}
+			if new_vidx, ok := corners[key]; ok {
+				face[i] = new_vidx
+			} else if self.tuvs[vidx] == nil {
+				self.tuvs[vidx] = []float32{key.uv[0], key.uv[1]}
+				corners[key] = vidx
+			} else {
+				face[i] = self.AddVertex(self.verts[vidx])
+				self.tuvs = append(self.tuvs, []float32{key.uv[0], key.uv[1]})
+				if nmode == "VERTEX" {
+					self.norms = append(self.norms, self.norms[vidx])
+				}
+				corners[key] = face[i]
+			}
+		}
+	}
+	for vidx := 0; vidx < nverts; vidx++ {
+		if self.tuvs[vidx] == nil { // not used by any face
+			self.tuvs[vidx] = []float32{0, 0}
+		}
+	}
+}
+
+func (self *Geometry) convert_texture_uvs(tmode string) {
+	// Convert texture UVs into PER_VERT or PER_FACE (with (0,0) for the missing ones)
+	switch self.get_texture_mode() {
+	case tmode:
+	case "VERTEX": // to PER_FACE
+		tuvs := make([][]float32, len(self.faces))
+		for fidx, face := range self.faces {
+			for _, vidx := range face {
+				tuvs[fidx] = append(tuvs[fidx], self.tuvs[vidx]...)
+			}
+		}
+		self.tuvs = tuvs
+	case "":
+		if tmode == "VERTEX" {
+			self.tuvs = make([][]float32, len(self.verts))
+			for vidx := range self.tuvs {
+				self.tuvs[vidx] = []float32{0, 0}
+			}
+		} else {
+			self.tuvs = make([][]float32, len(self.faces))
+			for fidx, face := range self.faces {
+				self.tuvs[fidx] = make([]float32, 2*len(face))
+			}
+		}
+	}
+}
+
 // ----------------------------------------------------------------------------
 // Texture UV coordinates
 // ----------------------------------------------------------------------------
@@ -222,6 +513,10 @@ func (self *Geometry) AddTextureUV(tuv []float32) *Geometry {
 	return self
 }
 
+func (self *Geometry) GetTextureUVs() [][]float32 {
+	return self.tuvs // PER_FACE [nfaces][2*face_len] or PER_VERT [nverts][2]
+}
+
 func (self *Geometry) SetTextureUVs(tuvs [][]float32) *Geometry {
 	self.tuvs = tuvs
 	return self
@@ -247,6 +542,10 @@ func (self *Geometry) AddNormal(normal_vector [3]float32) *Geometry {
 	return self
 }
 
+func (self *Geometry) GetNormals() [][3]float32 {
+	return self.norms // PER_FACE [nfaces][3] or PER_VERT [nverts][3]
+}
+
 func (self *Geometry) SetNormals(normals [][3]float32) *Geometry {
 	self.norms = normals
 	return self
@@ -315,6 +614,145 @@ func (self *Geometry) ChangeNormal(idx int, normal_vector [3]float32) *Geometry
 	return self
 }
 
+func (self *Geometry) BuildNormalsWithCreaseAngle(crease_angle_in_degree float32, weighting string) {
+	// Build PER_VERT normals by averaging the normals of the faces sharing each vertex,
+	//   only if they meet at an angle not greater than 'crease_angle' (otherwise the edge between them is hard).
+	// Vertices on hard edges are split (duplicated with their PER_VERT texture UVs),
+	//   so that the faces on each side of the edge have their own normal (0 for faceted, 180 for smooth look).
+	// 'weighting' of face normals : "AREA" (face area), "ANGLE" (corner angle), or "" (equal weights)
+	cos_crease := float32(math.Cos(float64(crease_angle_in_degree) * math.Pi / 180))
+	face_normals := make([]V3d, len(self.faces))
+	corner_weights := make([][]float32, len(self.faces))
+	vert_corners := make([][][2]int, len(self.verts)) // (fidx, i) of the face corners for each vertex
+	for fidx, face := range self.faces {
+		verts := make([][3]float32, len(face))
+		for i, vidx := range face {
+			verts[i] = self.verts[vidx]
+			vert_corners[vidx] = append(vert_corners[vidx], [2]int{fidx, i})
+		}
+		face_normals[fidx] = V3d(get_polygon_normal(verts)) // its length is twice the area
+		corner_weights[fidx] = make([]float32, len(face))
+		for i := range face {
+			switch weighting {
+			case "AREA":
+				corner_weights[fidx][i] = face_normals[fidx].Length() / 2
+			case "ANGLE":
+				vprev := NewV3dBySub(verts[(i+len(face)-1)%len(face)], verts[i]).Normalize()
+				vnext := NewV3dBySub(verts[(i+1)%len(face)], verts[i]).Normalize()
+				corner_weights[fidx][i] = float32(math.Acos(math.Max(-1, math.Min(1, float64(vprev.Dot(vnext))))))
+			default:
+				corner_weights[fidx][i] = 1
+			}
+		}
+		face_normals[fidx].Normalize()
+	}
+	tuv_per_vert := self.get_texture_mode() == "VERTEX"
+	self.norms = make([][3]float32, len(self.verts))
+	for vidx, corners := range vert_corners {
+		group_normals, group_vidx := []V3d{}, []uint32{}
+		for _, c := range corners {
+			fnormal := face_normals[c[0]]
+			normal := V3d{0, 0, 0}
+			for _, o := range corners { // sum of the normals of the faces on the smooth side
+				if o[0] == c[0] || fnormal.Dot(&face_normals[o[0]]) >= cos_crease {
+					onormal := face_normals[o[0]]
+					normal.Add(onormal.Scale(corner_weights[o[0]][o[1]], corner_weights[o[0]][o[1]], corner_weights[o[0]][o[1]]))
+				}
+			}
+			if normal.Length() == 0 {
+				normal = fnormal
+			}
+			normal.Normalize()
+			group := -1
+			for k := range group_normals {
+				if group_normals[k].Dot(&normal) > 0.9999 {
+					group = k
+					break
+				}
+			}
+			if group < 0 { // the first group keeps the vertex, while the others get a new one
+				new_vidx := uint32(vidx)
+				if len(group_normals) > 0 {
+					new_vidx = self.AddVertex(self.verts[vidx])
+					self.norms = append(self.norms, [3]float32{})
+					if tuv_per_vert {
+						self.tuvs = append(self.tuvs, self.tuvs[vidx])
+					}
+				}
+				self.norms[new_vidx] = normal
+				group, group_normals, group_vidx = len(group_normals), append(group_normals, normal), append(group_vidx, new_vidx)
+			}
+			self.faces[c[0]][c[1]] = group_vidx[group]
+		}
+	}
+	self.tans = nil
+	self.Clear(false, true, true)
+}
+
+// ----------------------------------------------------------------------------
+// Tangent Vectors (for normal mapping)
+// ----------------------------------------------------------------------------
+
+func (self *Geometry) HasTangents() bool {
+	return len(self.tans) > 0 && len(self.tans) == len(self.verts)
+}
+
+func (self *Geometry) GetTangents() [][4]float32 {
+	return self.tans // PER_VERT [nverts][4]
+}
+
+func (self *Geometry) BuildTangents() {
+	// Build PER_VERT tangent vectors from PER_VERT normals and texture UVs, as {tx, ty, tz, w},
+	//   where (tx,ty,tz) is the unit tangent in the direction of increasing U (orthogonal to the normal),
+	//   and bitangent (in the direction of increasing V) is cross(normal, tangent) * w (w is 1 or -1).
+	// Note that tangents are included in the data buffers, along with PER_VERT normals and texture UVs.
+	if !self.HasNormalFor("VERTEX") || self.get_texture_mode() != "VERTEX" {
+		common.Logger.Warn("Failed to BuildTangents() : PER_VERT normals and texture UVs are required\n")
+		return
+	}
+	sdirs, tdirs := make([]V3d, len(self.verts)), make([]V3d, len(self.verts))
+	for fidx := range self.faces {
+		for _, t := range self.GetFaceTriangles(fidx) {
+			p0, p1, p2 := self.verts[t[0]], self.verts[t[1]], self.verts[t[2]]
+			uv0, uv1, uv2 := self.tuvs[t[0]], self.tuvs[t[1]], self.tuvs[t[2]]
+			e1, e2 := NewV3dBySub(p1, p0), NewV3dBySub(p2, p0)
+			du1, dv1, du2, dv2 := uv1[0]-uv0[0], uv1[1]-uv0[1], uv2[0]-uv0[0], uv2[1]-uv0[1]
+			det := du1*dv2 - du2*dv1
+			if det == 0 {
+				continue // degenerate texture mapping
+			}
+			r := 1 / det
+			sdir := V3d{(e1[0]*dv2 - e2[0]*dv1) * r, (e1[1]*dv2 - e2[1]*dv1) * r, (e1[2]*dv2 - e2[2]*dv1) * r}
+			tdir := V3d{(e2[0]*du1 - e1[0]*du2) * r, (e2[1]*du1 - e1[1]*du2) * r, (e2[2]*du1 - e1[2]*du2) * r}
+			for _, vidx := range t {
+				sdirs[vidx].Add(&sdir)
+				tdirs[vidx].Add(&tdir)
+			}
+		}
+	}
+	self.tans = make([][4]float32, len(self.verts))
+	for vidx := range self.verts {
+		n := V3d(self.norms[vidx])
+		s := sdirs[vidx]
+		ns := n.Dot(&s)
+		tangent := *s.Add(n.Clone().Scale(-ns, -ns, -ns)).Normalize() // orthogonal to the normal (Gram-Schmidt)
+		if tangent.Length() == 0 {
+			// no texture mapping around, so use any vector orthogonal to the normal
+			if math.Abs(float64(n[0])) < 0.9 {
+				tangent = *n.Cross(&V3d{1, 0, 0}).Normalize()
+			} else {
+				tangent = *n.Cross(&V3d{0, 1, 0}).Normalize()
+			}
+		}
+		w := float32(1)
+		if n.Cross(&tangent).Dot(&tdirs[vidx]) < 0 {
+			w = -1
+		}
+		self.tans[vidx] = [4]float32{tangent[0], tangent[1], tangent[2], w}
+	}
+	self.Clear(false, true, true)
+}
+
 // ----------------------------------------------------------------------------
 // Trianulation
 // ----------------------------------------------------------------------------
@@ -327,47 +765,85 @@ func (self *Geometry) get_reverse(face_vlist []uint32) []uint32 {
 	return new_vlist
 }
 
-func (self *Geometry) splice_indices(a []uint32, pos int, delete_count int, new_entries ...uint32) []uint32 {
-	head := a[0:pos]
-	tail := a[pos+delete_count:]
-	return append(append(head, new_entries...), tail...)
-}
-
 func (self *Geometry) get_triangulation(face_vlist []uint32, face_normal *V3d) [][]uint32 {
-	vindices := make([]uint32, len(face_vlist))
-	copy(vindices, face_vlist)
-	new_faces := make([][]uint32, 0)
-	vidx, vcount := 0, len(vindices)
-	iterations, max_iterations := 0, 10*len(vindices)
-	for vcount > 3 && iterations < max_iterations {
-		i0, i1, i2 := vidx, (vidx+1)%vcount, (vidx+2)%vcount
-		v0, v1, v2 := self.verts[vindices[i0]], self.verts[vindices[i1]], self.verts[vindices[i2]]
-		temp_normal := NewV3dByFaceNormal(v0, v1, v2)
-		if face_normal.Dot(temp_normal) > 0 {
-			point_inside := false
-			for j := 0; j < vcount; j++ {
-				if j != i0 && j != i1 && j != i2 && IsPointInside(self.verts[vindices[j]], v0, v1, v2) {
-					point_inside = true
-					break
-				}
-			}
-			if !point_inside {
-				new_faces = append(new_faces, []uint32{vindices[i0], vindices[i1], vindices[i2]})
-				vindices = self.splice_indices(vindices, i1, 1)
-			}
-		}
-		vcount = len(vindices)
-		vidx = (vidx + 1) % vcount
-		iterations++
+	// Triangulate the face (which may be concave), and return the vertex indices of the triangles,
+	//   after projecting the face on the plane perpendicular to the dominant axis of its normal.
+	if len(face_vlist) <= 3 {
+		return [][]uint32{face_vlist}
+	}
+	verts := make([][3]float32, len(face_vlist))
+	for i, vidx := range face_vlist {
+		verts[i] = self.verts[vidx]
+	}
+	normal := get_polygon_normal(verts) // more reliable than 'face_normal' (from the first corner) for concave faces
+	if normal == [3]float32{0, 0, 0} {
+		normal = *face_normal
+	}
+	ring := make([][2]float32, len(face_vlist))
+	project := get_projection_to_plane(normal)
+	for i, xyz := range verts {
+		ring[i] = project(xyz)
 	}
-	new_faces = append(new_faces, vindices)
-	if iterations == max_iterations {
-		common.Logger.Error("failed to traiangulate : %v => %v\n", face_vlist, new_faces)
+	new_faces := make([][]uint32, 0, len(face_vlist)-2)
+	for _, t := range common.TriangulatePolygon([][][2]float32{ring}) {
+		new_faces = append(new_faces, []uint32{face_vlist[t[0]], face_vlist[t[1]], face_vlist[t[2]]})
+	}
+	if len(new_faces) == 0 {
+		common.Logger.Warn("failed to triangulate : %v\n", face_vlist)
 	}
-	// common.Logger.Trace("%v => %v\n", face_vlist, new_faces)
 	return new_faces
 }
 
+func get_projection_to_plane(normal [3]float32) func(xyz [3]float32) [2]float32 {
+	// Projection on XY, YZ or ZX plane, which keeps CCW (around the normal) as CCW in 2D
+	ax, ay, az := normal[0], normal[1], normal[2]
+	if ax < 0 {
+		ax = -ax
+	}
+	if ay < 0 {
+		ay = -ay
+	}
+	if az < 0 {
+		az = -az
+	}
+	switch {
+	case az >= ax && az >= ay && normal[2] >= 0:
+		return func(p [3]float32) [2]float32 { return [2]float32{p[0], p[1]} }
+	case az >= ax && az >= ay:
+		return func(p [3]float32) [2]float32 { return [2]float32{p[1], p[0]} }
+	case ax >= ay && normal[0] >= 0:
+		return func(p [3]float32) [2]float32 { return [2]float32{p[1], p[2]} }
+	case ax >= ay:
+		return func(p [3]float32) [2]float32 { return [2]float32{p[2], p[1]} }
+	case normal[1] >= 0:
+		return func(p [3]float32) [2]float32 { return [2]float32{p[2], p[0]} }
+	default:
+		return func(p [3]float32) [2]float32 { return [2]float32{p[0], p[2]} }
+	}
+}
+
+func get_polygon_normal(verts [][3]float32) [3]float32 {
+	// Normal vector of the polygon (by Newell's method, which works for concave polygons as well)
+	n := [3]float32{0, 0, 0}
+	for i, j := 0, len(verts)-1; i < len(verts); j, i = i, i+1 {
+		a, b := verts[j], verts[i]
+		n[0] += (a[1] - b[1]) * (a[2] + b[2])
+		n[1] += (a[2] - b[2]) * (a[0] + b[0])
+		n[2] += (a[0] - b[0]) * (a[1] + b[1])
+	}
+	return n
+}
+
+func (self *Geometry) GetFaceTriangles(fidx int) [][]uint32 {
+	// Triangulate the face (just like BuildDataBuffers() does), and return the vertex indices of the triangles
+	face := self.faces[fidx]
+	if len(face) <= 3 {
+		return [][]uint32{face}
+	}
+	nv := V3d(self.GetFaceNormal(fidx))
+	return self.get_triangulation(face, &nv)
+}
+
 // ----------------------------------------------------------------------------
 // Build Data Buffers
 // ----------------------------------------------------------------------------
@@ -389,7 +865,7 @@ func (self *Geometry) get_fpoint_new_vidx(fidx int, i int) int {
 	return int(self.fpoint_vidx_list[fidx]) + i
 }
 
-func (self *Geometry) buffer_copy_xyz(buf []float32, pinfo [4]int, new_vidx int, vidx int) {
+func (self *Geometry) buffer_copy_xyz(buf []float32, pinfo [5]int, new_vidx int, vidx int) {
 	stride, offset := pinfo[0], 0 // XY coordinates in 3 bytes
 	pos := new_vidx*stride + offset
 	buf[pos+0] = self.verts[vidx][0]
@@ -397,7 +873,7 @@ func (self *Geometry) buffer_copy_xyz(buf []float32, pinfo [4]int, new_vidx int,
 	buf[pos+2] = self.verts[vidx][2]
 }
 
-func (self *Geometry) buffer_copy_tuv(buf []float32, pinfo [4]int, new_vidx int, tuv_idx int, tuv_offset int) {
+func (self *Geometry) buffer_copy_tuv(buf []float32, pinfo [5]int, new_vidx int, tuv_idx int, tuv_offset int) {
 	stride, offset := pinfo[0], pinfo[1] // UV texture coordinates in 1 byte
 	u := uint32(self.tuvs[tuv_idx][tuv_offset+0] * 65535)
 	v := uint32(self.tuvs[tuv_idx][tuv_offset+1] * 65535)
@@ -405,7 +881,7 @@ func (self *Geometry) buffer_copy_tuv(buf []float32, pinfo [4]int, new_vidx int,
 	buf[pos] = math.Float32frombits(u + v<<16) // LittleEndian (lower byte comes first)
 }
 
-func (self *Geometry) buffer_copy_nor(buf []float32, pinfo [4]int, new_vidx int, nor_idx int) {
+func (self *Geometry) buffer_copy_nor(buf []float32, pinfo [5]int, new_vidx int, nor_idx int) {
 	stride, offset := pinfo[0], pinfo[1]+pinfo[2] // normal vector in 1 byte
 	nx := uint32(self.norms[nor_idx][0] * 127)
 	ny := uint32(self.norms[nor_idx][1] * 127)
@@ -414,10 +890,20 @@ func (self *Geometry) buffer_copy_nor(buf []float32, pinfo [4]int, new_vidx int,
 	buf[pos] = math.Float32frombits(nx + ny<<8 + nz<<16) // LittleEndian (lower byte comes first)
 }
 
+func (self *Geometry) buffer_copy_tan(buf []float32, pinfo [5]int, new_vidx int, vidx int) {
+	stride, offset := pinfo[0], pinfo[1]+pinfo[2]+pinfo[3] // tangent vector (with its 'w') in 1 byte
+	packed := uint32(0)
+	for i := 0; i < 4; i++ {
+		packed |= uint32(uint8(int8(self.tans[vidx][i]*127))) << (8 * i)
+	}
+	pos := new_vidx*stride + offset
+	buf[pos] = math.Float32frombits(packed) // LittleEndian (lower byte comes first)
+}
+
 func (self *Geometry) BuildDataBuffers(for_points bool, for_lines bool, for_faces bool) {
 	// create data buffer for vertex points
-	self.dbuffer_vpoint, self.dbuffer_vpoint_info = nil, [4]int{0, 0, 0, 0}
-	self.dbuffer_fpoint, self.dbuffer_fpoint_info = nil, [4]int{0, 0, 0, 0}
+	self.dbuffer_vpoint, self.dbuffer_vpoint_info = nil, [5]int{0, 0, 0, 0, 0}
+	self.dbuffer_fpoint, self.dbuffer_fpoint_info = nil, [5]int{0, 0, 0, 0, 0}
 	points_per_face := false
 	if for_faces {
 		points_per_face = self.HasNormalFor("FACE") || self.HasTextureFor("FACE")
@@ -425,7 +911,7 @@ func (self *Geometry) BuildDataBuffers(for_points bool, for_lines bool, for_face
 			self.count_fpoint_vidx_list()
 		}
 		if self.HasNormalFor("FACE") && self.HasTextureFor("FACE") {
-			self.dbuffer_fpoint_info = [4]int{(3 + 1 + 1), 3, 1, 1} // size, xyz_size, uv_size, normal_size
+			self.dbuffer_fpoint_info = [5]int{(3 + 1 + 1), 3, 1, 1, 0} // size, xyz_size, uv_size, normal_size, tangent_size
 			self.dbuffer_fpoint = make([]float32, self.fpoint_vert_total*self.dbuffer_fpoint_info[0])
 			for fidx, face_vlist := range self.faces {
 				for i := 0; i < len(face_vlist); i++ {
@@ -436,7 +922,7 @@ func (self *Geometry) BuildDataBuffers(for_points bool, for_lines bool, for_face
 				}
 			}
 		} else if self.HasNormalFor("FACE") && self.HasTextureFor("VERTEX") {
-			self.dbuffer_fpoint_info = [4]int{(3 + 1 + 1), 3, 1, 1} // size, xyz_size, uv_size, normal_size
+			self.dbuffer_fpoint_info = [5]int{(3 + 1 + 1), 3, 1, 1, 0} // size, xyz_size, uv_size, normal_size, tangent_size
 			self.dbuffer_fpoint = make([]float32, self.fpoint_vert_total*self.dbuffer_fpoint_info[0])
 			for fidx, face_vlist := range self.faces {
 				for i := 0; i < len(face_vlist); i++ {
@@ -447,7 +933,7 @@ func (self *Geometry) BuildDataBuffers(for_points bool, for_lines bool, for_face
 				}
 			}
 		} else if self.HasNormalFor("FACE") && !self.HasTextureFor("") {
-			self.dbuffer_fpoint_info = [4]int{(3 + 0 + 1), 3, 0, 1} // size, xyz_size, uv_size, normal_size
+			self.dbuffer_fpoint_info = [5]int{(3 + 0 + 1), 3, 0, 1, 0} // size, xyz_size, uv_size, normal_size, tangent_size
 			self.dbuffer_fpoint = make([]float32, self.fpoint_vert_total*self.dbuffer_fpoint_info[0])
 			for fidx, face_vlist := range self.faces {
 				for i := 0; i < len(face_vlist); i++ {
@@ -457,7 +943,7 @@ func (self *Geometry) BuildDataBuffers(for_points bool, for_lines bool, for_face
 				}
 			}
 		} else if self.HasNormalFor("VERTEX") && self.HasTextureFor("FACE") {
-			self.dbuffer_fpoint_info = [4]int{(3 + 1 + 1), 3, 1, 1} // size, xyz_size, uv_size, normal_size
+			self.dbuffer_fpoint_info = [5]int{(3 + 1 + 1), 3, 1, 1, 0} // size, xyz_size, uv_size, normal_size, tangent_size
 			self.dbuffer_fpoint = make([]float32, self.fpoint_vert_total*self.dbuffer_fpoint_info[0])
 			for fidx, face_vlist := range self.faces {
 				for i := 0; i < len(face_vlist); i++ {
@@ -468,17 +954,24 @@ func (self *Geometry) BuildDataBuffers(for_points bool, for_lines bool, for_face
 				}
 			}
 		} else if self.HasNormalFor("VERTEX") && self.HasTextureFor("VERTEX") {
-			self.dbuffer_fpoint_info = [4]int{(3 + 1 + 1), 3, 1, 1} // size, xyz_size, uv_size, normal_size
+			if self.HasTangents() {
+				self.dbuffer_fpoint_info = [5]int{(3 + 1 + 1 + 1), 3, 1, 1, 1} // size, xyz_size, uv_size, normal_size, tangent_size
+			} else {
+				self.dbuffer_fpoint_info = [5]int{(3 + 1 + 1), 3, 1, 1, 0} // size, xyz_size, uv_size, normal_size, tangent_size
+			}
 			self.dbuffer_fpoint = make([]float32, len(self.verts)*self.dbuffer_fpoint_info[0])
 			for vidx := 0; vidx < len(self.verts); vidx++ {
 				self.buffer_copy_xyz(self.dbuffer_fpoint, self.dbuffer_fpoint_info, vidx, vidx)
 				self.buffer_copy_tuv(self.dbuffer_fpoint, self.dbuffer_fpoint_info, vidx, vidx, 0)
 				self.buffer_copy_nor(self.dbuffer_fpoint, self.dbuffer_fpoint_info, vidx, vidx)
+				if self.dbuffer_fpoint_info[4] > 0 {
+					self.buffer_copy_tan(self.dbuffer_fpoint, self.dbuffer_fpoint_info, vidx, vidx)
+				}
 			}
 			self.dbuffer_vpoint = self.dbuffer_fpoint
 			self.dbuffer_vpoint_info = self.dbuffer_fpoint_info
 		} else if self.HasNormalFor("VERTEX") && !self.HasTextureFor("") {
-			self.dbuffer_fpoint_info = [4]int{(3 + 0 + 1), 3, 0, 1} // size, xyz_size, uv_size, normal_size
+			self.dbuffer_fpoint_info = [5]int{(3 + 0 + 1), 3, 0, 1, 0} // size, xyz_size, uv_size, normal_size, tangent_size
 			self.dbuffer_fpoint = make([]float32, len(self.verts)*self.dbuffer_fpoint_info[0])
 			for vidx := 0; vidx < len(self.verts); vidx++ {
 				self.buffer_copy_xyz(self.dbuffer_fpoint, self.dbuffer_fpoint_info, vidx, vidx)
@@ -487,7 +980,7 @@ func (self *Geometry) BuildDataBuffers(for_points bool, for_lines bool, for_face
 			self.dbuffer_vpoint = self.dbuffer_fpoint
 			self.dbuffer_vpoint_info = self.dbuffer_fpoint_info
 		} else if !self.HasNormalFor("") && self.HasTextureFor("FACE") {
-			self.dbuffer_fpoint_info = [4]int{(3 + 1 + 0), 3, 1, 0} // size, xyz_size, uv_size, normal_size
+			self.dbuffer_fpoint_info = [5]int{(3 + 1 + 0), 3, 1, 0, 0} // size, xyz_size, uv_size, normal_size, tangent_size
 			self.dbuffer_fpoint = make([]float32, self.fpoint_vert_total*self.dbuffer_fpoint_info[0])
 			for fidx, face_vlist := range self.faces {
 				for i := 0; i < len(face_vlist); i++ {
@@ -497,7 +990,7 @@ func (self *Geometry) BuildDataBuffers(for_points bool, for_lines bool, for_face
 				}
 			}
 		} else if !self.HasNormalFor("") && self.HasTextureFor("VERTEX") {
-			self.dbuffer_fpoint_info = [4]int{(3 + 1 + 0), 3, 1, 0} // size, xyz_size, uv_size, normal_size
+			self.dbuffer_fpoint_info = [5]int{(3 + 1 + 0), 3, 1, 0, 0} // size, xyz_size, uv_size, normal_size, tangent_size
 			self.dbuffer_fpoint = make([]float32, len(self.verts)*self.dbuffer_fpoint_info[0])
 			for vidx := 0; vidx < len(self.verts); vidx++ {
 				self.buffer_copy_xyz(self.dbuffer_fpoint, self.dbuffer_fpoint_info, vidx, vidx)
@@ -506,7 +999,7 @@ func (self *Geometry) BuildDataBuffers(for_points bool, for_lines bool, for_face
 			self.dbuffer_vpoint = self.dbuffer_fpoint
 			self.dbuffer_vpoint_info = self.dbuffer_fpoint_info
 		} else if !self.HasNormalFor("") && !self.HasTextureFor("") {
-			self.dbuffer_fpoint_info = [4]int{(3 + 0 + 0), 3, 0, 0} // size, xyz_size, uv_size, normal_size
+			self.dbuffer_fpoint_info = [5]int{(3 + 0 + 0), 3, 0, 0, 0} // size, xyz_size, uv_size, normal_size, tangent_size
 			self.dbuffer_fpoint = make([]float32, len(self.verts)*self.dbuffer_fpoint_info[0])
 			for vidx := 0; vidx < len(self.verts); vidx++ {
 				self.buffer_copy_xyz(self.dbuffer_fpoint, self.dbuffer_fpoint_info, vidx, vidx)
@@ -518,7 +1011,7 @@ func (self *Geometry) BuildDataBuffers(for_points bool, for_lines bool, for_face
 		self.dbuffer_fpoint = nil
 	}
 	if (for_points || for_lines) && self.dbuffer_vpoint == nil {
-		self.dbuffer_vpoint_info = [4]int{3, 3, 0, 0}
+		self.dbuffer_vpoint_info = [5]int{3, 3, 0, 0, 0}
 		self.dbuffer_vpoint = make([]float32, len(self.verts)*self.dbuffer_vpoint_info[0])
 		for vidx := 0; vidx < len(self.verts); vidx++ {
 			self.buffer_copy_xyz(self.dbuffer_vpoint, self.dbuffer_vpoint_info, vidx, vidx)
@@ -575,6 +1068,7 @@ func (self *Geometry) BuildDataBuffers(for_points bool, for_lines bool, for_face
 				tpos += 3
 			}
 		}
+		self.dbuffer_face = self.dbuffer_face[:tpos] // degenerate triangles may have been skipped
 	} else {
 		self.dbuffer_face = nil
 	}
@@ -592,7 +1086,7 @@ func (self *Geometry) BuildDataBuffersForWireframe() {
 			self.dbuffer_vpoint[vpos+2] = xyz[2]
 			vpos += 3
 		}
-		self.dbuffer_vpoint_info = [4]int{3, 3, 0, 0}
+		self.dbuffer_vpoint_info = [5]int{3, 3, 0, 0, 0}
 	}
 	// create data buffer for edges, by extracting wireframe from faces
 	self.dbuffer_line = make([]uint32, 0)
@@ -626,13 +1120,13 @@ func (self *Geometry) GetVtxBuffer(draw_mode int) []float32 {
 	}
 }
 
-func (self *Geometry) GetVtxBufferInfo(draw_mode int) [5]int {
+func (self *Geometry) GetVtxBufferInfo(draw_mode int) [6]int {
 	if draw_mode == 3 && self.dbuffer_fpoint != nil {
 		pinfo := self.dbuffer_fpoint_info // use extra vertex buffer (built for FACE drawing)s
-		return [5]int{(len(self.dbuffer_fpoint) / pinfo[0]), pinfo[0], pinfo[1], pinfo[2], pinfo[3]}
+		return [6]int{(len(self.dbuffer_fpoint) / pinfo[0]), pinfo[0], pinfo[1], pinfo[2], pinfo[3], pinfo[4]}
 	} else {
 		pinfo := self.dbuffer_vpoint_info // use original vertex buffer
-		return [5]int{(len(self.dbuffer_vpoint) / pinfo[0]), pinfo[0], pinfo[1], pinfo[2], pinfo[3]}
+		return [6]int{(len(self.dbuffer_vpoint) / pinfo[0]), pinfo[0], pinfo[1], pinfo[2], pinfo[3], pinfo[4]}
 	}
 }
 
@@ -659,3 +1153,60 @@ func (self *Geometry) GetIdxBufferCount(draw_mode int) int {
 		return 0 // n
 	}
 }
+
+func (self *Geometry) GetVtxBufferTracker(draw_mode int) *gigl.BufferTracker {
+	if draw_mode == 3 && self.dbuffer_fpoint != nil {
+		return &self.dbuffer_fpoint_tracker // use extra vertex buffer (built for FACE drawing)
+	} else {
+		return &self.dbuffer_vpoint_tracker // use original vertex buffer
+	}
+}
+
+func (self *Geometry) GetDataBufferUsage() cst.BufferUsage {
+	return self.dbuffer_usage
+}
+
+// ----------------------------------------------------------------------------
+// Updating Data Buffer (without rebuilding)
+// ----------------------------------------------------------------------------
+
+func (self *Geometry) SetDataBufferUsage(usage cst.BufferUsage) *Geometry {
+	// Set usage hint (StaticDraw/DynamicDraw/StreamDraw), if the vertices will be updated repeatedly
+	self.dbuffer_usage = usage
+	return self
+}
+
+func (self *Geometry) UpdateVertices(start int, vertices [][3]float32) *Geometry {
+	// Change the coordinates of the vertices from 'start', and update the data buffers in place,
+	//   so that only the changed values are uploaded again, when the geometry is rendered next time.
+	// Note that topology, texture UVs and normal vectors are not changed (rebuild the data buffers for them).
+	end := start + len(vertices)
+	if start < 0 || end > len(self.verts) {
+		common.Logger.Error("Failed to UpdateVertices() : invalid range [%d:%d] for %d vertices\n", start, end, len(self.verts))
+		return self
+	}
+	copy(self.verts[start:end], vertices)
+	if pinfo := self.dbuffer_vpoint_info; self.dbuffer_vpoint != nil && len(self.dbuffer_vpoint) >= end*pinfo[0] {
+		for vidx := start; vidx < end; vidx++ {
+			self.buffer_copy_xyz(self.dbuffer_vpoint, pinfo, vidx, vidx)
+		}
+		self.dbuffer_vpoint_tracker.MarkChanged(start*pinfo[0], end*pinfo[0])
+	}
+	if pinfo := self.dbuffer_fpoint_info; self.dbuffer_fpoint != nil {
+		if len(self.dbuffer_vpoint) > 0 && &self.dbuffer_fpoint[0] == &self.dbuffer_vpoint[0] {
+			// the same buffer for vertex points (PER_VERT normals & UVs, or none), which was updated already
+			self.dbuffer_fpoint_tracker.MarkChanged(start*pinfo[0], end*pinfo[0])
+		} else {
+			for fidx, face_vlist := range self.faces { // vertices are duplicated for each face
+				for i := 0; i < len(face_vlist); i++ {
+					if vidx := int(face_vlist[i]); vidx >= start && vidx < end {
+						new_vidx := self.get_fpoint_new_vidx(fidx, i)
+						self.buffer_copy_xyz(self.dbuffer_fpoint, pinfo, new_vidx, vidx)
+						self.dbuffer_fpoint_tracker.MarkChanged(new_vidx*pinfo[0], new_vidx*pinfo[0]+3)
+					}
+				}
+			}
+		}
+	}
+	return self
+}
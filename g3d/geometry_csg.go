@@ -0,0 +1,401 @@
+package g3d
+
+import (
+	"math"
+
+	"github.com/go4orward/gigl/common"
+)
+
+// ----------------------------------------------------------------------------
+// Constructive Solid Geometry (by BSP trees)
+// ----------------------------------------------------------------------------
+
+func (self *Geometry) Union(g *Geometry) *Geometry {
+	// Replace the geometry with the union of the two closed solids
+	return self.apply_csg("UNION", g)
+}
+
+func (self *Geometry) Subtract(g *Geometry) *Geometry {
+	// Replace the geometry with its difference from the closed solid 'g' (cutting 'g' out of it)
+	return self.apply_csg("SUBTRACT", g)
+}
+
+func (self *Geometry) Intersect(g *Geometry) *Geometry {
+	// Replace the geometry with the intersection of the two closed solids
+	return self.apply_csg("INTERSECT", g)
+}
+
+func (self *Geometry) apply_csg(operation string, g *Geometry) *Geometry {
+	// Combine the two solids with BSP trees (just like 'csg.js' by Evan Wallace), where the faces are
+	//   split by the planes of the other solid, and the ones inside (or outside) of it are removed.
+	//   Texture UVs (PER_VERT or PER_FACE) of the faces are preserved as PER_FACE texture UVs,
+	//   while normal vectors are calculated again for each face (and the edges are removed).
+	if !self.is_closed_solid() || !g.is_closed_solid() {
+		common.Logger.Warn("CSG %s of open geometry may give a wrong result\n", operation)
+	}
+	// Note that coordinates are normalized (to a unit box), to use a fixed epsilon for the planes
+	bbox := NewBBoxEmpty()
+	for _, geometry := range []*Geometry{self, g} {
+		for i := range geometry.verts {
+			bbox.AddPoint(&geometry.verts[i])
+		}
+	}
+	if bbox.IsEmpty() {
+		return self
+	}
+	center, shape := bbox.Center(), bbox.Shape()
+	size := math.Sqrt(float64(shape[0]*shape[0] + shape[1]*shape[1] + shape[2]*shape[2]))
+	if size == 0 {
+		size = 1
+	}
+	a := &csg_node{}
+	a.build(self.get_csg_polygons(center, size))
+	b := &csg_node{}
+	b.build(g.get_csg_polygons(center, size))
+	switch operation {
+	case "UNION":
+		a.clip_to(b)
+		b.clip_to(a)
+		b.invert()
+		b.clip_to(a)
+		b.invert()
+		a.build(b.all_polygons(nil))
+	case "SUBTRACT":
+		a.invert()
+		a.clip_to(b)
+		b.clip_to(a)
+		b.invert()
+		b.clip_to(a)
+		b.invert()
+		a.build(b.all_polygons(nil))
+		a.invert()
+	case "INTERSECT":
+		a.invert()
+		b.clip_to(a)
+		b.invert()
+		a.clip_to(b)
+		b.clip_to(a)
+		a.build(b.all_polygons(nil))
+		a.invert()
+	}
+	self.set_csg_polygons(a.all_polygons(nil), center, size)
+	return self
+}
+
+func (self *Geometry) is_closed_solid() bool {
+	// Check if every edge of the faces is shared by exactly two of them
+	for _, face := range self.faces {
+		if get_invalid_index(face, uint32(len(self.verts))) >= 0 {
+			return false
+		}
+	}
+	_, components := common.GetConsistentWinding(self.faces)
+	for _, component := range components {
+		if !common.IsClosedSurface(self.faces, component) {
+			return false
+		}
+	}
+	return len(self.faces) > 0
+}
+
+func (self *Geometry) get_csg_polygons(center [3]float32, size float64) []csg_polygon {
+	// Convert the faces into convex polygons (triangulating the concave or non-planar ones)
+	tmode := self.get_texture_mode()
+	get_vertex := func(fidx int, i int) csg_vertex {
+		xyz, v := self.verts[self.faces[fidx][i]], csg_vertex{}
+		for k := 0; k < 3; k++ {
+			v.pos[k] = float64(xyz[k]-center[k]) / size
+		}
+		if tmode == "VERTEX" {
+			tuv := self.tuvs[self.faces[fidx][i]]
+			v.uv = [2]float64{float64(tuv[0]), float64(tuv[1])}
+		} else if tmode == "FACE" && 2*i+1 < len(self.tuvs[fidx]) {
+			v.uv = [2]float64{float64(self.tuvs[fidx][2*i]), float64(self.tuvs[fidx][2*i+1])}
+		}
+		return v
+	}
+	polygons := []csg_polygon{}
+	for fidx, face := range self.faces {
+		if len(face) < 3 {
+			continue
+		}
+		p := csg_polygon{verts: make([]csg_vertex, len(face)), has_uv: tmode != ""}
+		for i := range face {
+			p.verts[i] = get_vertex(fidx, i)
+		}
+		if p.set_plane() && p.is_convex_and_planar() {
+			polygons = append(polygons, p)
+			continue
+		}
+		for _, t := range self.GetFaceTriangles(fidx) {
+			p := csg_polygon{verts: make([]csg_vertex, 3), has_uv: tmode != ""}
+			for i, vidx := range t {
+				p.verts[i] = get_vertex(fidx, find_corner(face, vidx))
+			}
+			if p.set_plane() {
+				polygons = append(polygons, p)
+			}
+		}
+	}
+	return polygons
+}
+
+func (self *Geometry) set_csg_polygons(polygons []csg_polygon, center [3]float32, size float64) {
+	// Replace the geometry with the polygons (sharing the vertices at the same position)
+	has_uv := false
+	for _, p := range polygons {
+		has_uv = has_uv || p.has_uv
+	}
+	self.Clear(true, true, true)
+	vmap := map[[3]float32]uint32{}
+	for _, p := range polygons {
+		face, tuv := []uint32{}, []float32{}
+		for _, v := range p.verts {
+			xyz := [3]float32{}
+			for k := 0; k < 3; k++ {
+				xyz[k] = float32(v.pos[k]*size) + center[k]
+			}
+			vidx, ok := vmap[xyz]
+			if !ok {
+				vidx = self.AddVertex(xyz)
+				vmap[xyz] = vidx
+			}
+			if len(face) > 0 && face[len(face)-1] == vidx {
+				continue // skip the vertex merged with its neighbor
+			}
+			face = append(face, vidx)
+			tuv = append(tuv, float32(v.uv[0]), float32(v.uv[1]))
+		}
+		if len(face) > 1 && face[len(face)-1] == face[0] {
+			face, tuv = face[:len(face)-1], tuv[:len(tuv)-2]
+		}
+		if len(face) < 3 || self.is_degenerate_face(face) {
+			continue
+		}
+		self.faces = append(self.faces, face)
+		if has_uv {
+			self.tuvs = append(self.tuvs, tuv)
+		}
+	}
+	self.BuildNormalsForFace()
+}
+
+// ----------------------------------------------------------------------------
+// BSP Tree
+// ----------------------------------------------------------------------------
+
+const csg_epsilon = 1e-5 // tolerance of the planes (for the coordinates normalized to a unit box)
+
+type csg_vertex struct {
+	pos [3]float64 // normalized position
+	uv  [2]float64 // texture UV coordinates
+}
+
+type csg_plane struct {
+	normal [3]float64 //
+	w      float64    // distance from the origin
+}
+
+type csg_polygon struct {
+	verts  []csg_vertex // vertices of the convex polygon
+	plane  csg_plane    //
+	has_uv bool         // true, if its source face had texture UVs
+}
+
+type csg_node struct {
+	plane     csg_plane     //
+	has_plane bool          //
+	front     *csg_node     //
+	back      *csg_node     //
+	polygons  []csg_polygon // polygons on the plane
+}
+
+func (self *csg_polygon) set_plane() bool {
+	// Set the plane of the polygon (by Newell's method), and return false if it's degenerate
+	n := [3]float64{0, 0, 0}
+	for i, j := 0, len(self.verts)-1; i < len(self.verts); j, i = i, i+1 {
+		a, b := self.verts[j].pos, self.verts[i].pos
+		n[0] += (a[1] - b[1]) * (a[2] + b[2])
+		n[1] += (a[2] - b[2]) * (a[0] + b[0])
+		n[2] += (a[0] - b[0]) * (a[1] + b[1])
+	}
+	length := math.Sqrt(n[0]*n[0] + n[1]*n[1] + n[2]*n[2])
+	if length < csg_epsilon*csg_epsilon {
+		return false
+	}
+	self.plane.normal = [3]float64{n[0] / length, n[1] / length, n[2] / length}
+	self.plane.w = get_csg_dot(self.plane.normal, self.verts[0].pos)
+	return true
+}
+
+func (self *csg_polygon) is_convex_and_planar() bool {
+	n := self.plane.normal
+	for i, v := range self.verts {
+		if d := get_csg_dot(n, v.pos) - self.plane.w; d < -csg_epsilon || d > csg_epsilon {
+			return false
+		}
+		prev, next := self.verts[(i+len(self.verts)-1)%len(self.verts)].pos, self.verts[(i+1)%len(self.verts)].pos
+		e0 := [3]float64{v.pos[0] - prev[0], v.pos[1] - prev[1], v.pos[2] - prev[2]}
+		e1 := [3]float64{next[0] - v.pos[0], next[1] - v.pos[1], next[2] - v.pos[2]}
+		cross := [3]float64{e0[1]*e1[2] - e0[2]*e1[1], e0[2]*e1[0] - e0[0]*e1[2], e0[0]*e1[1] - e0[1]*e1[0]}
+		if get_csg_dot(n, cross) < -csg_epsilon*csg_epsilon {
+			return false
+		}
+	}
+	return true
+}
+
+func (self *csg_polygon) get_flipped() csg_polygon {
+	verts := make([]csg_vertex, len(self.verts))
+	for i, v := range self.verts {
+		verts[len(verts)-1-i] = v
+	}
+	n := self.plane.normal
+	return csg_polygon{verts, csg_plane{[3]float64{-n[0], -n[1], -n[2]}, -self.plane.w}, self.has_uv}
+}
+
+func (self *csg_plane) split_polygon(p csg_polygon, coplanar_front *[]csg_polygon, coplanar_back *[]csg_polygon, front *[]csg_polygon, back *[]csg_polygon) {
+	// Split the polygon by the plane, and put the pieces into the lists (coplanar ones by their facing)
+	const COPLANAR, FRONT, BACK, SPANNING = 0, 1, 2, 3
+	ptype, vtypes := 0, make([]int, len(p.verts))
+	for i, v := range p.verts {
+		d := get_csg_dot(self.normal, v.pos) - self.w
+		if d < -csg_epsilon {
+			vtypes[i] = BACK
+		} else if d > csg_epsilon {
+			vtypes[i] = FRONT
+		}
+		ptype |= vtypes[i]
+	}
+	switch ptype {
+	case COPLANAR:
+		if get_csg_dot(self.normal, p.plane.normal) > 0 {
+			*coplanar_front = append(*coplanar_front, p)
+		} else {
+			*coplanar_back = append(*coplanar_back, p)
+		}
+	case FRONT:
+		*front = append(*front, p)
+	case BACK:
+		*back = append(*back, p)
+	case SPANNING:
+		f, b := []csg_vertex{}, []csg_vertex{}
+		for i, vi := range p.verts {
+			j := (i + 1) % len(p.verts)
+			vj := p.verts[j]
+			if vtypes[i] != BACK {
+				f = append(f, vi)
+			}
+			if vtypes[i] != FRONT {
+				b = append(b, vi)
+			}
+			if vtypes[i]|vtypes[j] == SPANNING {
+				d := [3]float64{vj.pos[0] - vi.pos[0], vj.pos[1] - vi.pos[1], vj.pos[2] - vi.pos[2]}
+				t := (self.w - get_csg_dot(self.normal, vi.pos)) / get_csg_dot(self.normal, d)
+				v := csg_vertex{}
+				for k := 0; k < 3; k++ {
+					v.pos[k] = vi.pos[k] + d[k]*t
+				}
+				for k := 0; k < 2; k++ {
+					v.uv[k] = vi.uv[k] + (vj.uv[k]-vi.uv[k])*t
+				}
+				f = append(f, v)
+				b = append(b, v)
+			}
+		}
+		if len(f) >= 3 {
+			*front = append(*front, csg_polygon{f, p.plane, p.has_uv})
+		}
+		if len(b) >= 3 {
+			*back = append(*back, csg_polygon{b, p.plane, p.has_uv})
+		}
+	}
+}
+
+func (self *csg_node) build(polygons []csg_polygon) {
+	// Build the BSP tree with the polygons (adding them to the existing tree)
+	if len(polygons) == 0 {
+		return
+	}
+	if !self.has_plane {
+		self.plane, self.has_plane = polygons[0].plane, true
+	}
+	front, back := []csg_polygon{}, []csg_polygon{}
+	for _, p := range polygons {
+		self.plane.split_polygon(p, &self.polygons, &self.polygons, &front, &back)
+	}
+	if len(front) > 0 {
+		if self.front == nil {
+			self.front = &csg_node{}
+		}
+		self.front.build(front)
+	}
+	if len(back) > 0 {
+		if self.back == nil {
+			self.back = &csg_node{}
+		}
+		self.back.build(back)
+	}
+}
+
+func (self *csg_node) invert() {
+	// Swap the inside and the outside of the solid
+	for i := range self.polygons {
+		self.polygons[i] = self.polygons[i].get_flipped()
+	}
+	n := self.plane.normal
+	self.plane = csg_plane{[3]float64{-n[0], -n[1], -n[2]}, -self.plane.w}
+	if self.front != nil {
+		self.front.invert()
+	}
+	if self.back != nil {
+		self.back.invert()
+	}
+	self.front, self.back = self.back, self.front
+}
+
+func (self *csg_node) clip_polygons(polygons []csg_polygon) []csg_polygon {
+	// Remove the parts of the polygons inside the solid of this BSP tree
+	if !self.has_plane {
+		return append([]csg_polygon{}, polygons...)
+	}
+	front, back := []csg_polygon{}, []csg_polygon{}
+	for _, p := range polygons {
+		self.plane.split_polygon(p, &front, &back, &front, &back)
+	}
+	if self.front != nil {
+		front = self.front.clip_polygons(front)
+	}
+	if self.back != nil {
+		back = self.back.clip_polygons(back)
+	} else {
+		back = nil
+	}
+	return append(front, back...)
+}
+
+func (self *csg_node) clip_to(bsp *csg_node) {
+	// Remove the polygons of this BSP tree inside the solid of the other BSP tree
+	self.polygons = bsp.clip_polygons(self.polygons)
+	if self.front != nil {
+		self.front.clip_to(bsp)
+	}
+	if self.back != nil {
+		self.back.clip_to(bsp)
+	}
+}
+
+func (self *csg_node) all_polygons(polygons []csg_polygon) []csg_polygon {
+	polygons = append(polygons, self.polygons...)
+	if self.front != nil {
+		polygons = self.front.all_polygons(polygons)
+	}
+	if self.back != nil {
+		polygons = self.back.all_polygons(polygons)
+	}
+	return polygons
+}
+
+func get_csg_dot(a [3]float64, b [3]float64) float64 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
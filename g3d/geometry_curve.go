@@ -0,0 +1,35 @@
+package g3d
+
+import (
+	"github.com/go4orward/gigl/common"
+)
+
+// ----------------------------------------------------------------------------
+// Curves (tessellated into polylines)
+// ----------------------------------------------------------------------------
+
+func NewGeometryCurve(curves []*common.Curve, tolerance float32, camera *Camera) *Geometry {
+	// Curves tessellated into polyline edges, one for each curve, within the 'tolerance'.
+	// If 'camera' is given, the tolerance is in pixels on the screen (rather than in world units),
+	//   for the current pose of the camera.
+	var project func(xyz [3]float32) [2]float32
+	if camera != nil {
+		project = camera.project_world_to_screen
+	}
+	geometry := NewGeometry()
+	for _, curve := range curves {
+		points := curve.Tessellate(tolerance, project)
+		if len(points) < 2 {
+			continue
+		}
+		edge := make([]uint32, len(points))
+		for i, p := range points {
+			edge[i] = geometry.AddVertex(p)
+		}
+		if curve.IsClosed() {
+			edge = append(edge, edge[0])
+		}
+		geometry.AddEdge(edge)
+	}
+	return geometry
+}
@@ -174,6 +174,275 @@ func NewGeometrySolidFromCentersAndRadii(centers [][3]float32, radii []float32,
 	return geometry
 }
 
+func NewGeometryTorus(radius float32, tube_radius float32, nsides int, tube_nsides int) *Geometry {
+	// Torus around Z-axis, with texture UVs & normal vectors for each vertex (and edges along U & V)
+	nsides, tube_nsides = get_max_int(nsides, 3), get_max_int(tube_nsides, 3)
+	profile := make([][4]float32, tube_nsides+1) // circle of the tube (R, Z, normal_R, normal_Z)
+	for j := 0; j <= tube_nsides; j++ {
+		a := math.Pi * 2 * float64(j%tube_nsides) / float64(tube_nsides) // (exactly the same point at the end)
+		cosA, sinA := float32(math.Cos(a)), float32(math.Sin(a))
+		profile[j] = [4]float32{radius + tube_radius*cosA, tube_radius * sinA, cosA, sinA}
+	}
+	geometry := NewGeometry()
+	geometry.add_revolution(profile, nsides)
+	return geometry
+}
+
+func NewGeometryCone(bottom_radius float32, top_radius float32, height float32, nsides int) *Geometry {
+	// Cone (or frustum, if 'top_radius' > 0) from Z=0 to Z=height, with the caps
+	//   and texture UVs & normal vectors for each vertex (and edges along U & V)
+	nsides = get_max_int(nsides, 3)
+	slant := [2]float32{height, bottom_radius - top_radius} // normal vector of the side
+	slant_length := float32(math.Hypot(float64(slant[0]), float64(slant[1])))
+	slant[0], slant[1] = slant[0]/slant_length, slant[1]/slant_length
+	profile := [][4]float32{}
+	if bottom_radius > 0 {
+		profile = append(profile, [4]float32{0, 0, 0, -1}, [4]float32{bottom_radius, 0, 0, -1})
+	}
+	profile = append(profile, [4]float32{bottom_radius, 0, slant[0], slant[1]}, [4]float32{top_radius, height, slant[0], slant[1]})
+	if top_radius > 0 {
+		profile = append(profile, [4]float32{top_radius, height, 0, 1}, [4]float32{0, height, 0, 1})
+	}
+	geometry := NewGeometry()
+	geometry.add_revolution(profile, nsides)
+	return geometry
+}
+
+func NewGeometryCapsule(radius float32, length float32, nsides int, nrings int) *Geometry {
+	// Capsule along Z-axis (cylinder of the length with hemispheres at both ends, centered at the origin),
+	//   with texture UVs & normal vectors for each vertex (and edges along U & V)
+	nsides, nrings = get_max_int(nsides, 3), get_max_int(nrings, 1)
+	profile := [][4]float32{}
+	for k := 0; k <= nrings; k++ { // bottom hemisphere
+		a := math.Pi / 2 * float64(k-nrings) / float64(nrings)
+		cosA, sinA := float32(math.Cos(a)), float32(math.Sin(a))
+		profile = append(profile, [4]float32{radius * cosA, -length/2 + radius*sinA, cosA, sinA})
+	}
+	for k := 0; k <= nrings; k++ { // top hemisphere
+		a := math.Pi / 2 * float64(k) / float64(nrings)
+		cosA, sinA := float32(math.Cos(a)), float32(math.Sin(a))
+		profile = append(profile, [4]float32{radius * cosA, +length/2 + radius*sinA, cosA, sinA})
+	}
+	profile[0][0], profile[len(profile)-1][0] = 0, 0 // (exactly on the axis)
+	geometry := NewGeometry()
+	geometry.add_revolution(profile, nsides)
+	return geometry
+}
+
+func NewGeometryArrow(length float32, shaft_radius float32, head_length float32, head_radius float32, nsides int) *Geometry {
+	// Arrow from the origin to Z=length (cylinder as its shaft and cone as its head),
+	//   with texture UVs & normal vectors for each vertex (and edges along U & V)
+	nsides = get_max_int(nsides, 3)
+	shaft_length := length - head_length
+	slant := [2]float32{head_length, head_radius}
+	slant_length := float32(math.Hypot(float64(slant[0]), float64(slant[1])))
+	slant[0], slant[1] = slant[0]/slant_length, slant[1]/slant_length
+	profile := [][4]float32{
+		{0, 0, 0, -1}, {shaft_radius, 0, 0, -1}, // bottom of the shaft
+		{shaft_radius, 0, 1, 0}, {shaft_radius, shaft_length, 1, 0}, // side of the shaft
+		{shaft_radius, shaft_length, 0, -1}, {head_radius, shaft_length, 0, -1}, // bottom of the head
+		{head_radius, shaft_length, slant[0], slant[1]}, {0, length, slant[0], slant[1]}} // side of the head
+	geometry := NewGeometry()
+	geometry.add_revolution(profile, nsides)
+	return geometry
+}
+
+func NewGeometryIcosphere(radius float32, subdivisions int) *Geometry {
+	// Geodesic sphere (by subdividing an icosahedron), with triangles of almost the same size (without pinching at the poles),
+	//   and texture UVs (by longitude & latitude) & normal vectors for each vertex (and edges of the triangles)
+	t := float32((1 + math.Sqrt(5)) / 2)
+	verts := [][3]float32{
+		{-1, t, 0}, {1, t, 0}, {-1, -t, 0}, {1, -t, 0}, {0, -1, t}, {0, 1, t}, {0, -1, -t}, {0, 1, -t},
+		{t, 0, -1}, {t, 0, 1}, {-t, 0, -1}, {-t, 0, 1}}
+	faces := [][3]uint32{
+		{0, 11, 5}, {0, 5, 1}, {0, 1, 7}, {0, 7, 10}, {0, 10, 11}, {1, 5, 9}, {5, 11, 4}, {11, 10, 2}, {10, 7, 6}, {7, 1, 8},
+		{3, 9, 4}, {3, 4, 2}, {3, 2, 6}, {3, 6, 8}, {3, 8, 9}, {4, 9, 5}, {2, 4, 11}, {6, 2, 10}, {8, 6, 7}, {9, 8, 1}}
+	for i := range verts {
+		verts[i] = *(*V3d)(&verts[i]).Normalize()
+	}
+	for s := 0; s < subdivisions; s++ { // split each triangle into four
+		midpoints := map[[2]uint32]uint32{}
+		get_midpoint := func(a uint32, b uint32) uint32 {
+			key := [2]uint32{a, b}
+			if a > b {
+				key = [2]uint32{b, a}
+			}
+			if m, ok := midpoints[key]; ok {
+				return m
+			}
+			verts = append(verts, *NewV3dByAvg(verts[a], verts[b]).Normalize())
+			midpoints[key] = uint32(len(verts) - 1)
+			return midpoints[key]
+		}
+		new_faces := make([][3]uint32, 0, len(faces)*4)
+		for _, f := range faces {
+			a, b, c := get_midpoint(f[0], f[1]), get_midpoint(f[1], f[2]), get_midpoint(f[2], f[0])
+			new_faces = append(new_faces, [3]uint32{f[0], a, c}, [3]uint32{f[1], b, a}, [3]uint32{f[2], c, b}, [3]uint32{a, b, c})
+		}
+		faces = new_faces
+	}
+	geometry := NewGeometry()
+	for _, v := range verts {
+		geometry.AddVertex([3]float32{v[0] * radius, v[1] * radius, v[2] * radius})
+		geometry.AddNormal(v)
+		lon, lat := math.Atan2(float64(v[1]), float64(v[0])), math.Asin(float64(v[2]))
+		geometry.AddTextureUV([]float32{float32(lon/(2*math.Pi) + 0.5), float32(0.5 - lat/math.Pi)})
+	}
+	seam := map[uint32]uint32{} // vertices duplicated on the seam of texture UVs (with U+1)
+	for _, f := range faces {
+		face := []uint32{f[0], f[1], f[2]}
+		for i := 0; i < 3; i++ {
+			if face[i] < face[(i+1)%3] { // edges of the triangles (only once, since they are shared by two)
+				geometry.AddEdge([]uint32{face[i], face[(i+1)%3]})
+			}
+		}
+		u0, u1, u2 := geometry.tuvs[f[0]][0], geometry.tuvs[f[1]][0], geometry.tuvs[f[2]][0]
+		umax := float32(math.Max(float64(u0), math.Max(float64(u1), float64(u2))))
+		for i, vidx := range face {
+			if umax-geometry.tuvs[vidx][0] > 0.5 { // crossing the seam
+				if _, ok := seam[vidx]; !ok {
+					seam[vidx] = geometry.AddVertex(geometry.verts[vidx])
+					geometry.AddNormal(geometry.norms[vidx])
+					geometry.AddTextureUV([]float32{geometry.tuvs[vidx][0] + 1, geometry.tuvs[vidx][1]})
+				}
+				face[i] = seam[vidx]
+			}
+		}
+		geometry.AddFace(face)
+	}
+	return geometry
+}
+
+func NewGeometryGridPlane(xsize float32, ysize float32, xsegs int, ysegs int) *Geometry {
+	// Grid on XY-plane (centered at the origin, and facing +Z), with texture UVs & normal vectors for each vertex
+	//   (and edges along X & Y)
+	geometry := NewGeometry()
+	ncols := uint32(xsegs + 1)
+	for j := 0; j <= ysegs; j++ {
+		for i := 0; i <= xsegs; i++ {
+			u, v := float32(i)/float32(xsegs), float32(j)/float32(ysegs)
+			geometry.AddVertex([3]float32{(u - 0.5) * xsize, (v - 0.5) * ysize, 0})
+			geometry.AddTextureUV([]float32{u, 1 - v})
+			geometry.AddNormal([3]float32{0, 0, 1})
+		}
+	}
+	for j := uint32(0); j < uint32(ysegs); j++ {
+		for i := uint32(0); i < uint32(xsegs); i++ {
+			a := j*ncols + i
+			geometry.AddFace([]uint32{a, a + 1, a + 1 + ncols, a + ncols})
+		}
+	}
+	for j := uint32(0); j <= uint32(ysegs); j++ {
+		edge := make([]uint32, ncols)
+		for i := range edge {
+			edge[i] = j*ncols + uint32(i)
+		}
+		geometry.AddEdge(edge)
+	}
+	for i := uint32(0); i < ncols; i++ {
+		edge := make([]uint32, ysegs+1)
+		for j := range edge {
+			edge[j] = uint32(j)*ncols + i
+		}
+		geometry.AddEdge(edge)
+	}
+	return geometry
+}
+
+func NewGeometryDisc(inner_radius float32, outer_radius float32, nsides int) *Geometry {
+	// Disc (or annulus, if 'inner_radius' > 0) on XY-plane (facing +Z), with texture UVs (by XY) & normal vectors
+	//   for each vertex (and edges along the circles and the radii)
+	nsides = get_max_int(nsides, 3)
+	geometry := NewGeometry()
+	radii := []float32{inner_radius, outer_radius}
+	for _, r := range radii {
+		for i := 0; i <= nsides; i++ { // (vertices on the seam are duplicated, just like the other primitives)
+			a := math.Pi * 2 * float64(i%nsides) / float64(nsides)
+			x, y := r*float32(math.Cos(a)), r*float32(math.Sin(a))
+			geometry.AddVertex([3]float32{x, y, 0})
+			geometry.AddTextureUV([]float32{0.5 + 0.5*x/outer_radius, 0.5 - 0.5*y/outer_radius})
+			geometry.AddNormal([3]float32{0, 0, 1})
+		}
+	}
+	n := uint32(nsides + 1)
+	for i := uint32(0); i < uint32(nsides); i++ {
+		if inner_radius > 0 {
+			geometry.AddFace([]uint32{i, n + i, n + i + 1, i + 1})
+		} else {
+			geometry.AddFace([]uint32{i, n + i, n + i + 1})
+		}
+		geometry.AddEdge([]uint32{i, n + i})
+	}
+	for k, r := range radii {
+		if r > 0 {
+			edge := make([]uint32, n)
+			for i := range edge {
+				edge[i] = uint32(k)*n + uint32(i)
+			}
+			geometry.AddEdge(edge)
+		}
+	}
+	return geometry
+}
+
+func (self *Geometry) add_revolution(profile [][4]float32, nsides int) {
+	// Revolve the profile (R, Z, normal_R, normal_Z) around Z-axis, with texture UVs & normal vectors for each vertex.
+	//   Faces are on the right side of the profile (facing outward for the profile going up), and
+	//   the profile may repeat a point with a different normal vector, to make a sharp edge.
+	plength, vlist := float32(0), make([]float32, len(profile))
+	for j := 1; j < len(profile); j++ {
+		plength += float32(math.Hypot(float64(profile[j][0]-profile[j-1][0]), float64(profile[j][1]-profile[j-1][1])))
+		vlist[j] = plength
+	}
+	is_same := func(j0 int, j1 int) bool {
+		return profile[j0][0] == profile[j1][0] && profile[j0][1] == profile[j1][1]
+	}
+	start, plen := uint32(len(self.verts)), uint32(len(profile))
+	for i := 0; i <= nsides; i++ { // (vertices on the seam are duplicated, for texture UVs)
+		a := math.Pi * 2 * float64(i%nsides) / float64(nsides)
+		cosA, sinA := float32(math.Cos(a)), float32(math.Sin(a))
+		for j, p := range profile {
+			self.AddVertex([3]float32{p[0] * cosA, p[0] * sinA, p[1]})
+			self.AddTextureUV([]float32{float32(i) / float32(nsides), 1 - vlist[j]/plength})
+			self.AddNormal([3]float32{p[2] * cosA, p[2] * sinA, p[3]})
+		}
+	}
+	for i := uint32(0); i < uint32(nsides); i++ {
+		for j := uint32(0); j+1 < plen; j++ {
+			a, b, c, d := start+i*plen+j, start+(i+1)*plen+j, start+(i+1)*plen+j+1, start+i*plen+j+1
+			switch {
+			case is_same(int(j), int(j+1)): // (sharp edge)
+			case profile[j][0] == 0 && profile[j+1][0] == 0: // on the axis
+			case profile[j][0] == 0:
+				self.AddFace([]uint32{a, c, d})
+			case profile[j+1][0] == 0:
+				self.AddFace([]uint32{a, b, d})
+			default:
+				self.AddFace([]uint32{a, b, c, d})
+			}
+		}
+	}
+	for j := 0; j < len(profile); j++ { // edges around Z-axis
+		if profile[j][0] == 0 || (j > 0 && is_same(j, j-1)) || (j == len(profile)-1 && is_same(j, 0)) {
+			continue
+		}
+		edge := make([]uint32, nsides+1)
+		for i := range edge {
+			edge[i] = start + uint32(i)*plen + uint32(j)
+		}
+		self.AddEdge(edge)
+	}
+	for i := uint32(0); i < uint32(nsides); i++ { // edges along the profile
+		edge := []uint32{}
+		for j := 0; j < len(profile); j++ {
+			if j == 0 || !is_same(j, j-1) {
+				edge = append(edge, start+i*plen+uint32(j))
+			}
+		}
+		self.AddEdge(edge)
+	}
+}
+
 func NewGeometryEmptyExample() *Geometry {
 	geometry := NewGeometry()
 	geometry.SetVertices([][3]float32{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {0, 0, 1}})
@@ -0,0 +1,422 @@
+package g3d
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+)
+
+// ----------------------------------------------------------------------------
+// Simplification (by Quadric Error Metrics)
+// ----------------------------------------------------------------------------
+
+func (self *Geometry) SimplifyByRatio(ratio float32) *Geometry {
+	// Simplify the geometry, down to the 'ratio' (0 ~ 1) of its triangles
+	triangle_count := 0
+	for _, face := range self.faces {
+		triangle_count += len(face) - 2
+	}
+	return self.Simplify(int(float32(triangle_count) * ratio))
+}
+
+func (self *Geometry) Simplify(target_face_count int) *Geometry {
+	// Simplify the geometry down to 'target_face_count' triangles, by collapsing its edges
+	//   in the order of quadric error (Garland & Heckbert), where a vertex is moved onto its neighbor,
+	//   so that the remaining vertices keep their PER_VERT texture UVs and normals as they are.
+	// Vertices on UV seams (split vertices at the same position) are not moved, and moving borders is penalized.
+	// PER_FACE texture UVs are converted to PER_VERT (by splitting the vertices on UV seams),
+	//   and PER_FACE normals are calculated again. Note that all the faces become triangles.
+	// Edges are rebuilt along the remaining triangles, and tangents (if any) are built again.
+	triangle_count := 0
+	for _, face := range self.faces {
+		triangle_count += len(face) - 2
+	}
+	if target_face_count >= triangle_count {
+		return self
+	}
+	if self.get_texture_mode() == "FACE" {
+		self.split_vertices_for_face_uvs()
+	}
+	nmode, had_tangents := self.get_normal_mode(), self.HasTangents()
+	s := new_simplifier(self)
+	s.collapse_edges(target_face_count)
+	s.rebuild_geometry()
+	if nmode == "FACE" {
+		self.BuildNormalsForFace()
+	} else if nmode == "" {
+		self.norms = [][3]float32{}
+	}
+	self.tans = nil
+	if had_tangents && self.HasNormalFor("VERTEX") && self.get_texture_mode() == "VERTEX" {
+		self.BuildTangents() // (for the new shape of the triangles)
+	}
+	self.Clear(false, true, true)
+	return self
+}
+
+type simplifier struct {
+	geometry  *Geometry      //
+	tris      [][3]uint32    // triangles
+	tri_face  []int          // index of the original face of each triangle
+	tri_alive []bool         // false, if the triangle was removed
+	ntris     int            // number of remaining triangles
+	vert_tris [][]int        // triangles around each vertex (including removed ones)
+	quadrics  []quadric      // quadric error of each vertex
+	locked    []bool         // vertices that cannot be moved (on UV seams)
+	remap     []uint32       // vertex that each vertex was moved onto (itself, if it was not moved)
+	version   []uint32       // version of each vertex, increased whenever its quadric changes
+	queue     collapse_queue // candidates of edge collapse
+	neighbors []uint32       // (temporary) neighbor vertices
+	marks     []uint32       // (temporary) vertices marked with 'mark' are in 'neighbors'
+	mark      uint32         //
+}
+
+func new_simplifier(geometry *Geometry) *simplifier {
+	nverts := len(geometry.verts)
+	s := simplifier{geometry: geometry, marks: make([]uint32, nverts)}
+	for fidx := range geometry.faces {
+		for _, t := range geometry.GetFaceTriangles(fidx) {
+			s.tris = append(s.tris, [3]uint32{t[0], t[1], t[2]})
+			s.tri_face = append(s.tri_face, fidx)
+		}
+	}
+	s.ntris = len(s.tris)
+	s.tri_alive = make([]bool, len(s.tris))
+	s.vert_tris = make([][]int, nverts)
+	for tidx, t := range s.tris {
+		s.tri_alive[tidx] = true
+		for _, vidx := range t {
+			s.vert_tris[vidx] = append(s.vert_tris[vidx], tidx)
+		}
+	}
+	// vertices at the same position (with different texture UVs or normals) are locked
+	s.locked = make([]bool, nverts)
+	positions := make(map[[3]float32]int, nverts)
+	for vidx, xyz := range geometry.verts {
+		if first, ok := positions[xyz]; ok {
+			s.locked[first], s.locked[vidx] = true, true
+		} else {
+			positions[xyz] = vidx
+		}
+	}
+	// edges of the triangles, sorted to find the border edges (with only one triangle)
+	edges := make([]simplifier_edge, 0, len(s.tris)*3)
+	for tidx, t := range s.tris {
+		for i := 0; i < 3; i++ {
+			edges = append(edges, simplifier_edge{get_edge_key(t[i], t[(i+1)%3]), tidx, i})
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		ki, kj := edges[i].key, edges[j].key
+		return ki[0] < kj[0] || (ki[0] == kj[0] && (ki[1] < kj[1] || (ki[1] == kj[1] && edges[i].tidx < edges[j].tidx)))
+	})
+	// quadrics of the planes of the triangles (weighted by area), with penalty for the border edges
+	s.quadrics = make([]quadric, nverts)
+	normals := make([]V3d, len(s.tris))
+	for tidx, t := range s.tris {
+		p0, p1, p2 := geometry.verts[t[0]], geometry.verts[t[1]], geometry.verts[t[2]]
+		normal := NewV3dBySub(p1, p0).Cross(NewV3dBySub(p2, p0))
+		area := float64(normal.Length()) / 2
+		normals[tidx] = *normal.Normalize()
+		q := new_plane_quadric(&normals[tidx], p0, area)
+		for _, vidx := range t {
+			s.quadrics[vidx].add(&q)
+		}
+	}
+	for i := 0; i < len(edges); {
+		j := i + 1
+		for j < len(edges) && edges[j].key == edges[i].key {
+			j++
+		}
+		if j == i+1 { // border edge
+			t, k := s.tris[edges[i].tidx], edges[i].i
+			pa, pb := geometry.verts[t[k]], geometry.verts[t[(k+1)%3]]
+			edge := NewV3dBySub(pb, pa)
+			length := float64(edge.Length())
+			q := new_plane_quadric(edge.Cross(&normals[edges[i].tidx]).Normalize(), pa, 10*length*length)
+			s.quadrics[t[k]].add(&q)
+			s.quadrics[t[(k+1)%3]].add(&q)
+		}
+		i = j
+	}
+	s.remap = make([]uint32, nverts)
+	for vidx := range s.remap {
+		s.remap[vidx] = uint32(vidx)
+	}
+	s.version = make([]uint32, nverts)
+	for i, edge := range edges {
+		if i == 0 || edge.key != edges[i-1].key {
+			s.push_candidate(edge.key[0], edge.key[1])
+		}
+	}
+	return &s
+}
+
+func (self *simplifier) push_candidate(a uint32, b uint32) {
+	// Push the edge, with the direction of the smaller error (if the vertex can be moved)
+	if self.locked[a] && self.locked[b] {
+		return
+	}
+	verts := self.geometry.verts
+	q := self.quadrics[a]
+	q.add(&self.quadrics[b])
+	cost_ab, cost_ba := q.evaluate(verts[b]), q.evaluate(verts[a])
+	if self.locked[a] || (!self.locked[b] && cost_ba < cost_ab) {
+		a, b, cost_ab = b, a, cost_ba
+	}
+	heap.Push(&self.queue, collapse{cost_ab, a, b, self.version[a], self.version[b], false})
+}
+
+func (self *simplifier) collapse_edges(target_count int) {
+	for self.ntris > target_count && self.queue.Len() > 0 {
+		c := heap.Pop(&self.queue).(collapse)
+		if self.remap[c.from] != c.from || self.remap[c.to] != c.to || self.version[c.from] != c.from_ver || self.version[c.to] != c.to_ver {
+			continue // outdated
+		}
+		a, b := c.from, c.to
+		if !self.is_valid_collapse(a, b) {
+			if !c.reversed && !self.locked[b] { // try the other direction later, with its own error
+				q := self.quadrics[a]
+				q.add(&self.quadrics[b])
+				heap.Push(&self.queue, collapse{q.evaluate(self.geometry.verts[a]), b, a, c.to_ver, c.from_ver, true})
+			}
+			continue // it may be pushed again later, when its neighborhood changes
+		}
+		// move vertex 'a' onto 'b'
+		for _, tidx := range self.vert_tris[a] {
+			if !self.tri_alive[tidx] {
+				continue
+			}
+			t := &self.tris[tidx]
+			if t[0] == b || t[1] == b || t[2] == b {
+				self.tri_alive[tidx] = false
+				self.ntris--
+				continue
+			}
+			for i := 0; i < 3; i++ {
+				if t[i] == a {
+					t[i] = b
+				}
+			}
+			self.vert_tris[b] = append(self.vert_tris[b], tidx)
+		}
+		self.vert_tris[a] = nil
+		self.remap[a] = b
+		self.quadrics[b].add(&self.quadrics[a])
+		self.version[b]++
+		// push the edges around the vertex 'b' again, with its new quadric
+		alive_tris := self.vert_tris[b][:0]
+		self.clear_neighbors()
+		for _, tidx := range self.vert_tris[b] {
+			if self.tri_alive[tidx] {
+				alive_tris = append(alive_tris, tidx)
+				for _, vidx := range self.tris[tidx] {
+					if vidx != b {
+						self.add_neighbor(vidx)
+					}
+				}
+			}
+		}
+		self.vert_tris[b] = alive_tris
+		for _, n := range self.neighbors {
+			self.push_candidate(b, n)
+		}
+	}
+}
+
+func (self *simplifier) is_valid_collapse(a uint32, b uint32) bool {
+	// Check if moving vertex 'a' onto 'b' keeps the surface manifold, without flipping any triangle
+	verts := self.geometry.verts
+	opposites := [2]uint32{}
+	shared_tris := 0
+	self.clear_neighbors()
+	for _, tidx := range self.vert_tris[a] {
+		if !self.tri_alive[tidx] {
+			continue
+		}
+		t := self.tris[tidx]
+		if t[0] == b || t[1] == b || t[2] == b {
+			if shared_tris < 2 {
+				opposites[shared_tris] = t[0] + t[1] + t[2] - a - b
+			}
+			shared_tris++
+			continue
+		}
+		for _, vidx := range t {
+			if vidx != a {
+				self.add_neighbor(vidx)
+			}
+		}
+		// the triangle after the collapse has to face the same side
+		p := [3][3]float32{verts[t[0]], verts[t[1]], verts[t[2]]}
+		old_normal := NewV3dBySub(p[1], p[0]).Cross(NewV3dBySub(p[2], p[0]))
+		for i := 0; i < 3; i++ {
+			if t[i] == a {
+				p[i] = verts[b]
+			}
+		}
+		new_normal := NewV3dBySub(p[1], p[0]).Cross(NewV3dBySub(p[2], p[0]))
+		if old_normal.Dot(new_normal) <= 0 || new_normal.Length() == 0 {
+			return false
+		}
+	}
+	if shared_tris == 0 || shared_tris > 2 {
+		return false // not an edge of the surface, or a non-manifold edge
+	}
+	// link condition : the only common neighbors of 'a' and 'b' are the opposite vertices of their shared triangles
+	for _, tidx := range self.vert_tris[b] {
+		if !self.tri_alive[tidx] {
+			continue
+		}
+		for _, vidx := range self.tris[tidx] {
+			if vidx != a && vidx != b && self.marks[vidx] == self.mark && vidx != opposites[0] && (shared_tris < 2 || vidx != opposites[1]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (self *simplifier) clear_neighbors() {
+	self.neighbors = self.neighbors[:0]
+	self.mark++
+}
+
+func (self *simplifier) add_neighbor(vidx uint32) {
+	if self.marks[vidx] != self.mark {
+		self.marks[vidx] = self.mark
+		self.neighbors = append(self.neighbors, vidx)
+	}
+}
+
+func (self *simplifier) rebuild_geometry() {
+	// Rebuild the geometry with the remaining vertices and triangles (in the order of original faces),
+	//   and the edges along the remaining triangles
+	g := self.geometry
+	new_vidx := make([]int, len(g.verts))
+	nverts := 0
+	for vidx := range g.verts {
+		if self.remap[vidx] == uint32(vidx) {
+			new_vidx[vidx] = nverts
+			g.verts[nverts] = g.verts[vidx]
+			if len(g.tuvs) == len(new_vidx) {
+				g.tuvs[nverts] = g.tuvs[vidx]
+			}
+			if len(g.norms) == len(new_vidx) {
+				g.norms[nverts] = g.norms[vidx]
+			}
+			nverts++
+		}
+	}
+	get_new_vidx := func(vidx uint32) uint32 {
+		for self.remap[vidx] != vidx {
+			vidx = self.remap[vidx]
+		}
+		return uint32(new_vidx[vidx])
+	}
+	if len(g.tuvs) == len(new_vidx) {
+		g.tuvs = g.tuvs[:nverts]
+	}
+	if len(g.norms) == len(new_vidx) {
+		g.norms = g.norms[:nverts]
+	}
+	g.verts = g.verts[:nverts]
+	// faces (and the face groups)
+	faces := make([][]uint32, 0, self.ntris)
+	face_count := make([]int, len(g.faces)+1) // number of triangles from each original face
+	for tidx, t := range self.tris {
+		if self.tri_alive[tidx] {
+			faces = append(faces, []uint32{get_new_vidx(t[0]), get_new_vidx(t[1]), get_new_vidx(t[2])})
+			face_count[self.tri_face[tidx]+1]++
+		}
+	}
+	for fidx := 1; fidx < len(face_count); fidx++ {
+		face_count[fidx] += face_count[fidx-1] // index of the first new face of each original face
+	}
+	for i, fgrp := range g.fgrps {
+		g.fgrps[i] = [2]int{face_count[fgrp[0]], face_count[fgrp[1]]}
+	}
+	g.faces = faces
+	g.edges = get_edges_of_faces(g.verts, faces) // (wireframe of the remaining triangles)
+}
+
+type simplifier_edge struct {
+	key  [2]uint32 // vertex indices (in increasing order)
+	tidx int       // triangle
+	i    int       // position in the triangle
+}
+
+func get_edge_key(a uint32, b uint32) [2]uint32 {
+	if a < b {
+		return [2]uint32{a, b}
+	}
+	return [2]uint32{b, a}
+}
+
+func get_edges_of_faces(verts [][3]float32, faces [][]uint32) [][]uint32 {
+	// Edges (of two vertices) along the boundaries of the faces, where the shared ones are included only once
+	//   (even if they were split for texture UVs, since they're at the same positions)
+	edges := [][]uint32{}
+	edge_map := map[[2][3]float32]bool{}
+	for _, face := range faces {
+		for i := 0; i < len(face); i++ {
+			a, b := face[i], face[(i+1)%len(face)]
+			pa, pb := verts[a], verts[b]
+			if pa == pb {
+				continue // (zero length)
+			} else if pb[0] < pa[0] || (pb[0] == pa[0] && (pb[1] < pa[1] || (pb[1] == pa[1] && pb[2] < pa[2]))) {
+				pa, pb = pb, pa
+			}
+			if key := [2][3]float32{pa, pb}; !edge_map[key] {
+				edge_map[key] = true
+				edges = append(edges, []uint32{a, b})
+			}
+		}
+	}
+	return edges
+}
+
+// ----------------------------------------------------------------------------
+// Quadric & Priority Queue
+// ----------------------------------------------------------------------------
+
+type quadric [10]float64 // symmetric 4x4 matrix : a2 ab ac ad b2 bc bd c2 cd d2
+
+func new_plane_quadric(normal *V3d, point [3]float32, weight float64) quadric {
+	a, b, c := float64(normal[0]), float64(normal[1]), float64(normal[2])
+	d := -(a*float64(point[0]) + b*float64(point[1]) + c*float64(point[2]))
+	return quadric{a * a * weight, a * b * weight, a * c * weight, a * d * weight,
+		b * b * weight, b * c * weight, b * d * weight, c * c * weight, c * d * weight, d * d * weight}
+}
+
+func (self *quadric) add(q2 *quadric) {
+	for i := 0; i < 10; i++ {
+		self[i] += q2[i]
+	}
+}
+
+func (self *quadric) evaluate(p [3]float32) float64 {
+	x, y, z := float64(p[0]), float64(p[1]), float64(p[2])
+	return math.Abs(self[0]*x*x + 2*self[1]*x*y + 2*self[2]*x*z + 2*self[3]*x + self[4]*y*y + 2*self[5]*y*z + 2*self[6]*y + self[7]*z*z + 2*self[8]*z + self[9])
+}
+
+type collapse struct {
+	cost     float64 // quadric error after the collapse
+	from, to uint32  // vertex 'from' is moved onto vertex 'to'
+	from_ver uint32  // versions of the vertices, when the cost was calculated
+	to_ver   uint32  //
+	reversed bool    // true, if it's the other direction of an invalid collapse
+}
+
+type collapse_queue []collapse // min-heap by cost
+
+func (self collapse_queue) Len() int            { return len(self) }
+func (self collapse_queue) Less(i, j int) bool  { return self[i].cost < self[j].cost }
+func (self collapse_queue) Swap(i, j int)       { self[i], self[j] = self[j], self[i] }
+func (self *collapse_queue) Push(x interface{}) { *self = append(*self, x.(collapse)) }
+func (self *collapse_queue) Pop() interface{} {
+	old := *self
+	c := old[len(old)-1]
+	*self = old[:len(old)-1]
+	return c
+}
@@ -0,0 +1,23 @@
+package g3d
+
+import (
+	"testing"
+)
+
+func TestSimplifyRebuildsEdgesAndTangents(t *testing.T) {
+	g := NewGeometryIcosphere(1, 3)
+	g.BuildTangents()
+	g.Simplify(200)
+	if len(g.faces) > 200 {
+		t.Errorf("%d faces remaining (expected 200 or less)", len(g.faces))
+	}
+	check_wireframe_of_faces(t, g)
+	if !g.HasTangents() {
+		t.Errorf("tangents were not built again (%d tangents for %d vertices)", len(g.tans), len(g.verts))
+	}
+	g = NewGeometryIcosphere(1, 3)
+	g.Simplify(200)
+	if len(g.tans) != 0 {
+		t.Errorf("tangents were built without being requested")
+	}
+}
@@ -0,0 +1,344 @@
+package g3d
+
+import (
+	"math"
+	"strings"
+
+	"github.com/go4orward/gigl/common"
+)
+
+// ----------------------------------------------------------------------------
+// Subdivision Surfaces (Catmull-Clark & Loop)
+// ----------------------------------------------------------------------------
+
+func (self *Geometry) Subdivide(scheme string, levels int) *Geometry {
+	// Subdivide the faces 'levels' times, to build a smooth surface from a coarse mesh.
+	// 'scheme' : "CATMULL_CLARK" (quads from any polygon), or "LOOP" (triangles, after triangulation)
+	// Vertices at the same position are welded, so that the surface is not broken on UV seams.
+	// Border edges (and the edges shared by more than two faces) are kept sharp as cubic B-spline curves,
+	//   with the corners (vertices of a single face) fixed.
+	// Texture UVs are interpolated linearly (keeping PER_VERT or PER_FACE layout), normals are calculated again,
+	//   and the edges are rebuilt along the subdivided faces (for wireframe).
+	scheme = strings.ToUpper(strings.ReplaceAll(scheme, "-", "_"))
+	if scheme != "CATMULL_CLARK" && scheme != "LOOP" {
+		common.Logger.Warn("Failed to Subdivide() : invalid scheme '%s' (use 'CATMULL_CLARK' or 'LOOP')\n", scheme)
+		return self
+	}
+	tmode, nmode := self.get_texture_mode(), self.get_normal_mode()
+	m := self.new_subdiv_mesh(scheme == "LOOP", tmode)
+	for level := 0; level < levels; level++ {
+		if scheme == "LOOP" {
+			m = m.subdivide_loop()
+		} else {
+			m = m.subdivide_catmull_clark()
+		}
+	}
+	// rebuild the geometry
+	self.verts, self.faces, self.edges = m.points, m.faces, get_edges_of_faces(m.points, m.faces)
+	self.tuvs, self.norms, self.tans = [][]float32{}, [][3]float32{}, nil
+	if m.fuvs != nil {
+		self.tuvs = m.fuvs // PER_FACE
+	}
+	if nmode == "VERTEX" {
+		self.norms = self.get_vertex_normals()
+	}
+	if tmode == "VERTEX" {
+		self.split_vertices_for_face_uvs() // back to PER_VERT (with new vertices only on UV seams)
+	}
+	if nmode == "FACE" {
+		self.BuildNormalsForFace()
+	}
+	if len(self.fgrps) > 0 {
+		first_face := make([]int, len(m.forig)+1) // index of the first new face of each original face
+		for _, fidx := range m.forig {
+			first_face[fidx+1]++
+		}
+		for fidx := 1; fidx < len(first_face); fidx++ {
+			first_face[fidx] += first_face[fidx-1]
+		}
+		for i, fgrp := range self.fgrps {
+			self.fgrps[i] = [2]int{first_face[fgrp[0]], first_face[fgrp[1]]}
+		}
+	}
+	self.Clear(false, true, true)
+	return self
+}
+
+type subdiv_mesh struct {
+	points [][3]float32 // positions of the (welded) vertices
+	faces  [][]uint32   // faces
+	fuvs   [][]float32  // texture UVs of the face corners (nil, if no texture)
+	forig  []int        // index of the original face of each face
+}
+
+type subdiv_edge struct {
+	v     [2]uint32 // vertices of the edge
+	faces []int     // faces sharing the edge
+	pidx  uint32    // index of the new edge point
+}
+
+func (self *Geometry) new_subdiv_mesh(triangulate bool, tmode string) *subdiv_mesh {
+	m := subdiv_mesh{}
+	// weld the vertices at the same position
+	pidx_list := make([]uint32, len(self.verts))
+	positions := map[[3]float32]uint32{}
+	for vidx, xyz := range self.verts {
+		pidx, ok := positions[xyz]
+		if !ok {
+			pidx = uint32(len(m.points))
+			positions[xyz] = pidx
+			m.points = append(m.points, xyz)
+		}
+		pidx_list[vidx] = pidx
+	}
+	if tmode != "" {
+		m.fuvs = [][]float32{}
+	}
+	get_corner_uv := func(fidx int, i int) []float32 {
+		if tmode == "FACE" {
+			return self.tuvs[fidx][2*i : 2*i+2]
+		}
+		return self.tuvs[self.faces[fidx][i]][0:2]
+	}
+	for fidx, face := range self.faces {
+		if len(face) < 3 {
+			continue
+		}
+		corners := [][]int{}
+		if triangulate && len(face) > 3 {
+			for _, t := range self.GetFaceTriangles(fidx) {
+				corners = append(corners, []int{find_corner(face, t[0]), find_corner(face, t[1]), find_corner(face, t[2])})
+			}
+		} else {
+			corners = append(corners, make([]int, len(face)))
+			for i := range face {
+				corners[0][i] = i
+			}
+		}
+		for _, c := range corners {
+			new_face := make([]uint32, len(c))
+			for i, ci := range c {
+				new_face[i] = pidx_list[face[ci]]
+			}
+			m.faces = append(m.faces, new_face)
+			m.forig = append(m.forig, fidx)
+			if m.fuvs != nil {
+				uvs := make([]float32, 0, 2*len(c))
+				for _, ci := range c {
+					uvs = append(uvs, get_corner_uv(fidx, ci)...)
+				}
+				m.fuvs = append(m.fuvs, uvs)
+			}
+		}
+	}
+	return &m
+}
+
+func find_corner(face []uint32, vidx uint32) int {
+	for i := 0; i < len(face); i++ {
+		if face[i] == vidx {
+			return i
+		}
+	}
+	return 0
+}
+
+func (self *subdiv_mesh) build_edges() ([]*subdiv_edge, map[[2]uint32]*subdiv_edge) {
+	// Collect the edges of the faces (in the order of their appearance)
+	edge_list := []*subdiv_edge{}
+	edge_map := map[[2]uint32]*subdiv_edge{}
+	for fidx, face := range self.faces {
+		for i := 0; i < len(face); i++ {
+			key := get_edge_key(face[i], face[(i+1)%len(face)])
+			edge, ok := edge_map[key]
+			if !ok {
+				edge = &subdiv_edge{v: key}
+				edge_map[key] = edge
+				edge_list = append(edge_list, edge)
+			}
+			edge.faces = append(edge.faces, fidx)
+		}
+	}
+	return edge_list, edge_map
+}
+
+func (self *subdiv_mesh) get_vertex_points(edge_list []*subdiv_edge, smooth_rule func(vidx int, neighbors []uint32, faces []int) [3]float32) [][3]float32 {
+	// New positions of the original vertices, with the rules for sharp edges & corners
+	npoints := len(self.points)
+	vert_edges := make([][]*subdiv_edge, npoints)
+	vert_faces := make([][]int, npoints)
+	for _, edge := range edge_list {
+		vert_edges[edge.v[0]] = append(vert_edges[edge.v[0]], edge)
+		vert_edges[edge.v[1]] = append(vert_edges[edge.v[1]], edge)
+	}
+	for fidx, face := range self.faces {
+		for i, vidx := range face {
+			if find_corner(face, vidx) == i { // count only once
+				vert_faces[vidx] = append(vert_faces[vidx], fidx)
+			}
+		}
+	}
+	points := make([][3]float32, npoints)
+	for vidx := 0; vidx < npoints; vidx++ {
+		v := self.points[vidx]
+		neighbors, sharp_neighbors := []uint32{}, []uint32{}
+		for _, edge := range vert_edges[vidx] {
+			other := edge.v[0] + edge.v[1] - uint32(vidx)
+			neighbors = append(neighbors, other)
+			if len(edge.faces) != 2 {
+				sharp_neighbors = append(sharp_neighbors, other)
+			}
+		}
+		switch {
+		case len(vert_faces[vidx]) == 0: // not on the surface
+			points[vidx] = v
+		case len(sharp_neighbors) == 0: // smooth
+			points[vidx] = smooth_rule(vidx, neighbors, vert_faces[vidx])
+		case len(sharp_neighbors) == 2 && len(vert_faces[vidx]) > 1: // on a sharp edge (cubic B-spline)
+			a, b := self.points[sharp_neighbors[0]], self.points[sharp_neighbors[1]]
+			for k := 0; k < 3; k++ {
+				points[vidx][k] = (6*v[k] + a[k] + b[k]) / 8
+			}
+		default: // corner
+			points[vidx] = v
+		}
+	}
+	return points
+}
+
+func (self *subdiv_mesh) subdivide_catmull_clark() *subdiv_mesh {
+	m := subdiv_mesh{forig: make([]int, 0, len(self.faces)*4)}
+	edge_list, edge_map := self.build_edges()
+	npoints := len(self.points)
+	// face points
+	face_points := make([][3]float32, len(self.faces))
+	for fidx, face := range self.faces {
+		for _, vidx := range face {
+			for k := 0; k < 3; k++ {
+				face_points[fidx][k] += self.points[vidx][k] / float32(len(face))
+			}
+		}
+	}
+	// edge points
+	edge_points := make([][3]float32, len(edge_list))
+	for eidx, edge := range edge_list {
+		edge.pidx = uint32(npoints + len(self.faces) + eidx)
+		v0, v1 := self.points[edge.v[0]], self.points[edge.v[1]]
+		if len(edge.faces) == 2 {
+			f0, f1 := face_points[edge.faces[0]], face_points[edge.faces[1]]
+			for k := 0; k < 3; k++ {
+				edge_points[eidx][k] = (v0[k] + v1[k] + f0[k] + f1[k]) / 4
+			}
+		} else { // sharp edge
+			for k := 0; k < 3; k++ {
+				edge_points[eidx][k] = (v0[k] + v1[k]) / 2
+			}
+		}
+	}
+	// vertex points : (Q + 2R + (n-3)V) / n
+	vertex_points := self.get_vertex_points(edge_list, func(vidx int, neighbors []uint32, faces []int) [3]float32 {
+		v, n := self.points[vidx], float32(len(neighbors))
+		q, r := [3]float32{}, [3]float32{}
+		for _, fidx := range faces {
+			for k := 0; k < 3; k++ {
+				q[k] += face_points[fidx][k] / float32(len(faces))
+			}
+		}
+		for _, nidx := range neighbors {
+			for k := 0; k < 3; k++ {
+				r[k] += (v[k] + self.points[nidx][k]) / 2 / n
+			}
+		}
+		return [3]float32{(q[0] + 2*r[0] + (n-3)*v[0]) / n, (q[1] + 2*r[1] + (n-3)*v[1]) / n, (q[2] + 2*r[2] + (n-3)*v[2]) / n}
+	})
+	m.points = append(append(vertex_points, face_points...), edge_points...)
+	// faces : a quad for each corner of the faces
+	if self.fuvs != nil {
+		m.fuvs = make([][]float32, 0, len(self.faces)*4)
+	}
+	for fidx, face := range self.faces {
+		fpidx := uint32(npoints + fidx)
+		n := len(face)
+		for i := 0; i < n; i++ {
+			prev, next := face[(i+n-1)%n], face[(i+1)%n]
+			enext, eprev := edge_map[get_edge_key(face[i], next)].pidx, edge_map[get_edge_key(prev, face[i])].pidx
+			m.faces = append(m.faces, []uint32{face[i], enext, fpidx, eprev})
+			m.forig = append(m.forig, self.forig[fidx])
+			if m.fuvs != nil {
+				uvs := self.fuvs[fidx]
+				uv, uv_next, uv_prev := uvs[2*i:2*i+2], uvs[2*((i+1)%n):2*((i+1)%n)+2], uvs[2*((i+n-1)%n):2*((i+n-1)%n)+2]
+				uv_center := [2]float32{}
+				for j := 0; j < n; j++ {
+					uv_center[0] += uvs[2*j+0] / float32(n)
+					uv_center[1] += uvs[2*j+1] / float32(n)
+				}
+				m.fuvs = append(m.fuvs, []float32{uv[0], uv[1], (uv[0] + uv_next[0]) / 2, (uv[1] + uv_next[1]) / 2,
+					uv_center[0], uv_center[1], (uv[0] + uv_prev[0]) / 2, (uv[1] + uv_prev[1]) / 2})
+			}
+		}
+	}
+	return &m
+}
+
+func (self *subdiv_mesh) subdivide_loop() *subdiv_mesh {
+	m := subdiv_mesh{forig: make([]int, 0, len(self.faces)*4)}
+	edge_list, edge_map := self.build_edges()
+	npoints := len(self.points)
+	// edge points : 3/8 (v0 + v1) + 1/8 (opposite vertices)
+	edge_points := make([][3]float32, len(edge_list))
+	for eidx, edge := range edge_list {
+		edge.pidx = uint32(npoints + eidx)
+		v0, v1 := self.points[edge.v[0]], self.points[edge.v[1]]
+		if len(edge.faces) == 2 {
+			f0, f1 := self.faces[edge.faces[0]], self.faces[edge.faces[1]]
+			o0 := self.points[f0[0]+f0[1]+f0[2]-edge.v[0]-edge.v[1]]
+			o1 := self.points[f1[0]+f1[1]+f1[2]-edge.v[0]-edge.v[1]]
+			for k := 0; k < 3; k++ {
+				edge_points[eidx][k] = 3*(v0[k]+v1[k])/8 + (o0[k]+o1[k])/8
+			}
+		} else { // sharp edge
+			for k := 0; k < 3; k++ {
+				edge_points[eidx][k] = (v0[k] + v1[k]) / 2
+			}
+		}
+	}
+	// vertex points : (1 - n*beta) V + beta * (sum of neighbors)
+	vertex_points := self.get_vertex_points(edge_list, func(vidx int, neighbors []uint32, faces []int) [3]float32 {
+		v, n := self.points[vidx], float64(len(neighbors))
+		c := 3.0/8.0 + math.Cos(2*math.Pi/n)/4
+		beta := float32((5.0/8.0 - c*c) / n)
+		p := [3]float32{}
+		for k := 0; k < 3; k++ {
+			p[k] = (1 - float32(n)*beta) * v[k]
+		}
+		for _, nidx := range neighbors {
+			for k := 0; k < 3; k++ {
+				p[k] += beta * self.points[nidx][k]
+			}
+		}
+		return p
+	})
+	m.points = append(vertex_points, edge_points...)
+	// faces : 4 triangles for each triangle
+	if self.fuvs != nil {
+		m.fuvs = make([][]float32, 0, len(self.faces)*4)
+	}
+	for fidx, face := range self.faces {
+		a, b, c := face[0], face[1], face[2]
+		eab, ebc, eca := edge_map[get_edge_key(a, b)].pidx, edge_map[get_edge_key(b, c)].pidx, edge_map[get_edge_key(c, a)].pidx
+		m.faces = append(m.faces, []uint32{a, eab, eca}, []uint32{eab, b, ebc}, []uint32{eca, ebc, c}, []uint32{eab, ebc, eca})
+		m.forig = append(m.forig, self.forig[fidx], self.forig[fidx], self.forig[fidx], self.forig[fidx])
+		if m.fuvs != nil {
+			uv := self.fuvs[fidx]
+			uab := []float32{(uv[0] + uv[2]) / 2, (uv[1] + uv[3]) / 2}
+			ubc := []float32{(uv[2] + uv[4]) / 2, (uv[3] + uv[5]) / 2}
+			uca := []float32{(uv[4] + uv[0]) / 2, (uv[5] + uv[1]) / 2}
+			m.fuvs = append(m.fuvs,
+				[]float32{uv[0], uv[1], uab[0], uab[1], uca[0], uca[1]},
+				[]float32{uab[0], uab[1], uv[2], uv[3], ubc[0], ubc[1]},
+				[]float32{uca[0], uca[1], ubc[0], ubc[1], uv[4], uv[5]},
+				[]float32{uab[0], uab[1], ubc[0], ubc[1], uca[0], uca[1]})
+		}
+	}
+	return &m
+}
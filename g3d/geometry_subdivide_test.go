@@ -0,0 +1,74 @@
+package g3d
+
+import (
+	"testing"
+)
+
+func TestSubdivideRebuildsEdges(t *testing.T) {
+	tests := []struct {
+		name      string
+		geometry  *Geometry
+		scheme    string
+		levels    int
+		faces     int
+		edges     int
+		triangles bool
+	}{
+		{"cube (without edges) by Catmull-Clark", NewGeometryCube(1, 1, 1), "CATMULL_CLARK", 1, 24, 48, false},
+		{"cube by Catmull-Clark (2 levels)", NewGeometryCube(1, 1, 1), "CATMULL_CLARK", 2, 96, 192, false},
+		{"scheme in lower case", NewGeometryCube(1, 1, 1), "catmull_clark", 1, 24, 48, false},
+		{"icosahedron by Loop", NewGeometryIcosphere(1, 0), "LOOP", 1, 80, 120, true},
+		{"cube (triangulated) by Loop", NewGeometryCube(1, 1, 1), "Loop", 1, 48, 72, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := tt.geometry.Subdivide(tt.scheme, tt.levels)
+			if len(g.faces) != tt.faces || len(g.edges) != tt.edges {
+				t.Errorf("%d faces & %d edges, while expecting %d faces & %d edges", len(g.faces), len(g.edges), tt.faces, tt.edges)
+			}
+			check_wireframe_of_faces(t, g)
+		})
+	}
+	g := NewGeometryCube(1, 1, 1)
+	if g.Subdivide("UNKNOWN", 1); len(g.faces) != 6 {
+		t.Errorf("unknown scheme should not change the geometry (%d faces)", len(g.faces))
+	}
+}
+
+func check_wireframe_of_faces(t *testing.T, g *Geometry) {
+	// Edges should be the boundaries of the faces (by their positions), without duplicates and without zero length
+	t.Helper()
+	get_key := func(a uint32, b uint32) [2][3]float32 {
+		pa, pb := g.verts[a], g.verts[b]
+		if pb[0] < pa[0] || (pb[0] == pa[0] && (pb[1] < pa[1] || (pb[1] == pa[1] && pb[2] < pa[2]))) {
+			pa, pb = pb, pa
+		}
+		return [2][3]float32{pa, pb}
+	}
+	face_edges := map[[2][3]float32]bool{}
+	for _, face := range g.faces {
+		for i := range face {
+			if a, b := face[i], face[(i+1)%len(face)]; g.verts[a] != g.verts[b] {
+				face_edges[get_key(a, b)] = true
+			}
+		}
+	}
+	seen := map[[2][3]float32]bool{}
+	for eidx, edge := range g.edges {
+		if len(edge) != 2 || int(edge[0]) >= len(g.verts) || int(edge[1]) >= len(g.verts) {
+			t.Fatalf("invalid edge %d : %v", eidx, edge)
+		}
+		key := get_key(edge[0], edge[1])
+		if g.verts[edge[0]] == g.verts[edge[1]] {
+			t.Errorf("edge %d %v has zero length", eidx, edge)
+		} else if !face_edges[key] {
+			t.Errorf("edge %d %v is not on the boundary of any face", eidx, edge)
+		} else if seen[key] {
+			t.Errorf("edge %d %v is duplicated", eidx, edge)
+		}
+		seen[key] = true
+	}
+	if len(seen) != len(face_edges) {
+		t.Errorf("%d edges for %d boundary edges of the faces", len(seen), len(face_edges))
+	}
+}
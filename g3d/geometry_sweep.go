@@ -0,0 +1,383 @@
+package g3d
+
+import (
+	"math"
+
+	"github.com/go4orward/gigl/common"
+	"github.com/go4orward/gigl/g2d"
+)
+
+// ----------------------------------------------------------------------------
+// Sweep (along a path, with rotation-minimizing frames)
+// ----------------------------------------------------------------------------
+
+func NewGeometrySweep(profile [][2]float32, closed_profile bool, path [][3]float32, closed_path bool) *Geometry {
+	// Sweep the 2D profile along the 3D path (polyline), where X & Y of the profile are the side & up directions
+	//   of the path (with up direction starting from Z-axis, and rotating as little as possible along the path).
+	// Faces are on the right side of the profile, so that they face outward for a closed counter-clockwise profile
+	//   (note that a closed profile is made counter-clockwise, and capped at both ends of an open path).
+	// Texture UVs are given for each vertex, with U across the profile (0 ~ 1),
+	//   and V along the path (by the path length, in the unit of the profile length).
+	geometry := NewGeometry()
+	frames := get_sweep_frames(path, closed_path)
+	if len(profile) < 2 || len(frames) < 2 {
+		common.Logger.Warn("Failed to NewGeometrySweep() : invalid profile or path\n")
+		return geometry
+	}
+	if closed_profile && get_profile_area(profile) < 0 {
+		reversed := make([][2]float32, len(profile))
+		for i, p := range profile {
+			reversed[len(profile)-1-i] = p
+		}
+		profile = reversed
+	}
+	geometry.add_sweep_sides(profile, closed_profile, frames, get_profile_length(profile, closed_profile))
+	if closed_profile && !closed_path {
+		face := make([]uint32, len(profile))
+		for i := range face {
+			face[i] = uint32(i)
+		}
+		geometry.add_sweep_caps(profile, [][]uint32{face}, frames)
+	}
+	return geometry
+}
+
+func NewGeometryExtrudeAlongPath(shape *g2d.Geometry, path [][3]float32, closed_path bool) *Geometry {
+	// Extrude the faces of the 2D shape (with holes, if any) along the 3D path, just like NewGeometrySweep(),
+	//   by sweeping the borders of the faces, and using the faces themselves as the caps (of an open path).
+	geometry := NewGeometry()
+	frames := get_sweep_frames(path, closed_path)
+	verts, faces := shape.GetVertices(), shape.GetFaces()
+	loops := get_border_loops(faces)
+	if len(loops) == 0 || len(frames) < 2 {
+		common.Logger.Warn("Failed to NewGeometryExtrudeAlongPath() : invalid shape or path\n")
+		return geometry
+	}
+	vscale := float32(0) // (V of texture UVs in the unit of the length of the first border)
+	for _, loop := range loops {
+		profile := make([][2]float32, len(loop))
+		for i, vidx := range loop {
+			profile[i] = verts[vidx]
+		}
+		if vscale == 0 {
+			vscale = get_profile_length(profile, true)
+		}
+		geometry.add_sweep_sides(profile, true, frames, vscale)
+	}
+	if !closed_path {
+		geometry.add_sweep_caps(verts, faces, frames)
+	}
+	return geometry
+}
+
+func NewGeometryTube(path [][3]float32, radius float32, nsides int, closed_path bool) *Geometry {
+	// Tube of the radius around the 3D path (with the caps at both ends of an open path)
+	profile := make([][2]float32, nsides)
+	for i := 0; i < nsides; i++ {
+		rad := math.Pi * 2 * float64(i) / float64(nsides)
+		profile[i] = [2]float32{radius * float32(math.Cos(rad)), radius * float32(math.Sin(rad))}
+	}
+	return NewGeometrySweep(profile, true, path, closed_path)
+}
+
+func NewGeometryLathe(profile [][2]float32, nsegments int, angle_in_degree float32) *Geometry {
+	// Revolve the 2D profile (R & Z) around Z-axis, by the angle (360 for a full revolution).
+	//   Faces are on the right side of the profile, so that they face outward for the profile going up.
+	// Texture UVs are given for each vertex, with U around Z-axis (0 ~ 1) and V along the profile (0 ~ 1).
+	geometry := NewGeometry()
+	if len(profile) < 2 || nsegments < 1 {
+		common.Logger.Warn("Failed to NewGeometryLathe() : invalid profile\n")
+		return geometry
+	}
+	plength, plen := get_profile_length(profile, false), uint32(len(profile))
+	for i := 0; i <= nsegments; i++ { // (vertices on the seam are duplicated, for texture UVs)
+		rad := float64(angle_in_degree) * InRadian * float64(i) / float64(nsegments)
+		cosA, sinA := float32(math.Cos(rad)), float32(math.Sin(rad))
+		length := float32(0)
+		for j, p := range profile {
+			if j > 0 {
+				length += get_distance_2d(p, profile[j-1])
+			}
+			geometry.AddVertex([3]float32{p[0] * cosA, p[0] * sinA, p[1]})
+			geometry.AddTextureUV([]float32{float32(i) / float32(nsegments), length / plength})
+		}
+	}
+	for i := uint32(0); i < uint32(nsegments); i++ {
+		for j := uint32(0); j+1 < plen; j++ {
+			a, b, c, d := i*plen+j, (i+1)*plen+j, (i+1)*plen+j+1, i*plen+j+1
+			switch {
+			case profile[j][0] == 0 && profile[j+1][0] == 0: // on the axis
+			case profile[j][0] == 0:
+				geometry.AddFace([]uint32{a, c, d})
+			case profile[j+1][0] == 0:
+				geometry.AddFace([]uint32{a, b, d})
+			default:
+				geometry.AddFace([]uint32{a, b, c, d})
+			}
+		}
+	}
+	return geometry
+}
+
+func GetCatmullRomPath(points [][3]float32, nsegments int, closed bool) [][3]float32 {
+	// Smooth path through the points (by centripetal Catmull-Rom spline), with 'nsegments' for each span
+	if len(points) < 3 || nsegments < 2 {
+		return points
+	}
+	return common.NewCurveCatmullRom(points, closed).Subdivide(nsegments)
+}
+
+// ----------------------------------------------------------------------------
+// Sweep Frames
+// ----------------------------------------------------------------------------
+
+type sweep_frame struct {
+	position V3d     //
+	tangent  V3d     // direction of the path
+	side     V3d     // X-axis of the profile
+	up       V3d     // Y-axis of the profile
+	bend     V3d     // direction of the bending (zero, if it's straight)
+	miter    float32 // scale along the bending direction (to keep the width of the sweep at the corner)
+	distance float32 // distance along the path
+}
+
+func get_sweep_frames(path [][3]float32, closed bool) []sweep_frame {
+	// Frames along the path (by the double reflection method of Wang et al.), where the frame of the first point
+	//   is repeated at the end of a closed path (after distributing the twist of the frames along the path).
+	points := [][3]float32{}
+	for _, p := range path {
+		if len(points) == 0 || NewV3dBySub(p, points[len(points)-1]).Length() > 1e-6 {
+			points = append(points, p)
+		}
+	}
+	if closed && len(points) > 2 && NewV3dBySub(points[0], points[len(points)-1]).Length() <= 1e-6 {
+		points = points[:len(points)-1]
+	}
+	n := len(points)
+	if n < 2 || (closed && n < 3) {
+		return nil
+	}
+	nsegs := n - 1
+	if closed {
+		nsegs = n
+	}
+	dirs := make([]V3d, nsegs)
+	for i := range dirs {
+		dirs[i] = *NewV3dBySub(points[(i+1)%n], points[i]).Normalize()
+	}
+	frames := make([]sweep_frame, n, n+1)
+	for i := range frames {
+		f := &frames[i]
+		f.position, f.miter = V3d(points[i]), 1
+		if i > 0 {
+			f.distance = frames[i-1].distance + NewV3dBySub(points[i], points[i-1]).Length()
+		}
+		if !closed && (i == 0 || i == n-1) {
+			f.tangent = dirs[get_min_int(i, nsegs-1)]
+			continue
+		}
+		din, dout := dirs[(i+nsegs-1)%nsegs], dirs[i%nsegs]
+		f.tangent = *din.Clone().Add(&dout)
+		if f.tangent.Length() < 1e-6 { // turning back
+			f.tangent = dout
+			continue
+		}
+		f.tangent.Normalize()
+		f.bend = *dout.Clone().Add(din.Clone().Scale(-1, -1, -1))
+		if f.bend.Length() > 1e-6 {
+			f.bend.Normalize()
+			f.miter = 1 / float32(math.Max(float64(f.tangent.Dot(&din)), 0.25))
+		}
+	}
+	// initial frame (with 'up' as close to Z-axis as possible)
+	side := frames[0].tangent.Cross(&V3d{0, 0, 1})
+	if side.Length() < 1e-3 {
+		side = frames[0].tangent.Cross(&V3d{0, 1, 0})
+	}
+	frames[0].side = *side.Normalize()
+	// rotation-minimizing frames
+	get_next_side := func(f *sweep_frame, next_position *V3d, next_tangent *V3d) V3d {
+		v1 := *NewV3dBySub(*next_position, f.position)
+		c1 := v1.Dot(&v1)
+		rL := *f.side.Clone().Add(v1.Clone().Scale(-2/c1*v1.Dot(&f.side), -2/c1*v1.Dot(&f.side), -2/c1*v1.Dot(&f.side)))
+		tL := *f.tangent.Clone().Add(v1.Clone().Scale(-2/c1*v1.Dot(&f.tangent), -2/c1*v1.Dot(&f.tangent), -2/c1*v1.Dot(&f.tangent)))
+		v2 := *next_tangent.Clone().Add(tL.Scale(-1, -1, -1))
+		if c2 := v2.Dot(&v2); c2 > 1e-12 {
+			rL.Add(v2.Clone().Scale(-2/c2*v2.Dot(&rL), -2/c2*v2.Dot(&rL), -2/c2*v2.Dot(&rL)))
+		}
+		return *rL.Add(next_tangent.Clone().Scale(-next_tangent.Dot(&rL), -next_tangent.Dot(&rL), -next_tangent.Dot(&rL))).Normalize()
+	}
+	for i := 0; i+1 < n; i++ {
+		frames[i+1].side = get_next_side(&frames[i], &frames[i+1].position, &frames[i+1].tangent)
+	}
+	if closed { // distribute the twist (between the last frame and the first one) along the path
+		last := frames[n-1]
+		total := last.distance + NewV3dBySub(points[0], points[n-1]).Length()
+		side := get_next_side(&last, &frames[0].position, &frames[0].tangent)
+		t0, s0 := frames[0].tangent, frames[0].side
+		twist := math.Atan2(float64(side.Cross(&s0).Dot(&t0)), float64(side.Dot(&s0)))
+		for i := range frames {
+			f := &frames[i]
+			rad := twist * float64(f.distance/total)
+			cosA, sinA := float32(math.Cos(rad)), float32(math.Sin(rad))
+			ts := f.tangent.Cross(&f.side)
+			f.side = *f.side.Clone().Scale(cosA, cosA, cosA).Add(ts.Scale(sinA, sinA, sinA)).Normalize()
+		}
+		frames = append(frames, frames[0])
+		frames[n].distance = total
+	}
+	for i := range frames {
+		frames[i].up = *frames[i].side.Cross(&frames[i].tangent)
+	}
+	return frames
+}
+
+func (self *sweep_frame) get_point(p [2]float32) [3]float32 {
+	// Position of the profile point, scaled along the bending direction at the corner
+	offset := self.side.Clone().Scale(p[0], p[0], p[0]).Add(self.up.Clone().Scale(p[1], p[1], p[1]))
+	if self.miter != 1 {
+		k := (self.miter - 1) * offset.Dot(&self.bend)
+		offset.Add(self.bend.Clone().Scale(k, k, k))
+	}
+	return *offset.Add(&self.position)
+}
+
+// ----------------------------------------------------------------------------
+// Sweep Faces
+// ----------------------------------------------------------------------------
+
+func (self *Geometry) add_sweep_sides(profile [][2]float32, closed_profile bool, frames []sweep_frame, vscale float32) {
+	// Add the faces of the profile swept along the frames (with PER_VERT texture UVs)
+	length := get_profile_length(profile, closed_profile)
+	ncols := len(profile)
+	if closed_profile { // (vertices on the seam are duplicated, for texture UVs)
+		ncols++
+	}
+	start := uint32(len(self.verts))
+	for _, f := range frames {
+		plength := float32(0)
+		for j := 0; j < ncols; j++ {
+			p := profile[j%len(profile)]
+			if j > 0 {
+				plength += get_distance_2d(p, profile[j-1])
+			}
+			self.AddVertex(f.get_point(p))
+			self.AddTextureUV([]float32{plength / length, f.distance / vscale})
+		}
+	}
+	for i := uint32(0); i+1 < uint32(len(frames)); i++ {
+		for j := uint32(0); j+1 < uint32(ncols); j++ {
+			a, b := start+i*uint32(ncols)+j, start+(i+1)*uint32(ncols)+j
+			self.AddFace([]uint32{a, b, b + 1, a + 1})
+		}
+	}
+}
+
+func (self *Geometry) add_sweep_caps(verts [][2]float32, faces [][]uint32, frames []sweep_frame) {
+	// Add the faces (of the counter-clockwise profile) as the caps at both ends of the path
+	//   (with PER_VERT texture UVs by the bounding box of the faces)
+	bmin, bmax := [2]float32{math.MaxFloat32, math.MaxFloat32}, [2]float32{-math.MaxFloat32, -math.MaxFloat32}
+	for _, p := range verts {
+		for k := 0; k < 2; k++ {
+			bmin[k], bmax[k] = float32(math.Min(float64(bmin[k]), float64(p[k]))), float32(math.Max(float64(bmax[k]), float64(p[k])))
+		}
+	}
+	for k, f := range []sweep_frame{frames[0], frames[len(frames)-1]} {
+		start := uint32(len(self.verts))
+		for _, p := range verts {
+			self.AddVertex(f.get_point(p))
+			self.AddTextureUV([]float32{(p[0] - bmin[0]) / (bmax[0] - bmin[0]), (p[1] - bmin[1]) / (bmax[1] - bmin[1])})
+		}
+		for _, face := range faces {
+			cap_face := make([]uint32, len(face))
+			for i, vidx := range face {
+				if k == 0 { // counter-clockwise profile is facing backward
+					cap_face[i] = start + vidx
+				} else {
+					cap_face[len(face)-1-i] = start + vidx
+				}
+			}
+			self.AddFace(cap_face)
+		}
+	}
+}
+
+func get_border_loops(faces [][]uint32) [][]uint32 {
+	// Loops of the border edges (used by only one face) of the faces, following the direction of the faces
+	used := map[[2]uint32]bool{}
+	for _, face := range faces {
+		for i := range face {
+			used[[2]uint32{face[i], face[(i+1)%len(face)]}] = true
+		}
+	}
+	next, order := map[uint32]uint32{}, []uint32{}
+	for _, face := range faces {
+		for i := range face {
+			a, b := face[i], face[(i+1)%len(face)]
+			if !used[[2]uint32{b, a}] {
+				next[a] = b
+				order = append(order, a)
+			}
+		}
+	}
+	loops := [][]uint32{}
+	for _, vidx := range order {
+		if _, ok := next[vidx]; !ok {
+			continue // already in a loop
+		}
+		loop := []uint32{}
+		for v := vidx; ; {
+			loop = append(loop, v)
+			v_next := next[v]
+			delete(next, v)
+			if _, ok := next[v_next]; !ok || v_next == vidx {
+				break
+			}
+			v = v_next
+		}
+		if len(loop) >= 3 {
+			loops = append(loops, loop)
+		}
+	}
+	return loops
+}
+
+func get_profile_length(profile [][2]float32, closed bool) float32 {
+	length := float32(0)
+	for i := 1; i < len(profile); i++ {
+		length += get_distance_2d(profile[i], profile[i-1])
+	}
+	if closed {
+		length += get_distance_2d(profile[0], profile[len(profile)-1])
+	}
+	if length == 0 {
+		return 1
+	}
+	return length
+}
+
+func get_profile_area(profile [][2]float32) float32 {
+	area := float32(0)
+	for i, j := 0, len(profile)-1; i < len(profile); j, i = i, i+1 {
+		area += (profile[j][0]*profile[i][1] - profile[i][0]*profile[j][1]) / 2
+	}
+	return area
+}
+
+func get_distance_2d(a [2]float32, b [2]float32) float32 {
+	return float32(math.Hypot(float64(b[0]-a[0]), float64(b[1]-a[1])))
+}
+
+func get_min_int(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func get_max_int(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,75 @@
+package g3d
+
+import (
+	"math"
+	"testing"
+)
+
+func TestUpdateVertices(t *testing.T) {
+	// Updated vertices should be found in the data buffers, whether the buffer for faces
+	//   is the same as the one for vertex points (PER_VERT or no normals) or duplicated for each face.
+	tests := []struct {
+		name     string
+		build    func(g *Geometry)
+		per_face bool
+	}{
+		{"without normals", func(g *Geometry) {}, false},
+		{"normals for vertex", func(g *Geometry) { g.BuildNormalsForVertex() }, false},
+		{"normals for face", func(g *Geometry) { g.BuildNormalsForFace() }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGeometrySphere(1, 8, 4)
+			tt.build(g)
+			g.BuildDataBuffers(true, false, true)
+			if aliased := &g.dbuffer_fpoint[0] == &g.dbuffer_vpoint[0]; aliased == tt.per_face {
+				t.Fatalf("buffer for faces is aliased (%t) to the buffer for vertex points", aliased)
+			}
+			version := g.dbuffer_fpoint_tracker.GetVersion()
+			g.UpdateVertices(0, [][3]float32{{0, 0, 2}, {0, 0, 3}})
+			pinfo := g.dbuffer_fpoint_info
+			for fidx, face := range g.faces {
+				for i, vidx := range face {
+					pos := int(vidx) * pinfo[0]
+					if tt.per_face {
+						pos = g.get_fpoint_new_vidx(fidx, i) * pinfo[0]
+					}
+					if xyz := g.dbuffer_fpoint[pos : pos+3]; xyz[0] != g.verts[vidx][0] || xyz[1] != g.verts[vidx][1] || xyz[2] != g.verts[vidx][2] {
+						t.Fatalf("vertex %d of face %d is %v in the buffer (expected %v)", vidx, fidx, xyz, g.verts[vidx])
+					}
+				}
+			}
+			if ranges, all := g.dbuffer_fpoint_tracker.GetChangesSince(version); all || len(ranges) == 0 {
+				t.Errorf("changes of the buffer for faces were not tracked (%v, %t)", ranges, all)
+			}
+		})
+	}
+}
+
+func TestGeometryExamplesWithTooFewSides(t *testing.T) {
+	// Segment counts less than 3 should be clamped to 3 (without panic)
+	tests := []struct {
+		name     string
+		geometry func(nsides int) *Geometry
+	}{
+		{"torus", func(n int) *Geometry { return NewGeometryTorus(1, 0.2, n, n) }},
+		{"cone", func(n int) *Geometry { return NewGeometryCone(1, 0, 1, n) }},
+		{"capsule", func(n int) *Geometry { return NewGeometryCapsule(0.5, 1, n, 2) }},
+		{"arrow", func(n int) *Geometry { return NewGeometryArrow(1, 0.1, 0.3, 0.2, n) }},
+		{"disc", func(n int) *Geometry { return NewGeometryDisc(0.5, 1, n) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := tt.geometry(3)
+			for _, nsides := range []int{0, 1, 2} {
+				g := tt.geometry(nsides)
+				if len(g.GetVertices()) != len(expected.GetVertices()) || len(g.GetFaces()) != len(expected.GetFaces()) {
+					t.Errorf("%d sides : %s (expected %s)", nsides, g.Summary(), expected.Summary())
+				}
+			}
+		})
+	}
+	if g := NewGeometryCapsule(0.5, 1, 3, 0); len(g.GetFaces()) == 0 || math.IsNaN(float64(g.GetVertices()[0][2])) {
+		t.Errorf("capsule without rings : %s", g.Summary())
+	}
+}
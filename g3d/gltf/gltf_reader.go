@@ -0,0 +1,662 @@
+package gltf
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"math"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/go4orward/gigl/common"
+	"github.com/go4orward/gigl/g3d"
+)
+
+// ----------------------------------------------------------------------------
+// glTF 2.0 Model
+// ----------------------------------------------------------------------------
+
+// Model is the set of meshes, nodes and materials read from a glTF (JSON) or GLB (binary) file.
+// Each primitive of a mesh becomes a geometry with PER_VERT texture UVs & normals
+// (or PER_FACE normals, if the primitive has no normals), and each node keeps its own
+// transformation to be used as the model matrix of its SceneObject (see NewSceneObject()).
+// Skins, animations, morph targets and cameras are ignored.
+
+type Model struct {
+	Scenes    [][]int     // root nodes of each scene
+	Scene     int         // index of the default scene
+	Nodes     []*Node     //
+	Meshes    []*Mesh     //
+	Materials []*Material //
+}
+
+type Node struct {
+	Name     string         //
+	Mesh     int            // index of the mesh (-1 if none)
+	Children []int          // indices of the child nodes
+	Matrix   common.Matrix4 // local transformation ('matrix', or 'translation' * 'rotation' * 'scale')
+}
+
+type Mesh struct {
+	Name       string       //
+	Primitives []*Primitive //
+}
+
+type Primitive struct {
+	Geometry *g3d.Geometry // faces (triangles) or edges (lines)
+	Material int           // index of the material (-1 if none)
+}
+
+type Material struct {
+	Name        string     //
+	BaseColor   [4]float32 // 'baseColorFactor' (converted from linear to sRGB color space)
+	AlphaMode   string     // "OPAQUE", "MASK" or "BLEND"
+	DoubleSided bool       //
+	image_name  string     // name of the base color texture image (with the extension of its format)
+	image_bytes []byte     // base color texture image
+}
+
+func (self *Model) Summary() string {
+	summary := fmt.Sprintf("glTF Model with %d scenes %d nodes %d meshes %d materials\n", len(self.Scenes), len(self.Nodes), len(self.Meshes), len(self.Materials))
+	for _, mesh := range self.Meshes {
+		for _, primitive := range mesh.Primitives {
+			summary += fmt.Sprintf("  %-12q material %-3d %s", mesh.Name, primitive.Material, primitive.Geometry.String())
+		}
+	}
+	return summary
+}
+
+// ----------------------------------------------------------------------------
+// Loading glTF or GLB
+// ----------------------------------------------------------------------------
+
+func LoadFile(filepath_gltf string) (*Model, error) {
+	// Load glTF or GLB file (with its buffers & images) from local file system
+	return Load(os.DirFS(filepath.Dir(filepath_gltf)), filepath.Base(filepath_gltf))
+}
+
+func Load(fsys fs.FS, path_gltf string) (*Model, error) {
+	// Load glTF or GLB file (with its buffers & images) from the file system (like 'embed.FS'),
+	//   where the URIs of external buffers and images are relative to the glTF file.
+	data, err := fs.ReadFile(fsys, path_gltf)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load glTF : %v", err)
+	}
+	return parse(data, fsys, path.Dir(path_gltf))
+}
+
+func Read(r io.Reader) (*Model, error) {
+	// Read glTF or GLB, with buffers & images embedded (in GLB binary chunk or as 'data:' URIs)
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read glTF : %v", err)
+	}
+	return parse(data, nil, "")
+}
+
+// ----------------------------------------------------------------------------
+// Parsing glTF JSON & GLB chunks
+// ----------------------------------------------------------------------------
+
+type gltf_document struct {
+	Scene  *int `json:"scene"`
+	Scenes []struct {
+		Nodes []int `json:"nodes"`
+	} `json:"scenes"`
+	Nodes []struct {
+		Name        string    `json:"name"`
+		Mesh        *int      `json:"mesh"`
+		Children    []int     `json:"children"`
+		Matrix      []float32 `json:"matrix"`
+		Translation []float32 `json:"translation"`
+		Rotation    []float32 `json:"rotation"`
+		Scale       []float32 `json:"scale"`
+	} `json:"nodes"`
+	Meshes []struct {
+		Name       string `json:"name"`
+		Primitives []struct {
+			Attributes map[string]int `json:"attributes"`
+			Indices    *int           `json:"indices"`
+			Material   *int           `json:"material"`
+			Mode       *int           `json:"mode"`
+		} `json:"primitives"`
+	} `json:"meshes"`
+	Materials []struct {
+		Name                 string `json:"name"`
+		AlphaMode            string `json:"alphaMode"`
+		DoubleSided          bool   `json:"doubleSided"`
+		PbrMetallicRoughness struct {
+			BaseColorFactor  []float32 `json:"baseColorFactor"`
+			BaseColorTexture *struct {
+				Index int `json:"index"`
+			} `json:"baseColorTexture"`
+		} `json:"pbrMetallicRoughness"`
+	} `json:"materials"`
+	Textures []struct {
+		Source *int `json:"source"`
+	} `json:"textures"`
+	Images []struct {
+		Name       string `json:"name"`
+		URI        string `json:"uri"`
+		MimeType   string `json:"mimeType"`
+		BufferView *int   `json:"bufferView"`
+	} `json:"images"`
+	Accessors   []gltf_accessor `json:"accessors"`
+	BufferViews []struct {
+		Buffer     int `json:"buffer"`
+		ByteOffset int `json:"byteOffset"`
+		ByteLength int `json:"byteLength"`
+		ByteStride int `json:"byteStride"`
+	} `json:"bufferViews"`
+	Buffers []struct {
+		URI        string `json:"uri"`
+		ByteLength int    `json:"byteLength"`
+	} `json:"buffers"`
+}
+
+type gltf_accessor struct {
+	BufferView    *int   `json:"bufferView"`
+	ByteOffset    int    `json:"byteOffset"`
+	ComponentType int    `json:"componentType"`
+	Normalized    bool   `json:"normalized"`
+	Count         int    `json:"count"`
+	Type          string `json:"type"`
+	Sparse        *struct {
+		Count   int `json:"count"`
+		Indices struct {
+			BufferView    int `json:"bufferView"`
+			ByteOffset    int `json:"byteOffset"`
+			ComponentType int `json:"componentType"`
+		} `json:"indices"`
+		Values struct {
+			BufferView int `json:"bufferView"`
+			ByteOffset int `json:"byteOffset"`
+		} `json:"values"`
+	} `json:"sparse"`
+}
+
+type gltf_parser struct {
+	doc     gltf_document //
+	buffers [][]byte      //
+	fsys    fs.FS         // file system for external URIs (nil if not available)
+	dir     string        // directory of the glTF file (for relative URIs)
+}
+
+func parse(data []byte, fsys fs.FS, dir string) (*Model, error) {
+	parser := gltf_parser{fsys: fsys, dir: dir}
+	json_chunk, bin_chunk, err := split_glb_chunks(data)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read GLB : %v", err)
+	}
+	if err := json.Unmarshal(json_chunk, &parser.doc); err != nil {
+		return nil, fmt.Errorf("Failed to read glTF : %v", err)
+	}
+	if err := parser.load_buffers(bin_chunk); err != nil {
+		return nil, fmt.Errorf("Failed to read glTF : %v", err)
+	}
+	model, err := parser.build_model()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read glTF : %v", err)
+	}
+	return model, nil
+}
+
+func split_glb_chunks(data []byte) ([]byte, []byte, error) {
+	// GLB has 12-byte header ('glTF', version, length), followed by JSON chunk and optional BIN chunk,
+	//   where each chunk has its length and type before its data. (Anything else is glTF JSON.)
+	if len(data) < 12 || string(data[0:4]) != "glTF" {
+		return data, nil, nil
+	}
+	if version := binary.LittleEndian.Uint32(data[4:8]); version != 2 {
+		return nil, nil, fmt.Errorf("unsupported version %d", version)
+	}
+	length := int(binary.LittleEndian.Uint32(data[8:12]))
+	if length > len(data) {
+		return nil, nil, fmt.Errorf("truncated data")
+	}
+	var json_chunk, bin_chunk []byte = nil, nil
+	for pos := 12; pos+8 <= length; {
+		chunk_length := int(binary.LittleEndian.Uint32(data[pos : pos+4]))
+		chunk_type := string(data[pos+4 : pos+8])
+		if pos+8+chunk_length > length {
+			return nil, nil, fmt.Errorf("truncated chunk")
+		}
+		chunk := data[pos+8 : pos+8+chunk_length]
+		switch chunk_type {
+		case "JSON":
+			json_chunk = chunk
+		case "BIN\x00":
+			bin_chunk = chunk
+		}
+		pos += 8 + chunk_length
+	}
+	if json_chunk == nil {
+		return nil, nil, fmt.Errorf("JSON chunk not found")
+	}
+	return json_chunk, bin_chunk, nil
+}
+
+func (self *gltf_parser) load_buffers(bin_chunk []byte) error {
+	self.buffers = make([][]byte, len(self.doc.Buffers))
+	for i, buffer := range self.doc.Buffers {
+		var data []byte
+		var err error
+		if buffer.URI == "" { // the BIN chunk of GLB
+			if i != 0 || bin_chunk == nil {
+				return fmt.Errorf("buffer %d without URI", i)
+			}
+			data = bin_chunk
+		} else if data, err = self.read_uri(buffer.URI); err != nil {
+			return err
+		}
+		if len(data) < buffer.ByteLength {
+			return fmt.Errorf("buffer %d too short (%d < %d)", i, len(data), buffer.ByteLength)
+		}
+		self.buffers[i] = data
+	}
+	return nil
+}
+
+func (self *gltf_parser) read_uri(uri string) ([]byte, error) {
+	// Read 'data:' URI with base64 encoding, or the file relative to the glTF file
+	if strings.HasPrefix(uri, "data:") {
+		idx := strings.Index(uri, ";base64,")
+		if idx < 0 {
+			return nil, fmt.Errorf("unsupported data URI")
+		}
+		return base64.StdEncoding.DecodeString(uri[idx+len(";base64,"):])
+	} else if self.fsys == nil {
+		return nil, fmt.Errorf("external URI %q cannot be read without file system (use Load())", uri)
+	}
+	unescaped, err := url.PathUnescape(uri)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URI %q", uri)
+	}
+	return fs.ReadFile(self.fsys, path.Join(self.dir, unescaped))
+}
+
+func (self *gltf_parser) build_model() (*Model, error) {
+	doc := &self.doc
+	model := Model{Scene: 0}
+	if doc.Scene != nil {
+		model.Scene = *doc.Scene
+	}
+	for _, scene := range doc.Scenes {
+		model.Scenes = append(model.Scenes, scene.Nodes)
+	}
+	for i, n := range doc.Nodes {
+		node := Node{Name: n.Name, Mesh: -1, Children: n.Children}
+		if n.Mesh != nil {
+			node.Mesh = *n.Mesh
+		}
+		node.Matrix.SetIdentity()
+		if len(n.Matrix) == 16 {
+			copy(node.Matrix.GetElements()[:], n.Matrix) // COLUMN-MAJOR (just like glTF)
+		} else {
+			t, r, s := [3]float32{0, 0, 0}, [4]float32{0, 0, 0, 1}, [3]float32{1, 1, 1}
+			copy(t[:], n.Translation)
+			copy(r[:], n.Rotation)
+			copy(s[:], n.Scale)
+			translation := common.NewMatrix4().SetTranslation(t[0], t[1], t[2])
+			scaling := common.NewMatrix4().SetScaling(s[0], s[1], s[2])
+			node.Matrix.SetMultiplyMatrices(translation, get_rotation_matrix(r), scaling)
+		}
+		for _, child := range node.Children {
+			if child < 0 || child >= len(doc.Nodes) || child == i {
+				return nil, fmt.Errorf("invalid child %d of node %d", child, i)
+			}
+		}
+		model.Nodes = append(model.Nodes, &node)
+	}
+	for i, m := range doc.Meshes {
+		mesh := Mesh{Name: m.Name}
+		for j, p := range m.Primitives {
+			mode := 4 // TRIANGLES by default
+			if p.Mode != nil {
+				mode = *p.Mode
+			}
+			primitive := Primitive{Material: -1}
+			if p.Material != nil {
+				primitive.Material = *p.Material
+			}
+			var err error
+			primitive.Geometry, err = self.build_geometry(p.Attributes, p.Indices, mode)
+			if err != nil {
+				return nil, fmt.Errorf("%v (mesh %d primitive %d)", err, i, j)
+			} else if primitive.Geometry == nil {
+				common.Logger.Warn("glTF primitive mode %d ignored (mesh %d primitive %d)\n", mode, i, j)
+				continue
+			}
+			mesh.Primitives = append(mesh.Primitives, &primitive)
+		}
+		model.Meshes = append(model.Meshes, &mesh)
+	}
+	for i, m := range doc.Materials {
+		material := Material{Name: m.Name, BaseColor: [4]float32{1, 1, 1, 1}, AlphaMode: m.AlphaMode, DoubleSided: m.DoubleSided}
+		if material.AlphaMode == "" {
+			material.AlphaMode = "OPAQUE"
+		}
+		copy(material.BaseColor[:], m.PbrMetallicRoughness.BaseColorFactor)
+		for k := 0; k < 3; k++ {
+			material.BaseColor[k] = linear_to_srgb(material.BaseColor[k])
+		}
+		if tex := m.PbrMetallicRoughness.BaseColorTexture; tex != nil {
+			if err := self.load_texture_image(&material, tex.Index); err != nil {
+				common.Logger.Warn("Failed to load glTF texture : %v (material %d)\n", err, i) // base color is still usable
+			}
+		}
+		model.Materials = append(model.Materials, &material)
+	}
+	return &model, nil
+}
+
+func (self *gltf_parser) load_texture_image(material *Material, tidx int) error {
+	doc := &self.doc
+	if tidx < 0 || tidx >= len(doc.Textures) || doc.Textures[tidx].Source == nil {
+		return fmt.Errorf("invalid texture %d", tidx)
+	}
+	iidx := *doc.Textures[tidx].Source
+	if iidx < 0 || iidx >= len(doc.Images) {
+		return fmt.Errorf("invalid image %d", iidx)
+	}
+	image := doc.Images[iidx]
+	mime_type := image.MimeType
+	if image.BufferView != nil {
+		data, _, err := self.get_buffer_view(*image.BufferView)
+		if err != nil {
+			return err
+		}
+		material.image_bytes = data
+	} else {
+		data, err := self.read_uri(image.URI)
+		if err != nil {
+			return err
+		}
+		material.image_bytes = data
+		if strings.HasPrefix(image.URI, "data:") {
+			mime_type = strings.SplitN(strings.TrimPrefix(image.URI, "data:"), ";", 2)[0]
+		} else if mime_type == "" {
+			mime_type = "image/" + strings.TrimPrefix(strings.ToLower(path.Ext(image.URI)), ".")
+		}
+	}
+	// name of the image (with the extension of its format, to be decoded by MaterialTexture)
+	material.image_name = fmt.Sprintf("image%d", iidx)
+	if image.Name != "" {
+		material.image_name = image.Name
+	}
+	switch mime_type {
+	case "image/png":
+		material.image_name += ".png"
+	case "image/jpeg", "image/jpg":
+		material.image_name += ".jpg"
+	default:
+		material.image_bytes = nil
+		return fmt.Errorf("unsupported image format %q", mime_type)
+	}
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+// Building Geometry
+// ----------------------------------------------------------------------------
+
+func (self *gltf_parser) build_geometry(attributes map[string]int, indices *int, mode int) (*g3d.Geometry, error) {
+	pidx, ok := attributes["POSITION"]
+	if !ok {
+		return nil, fmt.Errorf("POSITION not found")
+	}
+	positions, ncomp, err := self.read_accessor(pidx)
+	if err != nil {
+		return nil, err
+	} else if ncomp != 3 {
+		return nil, fmt.Errorf("invalid POSITION type")
+	}
+	nverts := len(positions) / 3
+	vlist := make([]uint32, 0)
+	if indices != nil {
+		values, ncomp, err := self.read_accessor(*indices)
+		if err != nil {
+			return nil, err
+		} else if ncomp != 1 {
+			return nil, fmt.Errorf("invalid indices type")
+		}
+		for _, value := range values {
+			if value < 0 || int(value) >= nverts {
+				return nil, fmt.Errorf("index out of range (%v)", value)
+			}
+			vlist = append(vlist, uint32(value))
+		}
+	} else {
+		for i := 0; i < nverts; i++ {
+			vlist = append(vlist, uint32(i))
+		}
+	}
+	geometry := g3d.NewGeometry()
+	switch mode {
+	case 4: // TRIANGLES
+		for i := 0; i+2 < len(vlist); i += 3 {
+			add_triangle(geometry, vlist[i], vlist[i+1], vlist[i+2])
+		}
+	case 5: // TRIANGLE_STRIP (with alternating winding)
+		for i := 0; i+2 < len(vlist); i++ {
+			if i%2 == 0 {
+				add_triangle(geometry, vlist[i], vlist[i+1], vlist[i+2])
+			} else {
+				add_triangle(geometry, vlist[i+1], vlist[i], vlist[i+2])
+			}
+		}
+	case 6: // TRIANGLE_FAN
+		for i := 1; i+1 < len(vlist); i++ {
+			add_triangle(geometry, vlist[0], vlist[i], vlist[i+1])
+		}
+	case 1: // LINES
+		for i := 0; i+1 < len(vlist); i += 2 {
+			geometry.AddEdge([]uint32{vlist[i], vlist[i+1]})
+		}
+	case 2, 3: // LINE_LOOP, LINE_STRIP
+		if mode == 2 && len(vlist) > 2 {
+			vlist = append(vlist, vlist[0])
+		}
+		if len(vlist) >= 2 {
+			geometry.AddEdge(vlist)
+		}
+	default: // POINTS
+		return nil, nil
+	}
+	vertices := make([][3]float32, nverts)
+	for i := range vertices {
+		vertices[i] = [3]float32{float32(positions[i*3+0]), float32(positions[i*3+1]), float32(positions[i*3+2])}
+	}
+	geometry.SetVertices(vertices)
+	if len(geometry.GetFaces()) == 0 {
+		return geometry, nil // edges only
+	}
+	// texture UVs (with V=0 at the top of the image, just like GIGL)
+	if tidx, ok := attributes["TEXCOORD_0"]; ok {
+		values, ncomp, err := self.read_accessor(tidx)
+		if err != nil {
+			return nil, err
+		} else if ncomp != 2 || len(values) != nverts*2 {
+			return nil, fmt.Errorf("invalid TEXCOORD_0")
+		}
+		tuvs := make([][]float32, nverts)
+		for i := range tuvs {
+			tuvs[i] = []float32{float32(values[i*2+0]), float32(values[i*2+1])}
+		}
+		geometry.SetTextureUVs(tuvs)
+	}
+	// normal vectors (or flat normals, as glTF requires, if not given)
+	if nidx, ok := attributes["NORMAL"]; ok {
+		values, ncomp, err := self.read_accessor(nidx)
+		if err != nil {
+			return nil, err
+		} else if ncomp != 3 || len(values) != nverts*3 {
+			return nil, fmt.Errorf("invalid NORMAL")
+		}
+		normals := make([][3]float32, nverts)
+		for i := range normals {
+			normals[i] = *g3d.NewV3d(float32(values[i*3+0]), float32(values[i*3+1]), float32(values[i*3+2])).Normalize()
+		}
+		geometry.SetNormals(normals)
+	} else {
+		geometry.BuildNormalsForFace()
+	}
+	return geometry, nil
+}
+
+func add_triangle(geometry *g3d.Geometry, i0 uint32, i1 uint32, i2 uint32) {
+	if i0 != i1 && i1 != i2 && i2 != i0 { // skip degenerate triangles
+		geometry.AddFace([]uint32{i0, i1, i2})
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Reading Accessors
+// ----------------------------------------------------------------------------
+
+var gltf_type_sizes = map[string]int{"SCALAR": 1, "VEC2": 2, "VEC3": 3, "VEC4": 4, "MAT2": 4, "MAT3": 9, "MAT4": 16}
+
+var gltf_component_sizes = map[int]int{5120: 1, 5121: 1, 5122: 2, 5123: 2, 5125: 4, 5126: 4}
+
+func (self *gltf_parser) get_buffer_view(bvidx int) ([]byte, int, error) {
+	// Get the data and the byte stride of the buffer view
+	if bvidx < 0 || bvidx >= len(self.doc.BufferViews) {
+		return nil, 0, fmt.Errorf("invalid buffer view %d", bvidx)
+	}
+	bv := self.doc.BufferViews[bvidx]
+	if bv.Buffer < 0 || bv.Buffer >= len(self.buffers) {
+		return nil, 0, fmt.Errorf("invalid buffer %d", bv.Buffer)
+	}
+	buffer := self.buffers[bv.Buffer]
+	if bv.ByteOffset < 0 || bv.ByteLength < 0 || bv.ByteOffset+bv.ByteLength > len(buffer) {
+		return nil, 0, fmt.Errorf("buffer view %d out of range", bvidx)
+	}
+	return buffer[bv.ByteOffset : bv.ByteOffset+bv.ByteLength], bv.ByteStride, nil
+}
+
+func (self *gltf_parser) read_accessor(aidx int) ([]float64, int, error) {
+	// Read all the values of the accessor (with 'normalized' & 'sparse' applied),
+	//   and return them with the number of components for each element.
+	if aidx < 0 || aidx >= len(self.doc.Accessors) {
+		return nil, 0, fmt.Errorf("invalid accessor %d", aidx)
+	}
+	accessor := self.doc.Accessors[aidx]
+	ncomp, csize := gltf_type_sizes[accessor.Type], gltf_component_sizes[accessor.ComponentType]
+	if ncomp == 0 || csize == 0 || accessor.Count < 0 {
+		return nil, 0, fmt.Errorf("invalid accessor %d", aidx)
+	}
+	values := make([]float64, accessor.Count*ncomp)
+	if accessor.BufferView != nil { // (all zeros, if no buffer view)
+		data, stride, err := self.get_buffer_view(*accessor.BufferView)
+		if err != nil {
+			return nil, 0, err
+		}
+		if stride == 0 {
+			stride = ncomp * csize
+		}
+		err = read_components(values, data, accessor.ByteOffset, stride, accessor.Count, ncomp, accessor.ComponentType, accessor.Normalized)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%v (accessor %d)", err, aidx)
+		}
+	}
+	if sparse := accessor.Sparse; sparse != nil && sparse.Count > 0 {
+		isize := gltf_component_sizes[sparse.Indices.ComponentType]
+		idata, _, err := self.get_buffer_view(sparse.Indices.BufferView)
+		if err != nil {
+			return nil, 0, err
+		}
+		vdata, _, err := self.get_buffer_view(sparse.Values.BufferView)
+		if err != nil {
+			return nil, 0, err
+		}
+		indices, sparse_values := make([]float64, sparse.Count), make([]float64, sparse.Count*ncomp)
+		err = read_components(indices, idata, sparse.Indices.ByteOffset, isize, sparse.Count, 1, sparse.Indices.ComponentType, false)
+		if err == nil {
+			err = read_components(sparse_values, vdata, sparse.Values.ByteOffset, ncomp*csize, sparse.Count, ncomp, accessor.ComponentType, accessor.Normalized)
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("%v (sparse accessor %d)", err, aidx)
+		}
+		for i, index := range indices {
+			if int(index) >= accessor.Count {
+				return nil, 0, fmt.Errorf("sparse index out of range (accessor %d)", aidx)
+			}
+			copy(values[int(index)*ncomp:int(index+1)*ncomp], sparse_values[i*ncomp:(i+1)*ncomp])
+		}
+	}
+	return values, ncomp, nil
+}
+
+func read_components(values []float64, data []byte, offset int, stride int, count int, ncomp int, ctype int, normalized bool) error {
+	csize := gltf_component_sizes[ctype]
+	if count > 0 && (offset < 0 || offset+(count-1)*stride+ncomp*csize > len(data)) {
+		return fmt.Errorf("accessor out of range")
+	}
+	for i := 0; i < count; i++ {
+		for k := 0; k < ncomp; k++ {
+			pos := offset + i*stride + k*csize
+			var value float64
+			switch ctype {
+			case 5120: // BYTE
+				value = float64(int8(data[pos]))
+				if normalized {
+					value = math.Max(value/127.0, -1.0)
+				}
+			case 5121: // UNSIGNED_BYTE
+				value = float64(data[pos])
+				if normalized {
+					value = value / 255.0
+				}
+			case 5122: // SHORT
+				value = float64(int16(binary.LittleEndian.Uint16(data[pos:])))
+				if normalized {
+					value = math.Max(value/32767.0, -1.0)
+				}
+			case 5123: // UNSIGNED_SHORT
+				value = float64(binary.LittleEndian.Uint16(data[pos:]))
+				if normalized {
+					value = value / 65535.0
+				}
+			case 5125: // UNSIGNED_INT
+				value = float64(binary.LittleEndian.Uint32(data[pos:]))
+			case 5126: // FLOAT
+				value = float64(math.Float32frombits(binary.LittleEndian.Uint32(data[pos:])))
+			}
+			values[i*ncomp+k] = value
+		}
+	}
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+// Utilities
+// ----------------------------------------------------------------------------
+
+func get_rotation_matrix(q [4]float32) *common.Matrix4 {
+	// Rotation matrix of the unit quaternion (x, y, z, w)
+	length := float32(math.Sqrt(float64(q[0]*q[0] + q[1]*q[1] + q[2]*q[2] + q[3]*q[3])))
+	if length == 0 {
+		return common.NewMatrix4()
+	}
+	x, y, z, w := q[0]/length, q[1]/length, q[2]/length, q[3]/length
+	return common.NewMatrix4().Set(
+		1-2*(y*y+z*z), 2*(x*y-z*w), 2*(x*z+y*w), 0,
+		2*(x*y+z*w), 1-2*(x*x+z*z), 2*(y*z-x*w), 0,
+		2*(x*z-y*w), 2*(y*z+x*w), 1-2*(x*x+y*y), 0,
+		0, 0, 0, 1)
+}
+
+func linear_to_srgb(c float32) float32 {
+	// glTF colors are in linear color space, while GIGL renders them as sRGB colors
+	if c <= 0.0031308 {
+		return c * 12.92
+	}
+	return float32(1.055*math.Pow(float64(c), 1/2.4) - 0.055)
+}
@@ -0,0 +1,164 @@
+package gltf
+
+import (
+	"github.com/go4orward/gigl"
+	"github.com/go4orward/gigl/common"
+	"github.com/go4orward/gigl/g2d"
+	"github.com/go4orward/gigl/g3d"
+)
+
+// ----------------------------------------------------------------------------
+// Building SceneObjects
+// ----------------------------------------------------------------------------
+
+func (self *Material) HasTexture() bool {
+	return self.image_bytes != nil
+}
+
+func (self *Material) NewMaterial(use_texture bool) gigl.GLMaterial {
+	// Create MaterialTexture for 'baseColorTexture' (if its image was loaded), or MaterialColors for 'baseColorFactor'
+	if use_texture && self.HasTexture() {
+		return g2d.NewMaterialTextureFromImageBytes(self.image_name, self.image_bytes)
+	}
+	return g2d.NewMaterialColors(self.BaseColor)
+}
+
+type scene_builder struct {
+	model       *Model                     //
+	rc          gigl.GLRenderingContext    //
+	materials   map[[2]int]gigl.GLMaterial // material for each (material index, texture)
+	shaders     map[int]gigl.GLShader      // shader for texture (1), color (0) or edges (2)
+	pivot_geom  *g3d.Geometry              // geometry of a single point (for the nodes without mesh)
+	built_geoms map[*g3d.Geometry]bool     // geometries with data buffers already built
+}
+
+func (self *Model) NewSceneObject(rc gigl.GLRenderingContext) *g3d.SceneObject {
+	// Create SceneObjects for the nodes of the default scene (keeping their hierarchy & transformations),
+	//   where the primitives of a mesh are added as the children of their node.
+	// If the scene has more than one root node, then they are added as the children of a new root.
+	scene_idx := self.Scene
+	if scene_idx < 0 || scene_idx >= len(self.Scenes) {
+		scene_idx = -1
+	}
+	roots := []int{}
+	if scene_idx >= 0 {
+		roots = self.Scenes[scene_idx]
+	} else { // no scene is given, so use all the nodes that are not children
+		is_child := make([]bool, len(self.Nodes))
+		for _, node := range self.Nodes {
+			for _, child := range node.Children {
+				is_child[child] = true
+			}
+		}
+		for nidx := range self.Nodes {
+			if !is_child[nidx] {
+				roots = append(roots, nidx)
+			}
+		}
+	}
+	builder := scene_builder{model: self, rc: rc, materials: map[[2]int]gigl.GLMaterial{}, shaders: map[int]gigl.GLShader{}, built_geoms: map[*g3d.Geometry]bool{}}
+	visiting := make([]bool, len(self.Nodes))
+	if len(roots) == 1 {
+		return builder.build_node(roots[0], visiting)
+	}
+	root := builder.new_pivot()
+	for _, nidx := range roots {
+		if child := builder.build_node(nidx, visiting); child != nil {
+			root.AddChild(child)
+		}
+	}
+	return root
+}
+
+func (self *scene_builder) build_node(nidx int, visiting []bool) *g3d.SceneObject {
+	if nidx < 0 || nidx >= len(self.model.Nodes) || visiting[nidx] {
+		common.Logger.Warn("glTF node %d ignored (invalid or cyclic)\n", nidx)
+		return nil
+	}
+	visiting[nidx] = true
+	defer func() { visiting[nidx] = false }()
+	node := self.model.Nodes[nidx]
+	var scnobj *g3d.SceneObject = nil
+	if node.Mesh >= 0 && node.Mesh < len(self.model.Meshes) {
+		for _, primitive := range self.model.Meshes[node.Mesh].Primitives {
+			if scnobj == nil {
+				scnobj = self.build_primitive(primitive)
+			} else {
+				scnobj.AddChild(self.build_primitive(primitive))
+			}
+		}
+	}
+	if scnobj == nil {
+		scnobj = self.new_pivot()
+	}
+	scnobj.GetModelMatrix().SetCopy(&node.Matrix)
+	for _, cidx := range node.Children {
+		if child := self.build_node(cidx, visiting); child != nil {
+			scnobj.AddChild(child)
+		}
+	}
+	return scnobj
+}
+
+func (self *scene_builder) build_primitive(primitive *Primitive) *g3d.SceneObject {
+	geometry := primitive.Geometry
+	if !self.built_geoms[geometry] {
+		geometry.BuildDataBuffers(true, true, true)
+		self.built_geoms[geometry] = true
+	}
+	var mtl *Material = nil
+	if primitive.Material >= 0 && primitive.Material < len(self.model.Materials) {
+		mtl = self.model.Materials[primitive.Material]
+	}
+	use_texture := mtl != nil && mtl.HasTexture() && geometry.HasTextureFor("")
+	key := [2]int{primitive.Material, 0}
+	if use_texture {
+		key[1] = 1
+	}
+	material, ok := self.materials[key]
+	if !ok {
+		if mtl != nil {
+			material = mtl.NewMaterial(use_texture)
+		} else {
+			material = g2d.NewMaterialColors("#ffffff") // default material of glTF
+		}
+		self.materials[key] = material
+	}
+	var scnobj *g3d.SceneObject = nil
+	if len(geometry.GetFaces()) > 0 {
+		scnobj = g3d.NewSceneObject(geometry, material, nil, nil, self.get_shader(key[1]))
+	} else {
+		scnobj = g3d.NewSceneObject(geometry, material, nil, self.get_shader(2), nil)
+	}
+	if mtl != nil && mtl.AlphaMode == "BLEND" {
+		scnobj.UseBlend = true // transparent material
+	}
+	return scnobj
+}
+
+func (self *scene_builder) get_shader(kind int) gigl.GLShader {
+	shader, ok := self.shaders[kind]
+	if !ok {
+		switch kind {
+		case 1:
+			shader = g3d.NewShader_NormalTexture(self.rc)
+		case 2:
+			shader = g3d.NewShader_ColorOnly(self.rc)
+		default:
+			shader = g3d.NewShader_NormalColor(self.rc)
+		}
+		self.shaders[kind] = shader
+	}
+	return shader
+}
+
+func (self *scene_builder) new_pivot() *g3d.SceneObject {
+	// SceneObject with nothing to render, only to transform its children
+	//   (Note that Renderer skips the children of a SceneObject without data buffers)
+	if self.pivot_geom == nil {
+		self.pivot_geom = g3d.NewGeometry()
+		self.pivot_geom.AddVertex([3]float32{0, 0, 0})
+		self.pivot_geom.BuildDataBuffers(true, false, false)
+	}
+	return g3d.NewSceneObject(self.pivot_geom, nil, nil, nil, nil)
+}
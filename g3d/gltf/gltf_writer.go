@@ -0,0 +1,507 @@
+package gltf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go4orward/gigl"
+	"github.com/go4orward/gigl/common"
+	"github.com/go4orward/gigl/g3d"
+)
+
+// ----------------------------------------------------------------------------
+// Exporting glTF or GLB
+// ----------------------------------------------------------------------------
+
+// Exporter writes the SceneObjects (with their children) as the nodes of a glTF scene,
+// where the faces, edges and vertices of a SceneObject are written as TRIANGLES, LINES and POINTS
+// (only if it has the shader for them), with the colors or the texture image of its material.
+// Instance poses are expected to have XYZ translation as their first 3 values (like 'NewShader_InstancePoseColor()'),
+// and they are written with 'EXT_mesh_gpu_instancing', or expanded into a child node for each instance.
+
+type Exporter struct {
+	Binary        bool                           // GLB (true), or glTF JSON with its buffer embedded as 'data:' URI (false)
+	GpuInstancing bool                           // instances with 'EXT_mesh_gpu_instancing' (true), or a node for each instance (false)
+	doc           gltf_output                    // glTF JSON to be written
+	bin           []byte                         // binary buffer
+	meshes        map[mesh_key]int               // mesh index of each SceneObject settings
+	materials     map[material_key]int           // material index for each (material, draw_mode, blend)
+	textures      map[gigl.GLMaterialTexture]int // texture index of each material
+	geometries    map[*g3d.Geometry]*geometry_accessors
+}
+
+type gltf_output struct {
+	Asset          map[string]string `json:"asset"`
+	ExtensionsUsed []string          `json:"extensionsUsed,omitempty"`
+	Scene          int               `json:"scene"`
+	Scenes         []map[string]any  `json:"scenes"`
+	Nodes          []map[string]any  `json:"nodes,omitempty"`
+	Meshes         []map[string]any  `json:"meshes,omitempty"`
+	Materials      []map[string]any  `json:"materials,omitempty"`
+	Textures       []map[string]any  `json:"textures,omitempty"`
+	Images         []map[string]any  `json:"images,omitempty"`
+	Accessors      []map[string]any  `json:"accessors,omitempty"`
+	BufferViews    []map[string]any  `json:"bufferViews,omitempty"`
+	Buffers        []map[string]any  `json:"buffers,omitempty"`
+}
+
+type mesh_key struct {
+	geometry *g3d.Geometry   //
+	material gigl.GLMaterial //
+	modes    [4]bool         // [1]:points, [2]:lines, [3]:triangles
+	blend    bool            //
+}
+
+type material_key struct {
+	material  gigl.GLMaterial //
+	draw_mode int             //
+	blend     bool            //
+}
+
+type geometry_accessors struct {
+	vert_position int            // positions of the vertices (for POINTS & LINES)
+	edge_indices  int            // LINES
+	face_attrs    map[string]int // POSITION, NORMAL & TEXCOORD_0 (for TRIANGLES)
+	face_indices  int            // TRIANGLES
+}
+
+func NewExporter(binary bool, gpu_instancing bool) *Exporter {
+	return &Exporter{Binary: binary, GpuInstancing: gpu_instancing}
+}
+
+func SaveFile(filepath_gltf string, scene *g3d.Scene) error {
+	// Save the scene as GLB or glTF (depending on the extension of the file), with 'EXT_mesh_gpu_instancing'
+	file, err := os.Create(filepath_gltf)
+	if err != nil {
+		return fmt.Errorf("Failed to save glTF : %v", err)
+	}
+	binary := strings.ToLower(filepath.Ext(filepath_gltf)) == ".glb"
+	if err = NewExporter(binary, true).WriteScene(file, scene); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return fmt.Errorf("Failed to save glTF : %v", err)
+	}
+	return nil
+}
+
+func (self *Exporter) WriteScene(w io.Writer, scene *g3d.Scene) error {
+	// Write all the SceneObjects of the scene (overlays are not included)
+	return self.Write(w, scene.GetSceneObjects()...)
+}
+
+func (self *Exporter) Write(w io.Writer, scnobjs ...*g3d.SceneObject) error {
+	// Write the SceneObjects (with their children) as the root nodes of a glTF scene
+	self.doc = gltf_output{Asset: map[string]string{"version": "2.0", "generator": "GIGL"}}
+	self.bin = []byte{}
+	self.meshes = map[mesh_key]int{}
+	self.materials = map[material_key]int{}
+	self.textures = map[gigl.GLMaterialTexture]int{}
+	self.geometries = map[*g3d.Geometry]*geometry_accessors{}
+	roots := []int{}
+	for _, scnobj := range scnobjs {
+		if scnobj != nil {
+			roots = append(roots, self.add_node(scnobj))
+		}
+	}
+	self.doc.Scenes = []map[string]any{{"nodes": roots}}
+	if len(self.bin) > 0 {
+		buffer := map[string]any{"byteLength": len(self.bin)}
+		if !self.Binary {
+			buffer["uri"] = "data:application/octet-stream;base64," + base64.StdEncoding.EncodeToString(self.bin)
+		}
+		self.doc.Buffers = []map[string]any{buffer}
+	}
+	json_chunk, err := json.Marshal(&self.doc)
+	if err != nil {
+		return fmt.Errorf("Failed to write glTF : %v", err)
+	}
+	if self.Binary {
+		_, err = w.Write(self.get_glb(json_chunk))
+	} else {
+		_, err = w.Write(json_chunk)
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to write glTF : %v", err)
+	}
+	return nil
+}
+
+func (self *Exporter) get_glb(json_chunk []byte) []byte {
+	// GLB header, JSON chunk (padded with spaces) and BIN chunk (padded with zeros)
+	for len(json_chunk)%4 != 0 {
+		json_chunk = append(json_chunk, ' ')
+	}
+	bin_chunk := self.bin
+	for len(bin_chunk)%4 != 0 {
+		bin_chunk = append(bin_chunk, 0)
+	}
+	length := 12 + 8 + len(json_chunk)
+	if len(bin_chunk) > 0 {
+		length += 8 + len(bin_chunk)
+	}
+	data := make([]byte, 0, length)
+	data = append(data, "glTF"...)
+	data = binary.LittleEndian.AppendUint32(data, 2)
+	data = binary.LittleEndian.AppendUint32(data, uint32(length))
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(json_chunk)))
+	data = append(data, "JSON"...)
+	data = append(data, json_chunk...)
+	if len(bin_chunk) > 0 {
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(bin_chunk)))
+		data = append(data, "BIN\x00"...)
+		data = append(data, bin_chunk...)
+	}
+	return data
+}
+
+// ----------------------------------------------------------------------------
+// Nodes & Meshes
+// ----------------------------------------------------------------------------
+
+func (self *Exporter) add_node(scnobj *g3d.SceneObject) int {
+	nidx := len(self.doc.Nodes)
+	node := map[string]any{}
+	self.doc.Nodes = append(self.doc.Nodes, node) // (children will be added after this node)
+	if m := scnobj.GetModelMatrix(); *m != *common.NewMatrix4() {
+		node["matrix"] = m.GetElements()[:] // COLUMN-MAJOR (just like glTF)
+	}
+	children := []int{}
+	if midx := self.get_mesh(scnobj); midx >= 0 {
+		count, stride, buffer := scnobj.GetInstanceBuffer()
+		if count > 0 && stride >= 3 && len(buffer) >= count*stride {
+			translations := make([]float32, count*3)
+			for i := 0; i < count; i++ {
+				copy(translations[i*3:i*3+3], buffer[i*stride:i*stride+3])
+			}
+			if self.GpuInstancing {
+				node["mesh"] = midx
+				attributes := map[string]int{"TRANSLATION": self.add_accessor_floats(translations, 3, 0, false)}
+				node["extensions"] = map[string]any{"EXT_mesh_gpu_instancing": map[string]any{"attributes": attributes}}
+				self.use_extension("EXT_mesh_gpu_instancing")
+			} else {
+				for i := 0; i < count; i++ {
+					children = append(children, len(self.doc.Nodes))
+					self.doc.Nodes = append(self.doc.Nodes, map[string]any{"mesh": midx, "translation": translations[i*3 : i*3+3]})
+				}
+			}
+		} else {
+			node["mesh"] = midx
+		}
+	}
+	for _, child := range scnobj.GetChildren() {
+		children = append(children, self.add_node(child))
+	}
+	if len(children) > 0 {
+		node["children"] = children
+	}
+	return nidx
+}
+
+func (self *Exporter) get_mesh(scnobj *g3d.SceneObject) int {
+	// Get the index of the mesh for the SceneObject (-1 if nothing to be rendered)
+	geometry, ok := scnobj.Geometry.(*g3d.Geometry)
+	if !ok {
+		if scnobj.Geometry != nil {
+			common.Logger.Warn("glTF export skipped the geometry of type %T\n", scnobj.Geometry)
+		}
+		return -1
+	}
+	key := mesh_key{geometry: geometry, material: scnobj.Material, blend: scnobj.UseBlend}
+	key.modes[1] = scnobj.VShader != nil && len(geometry.GetVertices()) > 0
+	key.modes[2] = scnobj.EShader != nil && len(get_edge_indices(geometry)) > 0
+	key.modes[3] = scnobj.FShader != nil && len(geometry.GetFaces()) > 0
+	if midx, ok := self.meshes[key]; ok {
+		return midx
+	} else if !key.modes[1] && !key.modes[2] && !key.modes[3] {
+		if scnobj.VShader != nil || scnobj.EShader != nil || scnobj.FShader != nil {
+			common.Logger.Warn("glTF export skipped the geometry without vertices/edges/faces for its shaders\n")
+		}
+		return -1
+	}
+	accessors := self.get_geometry_accessors(geometry)
+	primitives := []map[string]any{}
+	for _, draw_mode := range []int{3, 2, 1} {
+		if !key.modes[draw_mode] {
+			continue
+		}
+		var primitive map[string]any
+		switch draw_mode {
+		case 3:
+			primitive = map[string]any{"attributes": accessors.face_attrs, "indices": accessors.face_indices, "mode": 4}
+		case 2:
+			primitive = map[string]any{"attributes": map[string]int{"POSITION": accessors.vert_position}, "indices": accessors.edge_indices, "mode": 1}
+		case 1:
+			primitive = map[string]any{"attributes": map[string]int{"POSITION": accessors.vert_position}, "mode": 0}
+		}
+		use_texture := draw_mode == 3 && geometry.HasTextureFor("")
+		if midx := self.get_material(scnobj.Material, draw_mode, scnobj.UseBlend, use_texture); midx >= 0 {
+			primitive["material"] = midx
+		}
+		primitives = append(primitives, primitive)
+	}
+	midx := len(self.doc.Meshes)
+	self.doc.Meshes = append(self.doc.Meshes, map[string]any{"primitives": primitives})
+	self.meshes[key] = midx
+	return midx
+}
+
+func (self *Exporter) get_geometry_accessors(geometry *g3d.Geometry) *geometry_accessors {
+	if accessors, ok := self.geometries[geometry]; ok {
+		return accessors
+	}
+	accessors := geometry_accessors{vert_position: -1, edge_indices: -1, face_indices: -1}
+	verts, faces := geometry.GetVertices(), geometry.GetFaces()
+	tuvs, norms := geometry.GetTextureUVs(), geometry.GetNormals()
+	// POINTS & LINES, with the vertices of the geometry
+	positions := make([]float32, 0, len(verts)*3)
+	for _, v := range verts {
+		positions = append(positions, v[0], v[1], v[2])
+	}
+	if len(verts) > 0 {
+		accessors.vert_position = self.add_accessor_floats(positions, 3, 34962, true)
+	}
+	if indices := get_edge_indices(geometry); len(indices) > 0 {
+		accessors.edge_indices = self.add_accessor_indices(indices, 34963)
+	}
+	// TRIANGLES, with a new vertex for each face corner if texture UVs or normals are given PER_FACE
+	//   (just like BuildDataBuffers() does)
+	if len(faces) > 0 {
+		tuv_per_face, nor_per_face := geometry.HasTextureFor("FACE"), geometry.HasNormalFor("FACE")
+		tuv_per_vert, nor_per_vert := !tuv_per_face && geometry.HasTextureFor("VERTEX"), !nor_per_face && geometry.HasNormalFor("VERTEX")
+		per_corner := tuv_per_face || nor_per_face
+		face_positions, face_tuvs, face_norms, indices := positions, []float32{}, []float32{}, []uint32{}
+		if per_corner {
+			face_positions = []float32{}
+		} else {
+			for vidx := range verts {
+				if tuv_per_vert {
+					face_tuvs = append(face_tuvs, tuvs[vidx][0], tuvs[vidx][1])
+				}
+				if nor_per_vert {
+					face_norms = append(face_norms, get_unit_vector(norms[vidx])...)
+				}
+			}
+		}
+		for fidx, face := range faces {
+			triangles := geometry.GetFaceTriangles(fidx)
+			if !per_corner {
+				for _, triangle := range triangles {
+					indices = append(indices, triangle...)
+				}
+				continue
+			}
+			corner_start := uint32(len(face_positions) / 3)
+			corner := map[uint32]uint32{} // face corner of each vertex
+			for i := len(face) - 1; i >= 0; i-- {
+				corner[face[i]] = uint32(i)
+			}
+			for i, vidx := range face {
+				v := verts[vidx]
+				face_positions = append(face_positions, v[0], v[1], v[2])
+				if tuv_per_face && 2*i+1 < len(tuvs[fidx]) {
+					face_tuvs = append(face_tuvs, tuvs[fidx][2*i], tuvs[fidx][2*i+1])
+				} else if tuv_per_face {
+					face_tuvs = append(face_tuvs, 0, 0)
+				} else if tuv_per_vert {
+					face_tuvs = append(face_tuvs, tuvs[vidx][0], tuvs[vidx][1])
+				}
+				if nor_per_face {
+					face_norms = append(face_norms, get_unit_vector(norms[fidx])...)
+				} else if nor_per_vert {
+					face_norms = append(face_norms, get_unit_vector(norms[vidx])...)
+				}
+			}
+			for _, triangle := range triangles {
+				for _, vidx := range triangle {
+					indices = append(indices, corner_start+corner[vidx])
+				}
+			}
+		}
+		accessors.face_attrs = map[string]int{}
+		if per_corner {
+			accessors.face_attrs["POSITION"] = self.add_accessor_floats(face_positions, 3, 34962, true)
+		} else {
+			accessors.face_attrs["POSITION"] = accessors.vert_position
+		}
+		if len(face_norms) > 0 {
+			accessors.face_attrs["NORMAL"] = self.add_accessor_floats(face_norms, 3, 34962, false)
+		}
+		if len(face_tuvs) > 0 { // (with V=0 at the top of the image, just like GIGL)
+			accessors.face_attrs["TEXCOORD_0"] = self.add_accessor_floats(face_tuvs, 2, 34962, false)
+		}
+		accessors.face_indices = self.add_accessor_indices(indices, 34963)
+	}
+	self.geometries[geometry] = &accessors
+	return &accessors
+}
+
+// ----------------------------------------------------------------------------
+// Materials & Textures
+// ----------------------------------------------------------------------------
+
+func (self *Exporter) get_material(material gigl.GLMaterial, draw_mode int, blend bool, use_texture bool) int {
+	// Get the index of the material for the draw mode (-1 if not available)
+	if material == nil {
+		return -1
+	}
+	key := material_key{material: material, draw_mode: draw_mode, blend: blend}
+	if midx, ok := self.materials[key]; ok {
+		return midx
+	}
+	pbr := map[string]any{"metallicFactor": 0, "roughnessFactor": 1}
+	alpha := float32(1)
+	if mtex, ok := material.(gigl.GLMaterialTexture); ok {
+		if tidx := self.get_texture(mtex); use_texture && tidx >= 0 {
+			pbr["baseColorTexture"] = map[string]int{"index": tidx}
+		} else {
+			return -1
+		}
+	} else if mcolors, ok := material.(gigl.GLMaterialColors); ok {
+		c := mcolors.GetDrawModeColor(draw_mode)
+		pbr["baseColorFactor"] = []float32{srgb_to_linear(c[0]), srgb_to_linear(c[1]), srgb_to_linear(c[2]), c[3]}
+		alpha = c[3]
+	} else {
+		return -1
+	}
+	gmaterial := map[string]any{"pbrMetallicRoughness": pbr}
+	if blend || alpha < 1 {
+		gmaterial["alphaMode"] = "BLEND"
+	}
+	midx := len(self.doc.Materials)
+	self.doc.Materials = append(self.doc.Materials, gmaterial)
+	self.materials[key] = midx
+	return midx
+}
+
+func (self *Exporter) get_texture(mtex gigl.GLMaterialTexture) int {
+	// Get the index of the texture with the image pixels of the material (-1 if not loaded yet)
+	if tidx, ok := self.textures[mtex]; ok {
+		return tidx
+	}
+	tidx := -1
+	pixbuf, wh := mtex.GetTexturePixbuf(), mtex.GetTextureWH()
+	if pixbuf != nil && wh[0] > 0 && wh[1] > 0 && len(pixbuf) >= wh[0]*wh[1]*4 {
+		img := &image.NRGBA{Pix: pixbuf, Stride: wh[0] * 4, Rect: image.Rect(0, 0, wh[0], wh[1])}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			common.Logger.Warn("glTF export failed to encode texture image : %v\n", err)
+		} else {
+			bvidx := self.add_buffer_view(buf.Bytes(), 0)
+			self.doc.Images = append(self.doc.Images, map[string]any{"bufferView": bvidx, "mimeType": "image/png"})
+			tidx = len(self.doc.Textures)
+			self.doc.Textures = append(self.doc.Textures, map[string]any{"source": len(self.doc.Images) - 1})
+		}
+	} else {
+		common.Logger.Warn("glTF export skipped the texture not loaded (%s)\n", mtex.MaterialSummary())
+	}
+	self.textures[mtex] = tidx
+	return tidx
+}
+
+func (self *Exporter) use_extension(name string) {
+	for _, ext := range self.doc.ExtensionsUsed {
+		if ext == name {
+			return
+		}
+	}
+	self.doc.ExtensionsUsed = append(self.doc.ExtensionsUsed, name)
+}
+
+// ----------------------------------------------------------------------------
+// Buffer Views & Accessors
+// ----------------------------------------------------------------------------
+
+func (self *Exporter) add_buffer_view(data []byte, target int) int {
+	for len(self.bin)%4 != 0 { // (aligned to 4 bytes)
+		self.bin = append(self.bin, 0)
+	}
+	bview := map[string]any{"buffer": 0, "byteOffset": len(self.bin), "byteLength": len(data)}
+	if target > 0 {
+		bview["target"] = target
+	}
+	self.bin = append(self.bin, data...)
+	self.doc.BufferViews = append(self.doc.BufferViews, bview)
+	return len(self.doc.BufferViews) - 1
+}
+
+func (self *Exporter) add_accessor_floats(values []float32, ncomp int, target int, with_minmax bool) int {
+	data := make([]byte, 0, len(values)*4)
+	for _, value := range values {
+		data = binary.LittleEndian.AppendUint32(data, math.Float32bits(value))
+	}
+	accessor := map[string]any{"bufferView": self.add_buffer_view(data, target), "componentType": 5126,
+		"count": len(values) / ncomp, "type": []string{"", "SCALAR", "VEC2", "VEC3", "VEC4"}[ncomp]}
+	if with_minmax && len(values) >= ncomp { // (required for POSITION)
+		vmin, vmax := make([]float32, ncomp), make([]float32, ncomp)
+		copy(vmin, values[:ncomp])
+		copy(vmax, values[:ncomp])
+		for i := ncomp; i < len(values); i++ {
+			k := i % ncomp
+			if values[i] < vmin[k] {
+				vmin[k] = values[i]
+			} else if values[i] > vmax[k] {
+				vmax[k] = values[i]
+			}
+		}
+		accessor["min"], accessor["max"] = vmin, vmax
+	}
+	self.doc.Accessors = append(self.doc.Accessors, accessor)
+	return len(self.doc.Accessors) - 1
+}
+
+func (self *Exporter) add_accessor_indices(indices []uint32, target int) int {
+	data := make([]byte, 0, len(indices)*4)
+	for _, index := range indices {
+		data = binary.LittleEndian.AppendUint32(data, index)
+	}
+	accessor := map[string]any{"bufferView": self.add_buffer_view(data, target), "componentType": 5125,
+		"count": len(indices), "type": "SCALAR"}
+	self.doc.Accessors = append(self.doc.Accessors, accessor)
+	return len(self.doc.Accessors) - 1
+}
+
+func get_edge_indices(geometry *g3d.Geometry) []uint32 {
+	// Indices of LINES (pairs of vertices), from the edges of the geometry or from its data buffer for lines
+	//   (like the wireframe built by BuildDataBuffersForWireframe(), which doesn't keep the edges)
+	indices := []uint32{}
+	for _, edge := range geometry.GetEdges() {
+		for i := 1; i < len(edge); i++ {
+			indices = append(indices, edge[i-1], edge[i])
+		}
+	}
+	if len(indices) == 0 && geometry.GetIdxBufferCount(2) > 0 {
+		nverts := uint32(len(geometry.GetVertices()))
+		for _, vidx := range geometry.GetIdxBuffer(2) {
+			if vidx >= nverts {
+				return []uint32{} // (not the indices of the vertices)
+			}
+		}
+		indices = append(indices, geometry.GetIdxBuffer(2)...)
+	}
+	return indices
+}
+
+func get_unit_vector(v [3]float32) []float32 {
+	n := g3d.V3d(v)
+	return n.Normalize()[:]
+}
+
+func srgb_to_linear(c float32) float32 {
+	// GIGL colors are in sRGB color space, while glTF expects them in linear color space
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+	return float32(math.Pow((float64(c)+0.055)/1.055, 2.4))
+}
@@ -0,0 +1,51 @@
+package gltf
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go4orward/gigl/env/recording"
+	"github.com/go4orward/gigl/g3d"
+)
+
+func TestExportAndImport(t *testing.T) {
+	// SceneObjects exported should be imported back, including the wireframe without its edges
+	//   (whose lines are kept only in its data buffer)
+	rc := recording.NewRecordingRenderingContext(100, 100)
+	wireframe := g3d.NewSceneObject_CylinderWireframe(rc)
+	scnobjs := []*g3d.SceneObject{
+		g3d.NewSceneObject_CubeInstances(rc),
+		g3d.NewSceneObject_CubeWithTexture(rc),
+		wireframe,
+	}
+	for _, binary := range []bool{true, false} {
+		var buf bytes.Buffer
+		if err := NewExporter(binary, true).Write(&buf, scnobjs...); err != nil {
+			t.Fatalf("failed to export (binary:%t) : %v", binary, err)
+		}
+		model, err := Read(&buf)
+		if err != nil {
+			t.Fatalf("failed to import (binary:%t) : %v", binary, err)
+		}
+		if len(model.Meshes) != len(scnobjs) {
+			t.Fatalf("%d meshes imported for %d SceneObjects (binary:%t) :\n%s", len(model.Meshes), len(scnobjs), binary, model.Summary())
+		}
+		lines := model.Meshes[2].Primitives
+		wgeometry := wireframe.Geometry.(*g3d.Geometry)
+		if len(lines) != 1 || len(lines[0].Geometry.GetFaces()) != 0 {
+			t.Fatalf("wireframe imported as %d primitives (binary:%t) :\n%s", len(lines), binary, model.Summary())
+		}
+		nsegments := 0
+		for _, edge := range lines[0].Geometry.GetEdges() {
+			nsegments += len(edge) - 1
+		}
+		if nsegments != wgeometry.GetIdxBufferCount(2)/2 {
+			t.Errorf("wireframe imported with %d line segments (expected %d)", nsegments, wgeometry.GetIdxBufferCount(2)/2)
+		}
+		for i, mesh := range model.Meshes[:2] {
+			if len(mesh.Primitives) != 1 || len(mesh.Primitives[0].Geometry.GetFaces()) != 12 {
+				t.Errorf("cube %d imported as %d primitives (binary:%t) :\n%s", i, len(mesh.Primitives), binary, model.Summary())
+			}
+		}
+	}
+}
@@ -0,0 +1,367 @@
+package obj
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go4orward/gigl/common"
+	"github.com/go4orward/gigl/g3d"
+)
+
+// ----------------------------------------------------------------------------
+// Wavefront OBJ Model
+// ----------------------------------------------------------------------------
+
+// Model is a set of geometries read from an OBJ file, one for each group ('o' or 'g' or 'usemtl').
+// Since OBJ gives separate indices for positions, texture UVs and normals of each face corner,
+// vertices are duplicated for each unique combination of them, so that the geometries
+// have PER_VERT texture UVs and normals (as 'AddTextureUV()' & 'AddNormal()' expect).
+
+type Model struct {
+	Groups    []*Group             // geometries (in the order of the file)
+	Materials map[string]*Material // materials (read from 'mtllib' files by Load())
+	mtllibs   []string             // names of 'mtllib' files
+}
+
+type Group struct {
+	Name     string        // name of the object or group
+	Material string        // name of the material ('usemtl')
+	Geometry *g3d.Geometry //
+}
+
+type Material struct {
+	Name         string     //
+	DiffuseColor [4]float32 // 'Kd' with 'd' (or 1-'Tr') as alpha
+	DiffuseMap   string     // 'map_Kd' (path of the texture image, relative to the MTL file)
+	image_bytes  []byte     // texture image (read by Load())
+}
+
+func (self *Model) Summary() string {
+	summary := fmt.Sprintf("OBJ Model with %d groups %d materials\n", len(self.Groups), len(self.Materials))
+	for _, group := range self.Groups {
+		summary += fmt.Sprintf("  %-12q usemtl %-12q %s", group.Name, group.Material, group.Geometry.String())
+	}
+	return summary
+}
+
+// ----------------------------------------------------------------------------
+// Loading OBJ with MTL
+// ----------------------------------------------------------------------------
+
+func LoadFile(filepath_obj string) (*Model, error) {
+	// Load OBJ file (with its MTL files & texture images) from local file system
+	return Load(os.DirFS(filepath.Dir(filepath_obj)), filepath.Base(filepath_obj))
+}
+
+func Load(fsys fs.FS, path_obj string) (*Model, error) {
+	// Load OBJ file (with its MTL files & texture images) from the file system (like 'embed.FS'),
+	//   where the paths of MTL files and texture images are relative to the OBJ file.
+	file, err := fsys.Open(path_obj)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load OBJ : %v", err)
+	}
+	defer file.Close()
+	model, err := Read(file)
+	if err != nil {
+		return nil, err
+	}
+	dir := path.Dir(path_obj)
+	for _, mtllib := range model.mtllibs {
+		path_mtl := path.Join(dir, filepath.ToSlash(mtllib))
+		mtlfile, err := fsys.Open(path_mtl)
+		if err != nil {
+			common.Logger.Warn("Failed to load MTL : %v\n", err) // geometries are still usable
+			continue
+		}
+		materials, err := ReadMTL(mtlfile)
+		mtlfile.Close()
+		if err != nil {
+			return nil, err
+		}
+		for name, material := range materials {
+			if material.DiffuseMap != "" {
+				path_img := path.Join(path.Dir(path_mtl), filepath.ToSlash(material.DiffuseMap))
+				if material.image_bytes, err = fs.ReadFile(fsys, path_img); err != nil {
+					common.Logger.Warn("Failed to load texture : %v\n", err)
+				}
+			}
+			model.Materials[name] = material
+		}
+	}
+	return model, nil
+}
+
+// ----------------------------------------------------------------------------
+// Reading OBJ
+// ----------------------------------------------------------------------------
+
+type obj_corner [3]int // indices of position, texture UV and normal (-1 if missing)
+
+type obj_group struct {
+	name     string         //
+	material string         //
+	faces    [][]obj_corner // faces ('f')
+	lines    [][]obj_corner // edges ('l')
+}
+
+func Read(r io.Reader) (*Model, error) {
+	// Read OBJ geometries (MTL files are not read, see Load())
+	model := Model{Materials: map[string]*Material{}}
+	positions, tuvs, normals := [][3]float32{}, [][2]float32{}, [][3]float32{}
+	groups := []*obj_group{}
+	current := &obj_group{name: "default"}
+	start_group := func(name string, material string) {
+		if len(current.faces) > 0 || len(current.lines) > 0 {
+			groups = append(groups, current)
+		}
+		current = &obj_group{name: name, material: material}
+	}
+	err := scan_lines(r, func(line_num int, fields []string) error {
+		switch fields[0] {
+		case "v":
+			xyz, err := parse_floats(fields[1:], 3, 4)
+			if err != nil {
+				return err
+			}
+			positions = append(positions, [3]float32{xyz[0], xyz[1], xyz[2]})
+		case "vt":
+			uv, err := parse_floats(fields[1:], 1, 3)
+			if err != nil {
+				return err
+			}
+			uv = append(uv, 0)
+			tuvs = append(tuvs, [2]float32{uv[0], 1 - uv[1]}) // OBJ has V=0 at the bottom of the image
+		case "vn":
+			n, err := parse_floats(fields[1:], 3, 3)
+			if err != nil {
+				return err
+			}
+			normals = append(normals, *g3d.NewV3d(n[0], n[1], n[2]).Normalize())
+		case "f", "l":
+			corners := make([]obj_corner, len(fields)-1)
+			for i, field := range fields[1:] {
+				corner, err := parse_corner(field, [3]int{len(positions), len(tuvs), len(normals)})
+				if err != nil {
+					return err
+				}
+				corners[i] = corner
+			}
+			if fields[0] == "f" && len(corners) >= 3 {
+				current.faces = append(current.faces, corners)
+			} else if fields[0] == "l" && len(corners) >= 2 {
+				current.lines = append(current.lines, corners)
+			} else {
+				return fmt.Errorf("too few vertices")
+			}
+		case "o", "g":
+			name := strings.Join(fields[1:], " ")
+			if name != current.name {
+				start_group(name, current.material)
+			}
+		case "usemtl":
+			if len(fields) > 1 && fields[1] != current.material {
+				start_group(current.name, fields[1])
+			}
+		case "mtllib":
+			model.mtllibs = append(model.mtllibs, fields[1:]...)
+		default: // 's', 'p', curves and surfaces are ignored
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read OBJ : %v", err)
+	}
+	start_group("", "")
+	for _, g := range groups {
+		geometry := build_geometry(g, positions, tuvs, normals)
+		model.Groups = append(model.Groups, &Group{Name: g.name, Material: g.material, Geometry: geometry})
+	}
+	return &model, nil
+}
+
+func build_geometry(g *obj_group, positions [][3]float32, tuvs [][2]float32, normals [][3]float32) *g3d.Geometry {
+	// Texture UVs & normals are used only if all the faces have them
+	use_tuv, use_nor := len(g.faces) > 0, len(g.faces) > 0
+	for _, face := range g.faces {
+		for _, corner := range face {
+			use_tuv = use_tuv && corner[1] >= 0
+			use_nor = use_nor && corner[2] >= 0
+		}
+	}
+	geometry := g3d.NewGeometry()
+	vertex_map := map[obj_corner]uint32{} // new vertex for each unique corner
+	position_map := map[int]uint32{}      // the first new vertex of each position (for edges)
+	add_vertex := func(corner obj_corner) uint32 {
+		if !use_tuv {
+			corner[1] = -1
+		}
+		if !use_nor {
+			corner[2] = -1
+		}
+		if vidx, ok := vertex_map[corner]; ok {
+			return vidx
+		}
+		vidx := geometry.AddVertex(positions[corner[0]])
+		if use_tuv {
+			uv := [2]float32{0, 0} // (for the vertices of edges only)
+			if corner[1] >= 0 {
+				uv = tuvs[corner[1]]
+			}
+			geometry.AddTextureUV([]float32{uv[0], uv[1]})
+		}
+		if use_nor {
+			n := [3]float32{0, 0, 1} // (for the vertices of edges only)
+			if corner[2] >= 0 {
+				n = normals[corner[2]]
+			}
+			geometry.AddNormal(n)
+		}
+		vertex_map[corner] = vidx
+		if _, ok := position_map[corner[0]]; !ok {
+			position_map[corner[0]] = vidx
+		}
+		return vidx
+	}
+	for _, face := range g.faces {
+		vlist := make([]uint32, len(face))
+		for i, corner := range face {
+			vlist[i] = add_vertex(corner)
+		}
+		geometry.AddFace(vlist)
+	}
+	for _, line := range g.lines {
+		vlist := make([]uint32, len(line))
+		for i, corner := range line {
+			if vidx, ok := position_map[corner[0]]; ok { // share the vertex of faces
+				vlist[i] = vidx
+			} else {
+				vlist[i] = add_vertex(obj_corner{corner[0], -1, -1})
+			}
+		}
+		geometry.AddEdge(vlist)
+	}
+	return geometry
+}
+
+func parse_corner(field string, counts [3]int) (obj_corner, error) {
+	// Parse 'v', 'v/vt', 'v//vn' or 'v/vt/vn' (1-based, or negative for relative indices)
+	corner := obj_corner{-1, -1, -1}
+	for i, s := range strings.Split(field, "/") {
+		if i > 2 {
+			return corner, fmt.Errorf("invalid index %q", field)
+		} else if s == "" && i > 0 {
+			continue
+		}
+		idx, err := strconv.Atoi(s)
+		if err != nil {
+			return corner, fmt.Errorf("invalid index %q", field)
+		} else if idx < 0 {
+			idx = counts[i] + idx // relative to the end
+		} else {
+			idx = idx - 1
+		}
+		if idx < 0 || idx >= counts[i] {
+			return corner, fmt.Errorf("index out of range %q", field)
+		}
+		corner[i] = idx
+	}
+	return corner, nil
+}
+
+// ----------------------------------------------------------------------------
+// Reading MTL
+// ----------------------------------------------------------------------------
+
+func ReadMTL(r io.Reader) (map[string]*Material, error) {
+	// Read the materials (only diffuse colors & texture maps are used)
+	materials := map[string]*Material{}
+	var material *Material = nil
+	err := scan_lines(r, func(line_num int, fields []string) error {
+		if fields[0] == "newmtl" {
+			name := strings.Join(fields[1:], " ")
+			material = &Material{Name: name, DiffuseColor: [4]float32{1, 1, 1, 1}}
+			materials[name] = material
+			return nil
+		} else if material == nil {
+			return nil // ignore anything before the first 'newmtl'
+		}
+		switch fields[0] {
+		case "Kd":
+			rgb, err := parse_floats(fields[1:], 3, 3)
+			if err != nil {
+				return err
+			}
+			material.DiffuseColor = [4]float32{rgb[0], rgb[1], rgb[2], material.DiffuseColor[3]}
+		case "d", "Tr":
+			alpha, err := parse_floats(fields[len(fields)-1:], 1, 1) // (skipping options like '-halo')
+			if err != nil {
+				return err
+			}
+			if fields[0] == "Tr" {
+				alpha[0] = 1 - alpha[0]
+			}
+			material.DiffuseColor[3] = alpha[0]
+		case "map_Kd":
+			material.DiffuseMap = fields[len(fields)-1] // (skipping options like '-s 1 1 1')
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read MTL : %v", err)
+	}
+	return materials, nil
+}
+
+// ----------------------------------------------------------------------------
+// Parsing Utilities
+// ----------------------------------------------------------------------------
+
+func scan_lines(r io.Reader, handler func(line_num int, fields []string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line, line_num := "", 0
+	for scanner.Scan() {
+		line_num++
+		line += scanner.Text()
+		if strings.HasSuffix(line, "\\") { // line continuation
+			line = line[:len(line)-1] + " "
+			continue
+		}
+		if idx := strings.IndexByte(line, '#'); idx >= 0 {
+			line = line[:idx]
+		}
+		fields := strings.Fields(line)
+		line = ""
+		if len(fields) == 0 {
+			continue
+		}
+		if err := handler(line_num, fields); err != nil {
+			return fmt.Errorf("%v (line %d)", err, line_num)
+		}
+	}
+	return scanner.Err()
+}
+
+func parse_floats(fields []string, min_count int, max_count int) ([]float32, error) {
+	if len(fields) < min_count {
+		return nil, fmt.Errorf("too few values")
+	} else if len(fields) > max_count {
+		fields = fields[:max_count]
+	}
+	values := make([]float32, len(fields))
+	for i, field := range fields {
+		value, err := strconv.ParseFloat(field, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q", field)
+		}
+		values[i] = float32(value)
+	}
+	return values, nil
+}
@@ -0,0 +1,80 @@
+package obj
+
+import (
+	"github.com/go4orward/gigl"
+	"github.com/go4orward/gigl/g2d"
+	"github.com/go4orward/gigl/g3d"
+)
+
+// ----------------------------------------------------------------------------
+// Building SceneObjects
+// ----------------------------------------------------------------------------
+
+func (self *Material) HasTexture() bool {
+	return self.DiffuseMap != "" && self.image_bytes != nil
+}
+
+func (self *Material) NewMaterial(use_texture bool) gigl.GLMaterial {
+	// Create MaterialTexture for 'map_Kd' (if its image was loaded), or MaterialColors for 'Kd'
+	if use_texture && self.HasTexture() {
+		return g2d.NewMaterialTextureFromImageBytes(self.DiffuseMap, self.image_bytes)
+	}
+	return g2d.NewMaterialColors(self.DiffuseColor)
+}
+
+func (self *Model) NewSceneObject(rc gigl.GLRenderingContext) *g3d.SceneObject {
+	// Create a SceneObject for each group, with the first one as the root and the others as its children.
+	// Face normals are built for the groups without normals, and materials & shaders are shared among the groups.
+	type material_key struct {
+		name    string
+		texture bool
+	}
+	materials := map[material_key]gigl.GLMaterial{}
+	shaders := map[bool]gigl.GLShader{} // shader for texture (true) or color (false)
+	var edge_shader gigl.GLShader = nil
+	var root *g3d.SceneObject = nil
+	for _, group := range self.Groups {
+		geometry := group.Geometry
+		if !geometry.HasNormalFor("") {
+			geometry.BuildNormalsForFace()
+		}
+		geometry.BuildDataBuffers(true, true, true)
+		mtl, mtl_found := self.Materials[group.Material]
+		key := material_key{group.Material, mtl_found && mtl.HasTexture() && geometry.HasTextureFor("")}
+		material, ok := materials[key]
+		if !ok {
+			if mtl_found {
+				material = mtl.NewMaterial(key.texture)
+			} else {
+				material = g2d.NewMaterialColors("#cccccc") // default material
+			}
+			materials[key] = material
+		}
+		shader, ok := shaders[key.texture]
+		if !ok {
+			if key.texture {
+				shader = g3d.NewShader_NormalTexture(rc)
+			} else {
+				shader = g3d.NewShader_NormalColor(rc)
+			}
+			shaders[key.texture] = shader
+		}
+		var eshader gigl.GLShader = nil // for the edges ('l')
+		if len(geometry.GetEdges()) > 0 && !key.texture {
+			if edge_shader == nil {
+				edge_shader = g3d.NewShader_ColorOnly(rc)
+			}
+			eshader = edge_shader
+		}
+		scnobj := g3d.NewSceneObject(geometry, material, nil, eshader, shader)
+		if mtl_found && mtl.DiffuseColor[3] < 1 {
+			scnobj.UseBlend = true // transparent material
+		}
+		if root == nil {
+			root = scnobj
+		} else {
+			root.AddChild(scnobj)
+		}
+	}
+	return root
+}
@@ -0,0 +1,122 @@
+package obj
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/go4orward/gigl/g3d"
+)
+
+// ----------------------------------------------------------------------------
+// Writing OBJ
+// ----------------------------------------------------------------------------
+
+func WriteGeometry(w io.Writer, geometry *g3d.Geometry) error {
+	// Write a single geometry as OBJ (with its texture UVs & normals, either PER_VERT or PER_FACE)
+	return Write(w, &Group{Name: "geometry", Geometry: geometry})
+}
+
+func Write(w io.Writer, groups ...*Group) error {
+	// Write the geometries as OBJ groups (with 'usemtl' if the name of material is given)
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# OBJ written by GIGL\n")
+	offsets := [3]int{1, 1, 1} // OBJ indices are 1-based, and shared by all the groups
+	for _, group := range groups {
+		if group == nil || group.Geometry == nil {
+			continue
+		}
+		offsets = write_group(bw, group, offsets)
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("Failed to write OBJ : %v", err)
+	}
+	return nil
+}
+
+func write_group(bw *bufio.Writer, group *Group, offsets [3]int) [3]int {
+	geometry := group.Geometry
+	verts, faces, edges := geometry.GetVertices(), geometry.GetFaces(), geometry.GetEdges()
+	tuvs, norms := geometry.GetTextureUVs(), geometry.GetNormals()
+	tuv_per_vert, tuv_per_face := geometry.HasTextureFor("VERTEX"), geometry.HasTextureFor("FACE") && len(tuvs) == len(faces)
+	nor_per_vert, nor_per_face := geometry.HasNormalFor("VERTEX"), geometry.HasNormalFor("FACE")
+	fmt.Fprintf(bw, "g %s\n", group.Name)
+	if group.Material != "" {
+		fmt.Fprintf(bw, "usemtl %s\n", group.Material)
+	}
+	for _, v := range verts {
+		fmt.Fprintf(bw, "v %s %s %s\n", ftoa(v[0]), ftoa(v[1]), ftoa(v[2]))
+	}
+	// texture UVs (with V=0 at the bottom of the image)
+	tuv_count := 0
+	if tuv_per_vert {
+		for _, uv := range tuvs {
+			fmt.Fprintf(bw, "vt %s %s\n", ftoa(uv[0]), ftoa(1-uv[1]))
+		}
+		tuv_count = len(tuvs)
+	} else if tuv_per_face {
+		for _, uvs := range tuvs {
+			for i := 0; i+1 < len(uvs); i += 2 {
+				fmt.Fprintf(bw, "vt %s %s\n", ftoa(uvs[i]), ftoa(1-uvs[i+1]))
+			}
+			tuv_count += len(uvs) / 2
+		}
+	}
+	// normal vectors
+	if nor_per_vert || nor_per_face {
+		for _, n := range norms {
+			fmt.Fprintf(bw, "vn %s %s %s\n", ftoa(n[0]), ftoa(n[1]), ftoa(n[2]))
+		}
+	}
+	// faces with the indices of 'v/vt/vn'
+	tuv_face_start := 0
+	for fidx, face := range faces {
+		bw.WriteString("f")
+		for i, vidx := range face {
+			bw.WriteString(" " + strconv.Itoa(offsets[0]+int(vidx)))
+			tidx, nidx := -1, -1
+			if tuv_per_vert {
+				tidx = int(vidx)
+			} else if tuv_per_face && i < len(tuvs[fidx])/2 {
+				tidx = tuv_face_start + i
+			}
+			if nor_per_vert {
+				nidx = int(vidx)
+			} else if nor_per_face {
+				nidx = fidx
+			}
+			if tidx >= 0 || nidx >= 0 {
+				bw.WriteString("/")
+				if tidx >= 0 {
+					bw.WriteString(strconv.Itoa(offsets[1] + tidx))
+				}
+				if nidx >= 0 {
+					bw.WriteString("/" + strconv.Itoa(offsets[2]+nidx))
+				}
+			}
+		}
+		bw.WriteString("\n")
+		if tuv_per_face {
+			tuv_face_start += len(tuvs[fidx]) / 2
+		}
+	}
+	// edges
+	for _, edge := range edges {
+		bw.WriteString("l")
+		for _, vidx := range edge {
+			bw.WriteString(" " + strconv.Itoa(offsets[0]+int(vidx)))
+		}
+		bw.WriteString("\n")
+	}
+	offsets[0] += len(verts)
+	offsets[1] += tuv_count
+	if nor_per_vert || nor_per_face {
+		offsets[2] += len(norms)
+	}
+	return offsets
+}
+
+func ftoa(value float32) string {
+	return strconv.FormatFloat(float64(value), 'g', -1, 32)
+}
@@ -4,4 +4,5 @@ import "github.com/go4orward/gigl/common"
 
 type Overlay interface {
 	Render(proj *common.Matrix4, view *common.Matrix4)
+	Dispose() // delete GPU resources of the layer
 }
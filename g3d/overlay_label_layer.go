@@ -106,6 +106,22 @@ func (self *OverlayLabelLayer) Render(proj *common.Matrix4, view *common.Matrix4
 	}
 }
 
+func (self *OverlayLabelLayer) Dispose() {
+	// 'Overlay' interface function, to delete GPU resources of all the labels
+	for _, label := range self.Labels {
+		if label.bkgobj != nil {
+			label.bkgobj.Dispose(self.rc)
+			label.bkgobj = nil
+		}
+		if label.txtobj != nil {
+			label.txtobj.Dispose(self.rc)
+			label.txtobj = nil
+		}
+	}
+	self.Labels = make([]*OverlayLabel, 0)
+	self.alphabet_shader = nil // its shader program was disposed with the last label
+}
+
 // ----------------------------------------------------------------------------
 // Managing Labels
 // ----------------------------------------------------------------------------
@@ -27,6 +27,14 @@ func (self *OverlayMarkerLayer) Render(proj *common.Matrix4, vwmd *common.Matrix
 	}
 }
 
+func (self *OverlayMarkerLayer) Dispose() {
+	// 'Overlay' interface function, to delete GPU resources of all the markers
+	for _, marker := range self.Markers {
+		marker.Dispose(self.rc)
+	}
+	self.Markers = make([]*SceneObject, 0)
+}
+
 // ----------------------------------------------------------------------------
 // Managing Markers
 // ----------------------------------------------------------------------------
@@ -1,11 +1,13 @@
 package g3d
 
 import (
+	"errors"
 	"fmt"
 	"math"
 
 	"github.com/go4orward/gigl"
 	"github.com/go4orward/gigl/common"
+	cst "github.com/go4orward/gigl/common/constants"
 )
 
 // ----------------------------------------------------------------------------
@@ -23,9 +25,11 @@ type SceneObject struct {
 	UseBlend    bool            // blending flag with alpha (default is false)
 	children    []*SceneObject  //
 	// multiple instance poses
-	instance_count  int       // number of instances
-	instance_stride int       // number of values of a single pose
-	instance_buffer []float32 //
+	instance_count  int                // number of instances
+	instance_stride int                // number of values of a single pose
+	instance_buffer []float32          //
+	instance_change gigl.BufferTracker // changes of the instance buffer (to be uploaded again)
+	instance_usage  cst.BufferUsage    // usage hint for the instance buffer (StaticDraw by default)
 	// VAO (set of RenderingContext buffers)
 	vao *gigl.VAO //
 	//
@@ -49,6 +53,7 @@ func NewSceneObject(geometry gigl.GLGeometry, material gigl.GLMaterial,
 	sobj.UseDepth = true  // depth test is turned on by default
 	sobj.UseBlend = false // alpha blending is turned off by default
 	sobj.children = nil
+	sobj.retain_shared_resources()
 	return &sobj
 }
 
@@ -78,6 +83,45 @@ func (self *SceneObject) Summary() string {
 	return summary
 }
 
+// ----------------------------------------------------------------------------
+// Disposing GPU Resources
+// ----------------------------------------------------------------------------
+
+func (self *SceneObject) Dispose(rc gigl.GLRenderingContext) {
+	// Delete the GPU resources of the SceneObject and its children (they cannot be rendered any more).
+	// Note that shared material & shaders are disposed only when released by their last user.
+	for _, child := range self.children {
+		child.Dispose(rc)
+	}
+	if self.vao != nil {
+		self.vao.Dispose(rc)
+		self.vao = nil
+	}
+	if mtex, ok := self.Material.(gigl.GLMaterialTexture); ok && mtex.Release() {
+		mtex.Dispose(rc)
+	}
+	for _, shader := range []gigl.GLShader{self.VShader, self.EShader, self.FShader} {
+		if shader != nil && shader.Release() {
+			shader.Dispose()
+		}
+	}
+	self.Material, self.VShader, self.EShader, self.FShader = nil, nil, nil, nil
+	self.children = nil
+	self.err = errors.New("SceneObject was disposed")
+}
+
+func (self *SceneObject) retain_shared_resources() {
+	// Count this SceneObject as a user of its material & shaders
+	if mtex, ok := self.Material.(gigl.GLMaterialTexture); ok {
+		mtex.Retain()
+	}
+	for _, shader := range []gigl.GLShader{self.VShader, self.EShader, self.FShader} {
+		if shader != nil {
+			shader.Retain()
+		}
+	}
+}
+
 // ----------------------------------------------------------------------------
 // Basic Access
 // ----------------------------------------------------------------------------
@@ -106,6 +150,7 @@ func (self *SceneObject) ClearInstanceBuffer() {
 	self.instance_buffer = nil
 	self.instance_count = 0
 	self.instance_stride = 0
+	self.instance_change.MarkRebuilt()
 }
 
 func (self *SceneObject) SetInstanceBuffer(instance_count int, instance_stride int, data []float32) *SceneObject {
@@ -118,6 +163,18 @@ func (self *SceneObject) SetInstanceBuffer(instance_count int, instance_stride i
 			self.instance_buffer[i] = data[i]
 		}
 	}
+	self.instance_change.MarkRebuilt()
+	return self
+}
+
+func (self *SceneObject) GetInstanceBuffer() (int, int, []float32) {
+	// Get the number of instances, the number of values of a single pose, and the instance buffer (nil if not set)
+	return self.instance_count, self.instance_stride, self.instance_buffer
+}
+
+func (self *SceneObject) SetInstanceBufferUsage(usage cst.BufferUsage) *SceneObject {
+	// Set usage hint (StaticDraw/DynamicDraw/StreamDraw), if the instance poses will be updated repeatedly
+	self.instance_usage = usage
 	return self
 }
 
@@ -131,6 +188,7 @@ func (self *SceneObject) SetInstancePoseValues(instance_index int, offset int, v
 	for i := 0; i < len(values); i++ {
 		self.instance_buffer[pos+offset+i] = values[i]
 	}
+	self.instance_change.MarkChanged(pos+offset, pos+offset+len(values))
 }
 
 func (self *SceneObject) SetInstanceColorValues(instance_index int, offset int, v0 uint8, v1 uint8, v2 uint8, v3 uint8) {
@@ -142,6 +200,7 @@ func (self *SceneObject) SetInstanceColorValues(instance_index int, offset int,
 	pos := instance_index * self.instance_stride
 	b0, b1, b2, b3 := uint32(0), uint32(0), uint32(0), uint32(0)
 	self.instance_buffer[pos+offset] = math.Float32frombits(b0 + b1<<8 + b2<<16 + b3<<24) // LittleEndian (lower byte comes first)
+	self.instance_change.MarkChanged(pos+offset, pos+offset+1)
 }
 
 // ----------------------------------------------------------------------------
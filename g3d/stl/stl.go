@@ -0,0 +1,216 @@
+package stl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go4orward/gigl/g3d"
+)
+
+// ----------------------------------------------------------------------------
+// Reading STL (ASCII or Binary)
+// ----------------------------------------------------------------------------
+
+// STL gives three separate vertices for each triangle, so the duplicate vertices are welded
+// into shared indices, and the facet normals are kept as PER_FACE normals of the geometry.
+// (Facet normals of zero length are calculated from the vertices, as many applications leave them empty)
+
+func LoadFile(filepath string) (*g3d.Geometry, error) {
+	file, err := os.Open(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load STL : %v", err)
+	}
+	defer file.Close()
+	return Read(file)
+}
+
+func Read(r io.Reader) (*g3d.Geometry, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read STL : %v", err)
+	}
+	// Note that binary STL may also start with "solid", so check its size first
+	if len(data) >= 84 && 84+50*int(binary.LittleEndian.Uint32(data[80:84])) == len(data) {
+		return read_binary(data)
+	} else if bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte("solid")) {
+		return read_ascii(data)
+	}
+	return nil, fmt.Errorf("Failed to read STL : invalid format")
+}
+
+type stl_welder struct {
+	geometry *g3d.Geometry         //
+	vertices map[[3]float32]uint32 // index of each vertex already added
+}
+
+func (self *stl_welder) add_facet(normal [3]float32, v0 [3]float32, v1 [3]float32, v2 [3]float32) {
+	i0, i1, i2 := self.add_vertex(v0), self.add_vertex(v1), self.add_vertex(v2)
+	if i0 == i1 || i1 == i2 || i2 == i0 {
+		return // degenerate triangle
+	}
+	n := g3d.V3d(normal)
+	if n.Length() < 1e-6 {
+		n = *g3d.NewV3dByFaceNormal(v0, v1, v2)
+	}
+	self.geometry.AddFace([]uint32{i0, i1, i2})
+	self.geometry.AddNormal(*n.Normalize())
+}
+
+func (self *stl_welder) add_vertex(v [3]float32) uint32 {
+	if vidx, ok := self.vertices[v]; ok {
+		return vidx
+	}
+	vidx := self.geometry.AddVertex(v)
+	self.vertices[v] = vidx
+	return vidx
+}
+
+func read_binary(data []byte) (*g3d.Geometry, error) {
+	// 80-byte header, uint32 count, and 50 bytes for each triangle (normal, 3 vertices, attribute)
+	welder := stl_welder{geometry: g3d.NewGeometry(), vertices: map[[3]float32]uint32{}}
+	count := int(binary.LittleEndian.Uint32(data[80:84]))
+	get_xyz := func(pos int) [3]float32 {
+		return [3]float32{
+			math.Float32frombits(binary.LittleEndian.Uint32(data[pos+0:])),
+			math.Float32frombits(binary.LittleEndian.Uint32(data[pos+4:])),
+			math.Float32frombits(binary.LittleEndian.Uint32(data[pos+8:]))}
+	}
+	for i := 0; i < count; i++ {
+		pos := 84 + i*50
+		welder.add_facet(get_xyz(pos), get_xyz(pos+12), get_xyz(pos+24), get_xyz(pos+36))
+	}
+	return welder.geometry, nil
+}
+
+func read_ascii(data []byte) (*g3d.Geometry, error) {
+	welder := stl_welder{geometry: g3d.NewGeometry(), vertices: map[[3]float32]uint32{}}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	normal, verts := [3]float32{}, [][3]float32{}
+	for line_num := 1; scanner.Scan(); line_num++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		var err error
+		switch fields[0] {
+		case "facet": // 'facet normal nx ny nz'
+			if len(fields) < 5 || fields[1] != "normal" {
+				err = fmt.Errorf("invalid facet")
+			} else {
+				normal, err = parse_xyz(fields[2:5])
+			}
+			verts = verts[:0]
+		case "vertex": // 'vertex x y z'
+			var v [3]float32
+			if len(fields) < 4 {
+				err = fmt.Errorf("invalid vertex")
+			} else if v, err = parse_xyz(fields[1:4]); err == nil {
+				verts = append(verts, v)
+			}
+		case "endfacet":
+			for i := 2; i < len(verts); i++ { // (a polygon, if more than 3 vertices)
+				welder.add_facet(normal, verts[0], verts[i-1], verts[i])
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read STL : %v (line %d)", err, line_num)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Failed to read STL : %v", err)
+	}
+	return welder.geometry, nil
+}
+
+func parse_xyz(fields []string) ([3]float32, error) {
+	xyz := [3]float32{}
+	for i := 0; i < 3; i++ {
+		value, err := strconv.ParseFloat(fields[i], 32)
+		if err != nil {
+			return xyz, fmt.Errorf("invalid value %q", fields[i])
+		}
+		xyz[i] = float32(value)
+	}
+	return xyz, nil
+}
+
+// ----------------------------------------------------------------------------
+// Writing STL (ASCII or Binary)
+// ----------------------------------------------------------------------------
+
+type stl_facet struct {
+	normal [3]float32
+	verts  [3][3]float32
+}
+
+func get_facets(geometry *g3d.Geometry) []stl_facet {
+	// Triangulate the faces (just like BuildDataBuffers() does), with PER_FACE normals if available
+	verts, faces := geometry.GetVertices(), geometry.GetFaces()
+	has_face_normal := geometry.HasNormalFor("FACE")
+	facets := []stl_facet{}
+	for fidx := range faces {
+		for _, triangle := range geometry.GetFaceTriangles(fidx) {
+			for i := 2; i < len(triangle); i++ { // (a polygon, if triangulation failed)
+				v0, v1, v2 := verts[triangle[0]], verts[triangle[i-1]], verts[triangle[i]]
+				normal := [3]float32(*g3d.NewV3dByFaceNormal(v0, v1, v2))
+				if has_face_normal {
+					normal = geometry.GetNormals()[fidx]
+				}
+				facets = append(facets, stl_facet{normal: normal, verts: [3][3]float32{v0, v1, v2}})
+			}
+		}
+	}
+	return facets
+}
+
+func Write(w io.Writer, geometry *g3d.Geometry) error {
+	// Write the geometry as binary STL
+	facets := get_facets(geometry)
+	data := make([]byte, 84+50*len(facets))
+	copy(data, "Binary STL written by GIGL") // (header should not start with 'solid')
+	binary.LittleEndian.PutUint32(data[80:], uint32(len(facets)))
+	put_xyz := func(pos int, xyz [3]float32) {
+		for i := 0; i < 3; i++ {
+			binary.LittleEndian.PutUint32(data[pos+i*4:], math.Float32bits(xyz[i]))
+		}
+	}
+	for i, facet := range facets {
+		pos := 84 + i*50
+		put_xyz(pos, facet.normal)
+		for j := 0; j < 3; j++ {
+			put_xyz(pos+12+j*12, facet.verts[j])
+		}
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("Failed to write STL : %v", err)
+	}
+	return nil
+}
+
+func WriteASCII(w io.Writer, geometry *g3d.Geometry, name string) error {
+	// Write the geometry as ASCII STL
+	bw := bufio.NewWriter(w)
+	ftoa := func(value float32) string { return strconv.FormatFloat(float64(value), 'e', -1, 32) }
+	fmt.Fprintf(bw, "solid %s\n", name)
+	for _, facet := range get_facets(geometry) {
+		n := facet.normal
+		fmt.Fprintf(bw, "  facet normal %s %s %s\n    outer loop\n", ftoa(n[0]), ftoa(n[1]), ftoa(n[2]))
+		for _, v := range facet.verts {
+			fmt.Fprintf(bw, "      vertex %s %s %s\n", ftoa(v[0]), ftoa(v[1]), ftoa(v[2]))
+		}
+		fmt.Fprintf(bw, "    endloop\n  endfacet\n")
+	}
+	fmt.Fprintf(bw, "endsolid %s\n", name)
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("Failed to write STL : %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package gigl
+
+import "sort"
+
+// ----------------------------------------------------------------------------
+// BufferTracker for Updating Data Buffers
+// ----------------------------------------------------------------------------
+
+// BufferTracker keeps track of the changes of a data buffer (like geometry vertices or instance poses),
+// so that only the changed ranges are uploaded again by each VAO using the data.
+// Since a data buffer can be shared by many VAOs, the changes are recorded with their versions,
+// and each VAO keeps the version of the data it uploaded.
+
+type BufferTracker struct {
+	version int                    // increased whenever the data is changed
+	rebuilt int                    // version when the data was rebuilt entirely (with its size changed)
+	pruned  int                    // version of the last change dropped from 'changes'
+	changes []buffer_tracker_range // recent changes since the last rebuild
+}
+
+type buffer_tracker_range struct {
+	version int // version of the change
+	start   int // index of the first value changed
+	end     int // index after the last value changed
+}
+
+const buffer_tracker_max_changes = 64 // older changes are dropped (and the whole data will be uploaded)
+
+func (self *BufferTracker) GetVersion() int {
+	return self.version
+}
+
+func (self *BufferTracker) MarkRebuilt() {
+	// The whole data was rebuilt (and its size may have changed)
+	self.version++
+	self.rebuilt = self.version
+	self.changes = self.changes[:0]
+}
+
+func (self *BufferTracker) MarkChanged(start int, end int) {
+	// Values in the range of [start, end) were changed
+	if start >= end {
+		return
+	}
+	self.version++
+	if n := len(self.changes); n > 0 && start <= self.changes[n-1].end && end >= self.changes[n-1].start {
+		// merge it with the last change (uploading a bit more is harmless, even if it was uploaded already)
+		last := &self.changes[n-1]
+		last.version, last.start, last.end = self.version, min_int(last.start, start), max_int(last.end, end)
+		return
+	}
+	self.changes = append(self.changes, buffer_tracker_range{version: self.version, start: start, end: end})
+	if len(self.changes) > buffer_tracker_max_changes {
+		self.pruned = self.changes[0].version
+		self.changes = append(self.changes[:0], self.changes[1:]...)
+	}
+}
+
+func (self *BufferTracker) IsRebuiltSince(version int) bool {
+	return self.rebuilt > version
+}
+
+func (self *BufferTracker) GetChangesSince(version int) ([][2]int, bool) {
+	// Get the ranges changed since the given version, or 'true' if the whole data has to be uploaded again.
+	if version < self.rebuilt || version < self.pruned {
+		return nil, true
+	}
+	ranges := [][2]int{}
+	for _, change := range self.changes {
+		if change.version > version {
+			ranges = append(ranges, [2]int{change.start, change.end})
+		}
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+	merged := [][2]int{}
+	for _, r := range ranges {
+		if n := len(merged); n > 0 && r[0] <= merged[n-1][1] {
+			merged[n-1][1] = max_int(merged[n-1][1], r[1])
+		} else {
+			merged = append(merged, r)
+		}
+	}
+	return merged, false
+}
+
+func (self *BufferTracker) UploadChanges(rc GLRenderingContext, buffer any, version int, data []float32, usage uint32) int {
+	// Upload the data changed since the given version into the (ARRAY_BUFFER) buffer,
+	// and return the new version of the data in the buffer.
+	if buffer == nil || version >= self.version {
+		return self.version
+	}
+	c := rc.GetConstants()
+	rc.GLBindBuffer(c.ARRAY_BUFFER, buffer)
+	ranges, all := self.GetChangesSince(version)
+	if all {
+		rc.GLBufferData(c.ARRAY_BUFFER, data, usage)
+	} else {
+		for _, r := range ranges {
+			if r[1] <= len(data) {
+				rc.GLBufferSubData(c.ARRAY_BUFFER, r[0]*4, data[r[0]:r[1]])
+			}
+		}
+	}
+	return self.version
+}
+
+func min_int(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max_int(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,29 @@
+package gigl
+
+import "image"
+
+type GLCanvas interface {
+	// This interface defines a set of functions that both WebGL and OpenGL canvases provide,
+	// so that an application can be written once for both environments (see 'env.NewCanvas()').
+	GetWH() [2]int
+	GetRenderingContext() GLRenderingContext
+
+	// user interactions (event handling)
+	SetEventHandlerForClick(handler func(canvasxy [2]int, keystat [4]bool))
+	SetEventHandlerForDoubleClick(handler func(canvasxy [2]int, keystat [4]bool))
+	SetEventHandlerForMouseOver(handler func(canvasxy [2]int, keystat [4]bool))
+	SetEventHandlerForMouseDrag(handler func(canvasxy [2]int, dxy [2]int, keystat [4]bool))
+	SetEventHandlerForZoom(handler func(canvasxy [2]int, scale float32, keystat [4]bool)) // 'scale' in [ 0.01 ~ 1(default) ~ 100.0 ]
+	SetEventHandlerForScroll(handler func(canvasxy [2]int, dx int, dy int, keystat [4]bool))
+	SetEventHandlerForKeyPress(handler func(key string, code string, keystat [4]bool))
+	SetEventHandlerForWindowResize(handler func(w int, h int))
+
+	// animating with DrawHandler
+	Run(draw_handler func(now float64))     // run UI animation loop forever
+	RunOnce(draw_handler func(now float64)) // run UI animation loop only once
+	Pause()
+	Resume()
+
+	// capturing image (call it inside the 'draw_handler', after rendering)
+	CaptureImage() (image.Image, error)
+}
@@ -1,17 +1,25 @@
 package gigl
 
+import cst "github.com/go4orward/gigl/common/constants"
+
 type GLConstants struct {
 	ARRAY_BUFFER         uint32 //
 	BLEND                uint32 // for gl.enable(gl.BLEND)
 	BYTE                 uint32 //
 	CLAMP_TO_EDGE        uint32 // for gl.texParameteri()
+	COLOR_ATTACHMENT0    uint32 // for gl.framebufferTexture2D()
 	COLOR_BUFFER_BIT     uint32 //
 	COMPILE_STATUS       uint32 //
+	DEPTH_ATTACHMENT     uint32 // for gl.framebufferRenderbuffer()
 	DEPTH_BUFFER_BIT     uint32 //
+	DEPTH_COMPONENT16    uint32 // for gl.renderbufferStorage()
 	DEPTH_TEST           uint32 //
+	DYNAMIC_DRAW         uint32 // for gl.bufferData() with data changed repeatedly
 	ELEMENT_ARRAY_BUFFER uint32 //
 	FLOAT                uint32 //
 	FRAGMENT_SHADER      uint32 //
+	FRAMEBUFFER          uint32 // for gl.bindFramebuffer()
+	FRAMEBUFFER_COMPLETE uint32 // for gl.checkFramebufferStatus()
 	LEQUAL               uint32 //
 	LESS                 uint32 //
 	LINEAR               uint32 // for gl.texParameteri()
@@ -21,9 +29,11 @@ type GLConstants struct {
 	ONE                  uint32 // for gl.blendFunc()
 	ONE_MINUS_SRC_ALPHA  uint32 // for gl.blendFunc()
 	POINTS               uint32 //
+	RENDERBUFFER         uint32 // for gl.bindRenderbuffer()
 	RGBA                 uint32 //
 	SRC_ALPHA            uint32 // for gl.blendFunc()
 	STATIC_DRAW          uint32 //
+	STREAM_DRAW          uint32 // for gl.bufferData() with data changed for every frame
 	TEXTURE_2D           uint32 // for gl.texParameteri()
 	TEXTURE0             uint32 //
 	TEXTURE1             uint32 //
@@ -36,3 +46,15 @@ type GLConstants struct {
 	UNSIGNED_SHORT       uint32 //
 	VERTEX_SHADER        uint32 //
 }
+
+func (self *GLConstants) GetBufferUsage(usage cst.BufferUsage) uint32 {
+	// usage hint for gl.bufferData()
+	switch usage {
+	case cst.DynamicDraw:
+		return self.DYNAMIC_DRAW
+	case cst.StreamDraw:
+		return self.STREAM_DRAW
+	default:
+		return self.STATIC_DRAW
+	}
+}
@@ -24,4 +24,8 @@ type GLMaterialTexture interface {
 	IsLoading() bool // Texture is being loaded asynchronously by non-main thread (using Go function).
 	IsLoaded() bool  // Texture was successfully loaded, and it needs to be set up by main thread.
 	IsReady() bool   // Texture was successfully set up, and it's ready for rendering.
+
+	Retain()                       // called by each user (SceneObject) of the material
+	Release() bool                 // called by each user, and returns true if it was the last user
+	Dispose(rc GLRenderingContext) // delete the texture right away
 }
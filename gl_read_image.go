@@ -0,0 +1,43 @@
+package gigl
+
+import (
+	"fmt"
+	"image"
+)
+
+// ----------------------------------------------------------------------------
+// Reading Image from Framebuffer
+// ----------------------------------------------------------------------------
+
+// ReadImage reads the pixels of the framebuffer being drawn (canvas or RenderTarget) as an image.
+// Its rows are flipped, since the first row of a framebuffer is at the bottom.
+// Pixel colors are regarded as NOT pre-multiplied by alpha, because shaders write straight colors
+// (and translucent pixels over a transparent background keep them), so they are pre-multiplied for image.RGBA.
+
+func ReadImage(rc GLRenderingContext, width int, height int) (*image.RGBA, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("Failed to read image : invalid size %dx%d", width, height)
+	}
+	c := rc.GetConstants()
+	pixels := make([]uint8, width*height*4)
+	rc.GLReadPixels(0, 0, width, height, c.RGBA, c.UNSIGNED_BYTE, pixels)
+	return NewImageFromFramebufferPixels(pixels, width, height), nil
+}
+
+func NewImageFromFramebufferPixels(pixels []uint8, width int, height int) *image.RGBA {
+	// 'pixels' : RGBA pixels (NOT pre-multiplied by alpha) with the first row at the bottom
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	row_size := width * 4
+	for y := 0; y < height; y++ {
+		src := pixels[(height-1-y)*row_size : (height-y)*row_size]
+		dst := img.Pix[y*img.Stride : y*img.Stride+row_size]
+		for x := 0; x < row_size; x += 4 {
+			a := uint32(src[x+3])
+			for k := 0; k < 3; k++ { // pre-multiply the color by alpha (with rounding)
+				dst[x+k] = uint8((uint32(src[x+k])*a + 127) / 255)
+			}
+			dst[x+3] = uint8(a)
+		}
+	}
+	return img
+}
@@ -0,0 +1,43 @@
+package gigl
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestNewImageFromFramebufferPixels(t *testing.T) {
+	// 2x2 framebuffer pixels with the first row at the bottom
+	pixels := []uint8{
+		255, 0, 0, 255 /**/, 200, 100, 50, 128, // bottom row : opaque red, translucent orange
+		0, 0, 255, 255 /**/, 255, 255, 255, 0, // top row    : opaque blue, transparent white
+	}
+	img := NewImageFromFramebufferPixels(pixels, 2, 2)
+	tests := []struct {
+		name     string
+		x, y     int
+		expected color.RGBA // pre-multiplied by alpha
+	}{
+		{"opaque", 0, 1, color.RGBA{255, 0, 0, 255}},
+		{"translucent", 1, 1, color.RGBA{100, 50, 25, 128}},
+		{"top row", 0, 0, color.RGBA{0, 0, 255, 255}},
+		{"transparent", 1, 0, color.RGBA{0, 0, 0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if c := img.RGBAAt(tt.x, tt.y); c != tt.expected {
+				t.Errorf("pixel (%d,%d) = %v, want %v", tt.x, tt.y, c, tt.expected)
+			}
+		})
+	}
+	// translucent pixel should keep its straight color (within the rounding error)
+	if c := color.NRGBAModel.Convert(img.At(1, 1)).(color.NRGBA); abs_diff(c.R, 200) > 1 || abs_diff(c.G, 100) > 1 || abs_diff(c.B, 50) > 1 {
+		t.Errorf("translucent pixel lost its color : %v", c)
+	}
+}
+
+func abs_diff(a uint8, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
@@ -0,0 +1,32 @@
+package gigl
+
+// ----------------------------------------------------------------------------
+// RefCounter for Shared Resources
+// ----------------------------------------------------------------------------
+
+// RefCounter counts the users of a resource that can be shared (like materials & shaders),
+// so that its GPU resources are deleted only when the last user goes away.
+// SceneObjects call Retain() when they are created, and Release() when they are disposed.
+// Note that a resource never retained is not owned by any SceneObject, so it's not disposed by them.
+
+type RefCounter struct {
+	refcount int // number of users
+}
+
+func (self *RefCounter) Retain() {
+	self.refcount++
+}
+
+func (self *RefCounter) Release() bool {
+	// Returns true, if it was released by the last user (and it should be disposed).
+	// Releasing a resource without any user (never retained, or already released) returns false.
+	if self.refcount <= 0 {
+		return false
+	}
+	self.refcount--
+	return self.refcount == 0
+}
+
+func (self *RefCounter) GetRefCount() int {
+	return self.refcount
+}
@@ -0,0 +1,33 @@
+package gigl
+
+import (
+	"testing"
+)
+
+func TestRefCounterRelease(t *testing.T) {
+	tests := []struct {
+		name     string
+		retains  int
+		expected []bool // results of the consecutive Release() calls
+	}{
+		{"never retained", 0, []bool{false, false}},
+		{"single user", 1, []bool{true, false}},
+		{"two users", 2, []bool{false, true, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc := RefCounter{}
+			for i := 0; i < tt.retains; i++ {
+				rc.Retain()
+			}
+			for i, expected := range tt.expected {
+				if released := rc.Release(); released != expected {
+					t.Errorf("Release() #%d = %t, want %t", i+1, released, expected)
+				}
+			}
+			if rc.GetRefCount() != 0 {
+				t.Errorf("refcount %d after all the releases", rc.GetRefCount())
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package gigl
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/go4orward/gigl/common"
+)
+
+// ----------------------------------------------------------------------------
+// RenderTarget for Rendering Offscreen
+// ----------------------------------------------------------------------------
+
+// RenderTarget is an offscreen framebuffer, with a color texture and a depth buffer attached.
+// Renderers (g2d/g3d) can render a scene into it, and then its color texture can be used
+// as a GLMaterialTexture by another SceneObject. (for post-processing, picking, thumbnails, etc)
+// Note that the first row of the texture is the bottom row of the rendered image.
+// Note also that it's not disposed by the SceneObjects using it; its creator should dispose it.
+
+type RenderTarget struct {
+	rc            GLRenderingContext // WebGL/OpenGL rendering context
+	wh            [2]int             // width & height of the render target
+	framebuffer   any                // WebGL/OpenGL framebuffer
+	color_texture any                // texture attached as COLOR_ATTACHMENT0
+	depth_buffer  any                // renderbuffer attached as DEPTH_ATTACHMENT
+	texture_rgb   [3]float32         // extra RGB color to be multiplied with the texture
+	err           error              //
+}
+
+func NewRenderTarget(rc GLRenderingContext, width int, height int) (*RenderTarget, error) {
+	c := rc.GetConstants()
+	self := RenderTarget{rc: rc, wh: [2]int{width, height}}
+	if width <= 0 || height <= 0 {
+		self.err = fmt.Errorf("Failed to create RenderTarget : invalid size %dx%d", width, height)
+		common.Logger.Error("%v\n", self.err)
+		return &self, self.err
+	}
+	// color texture & depth buffer
+	self.color_texture = rc.CreateTexture(width, height)
+	self.depth_buffer = rc.CreateRenderbuffer()
+	rc.GLBindRenderbuffer(c.RENDERBUFFER, self.depth_buffer)
+	rc.GLRenderbufferStorage(c.RENDERBUFFER, c.DEPTH_COMPONENT16, width, height)
+	rc.GLBindRenderbuffer(c.RENDERBUFFER, nil)
+	// framebuffer with both of them attached
+	self.framebuffer = rc.CreateFramebuffer()
+	rc.GLBindFramebuffer(c.FRAMEBUFFER, self.framebuffer)
+	rc.GLFramebufferTexture2D(c.FRAMEBUFFER, c.COLOR_ATTACHMENT0, c.TEXTURE_2D, self.color_texture, 0)
+	rc.GLFramebufferRenderbuffer(c.FRAMEBUFFER, c.DEPTH_ATTACHMENT, c.RENDERBUFFER, self.depth_buffer)
+	if status := rc.GLCheckFramebufferStatus(c.FRAMEBUFFER); status != c.FRAMEBUFFER_COMPLETE {
+		self.err = fmt.Errorf("Failed to create RenderTarget : incomplete framebuffer (0x%x)", status)
+		common.Logger.Error("%v\n", self.err)
+	}
+	rc.GLBindFramebuffer(c.FRAMEBUFFER, nil)
+	return &self, self.err
+}
+
+func (self *RenderTarget) GetWH() [2]int {
+	return self.wh
+}
+
+func (self *RenderTarget) GetErr() error {
+	return self.err
+}
+
+// ----------------------------------------------------------------------------
+// Binding
+// ----------------------------------------------------------------------------
+
+func (self *RenderTarget) Bind() {
+	// Let all the following drawing go into this RenderTarget
+	c := self.rc.GetConstants()
+	self.rc.GLBindFramebuffer(c.FRAMEBUFFER, self.framebuffer)
+	self.rc.GLViewport(0, 0, self.wh[0], self.wh[1])
+}
+
+func (self *RenderTarget) Unbind() {
+	// Let all the following drawing go into the canvas again
+	c := self.rc.GetConstants()
+	wh := self.rc.GetWH()
+	self.rc.GLBindFramebuffer(c.FRAMEBUFFER, nil)
+	self.rc.GLViewport(0, 0, wh[0], wh[1])
+}
+
+func (self *RenderTarget) ReadImage() (*image.RGBA, error) {
+	// Read the rendered image (note that the canvas is bound again after reading)
+	if self.err != nil {
+		return nil, self.err
+	}
+	self.Bind()
+	defer self.Unbind()
+	return ReadImage(self.rc, self.wh[0], self.wh[1])
+}
+
+// ----------------------------------------------------------------------------
+// GLMaterialTexture (with the color texture)
+// ----------------------------------------------------------------------------
+
+func (self *RenderTarget) MaterialSummary() string {
+	return fmt.Sprintf("RenderTarget %dx%d", self.wh[0], self.wh[1])
+}
+
+func (self *RenderTarget) GetTexturePixbuf() []uint8 {
+	return nil // pixels are only in GPU memory
+}
+
+func (self *RenderTarget) GetTextureWH() [2]int {
+	return self.wh
+}
+
+func (self *RenderTarget) GetTexture() any {
+	return self.color_texture
+}
+
+func (self *RenderTarget) SetTexture(texture any) {
+	// DO NOTHING, since the color texture is owned by the framebuffer
+}
+
+func (self *RenderTarget) GetTextureRGB() [3]float32 {
+	return self.texture_rgb
+}
+
+func (self *RenderTarget) SetTextureRGB(color any) {
+	switch color.(type) {
+	case string:
+		self.texture_rgb = common.RGBFromHexString(color.(string))
+	case [3]float32:
+		self.texture_rgb = color.([3]float32)
+	case []float32:
+		c := color.([]float32)
+		self.texture_rgb = [3]float32{c[0], c[1], c[2]}
+	}
+}
+
+func (self *RenderTarget) IsLoading() bool {
+	return false
+}
+
+func (self *RenderTarget) IsLoaded() bool {
+	return false // nothing to be loaded
+}
+
+func (self *RenderTarget) IsReady() bool {
+	return self.color_texture != nil && self.err == nil
+}
+
+func (self *RenderTarget) Retain() {
+	// DO NOTHING, since it's owned by its creator (not by the SceneObjects using its color texture)
+}
+
+func (self *RenderTarget) Release() bool {
+	return false // (it's never disposed by the SceneObjects, while Renderers may still render into it)
+}
+
+func (self *RenderTarget) Dispose(rc GLRenderingContext) {
+	// Delete the framebuffer with its color texture & depth buffer (it cannot be used any more)
+	if self.framebuffer != nil {
+		rc.GLDeleteFramebuffer(self.framebuffer)
+		rc.GLDeleteTexture(self.color_texture)
+		rc.GLDeleteRenderbuffer(self.depth_buffer)
+		self.framebuffer, self.color_texture, self.depth_buffer = nil, nil, nil
+	}
+}
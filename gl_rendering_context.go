@@ -17,16 +17,31 @@ type GLRenderingContext interface {
 	CreateShader(vertex_shader string, fragment_shader string) (GLShader, error)
 	// DataBuffer
 	CreateDataBufferVAO() *VAO
-	CreateVtxDataBuffer(data_slice []float32) interface{}
+	CreateVtxDataBuffer(data_slice []float32, usage uint32) interface{} // 'usage' : STATIC_DRAW, DYNAMIC_DRAW or STREAM_DRAW
 	CreateIdxDataBuffer(data_slice []uint32) interface{}
 
 	// Binding DataBuffer
 	GLBindBuffer(binding_target uint32, buffer interface{})
+	GLBufferData(binding_target uint32, data_slice []float32, usage uint32)          // replace the whole data of the bound buffer
+	GLBufferSubData(binding_target uint32, offset_in_byte int, data_slice []float32) // update a part of the bound buffer
+	GLBindVertexArray(vertex_array interface{})                                      // native VAO (ignored, if not supported)
 
 	// Binding Texture
 	GLActiveTexture(texture_unit int)
 	GLBindTexture(target uint32, texture interface{})
 
+	// Framebuffer (for rendering offscreen)
+	CreateFramebuffer() interface{}
+	CreateRenderbuffer() interface{}
+	CreateTexture(width int, height int) interface{} // empty RGBA texture, to be attached to a framebuffer
+	GLBindFramebuffer(target uint32, framebuffer interface{})
+	GLBindRenderbuffer(target uint32, renderbuffer interface{})
+	GLRenderbufferStorage(target uint32, internalformat uint32, width int, height int)
+	GLFramebufferTexture2D(target uint32, attachment uint32, textarget uint32, texture interface{}, level int)
+	GLFramebufferRenderbuffer(target uint32, attachment uint32, renderbuffertarget uint32, renderbuffer interface{})
+	GLCheckFramebufferStatus(target uint32) uint32
+	GLViewport(x int, y int, width int, height int)
+
 	// Binding Uniforms
 	GLUniform1i(location interface{}, v0 int)
 	GLUniform1f(location interface{}, v0 float32)
@@ -56,6 +71,17 @@ type GLRenderingContext interface {
 	GLDrawElements(mode uint32, count int, dtype uint32, offset int)
 	GLDrawElementsInstanced(mode uint32, element_count int, dtype uint32, offset int, pose_count int)
 
+	// Reading Pixels (from the framebuffer being drawn, with its first row at the bottom)
+	GLReadPixels(x int, y int, width int, height int, format uint32, dtype uint32, pixels []uint8)
+
+	// Deleting Resources (when they are disposed)
+	GLDeleteBuffer(buffer interface{})
+	GLDeleteVertexArray(vertex_array interface{})
+	GLDeleteTexture(texture interface{})
+	GLDeleteProgram(program interface{})
+	GLDeleteFramebuffer(framebuffer interface{})
+	GLDeleteRenderbuffer(renderbuffer interface{})
+
 	// WebGL Extensions
 	SetupExtension(extname string)
 	IsExtensionReady(extname string) bool
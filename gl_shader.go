@@ -16,6 +16,11 @@ type GLShader interface {
 	IsReady() bool
 	GetErr() error // returns any error during the creator function
 
+	// releasing the shader program (shared by SceneObjects, with reference counting)
+	Retain()       // called by each user (SceneObject) of the shader
+	Release() bool // called by each user, and returns true if it was the last user
+	Dispose()      // delete the shader program right away
+
 	// setting up shader bindings
 	SetBindingForUniform(btype cst.BindType, name string, target any)
 	SetBindingForAttribute(btype cst.BindType, name string, target any)
@@ -104,6 +109,7 @@ func (self *GLShaderBinder) SetBindingForAttribute(btype cst.BindType, name stri
 		case "geometry.coords": // point coordinates
 		case "geometry.textuv": // texture UV coordinates
 		case "geometry.normal": // (3D only) normal vector
+		case "geometry.tangent": // (3D only) tangent vector, with the sign of bitangent as its 'w'
 		case "instance.pose", "instance.color": // instance pose or color, like "instance.pose:<stride>:<offset>"
 			if len(starget_split) != 3 {
 				common.Logger.Warn("Failed to SetBindingForAttribute('%s') : try 'instance.pose:<stride>:<offset>'\n", name)
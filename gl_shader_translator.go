@@ -0,0 +1,306 @@
+package gigl
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ----------------------------------------------------------------------------
+// Shader Translator (GLSL ES 1.00 => GLSL 330 / 410 / ES 3.00)
+// ----------------------------------------------------------------------------
+
+// All the shaders in this package are written in GLSL ES 1.00 (for WebGL 1.0).
+// TranslateShaderSource() translates them token by token, so that identifiers & comments
+// containing words like 'attribute' or 'texture2D' are left untouched,
+// and it keeps the line mapping to report compile errors with the original line numbers.
+
+const (
+	GLSL_330    = "330"    // OpenGL 3.3 core
+	GLSL_410    = "410"    // OpenGL 4.1 core (MacOS)
+	GLSL_ES_300 = "300 es" // WebGL 2.0
+)
+
+type ShaderTranslation struct {
+	Source   string // translated source code
+	Version  string // target GLSL version
+	line_map []int  // original line number for each line of the translated source (0 for added lines)
+}
+
+// TranslateShaderSource translates GLSL ES 1.00 shader source
+// for the given stage ("vertex" or "fragment") and the target version.
+func TranslateShaderSource(source string, stage string, version string) (*ShaderTranslation, error) {
+	if stage != "vertex" && stage != "fragment" {
+		return nil, fmt.Errorf("Failed to translate shader : invalid stage '%s'", stage)
+	}
+	if version != GLSL_330 && version != GLSL_410 && version != GLSL_ES_300 {
+		return nil, fmt.Errorf("Failed to translate shader : invalid version '%s'", version)
+	}
+	tokens, err := tokenize_glsl_source(source)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to translate %s shader : %v", stage, err)
+	}
+	uses_frag_color, has_float_precision, last_extension_line := false, false, 0
+	for i := 0; i < len(tokens); i++ {
+		t := &tokens[i]
+		switch t.kind {
+		case glsl_directive:
+			switch get_glsl_directive_name(t.text) {
+			case "version": // replaced by the new '#version' on the first line
+				t.text = strings.Repeat("\n", strings.Count(t.text, "\n"))
+			case "extension":
+				if fields := strings.Fields(strings.ReplaceAll(t.text[1:], ":", " ")); len(fields) > 1 && glsl_core_extensions[fields[1]] {
+					t.text = strings.Repeat("\n", strings.Count(t.text, "\n")) // already a part of the core
+				} else {
+					last_extension_line = t.line + strings.Count(t.text, "\n")
+				}
+			}
+		case glsl_identifier:
+			switch t.text {
+			case "attribute":
+				if stage == "vertex" {
+					t.text = "in"
+				}
+			case "varying":
+				if stage == "vertex" {
+					t.text = "out"
+				} else {
+					t.text = "in"
+				}
+			case "gl_FragColor":
+				t.text = "gigl_FragColor"
+				uses_frag_color = true
+			case "gl_FragData":
+				return nil, fmt.Errorf("Failed to translate %s shader : 'gl_FragData' is not supported (line %d)", stage, t.line)
+			case "precision":
+				if q := next_glsl_identifier(tokens, i+1); q >= 0 { // 'precision mediump float;'
+					if d := next_glsl_identifier(tokens, q+1); d >= 0 && tokens[d].text == "float" {
+						has_float_precision = true
+					}
+				}
+			default:
+				if renamed, ok := glsl_renamed_functions[t.text]; ok {
+					t.text = renamed
+				} else if glsl_reserved_words[t.text] {
+					t.text = TranslateShaderIdentifier(t.text)
+				}
+			}
+		}
+	}
+	// add the new '#version' and the declarations right after the extension directives
+	source_lines := strings.Split(join_glsl_tokens(tokens), "\n")
+	declarations := []string{}
+	if stage == "fragment" && version == GLSL_ES_300 && !has_float_precision {
+		declarations = append(declarations, "precision mediump float;")
+	}
+	if uses_frag_color {
+		if version == GLSL_ES_300 {
+			declarations = append(declarations, "out mediump vec4 gigl_FragColor;")
+		} else {
+			declarations = append(declarations, "out vec4 gigl_FragColor;")
+		}
+	}
+	self := ShaderTranslation{Version: version}
+	lines := make([]string, 0, len(source_lines)+len(declarations)+1)
+	lines = append(lines, "#version "+version)
+	self.line_map = append(self.line_map, 0)
+	for i, line := range source_lines {
+		if i == last_extension_line {
+			for _, declaration := range declarations {
+				lines = append(lines, declaration)
+				self.line_map = append(self.line_map, 0)
+			}
+		}
+		lines = append(lines, line)
+		self.line_map = append(self.line_map, i+1)
+	}
+	self.Source = strings.Join(lines, "\n")
+	return &self, nil
+}
+
+// TranslateShaderIdentifier returns the name of the identifier in the translated source.
+// Identifiers that are reserved in the newer versions (like 'sample' or 'texture') are renamed,
+// so the backends should use it to find the location of uniforms & attributes.
+func TranslateShaderIdentifier(name string) string {
+	if glsl_reserved_words[name] {
+		return "gigl_" + name
+	}
+	return name
+}
+
+func (self *ShaderTranslation) GetOriginalLine(line int) int {
+	// Original line number for the given line (1-based) of the translated source (0 if added)
+	if line < 1 || line > len(self.line_map) {
+		return 0
+	}
+	return self.line_map[line-1]
+}
+
+var glsl_error_line_regexp = regexp.MustCompile(`\b0([:(])(\d+)\b`)
+
+// MapErrorLog replaces the line numbers in the compile error log with the original ones.
+// (like "ERROR: 0:12: ..." of ANGLE/Mesa, or "0(12) : error ..." of NVIDIA)
+func (self *ShaderTranslation) MapErrorLog(log string) string {
+	return glsl_error_line_regexp.ReplaceAllStringFunc(log, func(s string) string {
+		m := glsl_error_line_regexp.FindStringSubmatch(s)
+		line, _ := strconv.Atoi(m[2])
+		if original := self.GetOriginalLine(line); original > 0 {
+			return "0" + m[1] + strconv.Itoa(original)
+		}
+		return s
+	})
+}
+
+// ----------------------------------------------------------------------------
+// Tokenizer
+// ----------------------------------------------------------------------------
+
+const (
+	glsl_space      = iota // whitespaces & newlines
+	glsl_comment           // '// ...' or '/* ... */'
+	glsl_directive         // '#...' to the end of the line
+	glsl_identifier        // keywords & identifiers
+	glsl_other             // numbers & operators
+)
+
+type glsl_token struct {
+	kind int
+	text string
+	line int // 1-based line number in the original source
+}
+
+func tokenize_glsl_source(source string) ([]glsl_token, error) {
+	tokens := []glsl_token{}
+	line, line_start := 1, true
+	for pos := 0; pos < len(source); {
+		start, c, kind := pos, source[pos], glsl_other
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			for pos < len(source) && strings.IndexByte(" \t\r\n", source[pos]) >= 0 {
+				pos++
+			}
+			kind = glsl_space
+		case strings.HasPrefix(source[pos:], "//"):
+			for pos < len(source) && source[pos] != '\n' {
+				pos++
+			}
+			kind = glsl_comment
+		case strings.HasPrefix(source[pos:], "/*"):
+			end := strings.Index(source[pos+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment (line %d)", line)
+			}
+			pos += 2 + end + 2
+			kind = glsl_comment
+		case c == '#' && line_start:
+			for pos < len(source) && source[pos] != '\n' {
+				if source[pos] == '\\' && pos+1 < len(source) && source[pos+1] == '\n' {
+					pos++ // line continuation
+				}
+				pos++
+			}
+			kind = glsl_directive
+		case is_glsl_identifier_char(c) && !(c >= '0' && c <= '9'):
+			for pos < len(source) && is_glsl_identifier_char(source[pos]) {
+				pos++
+			}
+			kind = glsl_identifier
+		case c >= '0' && c <= '9' || c == '.':
+			for pos < len(source) && (is_glsl_identifier_char(source[pos]) || source[pos] == '.') {
+				pos++
+			}
+		default:
+			pos++
+		}
+		text := source[start:pos]
+		tokens = append(tokens, glsl_token{kind: kind, text: text, line: line})
+		if kind == glsl_space {
+			line_start = line_start || strings.Contains(text, "\n")
+		} else if kind != glsl_comment {
+			line_start = false
+		}
+		line += strings.Count(text, "\n")
+	}
+	return tokens, nil
+}
+
+func join_glsl_tokens(tokens []glsl_token) string {
+	var sb strings.Builder
+	for _, t := range tokens {
+		sb.WriteString(t.text)
+	}
+	return sb.String()
+}
+
+func is_glsl_identifier_char(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func get_glsl_directive_name(text string) string {
+	fields := strings.Fields(strings.TrimPrefix(text, "#"))
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+func next_glsl_identifier(tokens []glsl_token, index int) int {
+	// Skip whitespaces & comments, and return the index of the next token if it's an identifier (or -1)
+	for ; index < len(tokens); index++ {
+		switch tokens[index].kind {
+		case glsl_space, glsl_comment:
+			continue
+		case glsl_identifier:
+			return index
+		default:
+			return -1
+		}
+	}
+	return -1
+}
+
+// ----------------------------------------------------------------------------
+// Translation Tables
+// ----------------------------------------------------------------------------
+
+var glsl_renamed_functions = map[string]string{
+	"texture2D":         "texture",
+	"texture2DProj":     "textureProj",
+	"texture2DLod":      "textureLod",
+	"texture2DProjLod":  "textureProjLod",
+	"textureCube":       "texture",
+	"textureCubeLod":    "textureLod",
+	"texture2DLodEXT":   "textureLod", // GL_EXT_shader_texture_lod
+	"textureCubeLodEXT": "textureLod",
+	"gl_FragDepthEXT":   "gl_FragDepth", // GL_EXT_frag_depth
+}
+
+var glsl_core_extensions = map[string]bool{
+	"GL_OES_standard_derivatives": true,
+	"GL_EXT_shader_texture_lod":   true,
+	"GL_EXT_frag_depth":           true,
+}
+
+// Identifiers valid in GLSL ES 1.00, but reserved as keywords or built-in functions in newer versions
+var glsl_reserved_words = map[string]bool{
+	// keywords
+	"layout": true, "centroid": true, "smooth": true, "noperspective": true, "case": true,
+	"patch": true, "sample": true, "subroutine": true, "precise": true, "resource": true,
+	"uint": true, "uvec2": true, "uvec3": true, "uvec4": true,
+	"sampler2DArray": true, "sampler2DArrayShadow": true, "samplerCubeShadow": true, "samplerBuffer": true,
+	"isampler2D": true, "isampler3D": true, "isamplerCube": true, "isampler2DArray": true,
+	"usampler2D": true, "usampler3D": true, "usamplerCube": true, "usampler2DArray": true,
+	"sampler2DMS": true, "isampler2DMS": true, "usampler2DMS": true,
+	// built-in functions
+	"texture": true, "textureProj": true, "textureLod": true, "textureProjLod": true,
+	"textureOffset": true, "textureProjOffset": true, "textureLodOffset": true, "textureProjLodOffset": true,
+	"textureGrad": true, "textureGradOffset": true, "textureProjGrad": true, "textureProjGradOffset": true,
+	"textureSize": true, "texelFetch": true, "texelFetchOffset": true,
+	"round": true, "roundEven": true, "trunc": true, "modf": true, "isnan": true, "isinf": true,
+	"sinh": true, "cosh": true, "tanh": true, "asinh": true, "acosh": true, "atanh": true,
+	"floatBitsToInt": true, "floatBitsToUint": true, "intBitsToFloat": true, "uintBitsToFloat": true,
+	"packSnorm2x16": true, "unpackSnorm2x16": true, "packUnorm2x16": true, "unpackUnorm2x16": true,
+	"packHalf2x16": true, "unpackHalf2x16": true,
+	"outerProduct": true, "transpose": true, "determinant": true, "inverse": true,
+}
@@ -0,0 +1,153 @@
+package gigl
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTranslateShaderSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		stage    string
+		version  string
+		expected string // translated source (without the first '#version' line)
+	}{
+		{"attribute & varying in vertex shader", "attribute vec2 xy;\nvarying vec2 v_xy;", "vertex", GLSL_330,
+			"in vec2 xy;\nout vec2 v_xy;"},
+		{"varying in fragment shader", "varying vec2 v_xy;", "fragment", GLSL_410,
+			"in vec2 v_xy;"},
+		{"keywords inside identifiers", "attribute vec2 my_attribute_x;\nvarying float varying1;\nfloat attributes;", "vertex", GLSL_330,
+			"in vec2 my_attribute_x;\nout float varying1;\nfloat attributes;"},
+		{"texture2D inside identifiers", "vec4 c = texture2D(s, uv) + my_texture2D(uv) + texture2Dx;", "fragment", GLSL_330,
+			"vec4 c = texture(s, uv) + my_texture2D(uv) + texture2Dx;"},
+		{"line comment", "// attribute varying texture2D gl_FragColor\nattribute float a;", "vertex", GLSL_330,
+			"// attribute varying texture2D gl_FragColor\nin float a;"},
+		{"block comment", "/* varying\n   texture2D */ varying float v;", "vertex", GLSL_330,
+			"/* varying\n   texture2D */ out float v;"},
+		{"directive (string-like context)", "#pragma message(\"attribute varying texture2D\")\nattribute float a;", "vertex", GLSL_330,
+			"#pragma message(\"attribute varying texture2D\")\nin float a;"},
+		{"numbers", "float f = 1.0e2 + 2.5;", "vertex", GLSL_330,
+			"float f = 1.0e2 + 2.5;"},
+		{"reserved words renamed", "uniform sampler2D texture;\nfloat sample = round(1.0);", "fragment", GLSL_330,
+			"uniform sampler2D gigl_texture;\nfloat gigl_sample = gigl_round(1.0);"},
+		{"old version removed", "#version 100\nattribute float a;", "vertex", GLSL_330,
+			"\nin float a;"},
+		{"core extension removed", "#extension GL_OES_standard_derivatives : enable\nvarying float v;", "fragment", GLSL_330,
+			"\nin float v;"},
+		{"precision inserted for ES 3.00", "varying float v;", "fragment", GLSL_ES_300,
+			"precision mediump float;\nin float v;"},
+		{"precision kept for ES 3.00", "precision highp float;\nvarying float v;", "fragment", GLSL_ES_300,
+			"precision highp float;\nin float v;"},
+		{"precision not inserted for vertex shader", "attribute float a;", "vertex", GLSL_ES_300,
+			"in float a;"},
+		{"precision not inserted for GLSL 330", "varying float v;", "fragment", GLSL_330,
+			"in float v;"},
+		{"gl_FragColor for ES 3.00", "precision mediump float;\nvoid main() { gl_FragColor = vec4(1.0); }", "fragment", GLSL_ES_300,
+			"out mediump vec4 gigl_FragColor;\nprecision mediump float;\nvoid main() { gigl_FragColor = vec4(1.0); }"},
+		{"gl_FragColor for GLSL 410", "void main() { gl_FragColor = vec4(1.0); }", "fragment", GLSL_410,
+			"out vec4 gigl_FragColor;\nvoid main() { gigl_FragColor = vec4(1.0); }"},
+		{"declarations after extensions", "#extension GL_EXT_foo : enable\nvoid main() { gl_FragColor = vec4(1.0); }", "fragment", GLSL_ES_300,
+			"#extension GL_EXT_foo : enable\nprecision mediump float;\nout mediump vec4 gigl_FragColor;\nvoid main() { gigl_FragColor = vec4(1.0); }"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			translation, err := TranslateShaderSource(tt.source, tt.stage, tt.version)
+			if err != nil {
+				t.Fatalf("unexpected error : %v", err)
+			}
+			expected := "#version " + tt.version + "\n" + tt.expected
+			if translation.Source != expected {
+				t.Errorf("translated source\n got: %q\nwant: %q", translation.Source, expected)
+			}
+		})
+	}
+}
+
+func TestTranslateShaderSourceErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		stage   string
+		version string
+	}{
+		{"invalid stage", "void main() {}", "geometry", GLSL_330},
+		{"invalid version", "void main() {}", "vertex", "450"},
+		{"gl_FragData", "void main() { gl_FragData[0] = vec4(1.0); }", "fragment", GLSL_330},
+		{"unterminated comment", "/* attribute", "vertex", GLSL_330},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := TranslateShaderSource(tt.source, tt.stage, tt.version); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func TestTranslateShaderIdentifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"texture", "gigl_texture"},
+		{"sample", "gigl_sample"},
+		{"uint", "gigl_uint"},
+		{"my_texture", "my_texture"},
+		{"attribute", "attribute"},
+		{"xy", "xy"},
+	}
+	for _, tt := range tests {
+		if got := TranslateShaderIdentifier(tt.name); got != tt.expected {
+			t.Errorf("TranslateShaderIdentifier(%q) = %q, want %q", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestShaderTranslationMapErrorLog(t *testing.T) {
+	// Translated source of the fragment shader below (for GLSL ES 3.00) :
+	//   1: #version 300 es					(added)
+	//   2: 								(original line 1)
+	//   3: #extension GL_EXT_foo : enable	(original line 2)
+	//   4: precision mediump float;			(added)
+	//   5: out mediump vec4 gigl_FragColor;	(added)
+	//   6: in vec2 v_uv;					(original line 3)
+	//   7: void main() {					(original line 4)
+	//   8:   gigl_FragColor = undefined;	(original line 5)
+	//   9: }								(original line 6)
+	source := strings.Join([]string{
+		"#version 100",
+		"#extension GL_EXT_foo : enable",
+		"varying vec2 v_uv;",
+		"void main() {",
+		"  gl_FragColor = undefined;",
+		"}"}, "\n")
+	translation, err := TranslateShaderSource(source, "fragment", GLSL_ES_300)
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	tests := []struct {
+		name     string
+		log      string
+		expected string
+	}{
+		{"ANGLE/Mesa style", "ERROR: 0:8: 'undefined' : undeclared identifier", "ERROR: 0:5: 'undefined' : undeclared identifier"},
+		{"NVIDIA style", "0(8) : error C1008: undefined variable", "0(5) : error C1008: undefined variable"},
+		{"multiple lines", "ERROR: 0:6: a\nERROR: 0:9: b", "ERROR: 0:3: a\nERROR: 0:6: b"},
+		{"added line kept", "ERROR: 0:4: precision", "ERROR: 0:4: precision"},
+		{"out of range kept", "ERROR: 0:99: c", "ERROR: 0:99: c"},
+		{"other numbers kept", "ERROR: 1 compilation errors. 10:8 vec4", "ERROR: 1 compilation errors. 10:8 vec4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := translation.MapErrorLog(tt.log); got != tt.expected {
+				t.Errorf("MapErrorLog(%q)\n got: %q\nwant: %q", tt.log, got, tt.expected)
+			}
+		})
+	}
+	for line, expected := range map[int]int{0: 0, 1: 0, 2: 1, 3: 2, 4: 0, 5: 0, 6: 3, 8: 5, 9: 6, 10: 0} {
+		if got := translation.GetOriginalLine(line); got != expected {
+			t.Errorf("GetOriginalLine(%d) = %d, want %d", line, got, expected)
+		}
+	}
+}
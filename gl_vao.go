@@ -7,10 +7,14 @@ import "github.com/go4orward/gigl/common"
 // ----------------------------------------------------------------------------
 
 type VAO struct {
+	VertexArray interface{} // WebGL2/OpenGL native vertex array object (nil, if not supported)
+
 	VertBuffer     interface{} // WebGL/OpenGL buffer for geometry's vertex points
 	FvtxBuffer     interface{} // WebGL/OpenGL buffer for geometry's face vertex points (points for PER_FACE vertices)
-	VertBufferInfo [5]int      // [nverts, stride, coord_size, texture_uv_size, vertex_normal_size]
-	FvtxBufferInfo [5]int      // [nverts, stride, coord_size, texture_uv_size, vertex_normal_size]
+	VertBufferInfo [6]int      // [nverts, stride, coord_size, texture_uv_size, vertex_normal_size, tangent_size]
+	FvtxBufferInfo [6]int      // [nverts, stride, coord_size, texture_uv_size, vertex_normal_size, tangent_size]
+	VertBufferVer  int         // version of the vertex data uploaded (BufferTracker version)
+	FvtxBufferVer  int         // version of the vertex data uploaded (BufferTracker version)
 
 	EdgeBuffer      interface{} // WebGL/OpenGL buffer for geometry's edge indices
 	FaceBuffer      interface{} // WebGL/OpenGL buffer for geometry's face indices
@@ -19,6 +23,7 @@ type VAO struct {
 
 	InstanceBuffer     interface{} // WebGL/OpenGL buffer for geometry's instance values
 	InstanceBufferInfo [2]int      // [instance_count, instance_stride] of instance poses
+	InstanceBufferVer  int         // version of the instance data uploaded (BufferTracker version)
 }
 
 func (self *VAO) ShowInfo() {
@@ -30,8 +35,8 @@ func (self *VAO) ShowInfo() {
 		}
 	}
 	common.Logger.Trace("VAO\n")
-	common.Logger.Trace("  VertBuffer : %s  [ nverts:%d stride:%d coord:%d tuv:%d norm:%d ]\n", ox(self.VertBuffer), self.FvtxBufferInfo[0], self.VertBufferInfo[1], self.VertBufferInfo[2], self.VertBufferInfo[3], self.VertBufferInfo[4])
-	common.Logger.Trace("  FvtxBuffer : %s  [ nverts:%d stride:%d coord:%d tuv:%d norm:%d ]\n", ox(self.FvtxBuffer), self.FvtxBufferInfo[0], self.FvtxBufferInfo[1], self.FvtxBufferInfo[2], self.FvtxBufferInfo[3], self.FvtxBufferInfo[4])
+	common.Logger.Trace("  VertBuffer : %s  [ nverts:%d stride:%d coord:%d tuv:%d norm:%d tan:%d ]\n", ox(self.VertBuffer), self.FvtxBufferInfo[0], self.VertBufferInfo[1], self.VertBufferInfo[2], self.VertBufferInfo[3], self.VertBufferInfo[4], self.VertBufferInfo[5])
+	common.Logger.Trace("  FvtxBuffer : %s  [ nverts:%d stride:%d coord:%d tuv:%d norm:%d tan:%d ]\n", ox(self.FvtxBuffer), self.FvtxBufferInfo[0], self.FvtxBufferInfo[1], self.FvtxBufferInfo[2], self.FvtxBufferInfo[3], self.FvtxBufferInfo[4], self.FvtxBufferInfo[5])
 	common.Logger.Trace("  EdgeBuffer : %s  [ count:%d ]\n", ox(self.EdgeBuffer), self.EdgeBufferCount)
 	common.Logger.Trace("  FaceBuffer : %s  [ count:%d ]\n", ox(self.FaceBuffer), self.FaceBufferCount)
 	common.Logger.Trace("  InstanceBuffer : %s  [ count:%d stride:%d ]\n", ox(self.InstanceBuffer), self.InstanceBufferInfo[0], self.InstanceBufferInfo[1])
@@ -50,6 +55,9 @@ func (self *VAO) GetVtxBuffer(draw_mode int, xun int) (interface{}, [4]int) {
 		case 2: // vertex normal
 			nverts, stride, size, offset := pinfo[0], pinfo[1], pinfo[4], pinfo[2]+pinfo[3]
 			return self.FvtxBuffer, [4]int{nverts, stride, size, offset}
+		case 3: // tangent vector
+			nverts, stride, size, offset := pinfo[0], pinfo[1], pinfo[5], pinfo[2]+pinfo[3]+pinfo[4]
+			return self.FvtxBuffer, [4]int{nverts, stride, size, offset}
 		default:
 			common.Logger.Error("invalid 'xun' (%d) in VAO.GetVtxBuffer()\n", xun)
 			return nil, [4]int{0, 0, 0, 0} // nverts, stride, size, offset
@@ -66,6 +74,9 @@ func (self *VAO) GetVtxBuffer(draw_mode int, xun int) (interface{}, [4]int) {
 		case 2: // vertex normal
 			nverts, stride, size, offset := pinfo[0], pinfo[1], pinfo[4], pinfo[2]+pinfo[3]
 			return self.VertBuffer, [4]int{nverts, stride, size, offset}
+		case 3: // tangent vector
+			nverts, stride, size, offset := pinfo[0], pinfo[1], pinfo[5], pinfo[2]+pinfo[3]+pinfo[4]
+			return self.VertBuffer, [4]int{nverts, stride, size, offset}
 		default:
 			common.Logger.Error("invalid 'xun' (%d) in VAO.GetVtxBuffer()\n", xun)
 			return nil, [4]int{0, 0, 0, 0} // nverts, stride, size, offset
@@ -89,3 +100,28 @@ func (self *VAO) GetInstanceBuffer() (interface{}, [2]int) {
 	count, stride := self.InstanceBufferInfo[0], self.InstanceBufferInfo[1]
 	return self.VertBuffer, [2]int{count, stride}
 }
+
+func (self *VAO) Dispose(rc GLRenderingContext) {
+	// Delete all the buffers, so that they can be created again (if rendered again)
+	self.DisposeGeometryBuffers(rc)
+	if self.InstanceBuffer != nil {
+		rc.GLDeleteBuffer(self.InstanceBuffer)
+	}
+	if self.VertexArray != nil {
+		rc.GLDeleteVertexArray(self.VertexArray)
+	}
+	*self = VAO{}
+}
+
+func (self *VAO) DisposeGeometryBuffers(rc GLRenderingContext) {
+	// Delete the buffers of the geometry only (when its data buffers were rebuilt)
+	for _, buffer := range []interface{}{self.VertBuffer, self.FvtxBuffer, self.EdgeBuffer, self.FaceBuffer} {
+		if buffer != nil {
+			rc.GLDeleteBuffer(buffer)
+		}
+	}
+	self.VertBuffer, self.VertBufferInfo, self.VertBufferVer = nil, [6]int{}, 0
+	self.FvtxBuffer, self.FvtxBufferInfo, self.FvtxBufferVer = nil, [6]int{}, 0
+	self.EdgeBuffer, self.EdgeBufferCount = nil, 0
+	self.FaceBuffer, self.FaceBufferCount = nil, 0
+}
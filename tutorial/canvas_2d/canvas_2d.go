@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/go4orward/gigl"
+	"github.com/go4orward/gigl/common"
+	"github.com/go4orward/gigl/env"
+	"github.com/go4orward/gigl/g2d"
+)
+
+func init() { // This is needed to let main() run on the startup thread (for OpenGL).
+	runtime.LockOSThread() // Ref: https://golang.org/pkg/runtime/#LockOSThread
+}
+
+type Config struct {
+	loglevel  string //
+	logfilter string //
+}
+
+func main() {
+	cfg := Config{loglevel: "info", logfilter: ""}
+	if cfg.loglevel != "" {
+		common.SetLogger(common.NewConsoleLogger(cfg.loglevel)).SetTraceFilter(cfg.logfilter).SetOption("", false)
+	}
+	// THE SAME CODE CAN BE BUILT FOR BOTH WEBGL AND OPENGL ENVIRONMENTS.
+	// BUILD IT LIKE 'GOOS=js GOARCH=wasm go build -o example.wasm .' (WebGL), or 'go build .' (OpenGL).
+	canvas, err := env.NewCanvas("wasmcanvas", 1200, 900, "Canvas2D: Triangle on any Canvas", true)
+	if err != nil {
+		common.Logger.Error("Failed to create canvas : %v\n", err)
+		return
+	}
+	rc := canvas.GetRenderingContext()
+	geometry := g2d.NewGeometryTriangle(0.5)                          // create geometry (a triangle with radius 0.5)
+	geometry.BuildDataBuffers(true, true, true)                       // build data buffers for vertices, edges and faces
+	mcolors := g2d.NewMaterialColors("#bbbbff", "#bbbbff", "#0000ff") // create material (with light-blue color)
+	shader := g2d.NewShaderForMaterialColors(rc)                      // shader with auto-binded color & PVM matrix
+	scnobj := g2d.NewSceneObject(geometry, mcolors, nil, shader, shader).Rotate(40)
+	scene := g2d.NewScene("#ffffff").Add(scnobj) // scene holds all the SceneObjects to be rendered
+	camera := g2d.NewCamera(rc.GetWH(), 2, 1)    // FOV 2 means range of [-1,+1] in X, ZoomLevel is 1.0
+	renderer := g2d.NewRenderer(rc)              // set up the renderer
+
+	SetUIEventHandlers(canvas, camera)
+
+	// run UI animation loop
+	canvas.Run(func(now float64) {
+		renderer.Clear(scene)               // prepare to render (clearing to white background)
+		renderer.RenderScene(scene, camera) // render the scene (iterating over all the SceneObjects in it)
+		renderer.RenderAxes(camera, 1.0)    // render the axes (just for visual reference)
+	})
+}
+
+func SetUIEventHandlers(canvas gigl.GLCanvas, camera *g2d.Camera) {
+	// set up user interactions (with the same semantics on both WebGL and OpenGL canvases)
+	canvas.SetEventHandlerForClick(func(canvasxy [2]int, keystat [4]bool) {
+		wxy := camera.UnprojectCanvasToWorld(canvasxy)
+		common.Logger.Info("canvas (%d %d)  world (%.2f %.2f)\n", canvasxy[0], canvasxy[1], wxy[0], wxy[1])
+	})
+	canvas.SetEventHandlerForDoubleClick(func(canvasxy [2]int, keystat [4]bool) {
+		fmt.Println(camera.Summary())
+	})
+	canvas.SetEventHandlerForMouseDrag(func(canvasxy [2]int, dxy [2]int, keystat [4]bool) {
+		wdxy := camera.UnprojectCanvasDeltaToWorld(dxy)
+		camera.Translate(-wdxy[0], -wdxy[1])
+	})
+	canvas.SetEventHandlerForZoom(func(canvasxy [2]int, scale float32, keystat [4]bool) {
+		camera.SetZoom(scale) // 'scale' in [ 0.01 ~ 1(default) ~ 100.0 ]
+	})
+	canvas.SetEventHandlerForWindowResize(func(w int, h int) {
+		camera.SetAspectRatio(w, h)
+	})
+	canvas.SetEventHandlerForKeyPress(func(key string, code string, keystat [4]bool) {
+		common.Logger.Info("keypress : key='%v' code='%v'  keystat=%v\n", key, code, keystat)
+	})
+	common.Logger.Info("Try mouse drag & wheel with SHIFT key pressed")
+}
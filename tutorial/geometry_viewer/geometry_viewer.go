@@ -6,11 +6,16 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/go4orward/gigl/common"
 	opengl "github.com/go4orward/gigl/env/opengl41"
 	"github.com/go4orward/gigl/g2d"
 	"github.com/go4orward/gigl/g3d"
+	"github.com/go4orward/gigl/g3d/gltf"
+	"github.com/go4orward/gigl/g3d/obj"
+	"github.com/go4orward/gigl/g3d/stl"
 )
 
 type Config struct {
@@ -24,7 +29,7 @@ func main() {
 	flag.StringVar(&cfg.logfilter, "F", cfg.logfilter, "log filter for trace log messages")
 	flag.Parse()
 	if len(flag.Args()) < 1 {
-		fmt.Printf("Usage:  geometry_viewer  INPUT_FILE\n")
+		fmt.Printf("Usage:  geometry_viewer  INPUT_FILE  (.obj, .gltf, .glb, .stl, or 'cube', 'sphere', 'cylinder')\n")
 		flag.PrintDefaults()
 		os.Exit(0)
 	}
@@ -33,6 +38,9 @@ func main() {
 	}
 	input_geometry := flag.Arg(0)
 	var geometry *g3d.Geometry = nil
+	var model *obj.Model = nil
+	var gmodel *gltf.Model = nil
+	var err error
 	switch input_geometry {
 	case "cube":
 		geometry = g3d.NewGeometryCube(1, 1, 1)
@@ -41,8 +49,29 @@ func main() {
 	case "cylinder":
 		geometry = g3d.NewGeometryCylinder(8, 1.0, 3.0, 0, true)
 	default:
-		geometry = g3d.NewGeometryCylinder(8, 1.0, 3.0, 0, true)
-		// TODO(go4orward) - load models from OBJ or STL file format
+		switch strings.ToLower(filepath.Ext(input_geometry)) {
+		case ".obj":
+			if model, err = obj.LoadFile(input_geometry); err != nil {
+				log.Fatal(err)
+			} else if len(model.Groups) == 0 {
+				log.Fatal(errors.New("No geometry found in " + input_geometry))
+			}
+			common.Logger.Info("%s", model.Summary())
+		case ".gltf", ".glb":
+			if gmodel, err = gltf.LoadFile(input_geometry); err != nil {
+				log.Fatal(err)
+			} else if len(gmodel.Nodes) == 0 {
+				log.Fatal(errors.New("No node found in " + input_geometry))
+			}
+			common.Logger.Info("%s", gmodel.Summary())
+		case ".stl":
+			if geometry, err = stl.LoadFile(input_geometry); err != nil {
+				log.Fatal(err)
+			}
+			common.Logger.Info("STL %s", geometry.String())
+		default:
+			geometry = g3d.NewGeometryCylinder(8, 1.0, 3.0, 0, true)
+		}
 	}
 
 	// TODO(go4orward) - crashes on OpenGL on Mac
@@ -53,12 +82,20 @@ func main() {
 	}
 	rc := canvas.GetRenderingContext()
 
-	geometry.BuildNormalsForFace()               // calculate normal vectors for each face
-	geometry.BuildDataBuffers(true, false, true) // build data buffers for vertices and faces
-	material := g2d.NewMaterialColors("#ffffff") // create material (with texture image)
-	shader := g3d.NewShader_NormalColor(rc)      // use the standard NORMAL+Color shader
 	scene := g3d.NewScene("#000000")
-	scene.Add(g3d.NewSceneObject(geometry, material, nil, nil, shader))
+	if model != nil {
+		scene.Add(model.NewSceneObject(rc)) // SceneObject for each group, with materials from MTL files
+	} else if gmodel != nil {
+		scene.Add(gmodel.NewSceneObject(rc)) // SceneObject for each node, keeping the hierarchy of glTF scene
+	} else {
+		if !geometry.HasNormalFor("FACE") {
+			geometry.BuildNormalsForFace() // calculate normal vectors for each face
+		}
+		geometry.BuildDataBuffers(true, false, true) // build data buffers for vertices and faces
+		material := g2d.NewMaterialColors("#ffffff") // create material (with texture image)
+		shader := g3d.NewShader_NormalColor(rc)      // use the standard NORMAL+Color shader
+		scene.Add(g3d.NewSceneObject(geometry, material, nil, nil, shader))
+	}
 	// llayer := g3d.NewOverlayLabelLayer(rc, 20, true).AddLabelsForTest()
 	// mlayer := g3d.NewOverlayMarkerLayer(rc).AddMarkersForTest()
 	// scene.AddOverlay(mlayer)
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"image/png"
+	"log"
+	"os"
+
+	"github.com/go4orward/gigl/common"
+	"github.com/go4orward/gigl/env/software"
+	"github.com/go4orward/gigl/g2d"
+	"github.com/go4orward/gigl/g3d"
+)
+
+type Config struct {
+	loglevel  string //
+	logfilter string //
+	output    string // path of the PNG image to be saved
+}
+
+func main() {
+	cfg := Config{loglevel: "info", logfilter: "", output: "software_3d.png"}
+	if cfg.loglevel != "" {
+		common.SetLogger(common.NewConsoleLogger(cfg.loglevel)).SetTraceFilter(cfg.logfilter).SetOption("", false)
+	}
+	rc := software.NewSoftwareRenderingContext(600, 450) // no window or GPU is needed
+	scene := g3d.NewScene("#ffffff")                     // Scene with WHITE background
+
+	geometry := g3d.NewGeometryCubeWithTexture(1, 1, 1)       // create geometry (a cube of size 1.0)
+	geometry.BuildNormalsForFace()                            // calculate normal vectors for each face
+	geometry.BuildDataBuffers(true, false, true)              // build data buffers for vertices and faces
+	material := g2d.NewMaterialTexture("./assets/gopher.png") // create material (with texture image)
+	shader := g3d.NewShader_NormalTexture(rc)                 // use the standard NORMAL+TEXTURE shader
+
+	scene.Add(g3d.NewSceneObject(geometry, material, nil, nil, shader))
+	scene.Get(0).Rotate([3]float32{0, 1, 1}, 30.0)
+	cam_ip := g3d.CamInternalParams{WH: rc.GetWH(), Fov: 15, Zoom: 1.0, NearFar: [2]float32{1, 100}}
+	cam_ep := g3d.CamExternalPose{From: [3]float32{0, 0, 10}, At: [3]float32{0, 0, 0}, Up: [3]float32{0, 1, 0}}
+	camera := g3d.NewCamera(true, &cam_ip, &cam_ep)
+	renderer := g3d.NewRenderer(rc) // set up the renderer
+
+	renderer.Clear(scene)               // prepare to render (clearing to white background)
+	renderer.RenderScene(scene, camera) // render the scene (iterating over all the SceneObjects in it)
+	renderer.RenderAxes(camera, 1.0)    // render the axes (just for visual reference)
+
+	file, err := os.Create(cfg.output) // save the rendered image as a PNG file
+	if err != nil {
+		log.Fatal(errors.New("Failed to create output file : " + err.Error()))
+	}
+	defer file.Close()
+	if err := png.Encode(file, rc.GetImage()); err != nil {
+		log.Fatal(errors.New("Failed to encode PNG image : " + err.Error()))
+	}
+	common.Logger.Info("Rendered image saved to %q\n", cfg.output)
+}